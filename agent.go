@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"tenantsdb-bench/bench"
+	"tenantsdb-bench/ms"
+	"tenantsdb-bench/my"
+	"tenantsdb-bench/pg"
+)
+
+// agentJob is one agent's shard of a distributed run. Proxy carries no
+// password; each agent has its own.
+type agentJob struct {
+	DB       string            `json:"db"`
+	Test     string            `json:"test"`
+	Proxy    bench.ConnConfig  `json:"proxy"`
+	Params   bench.BenchParams `json:"params"`
+	Settings agentSettings     `json:"settings"`
+	StartAt  time.Time         `json:"start_at"` // agents sleep until then so their windows line up
+}
+
+// agentSettings are the coordinator's reporting flags, which the bench
+// package keeps process-wide, so every agent measures the same way.
+type agentSettings struct {
+	Window      string          `json:"window"`
+	Percentiles []float64       `json:"percentiles,omitempty"`
+	SLO         []time.Duration `json:"slo,omitempty"`
+	Trim        float64         `json:"trim,omitempty"`
+}
+
+func currentSettings() agentSettings {
+	return agentSettings{Window: bench.WindowPolicy, Percentiles: bench.Percentiles, SLO: bench.SLOBounds, Trim: bench.TrimPct}
+}
+
+func (s agentSettings) apply() {
+	bench.WindowPolicy, bench.Percentiles, bench.SLOBounds, bench.TrimPct = s.Window, s.Percentiles, s.SLO, s.Trim
+}
+
+// agentReport is what an agent sends back for its shard.
+type agentReport struct {
+	Host  string             `json:"host"`
+	Stats []bench.BenchStats `json:"stats,omitempty"`
+	Hist  *bench.Histogram   `json:"histogram,omitempty"`
+	Err   string             `json:"error,omitempty"`
+}
+
+// distributedTests are the tests -agents can shard.
+var distributedTests = map[string]bool{"throughput": true}
+
+// runAgent implements `tdb-bench agent -listen :7070`: wait for a
+// coordinator's `run -agents` to hand over a shard, run it, and send back
+// its stats and latency histogram. Jobs must carry the agent's -token.
+func runAgent(args []string) {
+	cmd := newFlagSet("agent", "-token <secret> [-listen :7070] [-proxy-pass <pass> | -password-file <file>]")
+	listen := cmd.String("listen", ":7070", "Address to accept coordinator jobs on")
+	token := cmd.String("token", os.Getenv("TDB_BENCH_TOKEN"), "Shared secret coordinators must send (default $TDB_BENCH_TOKEN)")
+	proxyPass := cmd.String("proxy-pass", "", "Proxy password for jobs (default $TDB_PROXY_PASS, else -password-file)")
+	passwordFile := cmd.String("password-file", "", "File with a TDB_PROXY_PASS= line (or the password alone)")
+	logs := addLogFlags(cmd)
+	cmd.Parse(args)
+	logs.apply()
+
+	if *token == "" {
+		fail("agent needs -token (or $TDB_BENCH_TOKEN), shared with the coordinator's -agent-token")
+	}
+	password := *proxyPass
+	if password == "" {
+		password = os.Getenv("TDB_PROXY_PASS")
+	}
+	if password == "" && *passwordFile != "" {
+		pw, err := readPasswordFile(*passwordFile)
+		if err != nil {
+			fail("%v", err)
+		}
+		password = pw["TDB_PROXY_PASS"]
+	}
+
+	host, _ := os.Hostname()
+	var busy sync.Mutex
+	http.HandleFunc("/run", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "POST a job", http.StatusMethodNotAllowed)
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+*token)) != 1 {
+			http.Error(w, "missing or wrong bearer token", http.StatusUnauthorized)
+			return
+		}
+		if !busy.TryLock() {
+			http.Error(w, "agent is already running a job", http.StatusConflict)
+			return
+		}
+		defer busy.Unlock()
+
+		var job agentJob
+		if err := json.NewDecoder(r.Body).Decode(&job); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		report := agentReport{Host: host}
+		switch {
+		case !distributedTests[job.Test]:
+			report.Err = "test " + job.Test + " cannot run on an agent"
+		case job.Proxy.DSN != "":
+			report.Err = "jobs name the proxy by host and port, not a DSN"
+		default:
+			job.Proxy.Password = password
+			job.Settings.apply()
+			bench.Stepf("Job from %s: %s %s, %d workers, starting %s",
+				r.RemoteAddr, job.DB, job.Test, job.Params.Concurrency, job.StartAt.Local().Format("15:04:05"))
+			bench.Sleep(r.Context(), time.Until(job.StartAt))
+			bench.StartHistogram()
+			res := runTest(r.Context(), job.DB, job.Test, job.Proxy, bench.ConnConfig{}, job.Params, nil)
+			report.Hist = bench.StopHistogram()
+			if res == nil {
+				report.Err = "test did not complete"
+			} else {
+				report.Stats = res.Stats
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(report)
+	})
+
+	bench.Infof("Agent %s listening on %s", host, *listen)
+	if err := http.ListenAndServe(*listen, nil); err != nil {
+		fail("agent: %v", err)
+	}
+}
+
+// runDistributed is the coordinator side of `run -agents`: seed once, split
+// the concurrency (and query count) across the agents, start them together
+// and merge what they send back.
+func runDistributed(ctx context.Context, agents []string, token, dbType, test string, proxyCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
+	bench.Stepf("Distributed %s across %d agents", test, len(agents))
+
+	bench.Stepf("[1/3] Seeding test data...")
+	seed := pg.RunSeed
+	switch dbType {
+	case "mysql":
+		seed = my.RunSeed
+	case "mssql":
+		seed = ms.RunSeed
+	}
+	if err := seed(proxyCfg, params); err != nil {
+		bench.Failf("Seed failed: %v", err)
+		return nil
+	}
+	bench.Okf("Data ready")
+	params.Reseed = false
+
+	bench.Stepf("[2/3] Dispatching to %d agents...", len(agents))
+	start := time.Now().Add(3 * time.Second)
+	shared := proxyCfg
+	shared.Password = "" // agents use their own
+	settings := currentSettings()
+	reports := make([]agentReport, len(agents))
+	var wg sync.WaitGroup
+	for i, addr := range agents {
+		p := params
+		_, p.Concurrency = bench.Share(params.Concurrency, len(agents), i)
+		_, p.Queries = bench.Share(params.Queries, len(agents), i)
+		_, p.AggWorkers = bench.Share(params.AggWorkers, len(agents), i)
+		p.Runs, p.TenantPassword = 0, ""
+		job := agentJob{DB: dbType, Test: test, Proxy: shared, Params: p, Settings: settings, StartAt: start}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reports[i] = dispatch(ctx, addr, token, job)
+			if reports[i].Host == "" {
+				reports[i].Host = addr
+			}
+		}()
+	}
+	wg.Wait()
+
+	bench.Stepf("[3/3] Merging results...")
+	var parts []bench.BenchStats
+	merged := bench.NewHistogram()
+	for i, rep := range reports {
+		if rep.Err == "" && len(rep.Stats) == 0 {
+			rep.Err = "no stats returned"
+		}
+		if rep.Err != "" {
+			bench.Failf("%s (%s): %s", agents[i], rep.Host, rep.Err)
+			continue
+		}
+		st := rep.Stats[0]
+		st.Label = rep.Host
+		bench.Okf("%s: QPS=%.1f  p50=%s  p99=%s  errors=%d",
+			rep.Host, st.QPS, bench.FmtDur(st.LatencyP50), bench.FmtDur(st.LatencyP99), st.Errors)
+		parts = append(parts, st)
+		merged.Merge(rep.Hist)
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+	if len(parts) < len(agents) {
+		bench.Warnf("Only %d/%d agents reported; totals cover those", len(parts), len(agents))
+	}
+
+	label := fmt.Sprintf("Throughput (%d agents)", len(parts))
+	stats := bench.MergeStats(label, parts, merged)
+	bench.PrintStats(stats)
+	return &bench.Result{Stats: []bench.BenchStats{stats}, Agents: parts}
+}
+
+func dispatch(ctx context.Context, addr, token string, job agentJob) agentReport {
+	body, err := json.Marshal(job)
+	if err != nil {
+		return agentReport{Err: err.Error()}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://"+addr+"/run", bytes.NewReader(body))
+	if err != nil {
+		return agentReport{Err: err.Error()}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return agentReport{Err: err.Error()}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var msg bytes.Buffer
+		msg.ReadFrom(resp.Body)
+		return agentReport{Err: fmt.Sprintf("%s: %s", resp.Status, bytes.TrimSpace(msg.Bytes()))}
+	}
+	var rep agentReport
+	if err := json.NewDecoder(resp.Body).Decode(&rep); err != nil {
+		return agentReport{Err: err.Error()}
+	}
+	return rep
+}
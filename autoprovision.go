@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"tenantsdb-bench/bench"
+)
+
+// tenantsNeeded is how many tenants a test uses when -tenants is not set.
+func tenantsNeeded(test string) int {
+	switch test {
+	case "scale", "churn", "all", "suite":
+		return 100
+	case "multi", "isolation", "leakage", "models":
+		return 10
+	case "quota":
+		return 4
+	}
+	return 1
+}
+
+// autoProvision creates the tenants the test needs through cp (-tenants,
+// or <prefix>001.. otherwise), points the proxy endpoint and params at them
+// and returns a function that drops them again unless keep is set.
+func autoProvision(cp bench.ControlPlane, test string, keep bool, params *bench.BenchParams, proxyCfg *bench.ConnConfig) func() {
+	names := params.Tenants
+	if len(names) == 0 {
+		for i := 1; i <= tenantsNeeded(test); i++ {
+			names = append(names, fmt.Sprintf("%s%03d", params.TenantPrefix, i))
+		}
+	}
+
+	ctx := context.Background()
+	fmt.Printf("Provisioning %d bench tenants...\n", len(names))
+	databases, created, err := bench.ProvisionTenants(ctx, cp, names)
+	teardown := func() {
+		fmt.Printf("\nDropping %d bench tenants...\n", len(created))
+		bench.DropTenants(ctx, cp, created)
+	}
+	if err != nil {
+		bench.Failf("%v", err)
+		teardown()
+		fail("auto-provisioning failed")
+	}
+
+	if keep {
+		teardown = func() { fmt.Printf("\n  Kept %d bench tenants (-keep-tenants)\n", len(created)) }
+	}
+	switch params.Tenancy {
+	case "schema":
+		// Schemas live in the proxy endpoint's database, which stays put
+		fmt.Printf("  %d schemas in %s\n", len(databases), proxyCfg.Database)
+		params.Tenants = databases
+	case "rls":
+		fmt.Printf("  %d roles sharing %s in %s\n", len(databases), params.TableName(), proxyCfg.Database)
+		params.Tenants = databases
+	default:
+		proxyCfg.Database = databases[0]
+		if len(databases) > 1 {
+			params.Tenants = databases
+		}
+	}
+	fmt.Println()
+	return teardown
+}
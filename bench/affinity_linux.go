@@ -0,0 +1,45 @@
+package bench
+
+import (
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+type cpuMask [1024 / 64]uint64
+
+var (
+	allowedOnce sync.Once
+	allowedCPUs []int
+	allowedErr  error
+)
+
+// setAffinity binds the calling OS thread to the n-th CPU the process may
+// run on, so pinning works inside a restricted cpuset too.
+func setAffinity(n int) error {
+	allowedOnce.Do(func() {
+		var mask cpuMask
+		_, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_GETAFFINITY, 0, unsafe.Sizeof(mask), uintptr(unsafe.Pointer(&mask)))
+		if errno != 0 {
+			allowedErr = errno
+			return
+		}
+		for cpu := 0; cpu < len(mask)*64; cpu++ {
+			if mask[cpu/64]&(1<<(cpu%64)) != 0 {
+				allowedCPUs = append(allowedCPUs, cpu)
+			}
+		}
+	})
+	if allowedErr != nil {
+		return allowedErr
+	}
+
+	cpu := allowedCPUs[n%len(allowedCPUs)]
+	var mask cpuMask
+	mask[cpu/64] |= 1 << (cpu % 64)
+	_, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_SETAFFINITY, 0, unsafe.Sizeof(mask), uintptr(unsafe.Pointer(&mask)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package bench
+
+import "errors"
+
+// setAffinity is only implemented on Linux.
+func setAffinity(cpu int) error {
+	return errors.New("CPU affinity is only supported on Linux")
+}
@@ -0,0 +1,106 @@
+package bench
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// AuthStats is one user's connects to one endpoint in the auth test.
+type AuthStats struct {
+	Endpoint string        `json:"endpoint"` // proxy or direct
+	User     string        `json:"user"`
+	Method   string        `json:"method"` // as the server asked for it; "" = not seen
+	Connects int           `json:"connects"`
+	Errors   int           `json:"errors"`
+	FirstErr string        `json:"first_error,omitempty"`
+	DialP50  time.Duration `json:"dial_p50_ns"` // TCP connect alone
+	P50      time.Duration `json:"p50_ns"`      // dial to ready for queries
+	P95      time.Duration `json:"p95_ns"`
+	P99      time.Duration `json:"p99_ns"`
+}
+
+// AuthP50 is the p50 connect time past the TCP dial: startup and
+// authentication.
+func (a AuthStats) AuthP50() time.Duration {
+	return max(a.P50-a.DialP50, 0)
+}
+
+// SummarizeAuth builds the AuthStats of one user's connects, results
+// timing each whole connect and dials their TCP dials.
+func SummarizeAuth(endpoint, user, method string, results []QueryResult, dials []time.Duration) AuthStats {
+	a := AuthStats{Endpoint: endpoint, User: user, Method: method, Connects: len(results)}
+	var ok []time.Duration
+	for _, r := range results {
+		if r.Err != nil {
+			if a.Errors == 0 {
+				a.FirstErr = r.Err.Error()
+			}
+			a.Errors++
+			continue
+		}
+		ok = append(ok, r.Duration)
+	}
+	sort.Slice(ok, func(i, j int) bool { return ok[i] < ok[j] })
+	sort.Slice(dials, func(i, j int) bool { return dials[i] < dials[j] })
+	a.P50, a.P95, a.P99 = pct(ok, 50), pct(ok, 95), pct(ok, 99)
+	a.DialP50 = pct(dials, 50)
+	return a
+}
+
+// AuthPassword is login's password in the auth test, falling back to
+// password, the endpoint's.
+func (p BenchParams) AuthPassword(login, password string) string {
+	if pass, ok := p.AuthPasswords[login]; ok {
+		return pass
+	}
+	return password
+}
+
+// PrintAuth prints connect and authentication times per endpoint, user and
+// method, what the proxy adds to each method and a verdict.
+func PrintAuth(rows []AuthStats) {
+	fmt.Printf("\n╔═══════════════════════════════════════════════════════════════════════════════════╗\n")
+	fmt.Printf("║  AUTHENTICATION LATENCY                                                           ║\n")
+	fmt.Printf("╠══════════╦══════════════╦═════════════════════════╦═══════╦══════════╦═══════════╣\n")
+	fmt.Printf("║ Endpoint ║ User         ║ Method                  ║ OK    ║ Auth p50 ║ Total p99 ║\n")
+	fmt.Printf("╠══════════╬══════════════╬═════════════════════════╬═══════╬══════════╬═══════════╣\n")
+	for _, a := range rows {
+		user := a.User
+		if len(user) > 12 {
+			user = user[:12]
+		}
+		fmt.Printf("║ %-8s ║ %-12s ║ %-23s ║ %5s ║ %8s ║ %9s ║\n", a.Endpoint, user, orDash(a.Method),
+			fmt.Sprintf("%d/%d", a.Connects-a.Errors, a.Connects), FmtDur(a.AuthP50()), FmtDur(a.P99))
+	}
+	fmt.Printf("╚══════════╩══════════════╩═════════════════════════╩═══════╩══════════╩═══════════╝\n")
+	fmt.Println("  Auth p50: connect p50 past the TCP dial (startup, authentication, ready for queries)")
+
+	for _, p := range rows {
+		if p.Endpoint != "proxy" || p.Method == "" || p.Errors == p.Connects {
+			continue
+		}
+		// The same user directly if it was measured, else any with the method
+		var match *AuthStats
+		for i, d := range rows {
+			if d.Endpoint == "direct" && d.Method == p.Method && d.Errors < d.Connects && (match == nil || d.User == p.User) {
+				match = &rows[i]
+			}
+		}
+		if match != nil {
+			fmt.Printf("  Proxy adds %s to %s's %s authentication at p50 (%s)\n", FmtDur(p.AuthP50()-match.AuthP50()),
+				p.User, p.Method, pctChange(float64(match.AuthP50()), float64(p.AuthP50())))
+		}
+	}
+
+	failed := 0
+	for _, a := range rows {
+		if a.Errors > 0 {
+			failed++
+			Failf("%s %s: %d of %d connects failed: %s", a.Endpoint, a.User, a.Errors, a.Connects, a.FirstErr)
+		}
+	}
+	if failed == 0 && len(rows) > 0 {
+		Okf("Every connect authenticated (%d users/endpoints)", len(rows))
+	}
+}
@@ -0,0 +1,93 @@
+package bench
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// Tolerances are how far a result may fall behind its baseline before
+// CheckBaseline fails it. A negative value turns that check off.
+type Tolerances struct {
+	QPSDropPct float64 // QPS below the baseline
+	P50RisePct float64 // p50 above the baseline
+	P99RisePct float64 // p99 above the baseline
+	ErrorRise  int     // errors above the baseline's
+	Overhead   float64 // proxy overhead percentage points above the baseline's
+}
+
+// DefaultTolerances are loose enough for run-to-run noise on a shared host.
+var DefaultTolerances = Tolerances{QPSDropPct: 10, P50RisePct: 15, P99RisePct: 25, ErrorRise: 0, Overhead: 5}
+
+// BaselinePath is where the baseline for dbType and test lives under dir.
+func BaselinePath(dir, dbType, test string) string {
+	return filepath.Join(dir, dbType+"-"+test+".json")
+}
+
+// CheckBaseline compares r's headline stats and overhead with base's and
+// returns one line per tolerance exceeded; none means r passes. Suites are
+// compared test by test, skipping tests the baseline did not run.
+func CheckBaseline(base, r *Result, tol Tolerances) []string {
+	if len(base.Suite) > 0 || len(r.Suite) > 0 {
+		switch {
+		case len(base.Suite) == 0:
+			return []string{"baseline is a single test but the result is a suite"}
+		case len(r.Suite) == 0:
+			return []string{"baseline is a suite but the result has no suite tests"}
+		}
+		// Every baseline test must have run; tests new since then pass
+		var failed []string
+		for _, b := range base.Suite {
+			i := slices.IndexFunc(r.Suite, func(t *Result) bool { return t.Test == b.Test })
+			if i < 0 {
+				failed = append(failed, b.Test+": in the baseline but did not complete")
+				continue
+			}
+			for _, f := range CheckBaseline(b, r.Suite[i], tol) {
+				failed = append(failed, b.Test+": "+f)
+			}
+		}
+		return failed
+	}
+	b, ok := base.Headline()
+	if !ok {
+		return []string{"baseline has no stats"}
+	}
+	s, ok := r.Headline()
+	if !ok {
+		return []string{"result has no stats"}
+	}
+	var failed []string
+	if tol.QPSDropPct >= 0 && b.QPS > 0 {
+		if drop := (b.QPS - s.QPS) / b.QPS * 100; drop > tol.QPSDropPct {
+			failed = append(failed, fmt.Sprintf("QPS %.1f is %.1f%% below baseline %.1f (tolerance %g%%)", s.QPS, drop, b.QPS, tol.QPSDropPct))
+		}
+	}
+	for _, l := range []struct {
+		name      string
+		base, cur time.Duration
+		tol       float64
+	}{
+		{"p50", b.LatencyP50, s.LatencyP50, tol.P50RisePct},
+		{"p99", b.LatencyP99, s.LatencyP99, tol.P99RisePct},
+	} {
+		if l.tol < 0 || l.base <= 0 {
+			continue
+		}
+		if rise := float64(l.cur-l.base) / float64(l.base) * 100; rise > l.tol {
+			failed = append(failed, fmt.Sprintf("%s %s is %.1f%% above baseline %s (tolerance %g%%)",
+				l.name, FmtDur(l.cur), rise, FmtDur(l.base), l.tol))
+		}
+	}
+	if tol.ErrorRise >= 0 && s.Errors > b.Errors+tol.ErrorRise {
+		failed = append(failed, fmt.Sprintf("%d errors against %d in the baseline (tolerance +%d)", s.Errors, b.Errors, tol.ErrorRise))
+	}
+	if tol.Overhead >= 0 && base.Comparison != nil && r.Comparison != nil {
+		if rise := r.Comparison.OverheadPct - base.Comparison.OverheadPct; rise > tol.Overhead {
+			failed = append(failed, fmt.Sprintf("overhead %.1f%% is %.1f points above baseline %.1f%% (tolerance %g)",
+				r.Comparison.OverheadPct, rise, base.Comparison.OverheadPct, tol.Overhead))
+		}
+	}
+	return failed
+}
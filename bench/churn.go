@@ -0,0 +1,41 @@
+package bench
+
+import "fmt"
+
+// ChurnReport is the outcome of tenants joining and leaving while
+// long-lived tenants run a steady load.
+type ChurnReport struct {
+	Rate     float64    `json:"joins_per_sec"`
+	Sessions int        `json:"sessions"` // churning tenant sessions started
+	Failed   int        `json:"failed"`   // of them, ones that could not connect
+	Baseline BenchStats `json:"stable_alone"`
+	Churned  BenchStats `json:"stable_under_churn"`
+	Churners BenchStats `json:"churners"`
+}
+
+// PrintChurn prints how the long-lived tenants fared while others churned.
+func PrintChurn(r ChurnReport) {
+	fmt.Println()
+	fmt.Println("╔═════════════════════════════════════════════════════════════╗")
+	fmt.Println("║  TENANT CHURN                                               ║")
+	fmt.Println("╠═════════════════════════════════════════════════════════════╣")
+	fmt.Printf("║  Churn sessions:      %-38s║\n", fmt.Sprintf("%d at %.1f/s (%d failed to connect)", r.Sessions, r.Rate, r.Failed))
+	fmt.Printf("║  Churner p50 / p99:   %-38s║\n", FmtDur(r.Churners.LatencyP50)+" / "+FmtDur(r.Churners.LatencyP99))
+	fmt.Printf("║  Stable p50:          %-38s║\n", FmtDur(r.Baseline.LatencyP50)+" alone → "+FmtDur(r.Churned.LatencyP50))
+	fmt.Printf("║  Stable p99:          %-38s║\n", FmtDur(r.Baseline.LatencyP99)+" alone → "+FmtDur(r.Churned.LatencyP99))
+	fmt.Printf("║  Stable QPS:          %-38s║\n", fmt.Sprintf("%.0f alone → %.0f", r.Baseline.QPS, r.Churned.QPS))
+	fmt.Println("╚═════════════════════════════════════════════════════════════╝")
+
+	if r.Failed > 0 {
+		Failf("%d of %d churning tenants failed to connect", r.Failed, r.Sessions)
+	}
+	if r.Churned.Errors > 0 {
+		Failf("Long-lived tenants saw %d errors during churn", r.Churned.Errors)
+	}
+	if r.Baseline.LatencyP99 > 0 && r.Churned.LatencyP99 > r.Baseline.LatencyP99*3/2 {
+		Warnf("Long-lived tenant p99 rose %s under churn",
+			pctChange(float64(r.Baseline.LatencyP99), float64(r.Churned.LatencyP99)))
+	} else {
+		Okf("Long-lived tenants unaffected by churn")
+	}
+}
@@ -0,0 +1,143 @@
+package bench
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// ClientSampleInterval is how often MonitorClient samples the bench process.
+const ClientSampleInterval = 500 * time.Millisecond
+
+// ClientStats is what the bench process itself used during a run, to tell
+// a saturated client from a slow proxy.
+type ClientStats struct {
+	Samples        int           `json:"samples"`
+	CPUAvg         float64       `json:"cpu_avg_pct"` // of all cores; -1 when unmeasured
+	CPUMax         float64       `json:"cpu_max_pct"`
+	CPUSaturated   float64       `json:"cpu_saturated_pct"` // share of samples above 90% CPU
+	PeakHeapMB     float64       `json:"peak_heap_mb"`
+	PeakGoroutines int           `json:"peak_goroutines"`
+	GCs            uint32        `json:"gcs"`
+	GCPauseTotal   time.Duration `json:"gc_pause_total_ns"`
+	GCPauseMax     time.Duration `json:"gc_pause_max_ns"`
+	GOMAXPROCS     int           `json:"gomaxprocs"`
+	Pinned         bool          `json:"pinned,omitempty"`
+	SchedP50       time.Duration `json:"sched_p50_ns"` // runnable goroutine waiting for a P
+	SchedP99       time.Duration `json:"sched_p99_ns"`
+	SchedMax       time.Duration `json:"sched_max_ns"`
+}
+
+// MonitorClient samples the process's CPU, heap, goroutines, GC pauses and
+// scheduler latency until the returned stop is called.
+func MonitorClient() (stop func() ClientStats) {
+	var (
+		mu   sync.Mutex
+		c    ClientStats
+		done = make(chan struct{})
+		wg   sync.WaitGroup
+	)
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	startGC, startPause := m.NumGC, m.PauseTotalNs
+	startSched := readSched()
+	lastGC := m.NumGC
+	lastCPU, cpuOK := processCPU()
+	lastAt := time.Now()
+	var cpuSum float64
+	var saturated int
+
+	sample := func() {
+		now := time.Now()
+		runtime.ReadMemStats(&m)
+		mu.Lock()
+		defer mu.Unlock()
+		c.Samples++
+		if cpu, ok := processCPU(); ok && cpuOK {
+			pct := float64(cpu-lastCPU) / float64(now.Sub(lastAt)) / float64(runtime.NumCPU()) * 100
+			lastCPU = cpu
+			cpuSum += pct
+			c.CPUMax = max(c.CPUMax, pct)
+			if pct > 90 {
+				saturated++
+			}
+		}
+		lastAt = now
+		c.PeakHeapMB = max(c.PeakHeapMB, float64(m.HeapAlloc)/1e6)
+		c.PeakGoroutines = max(c.PeakGoroutines, runtime.NumGoroutine())
+		// PauseNs holds the last 256 pauses; older ones since the last sample are lost
+		for gc := max(lastGC, m.NumGC-min(m.NumGC, 256)) + 1; gc <= m.NumGC; gc++ {
+			c.GCPauseMax = max(c.GCPauseMax, time.Duration(m.PauseNs[(gc+255)%256]))
+		}
+		lastGC = m.NumGC
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		tick := time.NewTicker(ClientSampleInterval)
+		defer tick.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-tick.C:
+				sample()
+			}
+		}
+	}()
+
+	return func() ClientStats {
+		close(done)
+		wg.Wait()
+		sample()
+		c.GCs = m.NumGC - startGC
+		c.GCPauseTotal = time.Duration(m.PauseTotalNs - startPause)
+		c.GOMAXPROCS, c.Pinned = runtime.GOMAXPROCS(0), PinWorkers
+		c.SchedP50, c.SchedP99, c.SchedMax = schedPercentiles(startSched, readSched())
+		c.CPUAvg, c.CPUSaturated = -1, 0
+		if cpuOK {
+			c.CPUAvg = cpuSum / float64(c.Samples)
+			c.CPUSaturated = float64(saturated) / float64(c.Samples) * 100
+		}
+		return c
+	}
+}
+
+// PrintClient prints the bench process's own resource use and warns when
+// the client, not the proxy, was likely the bottleneck.
+func PrintClient(c ClientStats) {
+	cpu := "n/a"
+	if c.CPUAvg >= 0 {
+		cpu = fmt.Sprintf("%.0f%% avg, %.0f%% max", c.CPUAvg, c.CPUMax)
+	}
+	fmt.Printf("\n┌─────────────────────────────────────────┐\n")
+	fmt.Printf("│  %-39s│\n", "Bench client")
+	fmt.Printf("├─────────────────────────────────────────┤\n")
+	fmt.Printf("│  CPU:        %-27s│\n", cpu)
+	fmt.Printf("│  Peak heap:  %-27s│\n", fmt.Sprintf("%.0f MB", c.PeakHeapMB))
+	fmt.Printf("│  Goroutines: %-27d│\n", c.PeakGoroutines)
+	fmt.Printf("│  GC:         %-27s│\n", fmt.Sprintf("%d runs, %s paused", c.GCs, FmtDur(c.GCPauseTotal)))
+	fmt.Printf("│  GC max:     %-27s│\n", FmtDur(c.GCPauseMax))
+	procs := fmt.Sprintf("%d", c.GOMAXPROCS)
+	if c.Pinned {
+		procs += ", workers pinned"
+	}
+	fmt.Printf("│  GOMAXPROCS: %-27s│\n", procs)
+	fmt.Printf("│  Sched wait: %-27s│\n", fmt.Sprintf("p50 %s  p99 %s", FmtDur(c.SchedP50), FmtDur(c.SchedP99)))
+	fmt.Printf("│  Sched max:  %-27s│\n", FmtDur(c.SchedMax))
+	fmt.Printf("└─────────────────────────────────────────┘\n")
+
+	if c.CPUSaturated >= 25 {
+		Failf("CLIENT CPU-SATURATED: above 90%% CPU for %.0f%% of the run — these numbers measure this machine, not the proxy. Lower -concurrency or run from a bigger client.", c.CPUSaturated)
+	} else if c.CPUMax > 90 {
+		Warnf("Client CPU peaked at %.0f%% — latency spikes may be client-side", c.CPUMax)
+	}
+	if c.SchedP99 > time.Millisecond {
+		Warnf("Workers waited %s (p99) for a CPU — raise -gomaxprocs or lower -concurrency", FmtDur(c.SchedP99))
+	}
+	if c.GCPauseMax > 10*time.Millisecond {
+		Warnf("A client GC pause of %s lands in the latency tail", FmtDur(c.GCPauseMax))
+	}
+}
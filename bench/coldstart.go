@@ -0,0 +1,41 @@
+package bench
+
+import (
+	"fmt"
+	"time"
+)
+
+// WarmQueries is how many queries follow each wake-up in the coldstart test
+// to measure the tenant warm.
+const WarmQueries = 10
+
+// WakeTimeout bounds one wake-up; resuming a tenant from zero can take far
+// longer than a normal connect.
+const WakeTimeout = 2 * time.Minute
+
+// PrintColdStart compares waking a hibernated tenant (connect plus first
+// query) with the queries that followed on the warm tenant.
+func PrintColdStart(wake, warm BenchStats) {
+	fmt.Printf("\n┌─────────────────────────────────────────┐\n")
+	fmt.Printf("│  %-39s│\n", "Cold start")
+	fmt.Printf("├─────────────────────────────────────────┤\n")
+	fmt.Printf("│  Cycles:     %-26s│\n", fmt.Sprintf("%d (%d failed)", wake.Total, wake.Errors))
+	fmt.Printf("│  Wake p50:   %-26s│\n", FmtDur(wake.LatencyP50))
+	fmt.Printf("│  Wake p99:   %-26s│\n", FmtDur(wake.LatencyP99))
+	fmt.Printf("│  Wake max:   %-26s│\n", FmtDur(wake.LatencyMax))
+	fmt.Printf("│  Warm p50:   %-26s│\n", FmtDur(warm.LatencyP50))
+	fmt.Printf("└─────────────────────────────────────────┘\n")
+
+	if wake.Errors > 0 {
+		Failf("%d of %d wake-ups failed", wake.Errors, wake.Total)
+	}
+	if warm.LatencyP50 <= 0 || wake.LatencyP50 <= 0 {
+		return
+	}
+	ratio := float64(wake.LatencyP50) / float64(warm.LatencyP50)
+	if ratio > 10 {
+		Warnf("Waking costs %.0fx a warm query (%s) — the tenant hibernated", ratio, FmtDur(wake.LatencyP50-warm.LatencyP50))
+	} else {
+		Okf("No wake-up penalty (%.1fx a warm query) — the tenant did not hibernate or wakes fast", ratio)
+	}
+}
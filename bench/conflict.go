@@ -0,0 +1,59 @@
+package bench
+
+import "fmt"
+
+// ConflictRetries is how often the conflict test retries a transaction that
+// failed to serialize before giving up on it.
+const ConflictRetries = 10
+
+// FailureRate is the percentage of transaction attempts that hit a
+// serialization failure or deadlock.
+func FailureRate(s BenchStats) float64 {
+	if s.Total == 0 {
+		return 0
+	}
+	return float64(s.Serialization+s.Deadlocks) / float64(s.Total) * 100
+}
+
+// RetriesPerTxn is the number of failed attempts per committed transaction.
+func RetriesPerTxn(s BenchStats) float64 {
+	committed := s.Total - s.Errors
+	if committed == 0 {
+		return 0
+	}
+	return float64(s.Serialization+s.Deadlocks) / float64(committed)
+}
+
+// PrintConflicts prints failure and retry rates and lost updates side by
+// side, and flags a proxy that behaves as if running at a weaker isolation
+// level. direct is nil when no direct endpoint was given; lostAllowed is set
+// when the level permits lost updates (MySQL REPEATABLE READ).
+func PrintConflicts(direct *BenchStats, proxy BenchStats, lostAllowed bool) {
+	col := func(s *BenchStats, f func(BenchStats) string) string {
+		if s == nil {
+			return "-"
+		}
+		return f(*s)
+	}
+	rate := func(s BenchStats) string { return fmt.Sprintf("%.1f%%", FailureRate(s)) }
+	retries := func(s BenchStats) string { return fmt.Sprintf("%.2f", RetriesPerTxn(s)) }
+	lost := func(s BenchStats) string { return fmt.Sprintf("%d", s.LostUpdates) }
+
+	fmt.Println()
+	fmt.Println("╔════════════════════════════════════════════════════╗")
+	fmt.Println("║  CONFLICTS                                         ║")
+	fmt.Println("╠══════════════════╦═════════════════╦═══════════════╣")
+	fmt.Println("║  Metric          ║  Direct         ║  Proxy        ║")
+	fmt.Println("╠══════════════════╬═════════════════╬═══════════════╣")
+	fmt.Printf("║  Failure rate    ║  %-14s ║  %-12s ║\n", col(direct, rate), rate(proxy))
+	fmt.Printf("║  Retries / txn   ║  %-14s ║  %-12s ║\n", col(direct, retries), retries(proxy))
+	fmt.Printf("║  Lost updates    ║  %-14s ║  %-12s ║\n", col(direct, lost), lost(proxy))
+	fmt.Println("╚══════════════════╩═════════════════╩═══════════════╝")
+
+	if proxy.LostUpdates > 0 && !lostAllowed {
+		Warnf("%d lost updates through the proxy — isolation is weaker than requested", proxy.LostUpdates)
+	}
+	if direct != nil && FailureRate(*direct) > 0 && FailureRate(proxy) == 0 {
+		Warnf("No conflicts through the proxy but some direct — the isolation level may not reach the backend")
+	}
+}
@@ -0,0 +1,78 @@
+package bench
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ConnLimitTimeout is how long the connlimit test waits for one connection
+// before calling the proxy hung.
+const ConnLimitTimeout = 10 * time.Second
+
+// ConnLimit is the outcome of opening connections until one was refused.
+type ConnLimit struct {
+	Opened      int           `json:"opened"`
+	Rejected    bool          `json:"rejected"`
+	RejectCode  string        `json:"reject_code,omitempty"` // SQLSTATE or MySQL error number
+	RejectErr   string        `json:"reject_error,omitempty"`
+	RejectAfter time.Duration `json:"reject_after_ns,omitempty"`
+	Hung        bool          `json:"hung,omitempty"` // the refused attempt timed out
+	EarlyP50    time.Duration `json:"early_p50_ns"`   // first tenth of the connections
+	LateP50     time.Duration `json:"late_p50_ns"`    // last tenth, nearest the limit
+}
+
+// SummarizeConnLimit fills the latency fields of c from the connect results
+// in the order they were opened; failed results are skipped.
+func SummarizeConnLimit(c *ConnLimit, results []QueryResult) {
+	var ok []time.Duration
+	for _, r := range results {
+		if r.Err == nil {
+			ok = append(ok, r.Duration)
+		}
+	}
+	c.Opened = len(ok)
+	if len(ok) == 0 {
+		return
+	}
+	tenth := max(len(ok)/10, 1)
+	early := append([]time.Duration(nil), ok[:tenth]...)
+	late := append([]time.Duration(nil), ok[len(ok)-tenth:]...)
+	sort.Slice(early, func(i, j int) bool { return early[i] < early[j] })
+	sort.Slice(late, func(i, j int) bool { return late[i] < late[j] })
+	c.EarlyP50 = pct(early, 50)
+	c.LateP50 = pct(late, 50)
+}
+
+// PrintConnLimit prints where the limit was hit and how the refusal looked.
+func PrintConnLimit(c ConnLimit) {
+	fmt.Printf("\n┌─────────────────────────────────────────┐\n")
+	fmt.Printf("│  %-39s│\n", "Connection limit")
+	fmt.Printf("├─────────────────────────────────────────┤\n")
+	fmt.Printf("│  Opened:       %-24d│\n", c.Opened)
+	fmt.Printf("│  Connect p50:  %-24s│\n", FmtDur(c.EarlyP50)+" first / "+FmtDur(c.LateP50)+" last")
+	if c.Rejected {
+		code := c.RejectCode
+		if code == "" {
+			code = "none"
+		}
+		fmt.Printf("│  Refused with: %-24s│\n", code)
+		fmt.Printf("│  Refused in:   %-24s│\n", FmtDur(c.RejectAfter))
+	}
+	fmt.Printf("└─────────────────────────────────────────┘\n")
+
+	switch {
+	case !c.Rejected:
+		Warnf("No limit reached after %d connections (raise -max-conns)", c.Opened)
+	case c.Hung:
+		Failf("Connection %d hung for %s instead of being refused", c.Opened+1, ConnLimitTimeout)
+	case c.RejectCode == "":
+		Warnf("Connection %d was dropped without an error code: %s", c.Opened+1, c.RejectErr)
+	default:
+		Okf("Connection %d refused cleanly: %s", c.Opened+1, c.RejectErr)
+	}
+	if c.EarlyP50 > 0 && c.LateP50 > 3*c.EarlyP50 {
+		Warnf("Connecting near the limit is %.1fx slower — the proxy queues before refusing",
+			float64(c.LateP50)/float64(c.EarlyP50))
+	}
+}
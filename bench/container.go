@@ -0,0 +1,140 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ContainerSampleInterval is how often MonitorContainer samples.
+const ContainerSampleInterval = time.Second
+
+// ContainerSample is one reading of a container's counters.
+type ContainerSample struct {
+	At      time.Time
+	CPU     time.Duration // cumulative CPU time
+	Cores   int           // CPUs available to the container
+	Memory  uint64        // bytes in use now
+	RxBytes uint64        // cumulative
+	TxBytes uint64
+}
+
+// ContainerSampler reads a container's counters, e.g. from Docker.
+type ContainerSampler interface {
+	Sample(ctx context.Context) (ContainerSample, error)
+}
+
+// ContainerStats is what the proxy container used during a run.
+type ContainerStats struct {
+	Name        string  `json:"name"`
+	Samples     int     `json:"samples"`
+	Cores       int     `json:"cores"`
+	CPUAvgCores float64 `json:"cpu_avg_cores"` // cores busy on average
+	CPUMaxCores float64 `json:"cpu_max_cores"`
+	MemAvgMB    float64 `json:"mem_avg_mb"`
+	MemPeakMB   float64 `json:"mem_peak_mb"`
+	NetRxMB     float64 `json:"net_rx_mb"`
+	NetTxMB     float64 `json:"net_tx_mb"`
+	QPS         float64 `json:"qps"`          // headline throughput of the run
+	QPSPerCore  float64 `json:"qps_per_core"` // QPS / CPUAvgCores
+}
+
+// MonitorContainer samples the container every ContainerSampleInterval
+// until the returned stop is called. It fails if the first sample does.
+func MonitorContainer(name string, s ContainerSampler) (stop func() *ContainerStats, err error) {
+	first, err := s.Sample(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	Okf("Sampling container %s (%d CPUs)", name, first.Cores)
+
+	var (
+		samples = []ContainerSample{first}
+		failed  int
+		done    = make(chan struct{})
+		wg      sync.WaitGroup
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		tick := time.NewTicker(ContainerSampleInterval)
+		defer tick.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-tick.C:
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			smp, err := s.Sample(ctx)
+			cancel()
+			if err != nil {
+				failed++
+				continue
+			}
+			samples = append(samples, smp)
+		}
+	}()
+
+	return func() *ContainerStats {
+		close(done)
+		wg.Wait()
+		if failed > 0 {
+			Warnf("%d container samples failed", failed)
+		}
+		if last, err := s.Sample(context.Background()); err == nil {
+			samples = append(samples, last)
+		}
+		return summarizeContainer(name, samples)
+	}, nil
+}
+
+func summarizeContainer(name string, samples []ContainerSample) *ContainerStats {
+	c := &ContainerStats{Name: name, Samples: len(samples), Cores: samples[0].Cores}
+	first, last := samples[0], samples[len(samples)-1]
+	if wall := last.At.Sub(first.At); wall > 0 {
+		c.CPUAvgCores = float64(last.CPU-first.CPU) / float64(wall)
+	}
+	var memSum float64
+	for i, s := range samples {
+		mb := float64(s.Memory) / 1e6
+		memSum += mb
+		c.MemPeakMB = max(c.MemPeakMB, mb)
+		if i == 0 {
+			continue
+		}
+		if wall := s.At.Sub(samples[i-1].At); wall > 0 {
+			c.CPUMaxCores = max(c.CPUMaxCores, float64(s.CPU-samples[i-1].CPU)/float64(wall))
+		}
+	}
+	c.MemAvgMB = memSum / float64(len(samples))
+	c.NetRxMB = float64(last.RxBytes-first.RxBytes) / 1e6
+	c.NetTxMB = float64(last.TxBytes-first.TxBytes) / 1e6
+	return c
+}
+
+// SetQPS records the run's throughput and the QPS per busy core it implies.
+func (c *ContainerStats) SetQPS(qps float64) {
+	c.QPS = qps
+	if c.CPUAvgCores > 0 {
+		c.QPSPerCore = qps / c.CPUAvgCores
+	}
+}
+
+// PrintContainer prints the proxy container's utilization and efficiency.
+func PrintContainer(c *ContainerStats) {
+	fmt.Printf("\n┌─────────────────────────────────────────┐\n")
+	fmt.Printf("│  %-39s│\n", "Proxy container: "+c.Name)
+	fmt.Printf("├─────────────────────────────────────────┤\n")
+	fmt.Printf("│  CPU cores:  %-27s│\n", fmt.Sprintf("%.2f avg, %.2f max (of %d)", c.CPUAvgCores, c.CPUMaxCores, c.Cores))
+	fmt.Printf("│  Memory:     %-27s│\n", fmt.Sprintf("%.0f MB avg, %.0f MB peak", c.MemAvgMB, c.MemPeakMB))
+	fmt.Printf("│  Network:    %-27s│\n", fmt.Sprintf("%.1f MB in, %.1f MB out", c.NetRxMB, c.NetTxMB))
+	if c.QPSPerCore > 0 {
+		fmt.Printf("│  QPS/core:   %-27s│\n", fmt.Sprintf("%.0f (at %.0f QPS)", c.QPSPerCore, c.QPS))
+	}
+	fmt.Printf("└─────────────────────────────────────────┘\n")
+	if c.Cores > 0 && c.CPUMaxCores >= 0.9*float64(c.Cores) {
+		Warnf("Proxy container hit %.1f of %d cores — it is CPU-bound at this load", c.CPUMaxCores, c.Cores)
+	}
+}
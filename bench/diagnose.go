@@ -0,0 +1,84 @@
+package bench
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+)
+
+// DiagnoseTimeout bounds each network step of DiagnoseNetwork.
+const DiagnoseTimeout = 10 * time.Second
+
+// TLSProbe asks the server on c to switch to TLS in its own protocol. It
+// returns a handshaken connection, or nil when the server does not offer TLS.
+type TLSProbe func(c net.Conn, host string) (*tls.Conn, error)
+
+// DiagnoseNetwork walks the steps below authentication — DNS, TCP, then TLS
+// through probe — printing each, and returns an error for the first step
+// that makes the endpoint unreachable. TLS problems are reported but not
+// fatal, since the bench connects without TLS.
+func DiagnoseNetwork(host string, port int, probe TLSProbe) error {
+	start := time.Now()
+	if ip := net.ParseIP(host); ip != nil {
+		Okf("DNS: %s is an IP address", host)
+	} else {
+		ctx, cancel := context.WithTimeout(context.Background(), DiagnoseTimeout)
+		addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+		cancel()
+		if err != nil {
+			Failf("DNS: %v", err)
+			return fmt.Errorf("resolve %s: %w", host, err)
+		}
+		Okf("DNS: %s → %v in %s", host, addrs, FmtDur(time.Since(start)))
+	}
+
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
+	start = time.Now()
+	c, err := net.DialTimeout("tcp", addr, DiagnoseTimeout)
+	if err != nil {
+		Failf("TCP: %v", err)
+		return fmt.Errorf("connect %s: %w", addr, err)
+	}
+	Okf("TCP: %s (from %s) in %s", c.RemoteAddr(), c.LocalAddr(), FmtDur(time.Since(start)))
+	defer c.Close()
+
+	c.SetDeadline(time.Now().Add(DiagnoseTimeout))
+	start = time.Now()
+	tc, err := probe(c, host)
+	switch {
+	case err != nil:
+		Warnf("TLS: %v", err)
+	case tc == nil:
+		Warnf("TLS: not offered by the server")
+	default:
+		st := tc.ConnectionState()
+		Okf("TLS: %s, %s in %s", tls.VersionName(st.Version), tls.CipherSuiteName(st.CipherSuite), FmtDur(time.Since(start)))
+		if len(st.PeerCertificates) > 0 {
+			cert := st.PeerCertificates[0]
+			fmt.Printf("    Certificate: %s, issued by %s, expires %s\n", cert.Subject.CommonName, cert.Issuer.CommonName, cert.NotAfter.Format("2006-01-02"))
+			if err := verifyChain(st.PeerCertificates, host); err != nil {
+				Warnf("TLS: certificate would not verify: %v", err)
+			} else {
+				Okf("TLS: certificate verifies against the system roots")
+			}
+			if time.Until(cert.NotAfter) < 14*24*time.Hour {
+				Warnf("TLS: certificate expires in %s", time.Until(cert.NotAfter).Round(time.Hour))
+			}
+		}
+	}
+	return nil
+}
+
+// verifyChain checks the server's certificates as a verifying client would.
+func verifyChain(certs []*x509.Certificate, host string) error {
+	inter := x509.NewCertPool()
+	for _, c := range certs[1:] {
+		inter.AddCert(c)
+	}
+	_, err := certs[0].Verify(x509.VerifyOptions{DNSName: host, Intermediates: inter})
+	return err
+}
@@ -0,0 +1,23 @@
+package bench
+
+import "fmt"
+
+var (
+	docTiers   = []string{"free", "basic", "pro", "enterprise"}
+	docRegions = []string{"us-east", "us-west", "eu", "apac", "latam"}
+	docThemes  = []string{"light", "dark"}
+)
+
+// Document is the deterministic JSON document seeded into the doc column
+// of row n for the json workload.
+func Document(n int) string {
+	return fmt.Sprintf(`{"id": %d, "tier": %q, "region": %q, "tags": ["t%d", "t%d"], "prefs": {"theme": %q, "notify": %t}, "notes": ""}`,
+		n, docTiers[n%len(docTiers)], docRegions[n%len(docRegions)], n%10, n%7,
+		docThemes[n%len(docThemes)], n%3 == 0)
+}
+
+// DocumentFilter is a containment filter matching some of the seeded
+// documents, chosen by k.
+func DocumentFilter(k int) string {
+	return fmt.Sprintf(`{"tier": %q, "region": %q}`, docTiers[k%len(docTiers)], docRegions[k%len(docRegions)])
+}
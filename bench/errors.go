@@ -0,0 +1,17 @@
+package bench
+
+import "errors"
+
+// Contention errors. The database packages wrap driver errors in these so
+// stats can count them without knowing SQLSTATEs or MySQL error numbers.
+var (
+	ErrDeadlock    = errors.New("deadlock")
+	ErrLockTimeout = errors.New("lock wait timeout")
+
+	// ErrSerialization is a transaction the database refused to commit
+	// because it could not be serialized with concurrent ones.
+	ErrSerialization = errors.New("serialization failure")
+
+	// ErrLeak is a query that saw another tenant's data, or not its own.
+	ErrLeak = errors.New("cross-tenant leak")
+)
@@ -0,0 +1,109 @@
+package bench
+
+import (
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FailoverStats describes how a workload rode through a failover triggered
+// at Trigger.
+type FailoverStats struct {
+	Trigger       time.Time     `json:"trigger"`
+	Errors        int           `json:"errors"`
+	ErrorBurst    time.Duration `json:"error_burst_ns"`     // first failed query to end of the last
+	TimeToRecover time.Duration `json:"time_to_recover_ns"` // trigger to the first success after the first error
+	BeforeP50     time.Duration `json:"before_p50_ns"`
+	AfterP50      time.Duration `json:"after_p50_ns"` // successes after the last error
+	AfterP99      time.Duration `json:"after_p99_ns"`
+}
+
+// ScheduleFailover fires the failover after at: it runs cmd through sh -c,
+// or with no cmd asks the operator to restart things by hand. The returned
+// function waits for the command and reports when the failover started.
+func ScheduleFailover(at time.Duration, cmd string) func() time.Time {
+	done := make(chan time.Time, 1)
+	time.AfterFunc(at, func() {
+		trigger := time.Now()
+		if cmd == "" {
+			Stepf("Failover point reached — restart the backend or proxy now")
+			done <- trigger
+			return
+		}
+		Stepf("Running failover command: %s", cmd)
+		out, err := exec.Command("sh", "-c", cmd).CombinedOutput()
+		if err != nil {
+			Warnf("Failover command failed: %v: %s", err, strings.TrimSpace(string(out)))
+		} else {
+			Okf("Failover command finished in %s", time.Since(trigger).Round(time.Millisecond))
+		}
+		done <- trigger
+	})
+	return func() time.Time { return <-done }
+}
+
+// AnalyzeFailover splits results around trigger and the burst of errors
+// that followed it.
+func AnalyzeFailover(results []QueryResult, trigger time.Time) FailoverStats {
+	f := FailoverStats{Trigger: trigger}
+	sorted := append([]QueryResult(nil), results...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].At.Before(sorted[j].At) })
+
+	var firstErr, lastErrEnd time.Time
+	for _, r := range sorted {
+		if r.Err == nil || r.At.Before(trigger) {
+			continue
+		}
+		f.Errors++
+		if firstErr.IsZero() {
+			firstErr = r.At
+		}
+		if end := r.At.Add(r.Duration); end.After(lastErrEnd) {
+			lastErrEnd = end
+		}
+	}
+
+	var before, after []time.Duration
+	for _, r := range sorted {
+		if r.Err != nil {
+			continue
+		}
+		switch {
+		case r.At.Before(trigger):
+			before = append(before, r.Duration)
+		case firstErr.IsZero() || !r.At.Before(lastErrEnd):
+			after = append(after, r.Duration)
+		}
+		if !firstErr.IsZero() && f.TimeToRecover == 0 && r.At.After(firstErr) {
+			f.TimeToRecover = r.At.Add(r.Duration).Sub(trigger)
+		}
+	}
+	if !firstErr.IsZero() {
+		f.ErrorBurst = lastErrEnd.Sub(firstErr)
+	}
+	sort.Slice(before, func(i, j int) bool { return before[i] < before[j] })
+	sort.Slice(after, func(i, j int) bool { return after[i] < after[j] })
+	f.BeforeP50 = pct(before, 50)
+	f.AfterP50 = pct(after, 50)
+	f.AfterP99 = pct(after, 99)
+	return f
+}
+
+// PrintFailover prints the failover summary.
+func PrintFailover(f FailoverStats) {
+	fmt.Printf("\n┌─────────────────────────────────────────┐\n")
+	fmt.Printf("│  %-39s│\n", "Failover")
+	fmt.Printf("├─────────────────────────────────────────┤\n")
+	fmt.Printf("│  Errors:       %-24d│\n", f.Errors)
+	fmt.Printf("│  Error burst:  %-24s│\n", f.ErrorBurst.Round(time.Millisecond))
+	fmt.Printf("│  Recovered in: %-24s│\n", f.TimeToRecover.Round(time.Millisecond))
+	fmt.Printf("│  p50 before:   %-24s│\n", FmtDur(f.BeforeP50))
+	fmt.Printf("│  p50 after:    %-24s│\n", FmtDur(f.AfterP50))
+	fmt.Printf("│  p99 after:    %-24s│\n", FmtDur(f.AfterP99))
+	fmt.Printf("└─────────────────────────────────────────┘\n")
+	if f.Errors == 0 {
+		Okf("No query failed across the failover")
+	}
+}
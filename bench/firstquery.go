@@ -0,0 +1,55 @@
+package bench
+
+import "fmt"
+
+// SplitFirst separates each tenant's very first query (the earliest issued)
+// from the rest of its results. Unfilled results (zero At) are dropped.
+func SplitFirst(byTenant [][]QueryResult) (first, rest []QueryResult) {
+	for _, results := range byTenant {
+		fi := -1
+		for i, r := range results {
+			if r.At.IsZero() {
+				continue
+			}
+			if fi < 0 || r.At.Before(results[fi].At) {
+				fi = i
+			}
+		}
+		for i, r := range results {
+			switch {
+			case i == fi:
+				first = append(first, r)
+			case !r.At.IsZero():
+				rest = append(rest, r)
+			}
+		}
+	}
+	return first, rest
+}
+
+// PrintFirstQueries prints the percentiles of the tenants' cold first
+// queries next to the steady-state queries that followed.
+func PrintFirstQueries(first, steady BenchStats) {
+	fmt.Println()
+	fmt.Println("╔═════════════════════════════════════════════════════════════╗")
+	fmt.Println("║  COLD FIRST QUERY PER TENANT                                ║")
+	fmt.Println("╠═════════════════════════════════════════════════════════════╣")
+	fmt.Printf("║  %-12s %22s %22s ║\n", "", "First query", "Steady state")
+	row := func(name string, a, b string) {
+		fmt.Printf("║  %-12s %22s %22s ║\n", name, a, b)
+	}
+	row("Queries", fmt.Sprint(first.Total), fmt.Sprint(steady.Total))
+	row("Errors", fmt.Sprint(first.Errors), fmt.Sprint(steady.Errors))
+	row("p50", FmtDur(first.LatencyP50), FmtDur(steady.LatencyP50))
+	row("p90", FmtDur(first.LatencyP90), FmtDur(steady.LatencyP90))
+	row("p99", FmtDur(first.LatencyP99), FmtDur(steady.LatencyP99))
+	row("Max", FmtDur(first.LatencyMax), FmtDur(steady.LatencyMax))
+	fmt.Println("╚═════════════════════════════════════════════════════════════╝")
+
+	if steady.LatencyP50 > 0 && first.LatencyP50 > 3*steady.LatencyP50 {
+		Warnf("A tenant's first query costs %.1fx steady state at p50 — routing or pool warm-up on the proxy",
+			float64(first.LatencyP50)/float64(steady.LatencyP50))
+	} else if steady.LatencyP50 > 0 {
+		Okf("No noticeable warm-up on a tenant's first query")
+	}
+}
@@ -0,0 +1,53 @@
+package bench
+
+import (
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// GuardFDs makes sure the client can hold need open connections: it raises
+// the soft open-file limit toward the hard limit if needed and returns how
+// many connections fit (need, or fewer if the limit cannot be raised).
+func GuardFDs(need int) int {
+	const reserve = 64 // stdio, DNS, result files
+	limit, err := raiseFDLimit(uint64(need + reserve))
+	if err != nil {
+		Warnf("Open-file limit: %v", err)
+		return need
+	}
+	if fit := int(limit) - reserve; fit < need {
+		Warnf("Open-file limit %d allows only %d client connections (raise it with ulimit -n)", limit, fit)
+		return max(fit, 1)
+	}
+	return need
+}
+
+// GuardMemory sets a soft memory limit of mb MiB for the client and returns
+// a channel that is closed once the heap passes it despite the GC, so a run
+// can stop before the client starts swapping. stop ends the watch.
+func GuardMemory(mb int) (exceeded <-chan struct{}, stop func()) {
+	limit := int64(mb) << 20
+	debug.SetMemoryLimit(limit)
+	ch := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		tick := time.NewTicker(time.Second)
+		defer tick.Stop()
+		var m runtime.MemStats
+		for {
+			select {
+			case <-done:
+				return
+			case <-tick.C:
+			}
+			runtime.ReadMemStats(&m)
+			if int64(m.HeapAlloc) > limit {
+				Warnf("Client heap %d MiB passed -max-client-mem %d MiB — stopping the run early", m.HeapAlloc>>20, mb)
+				close(ch)
+				return
+			}
+		}
+	}()
+	return ch, func() { close(done) }
+}
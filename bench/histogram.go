@@ -0,0 +1,212 @@
+package bench
+
+import (
+	"math"
+	"math/bits"
+	"sort"
+	"sync"
+	"time"
+)
+
+// histSub is the number of buckets per power of two, so a bucket's
+// midpoint is within ~1% of every latency counted in it.
+const histSub = 64
+
+// Histogram counts latencies in log-linear buckets. Unlike a sorted slice of
+// samples it stays small and two histograms merge by adding counts, which is
+// how agents' results are combined.
+type Histogram struct {
+	Counts map[int]int64 `json:"counts"`
+	N      int64         `json:"n"`
+	Errors int64         `json:"errors"`
+	Sum    time.Duration `json:"sum_ns"`
+	Min    time.Duration `json:"min_ns"`
+	Max    time.Duration `json:"max_ns"`
+}
+
+func NewHistogram() *Histogram {
+	return &Histogram{Counts: map[int]int64{}}
+}
+
+func histIndex(d time.Duration) int {
+	ns := uint64(max(d, 0))
+	if ns < histSub {
+		return int(ns)
+	}
+	e := bits.Len64(ns) - 7
+	return (e+1)*histSub + int(ns>>e) - histSub
+}
+
+func histValue(idx int) time.Duration {
+	if idx < histSub {
+		return time.Duration(idx)
+	}
+	e := idx/histSub - 1
+	m := uint64(idx%histSub + histSub)
+	return time.Duration(m<<e + (uint64(1)<<e)/2)
+}
+
+// Record adds one result.
+func (h *Histogram) Record(r QueryResult) {
+	if r.Err != nil {
+		h.Errors++
+		return
+	}
+	if h.N == 0 || r.Duration < h.Min {
+		h.Min = r.Duration
+	}
+	h.Max = max(h.Max, r.Duration)
+	h.N++
+	h.Sum += r.Duration
+	h.Counts[histIndex(r.Duration)]++
+}
+
+// Merge adds o's counts to h.
+func (h *Histogram) Merge(o *Histogram) {
+	if o == nil || o.N+o.Errors == 0 {
+		return
+	}
+	if h.N == 0 || (o.N > 0 && o.Min < h.Min) {
+		h.Min = o.Min
+	}
+	h.Max = max(h.Max, o.Max)
+	h.N += o.N
+	h.Errors += o.Errors
+	h.Sum += o.Sum
+	for k, c := range o.Counts {
+		h.Counts[k] += c
+	}
+}
+
+// Within counts the successful results at or below d, to the bucket.
+func (h *Histogram) Within(d time.Duration) int64 {
+	var n int64
+	for k, c := range h.Counts {
+		if k <= histIndex(d) {
+			n += c
+		}
+	}
+	return n
+}
+
+// Bin is one bar of a latency distribution: the successful results above
+// the previous bin's Upper and at most this one's.
+type Bin struct {
+	Upper time.Duration
+	Count int64
+}
+
+// Bins regroups h into n log-spaced bins from its minimum to its maximum.
+func (h *Histogram) Bins(n int) []Bin {
+	if h.N == 0 || n < 1 {
+		return nil
+	}
+	lo, hi := float64(max(h.Min, 1)), float64(max(h.Max, h.Min+1))
+	ratio := math.Pow(hi/lo, 1/float64(n))
+	bins := make([]Bin, n)
+	for i := range bins {
+		bins[i].Upper = time.Duration(lo * math.Pow(ratio, float64(i+1)))
+	}
+	bins[n-1].Upper = time.Duration(hi)
+	for k, c := range h.Counts {
+		v := histValue(k)
+		i := sort.Search(n, func(i int) bool { return bins[i].Upper >= v })
+		bins[min(i, n-1)].Count += c
+	}
+	return bins
+}
+
+// Percentile returns the p-th percentile latency, clamped to the observed
+// minimum and maximum.
+func (h *Histogram) Percentile(p float64) time.Duration {
+	if h.N == 0 {
+		return 0
+	}
+	keys := make([]int, 0, len(h.Counts))
+	for k := range h.Counts {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	target := int64(math.Ceil(p / 100 * float64(h.N)))
+	var seen int64
+	for _, k := range keys {
+		seen += h.Counts[k]
+		if seen >= target {
+			return min(max(histValue(k), h.Min), h.Max)
+		}
+	}
+	return h.Max
+}
+
+var histogram struct {
+	sync.Mutex
+	h *Histogram
+}
+
+// StartHistogram begins counting what the benchmark workers Observe.
+func StartHistogram() {
+	histogram.Lock()
+	defer histogram.Unlock()
+	histogram.h = NewHistogram()
+}
+
+// StopHistogram ends counting and returns the histogram.
+func StopHistogram() *Histogram {
+	histogram.Lock()
+	defer histogram.Unlock()
+	h := histogram.h
+	histogram.h = nil
+	return h
+}
+
+func recordHistogram(results []QueryResult) {
+	histogram.Lock()
+	defer histogram.Unlock()
+	if histogram.h == nil {
+		return
+	}
+	for _, r := range results {
+		histogram.h.Record(r)
+	}
+}
+
+// MergeStats combines the stats of runs that went on side by side (one per
+// agent) into one: counts and rates add up, latencies come from the merged
+// histogram.
+func MergeStats(label string, parts []BenchStats, h *Histogram) BenchStats {
+	out := BenchStats{Label: label}
+	for _, p := range parts {
+		out.Total += p.Total
+		out.Errors += p.Errors
+		out.Deadlocks += p.Deadlocks
+		out.LockTimeouts += p.LockTimeouts
+		out.Serialization += p.Serialization
+		out.Bytes += p.Bytes
+		out.Rows += p.Rows
+		out.QPS += p.QPS
+		out.MBps += p.MBps
+		out.RowsPerSec += p.RowsPerSec
+		out.Duration = max(out.Duration, p.Duration)
+	}
+	if h == nil || h.N == 0 {
+		return out
+	}
+	out.SLO = sloReport(int(h.N+h.Errors), func(d time.Duration) int { return int(h.Within(d)) })
+	latencyFrom(&out, h)
+	return out
+}
+
+// latencyFrom fills the latency fields of stats from h, which is not empty.
+func latencyFrom(stats *BenchStats, h *Histogram) {
+	stats.LatencyAvg = h.Sum / time.Duration(h.N)
+	stats.LatencyMin = h.Min
+	stats.LatencyMax = h.Max
+	stats.LatencyP50 = h.Percentile(50)
+	stats.LatencyP75 = h.Percentile(75)
+	stats.LatencyP90 = h.Percentile(90)
+	stats.LatencyP95 = h.Percentile(95)
+	stats.LatencyP99 = h.Percentile(99)
+	for _, p := range Percentiles {
+		stats.Percentiles = append(stats.Percentiles, Percentile{P: p, Latency: h.Percentile(p)})
+	}
+}
@@ -0,0 +1,60 @@
+package bench
+
+import (
+	"fmt"
+	"time"
+)
+
+// IdleQueryTimeout is how long the idle test waits for the query on a
+// connection that has been idle before calling it hung.
+const IdleQueryTimeout = 10 * time.Second
+
+// IdleProbe is one connection left idle for Idle and then queried.
+type IdleProbe struct {
+	Idle    time.Duration `json:"idle_ns"`
+	Alive   bool          `json:"alive"`
+	Latency time.Duration `json:"latency_ns"`     // of the query after the idle
+	Code    string        `json:"code,omitempty"` // SQLSTATE or MySQL error number
+	Err     string        `json:"error,omitempty"`
+	Hung    bool          `json:"hung,omitempty"` // the query timed out
+}
+
+// PrintIdle prints each probe, shortest idle first, and at what idle time
+// the proxy started dropping connections.
+func PrintIdle(probes []IdleProbe) {
+	fmt.Printf("\n┌─────────────────────────────────────────┐\n")
+	fmt.Printf("│  %-10s %-14s %-13s│\n", "Idle", "Result", "Query")
+	fmt.Printf("├─────────────────────────────────────────┤\n")
+	for _, p := range probes {
+		result := "alive"
+		switch {
+		case p.Hung:
+			result = "hung"
+		case !p.Alive && p.Code != "":
+			result = "closed " + p.Code
+		case !p.Alive:
+			result = "dropped"
+		}
+		fmt.Printf("│  %-10s %-14s %-13s│\n", p.Idle, result, FmtDur(p.Latency))
+	}
+	fmt.Printf("└─────────────────────────────────────────┘\n")
+
+	var lastAlive time.Duration
+	for _, p := range probes {
+		if p.Alive {
+			lastAlive = p.Idle
+			continue
+		}
+		Warnf("The proxy drops connections idle between %s and %s", lastAlive, p.Idle)
+		switch {
+		case p.Hung:
+			Failf("The next query hung for %s instead of failing", IdleQueryTimeout)
+		case p.Code == "":
+			Failf("Dropped silently — the client only saw: %s", p.Err)
+		default:
+			Okf("Closed with an error code: %s", p.Err)
+		}
+		return
+	}
+	Okf("Connections survived idling up to %s", lastAlive)
+}
@@ -0,0 +1,63 @@
+package bench
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// DefaultInParams are the IN-list sizes the inlist test runs by default.
+var DefaultInParams = []int{10, 100, 1000}
+
+// InListPoint is the inlist test outcome for one parameter count. Direct is
+// nil when no direct endpoint was given.
+type InListPoint struct {
+	Params int
+	Direct *BenchStats
+	Proxy  BenchStats
+}
+
+// PrintInList prints p50 latency per parameter count with the proxy's
+// overhead, and flags overhead that grows with the size of the IN-list.
+func PrintInList(points []InListPoint) {
+	fmt.Println()
+	fmt.Println("╔═════════════════════════════════════════════════════════════╗")
+	fmt.Println("║  IN-LIST SCALING (p50)                                      ║")
+	fmt.Println("╠════════════╦══════════════╦══════════════╦═════════════════╣")
+	fmt.Println("║  Params    ║  Direct      ║  Proxy       ║  Overhead       ║")
+	fmt.Println("╠════════════╬══════════════╬══════════════╬═════════════════╣")
+	for _, p := range points {
+		direct, overhead := "-", "-"
+		if p.Direct != nil {
+			direct = FmtDur(p.Direct.LatencyP50)
+			overhead = FmtDur(p.Proxy.LatencyP50 - p.Direct.LatencyP50)
+		}
+		fmt.Printf("║  %-9d ║  %-11s ║  %-11s ║  %-14s ║\n", p.Params, direct, FmtDur(p.Proxy.LatencyP50), overhead)
+	}
+	fmt.Println("╚════════════╩══════════════╩══════════════╩═════════════════╝")
+
+	if len(points) < 2 || points[0].Direct == nil {
+		return
+	}
+	first, last := points[0], points[len(points)-1]
+	small := first.Proxy.LatencyP50 - first.Direct.LatencyP50
+	large := last.Proxy.LatencyP50 - last.Direct.LatencyP50
+	if small > 0 && large > 10*small {
+		Warnf("Proxy overhead grows %.0fx from %d to %d parameters — large parameter sets may be parsed or rewritten slowly",
+			float64(large)/float64(small), first.Params, last.Params)
+	}
+}
+
+// DistinctIDs returns n distinct random ids in 1..maxID; n must not exceed
+// maxID.
+func DistinctIDs(n, maxID int) []int {
+	seen := make(map[int]bool, n)
+	ids := make([]int, 0, n)
+	for len(ids) < n {
+		id := rand.Intn(maxID) + 1
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
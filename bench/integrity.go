@@ -0,0 +1,50 @@
+package bench
+
+import (
+	"fmt"
+	"math"
+)
+
+// MaxWriteDelta bounds how far one workload write can move a balance; the
+// integrity check allows this much drift in SUM(balance) per query run.
+const MaxWriteDelta = 100
+
+// Snapshot is the state of a benchmark table the integrity check compares
+// before and after a run.
+type Snapshot struct {
+	Rows    int64   // seeded rows (id > 0)
+	Related int64   // orders and order_items rows, when relational
+	Nulls   int64   // rows with a NULL in any seeded column
+	Sum     float64 // SUM(balance) over seeded rows
+}
+
+// CheckIntegrity lists the invariants a run of queries broke between before
+// and after: row counts must not change, no NULLs may appear and the balance
+// sum may move at most MaxWriteDelta per query.
+func CheckIntegrity(before, after Snapshot, queries int) []string {
+	var v []string
+	if after.Rows != before.Rows {
+		v = append(v, fmt.Sprintf("row count changed from %d to %d", before.Rows, after.Rows))
+	}
+	if after.Related != before.Related {
+		v = append(v, fmt.Sprintf("order/item row count changed from %d to %d", before.Related, after.Related))
+	}
+	if after.Nulls > before.Nulls {
+		v = append(v, fmt.Sprintf("%d rows gained NULLs", after.Nulls-before.Nulls))
+	}
+	if d := math.Abs(after.Sum - before.Sum); d > float64(queries)*MaxWriteDelta {
+		v = append(v, fmt.Sprintf("SUM(balance) moved by %.2f, more than %d queries can explain", d, queries))
+	}
+	return v
+}
+
+// PrintIntegrity prints the integrity check verdict for label.
+func PrintIntegrity(label string, violations []string) {
+	if len(violations) == 0 {
+		Okf("Integrity: %s unchanged", label)
+		return
+	}
+	for _, v := range violations {
+		Failf("Integrity: %s: %s", label, v)
+	}
+}
@@ -0,0 +1,148 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrUnknownTest is returned by a Driver for a test it does not implement.
+var ErrUnknownTest = errors.New("unknown test")
+
+// ErrIncomplete is returned when a test ran but produced no result; the
+// runner has printed why.
+var ErrIncomplete = errors.New("test did not complete")
+
+// Driver runs the tests of one database type. pg.Driver and my.Driver
+// implement it.
+type Driver interface {
+	Name() string
+	Run(ctx context.Context, test string, proxy, direct ConnConfig, params BenchParams) (*Result, error)
+}
+
+// Runner runs one test from Go code, for services that embed the bench (a
+// proxy self-test endpoint, say) instead of shelling out to the binary:
+//
+//	r := bench.NewRunner(pg.Driver{}, bench.DefaultParams())
+//	r.Proxy = bench.ConnConfig{Host: "127.0.0.1", Port: 5432, ...}
+//	res, err := r.Run(ctx)
+//
+// Runners print their progress to stdout as the command does. The settings
+// the command's flags set (Percentiles, SLOBounds, TrimPct, WindowPolicy,
+// Slow, PinWorkers, ProgressOut) and the Observe collectors are package
+// state shared by every Runner, so Run holds a lock for the whole test and
+// Runners in one process run one at a time.
+type Runner struct {
+	Driver Driver
+	Params BenchParams
+	Test   string     // "throughput" when empty
+	Proxy  ConnConfig // the endpoint under test
+	Direct ConnConfig // the backend, for tests that compare against it
+	Tags   map[string]string
+}
+
+// directTests compare the proxy against the backend and need Direct.
+var directTests = map[string]bool{"overhead": true, "verify": true}
+
+// runMu serializes Runner.Run over the package state it reads and writes.
+var runMu sync.Mutex
+
+func NewRunner(d Driver, params BenchParams) *Runner {
+	return &Runner{Driver: d, Params: params, Test: "throughput"}
+}
+
+// Run runs the test and returns its result stamped like the command's
+// -json output.
+func (r *Runner) Run(ctx context.Context) (*Result, error) {
+	if r.Driver == nil {
+		return nil, errors.New("runner has no driver")
+	}
+	if !r.Proxy.IsSet() {
+		return nil, errors.New("runner has no proxy endpoint")
+	}
+	if err := r.Params.Validate(); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	test := r.Test
+	if test == "" {
+		test = "throughput"
+	}
+	if directTests[test] && !r.Direct.IsSet() {
+		return nil, fmt.Errorf("runner has no direct endpoint, which the %s test compares against", test)
+	}
+
+	runMu.Lock()
+	defer runMu.Unlock()
+	started := time.Now()
+	res, err := r.Driver.Run(ctx, test, r.Proxy, r.Direct, r.Params)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", r.Driver.Name(), test, err)
+	}
+	versions := res.Manifest
+	res.Manifest = NewManifest(nil)
+	res.Manifest.ProxyVersion, res.Manifest.BackendVersion = versions.ProxyVersion, versions.BackendVersion
+	res.DB, res.Test, res.Started, res.Tags = r.Driver.Name(), test, started, r.Tags
+	return res, nil
+}
+
+// Validate reports params a test cannot run with: no query count or
+// duration, no workers, or no workers left beside the aggregation ones.
+func (p BenchParams) Validate() error {
+	switch {
+	case p.Queries <= 0 && p.Duration <= 0:
+		return errors.New("params need Queries or Duration")
+	case p.Concurrency <= 0:
+		return errors.New("params need a positive Concurrency")
+	case p.AggWorkers < 0 || p.AggWorkers >= p.Concurrency:
+		return fmt.Errorf("params.AggWorkers must be between 0 and Concurrency - 1 (%d)", p.Concurrency-1)
+	case p.Warmup < 0:
+		return errors.New("params.Warmup must not be negative")
+	case p.SeedRows <= 0:
+		return errors.New("params need a positive SeedRows")
+	}
+	return nil
+}
+
+// DefaultParams are the run command's defaults.
+func DefaultParams() BenchParams {
+	return BenchParams{
+		Queries:        10000,
+		Concurrency:    10,
+		Warmup:         100,
+		SeedRows:       10000,
+		Workload:       "mixed",
+		StreamRows:     []int{100, 10000, 1000000},
+		StreamIters:    5,
+		PageSize:       20,
+		IngestRows:     100000,
+		IngestBatch:    10000,
+		Notifications:  1000,
+		FetchSize:      100,
+		CursorFetches:  10,
+		SessionQueries: 10,
+		HotRows:        10,
+		Isolation:      "serializable",
+		LockKeys:       10,
+		PreparedStmts:  []int{100, 1000, 10000},
+		InParams:       []int{10, 100, 1000},
+		LagProbes:      200,
+		MaxConns:       500,
+		BystanderQPS:   50,
+		IdleIntervals:  []time.Duration{30 * time.Second, 2 * time.Minute, 5 * time.Minute, 10 * time.Minute},
+		SoakInterval:   time.Minute,
+		HibernateAfter: 5 * time.Minute,
+		WakeCycles:     5,
+		ProvisionCount: 5,
+		TenantPrefix:   "tdbbench",
+		ChurnRate:      2,
+		ChurnQueries:   20,
+		RampEvery:      30 * time.Second,
+		CycleQueries:   50,
+		MaxClientMem:   2048,
+	}
+}
@@ -0,0 +1,125 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Logger receives progress messages: steps, successes, warnings and
+// failures. Result tables are printed directly and never go through it.
+// SetLogger replaces it for -v, -q and -log-format.
+var Logger = slog.New(NewHumanHandler(os.Stdout, slog.LevelInfo))
+
+// SetLogger makes l the progress logger.
+func SetLogger(l *slog.Logger) {
+	Logger = l
+}
+
+// Stepf logs the start of a stage, such as "[2/3] Seeding data...".
+func Stepf(format string, args ...any) { logf(slog.LevelInfo, "step", format, args...) }
+
+// Okf logs something that succeeded.
+func Okf(format string, args ...any) { logf(slog.LevelInfo, "ok", format, args...) }
+
+// Infof logs a progress note.
+func Infof(format string, args ...any) { logf(slog.LevelInfo, "", format, args...) }
+
+// Debugf logs detail shown only with -v.
+func Debugf(format string, args ...any) { logf(slog.LevelDebug, "", format, args...) }
+
+// Warnf logs a problem the run carries on past.
+func Warnf(format string, args ...any) { logf(slog.LevelWarn, "warn", format, args...) }
+
+// Failf logs something that failed.
+func Failf(format string, args ...any) { logf(slog.LevelError, "fail", format, args...) }
+
+func logf(level slog.Level, status, format string, args ...any) {
+	ctx := context.Background()
+	if !Logger.Enabled(ctx, level) {
+		return
+	}
+	msg := fmt.Sprintf(format, args...)
+	if status == "" {
+		Logger.Log(ctx, level, msg)
+		return
+	}
+	Logger.Log(ctx, level, msg, slog.String("status", status))
+}
+
+// HumanHandler writes progress the way the tables around it read: "  ✓ "
+// before successes, "  ⚠ " before warnings, "  ✗ " before failures and a
+// blank line before each step. Attributes other than status are appended
+// as key=value.
+type HumanHandler struct {
+	w     io.Writer
+	level slog.Leveler
+	mu    *sync.Mutex
+	attrs []slog.Attr
+}
+
+// NewHumanHandler returns a HumanHandler writing records at level or above
+// to w.
+func NewHumanHandler(w io.Writer, level slog.Leveler) *HumanHandler {
+	return &HumanHandler{w: w, level: level, mu: &sync.Mutex{}}
+}
+
+func (h *HumanHandler) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= h.level.Level()
+}
+
+func (h *HumanHandler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+	status := ""
+	var extra []string
+	each := func(a slog.Attr) bool {
+		if a.Key == "status" {
+			status = a.Value.String()
+		} else {
+			extra = append(extra, a.Key+"="+a.Value.String())
+		}
+		return true
+	}
+	for _, a := range h.attrs {
+		each(a)
+	}
+	r.Attrs(each)
+
+	switch status {
+	case "step":
+		b.WriteString("\n")
+	case "ok":
+		b.WriteString("  ✓ ")
+	case "warn":
+		b.WriteString("  ⚠ ")
+	case "fail":
+		b.WriteString("  ✗ ")
+	default:
+		b.WriteString("  ")
+	}
+	b.WriteString(r.Message)
+	for _, e := range extra {
+		b.WriteString(" " + e)
+	}
+	b.WriteString("\n")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func (h *HumanHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	c.attrs = append(append([]slog.Attr(nil), h.attrs...), attrs...)
+	return &c
+}
+
+// WithGroup is a no-op: progress messages are flat.
+func (h *HumanHandler) WithGroup(string) slog.Handler {
+	return h
+}
@@ -0,0 +1,114 @@
+package bench
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"net/url"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// Manifest records what produced a result so it can be reproduced and
+// audited later.
+type Manifest struct {
+	RunID          string            `json:"run_id"`
+	ToolRevision   string            `json:"tool_revision"`
+	ToolModified   bool              `json:"tool_modified"`
+	GoVersion      string            `json:"go_version"`
+	OS             string            `json:"os"`
+	Arch           string            `json:"arch"`
+	Hostname       string            `json:"hostname"`
+	Flags          map[string]string `json:"flags"`
+	ProxyVersion   string            `json:"proxy_version,omitempty"`
+	BackendVersion string            `json:"backend_version,omitempty"`
+}
+
+// NewManifest captures the build, host, and every effective flag value
+// (defaults included). Secrets are masked.
+func NewManifest(fs *flag.FlagSet) Manifest {
+	m := Manifest{
+		RunID:        newRunID(),
+		ToolRevision: "unknown",
+		GoVersion:    runtime.Version(),
+		OS:           runtime.GOOS,
+		Arch:         runtime.GOARCH,
+		Flags:        map[string]string{},
+	}
+	m.Hostname, _ = os.Hostname()
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range info.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				m.ToolRevision = s.Value
+			case "vcs.modified":
+				m.ToolModified = s.Value == "true"
+			}
+		}
+	}
+
+	if fs != nil {
+		fs.VisitAll(func(f *flag.Flag) {
+			v := f.Value.String()
+			if isSecretFlag(f.Name) && v != "" {
+				v = "***"
+			}
+			if strings.HasSuffix(f.Name, "-dsn") {
+				v = RedactDSN(v)
+			}
+			m.Flags[f.Name] = v
+		})
+	}
+	return m
+}
+
+// ShortRevision is the first 12 characters of the tool's git SHA.
+func (m Manifest) ShortRevision() string {
+	rev := m.ToolRevision
+	if len(rev) > 12 {
+		rev = rev[:12]
+	}
+	if m.ToolModified {
+		rev += "-dirty"
+	}
+	return rev
+}
+
+// RedactDSN masks the password in a postgres:// URL, a key=value connection
+// string, or a MySQL user:pass@tcp(...) DSN.
+func RedactDSN(dsn string) string {
+	if u, err := url.Parse(dsn); err == nil && u.Scheme != "" && u.User != nil {
+		return u.Redacted()
+	}
+	if strings.Contains(dsn, "password=") {
+		fields := strings.Fields(dsn)
+		for i, f := range fields {
+			if strings.HasPrefix(f, "password=") {
+				fields[i] = "password=***"
+			}
+		}
+		return strings.Join(fields, " ")
+	}
+	if at := strings.LastIndex(dsn, "@"); at > 0 {
+		if colon := strings.Index(dsn[:at], ":"); colon >= 0 {
+			return dsn[:colon+1] + "***" + dsn[at:]
+		}
+	}
+	return dsn
+}
+
+func isSecretFlag(name string) bool {
+	if strings.HasSuffix(name, "-file") {
+		return false
+	}
+	return strings.Contains(name, "pass") || strings.Contains(name, "secret") || strings.Contains(name, "token")
+}
+
+// newRunID is the start time plus a random suffix, e.g. 20250301-142530-9f2c.
+func newRunID() string {
+	return fmt.Sprintf("%s-%04x", time.Now().Format("20060102-150405"), rand.Intn(1<<16))
+}
@@ -0,0 +1,134 @@
+package bench
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ModelReport is one tenancy model's share of a -compare-models run: the
+// multi test's load across its tenants and the isolation test's victim.
+type ModelReport struct {
+	Model    string      `json:"model"` // db, schema or rls
+	Multi    *BenchStats `json:"multi,omitempty"`
+	Fairness float64     `json:"fairness,omitempty"` // slowest/fastest tenant p50 in multi
+	Alone    *BenchStats `json:"alone,omitempty"`    // isolation victim alone
+	Noise    *BenchStats `json:"noise,omitempty"`    // and under noise
+}
+
+// ImpactPct is how much the isolation victim's p50 grew under noise.
+func (m ModelReport) ImpactPct() (float64, bool) {
+	if m.Alone == nil || m.Noise == nil || m.Alone.LatencyP50 == 0 {
+		return 0, false
+	}
+	return float64(m.Noise.LatencyP50-m.Alone.LatencyP50) / float64(m.Alone.LatencyP50) * 100, true
+}
+
+var tenantLatency struct {
+	sync.Mutex
+	on  bool
+	lat map[string][]time.Duration
+}
+
+// StartTenantLatency begins collecting the latency of each tenant's
+// successful queries as the benchmark workers ObserveTenant them.
+func StartTenantLatency() {
+	tenantLatency.Lock()
+	defer tenantLatency.Unlock()
+	tenantLatency.on, tenantLatency.lat = true, map[string][]time.Duration{}
+}
+
+func recordTenant(tenant string, results []QueryResult) {
+	if tenant == "" {
+		return
+	}
+	tenantLatency.Lock()
+	defer tenantLatency.Unlock()
+	if !tenantLatency.on {
+		return
+	}
+	for _, r := range results {
+		if r.Err == nil && !r.Ramp {
+			tenantLatency.lat[tenant] = append(tenantLatency.lat[tenant], r.Duration)
+		}
+	}
+}
+
+// StopTenantLatency ends collection and returns the slowest tenant's p50
+// over the fastest's, as PrintSkew reports it; 0 with fewer than two
+// tenants of ten or more queries.
+func StopTenantLatency() float64 {
+	tenantLatency.Lock()
+	lat := tenantLatency.lat
+	tenantLatency.on, tenantLatency.lat = false, nil
+	tenantLatency.Unlock()
+
+	var fastest, slowest time.Duration
+	n := 0
+	for _, l := range lat {
+		if len(l) < 10 {
+			continue
+		}
+		sort.Slice(l, func(i, j int) bool { return l[i] < l[j] })
+		p50 := pct(l, 50)
+		if fastest == 0 || p50 < fastest {
+			fastest = p50
+		}
+		slowest = max(slowest, p50)
+		n++
+	}
+	if n < 2 || fastest == 0 {
+		return 0
+	}
+	return float64(slowest) / float64(fastest)
+}
+
+// PrintModels prints one line per tenancy model with its multi-tenant
+// throughput, its p50 against the first model's, its fairness and its
+// isolation impact, then a verdict per model.
+func PrintModels(models []ModelReport) {
+	fmt.Printf("\n╔══════════════════════════════════════════════════════════════════════╗\n")
+	fmt.Printf("║  TENANCY MODEL COMPARISON                                            ║\n")
+	fmt.Printf("╠══════════╦══════════╦══════════╦══════════╦══════════╦═══════════════╣\n")
+	fmt.Printf("║ Model    ║   QPS    ║   p50    ║ Overhead ║ Fairness ║ Noise impact  ║\n")
+	fmt.Printf("╠══════════╬══════════╬══════════╬══════════╬══════════╬═══════════════╣\n")
+	var base time.Duration
+	for _, m := range models {
+		qps, p50, overhead, fairness, impact := "-", "-", "-", "-", "-"
+		if m.Multi != nil {
+			qps, p50 = fmt.Sprintf("%.1f", m.Multi.QPS), FmtDur(m.Multi.LatencyP50)
+			switch {
+			case base == 0:
+				base, overhead = m.Multi.LatencyP50, "baseline"
+			default:
+				overhead = pctChange(float64(base), float64(m.Multi.LatencyP50))
+			}
+		}
+		if m.Fairness > 0 {
+			fairness = fmt.Sprintf("%.1fx", m.Fairness)
+		}
+		if pct, ok := m.ImpactPct(); ok {
+			impact = fmt.Sprintf("%+.1f%% p50", pct)
+		}
+		fmt.Printf("║ %-8s ║ %8s ║ %8s ║ %8s ║ %8s ║ %-13s ║\n", m.Model, qps, p50, overhead, fairness, impact)
+	}
+	fmt.Printf("╚══════════╩══════════╩══════════╩══════════╩══════════╩═══════════════╝\n")
+	fmt.Println("  Overhead: multi-tenant p50 against the first model's; fairness: slowest/fastest tenant p50")
+
+	for _, m := range models {
+		pct, ok := m.ImpactPct()
+		switch {
+		case m.Multi == nil && !ok:
+			Failf("%s: did not complete", m.Model)
+		case !ok:
+			Warnf("%s: isolation did not complete", m.Model)
+		case pct < 20 && m.Fairness < 3:
+			Okf("%s: isolated and fair", m.Model)
+		case pct >= 50 || m.Fairness >= 5:
+			Failf("%s: noisy neighbors or unfair tenants (%+.1f%% p50 under noise, %.1fx)", m.Model, pct, m.Fairness)
+		default:
+			Warnf("%s: moderate impact (%+.1f%% p50 under noise, %.1fx)", m.Model, pct, m.Fairness)
+		}
+	}
+}
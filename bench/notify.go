@@ -0,0 +1,92 @@
+package bench
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Summary is the compact outcome of a run that -notify-url posts.
+type Summary struct {
+	RunID       string            `json:"run_id,omitempty"`
+	DB          string            `json:"db"`
+	Test        string            `json:"test"`
+	Verdict     string            `json:"verdict"` // passed, violated, failed
+	QPS         float64           `json:"qps"`
+	P50         time.Duration     `json:"p50_ns"`
+	P99         time.Duration     `json:"p99_ns"`
+	Errors      int               `json:"errors"`
+	OverheadPct *float64          `json:"overhead_pct,omitempty"`
+	Violations  []string          `json:"violations,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+}
+
+// Summarize judges r against the thresholds; a zero threshold is not checked.
+func Summarize(r *Result, maxP99 time.Duration, maxOverheadPct float64) Summary {
+	s := Summary{RunID: r.Manifest.RunID, DB: r.DB, Test: r.Test, Verdict: "passed", Tags: r.Tags}
+	st, ok := r.Headline()
+	if !ok {
+		s.Verdict = "failed"
+		return s
+	}
+	s.QPS, s.P50, s.P99, s.Errors = st.QPS, st.LatencyP50, st.LatencyP99, st.Errors
+	if r.Comparison != nil {
+		pct := r.Comparison.OverheadPct
+		s.OverheadPct = &pct
+	}
+	if maxP99 > 0 && s.P99 > maxP99 {
+		s.Violations = append(s.Violations, fmt.Sprintf("p99 %s > %s", FmtDur(s.P99), FmtDur(maxP99)))
+	}
+	if maxOverheadPct > 0 && s.OverheadPct != nil && *s.OverheadPct > maxOverheadPct {
+		s.Violations = append(s.Violations, fmt.Sprintf("overhead %.1f%% > %.1f%%", *s.OverheadPct, maxOverheadPct))
+	}
+	if len(s.Violations) > 0 {
+		s.Verdict = "violated"
+	}
+	return s
+}
+
+// Text is the one-message form, readable in a chat channel.
+func (s Summary) Text() string {
+	icon := map[string]string{"passed": "✅", "violated": "❌", "failed": "❌"}[s.Verdict]
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s tdb-bench %s/%s %s", icon, s.DB, s.Test, s.Verdict)
+	if s.Verdict != "failed" {
+		fmt.Fprintf(&b, ": %.1f QPS, p50 %s, p99 %s, %d errors", s.QPS, FmtDur(s.P50), FmtDur(s.P99), s.Errors)
+		if s.OverheadPct != nil {
+			fmt.Fprintf(&b, ", overhead %.1f%%", *s.OverheadPct)
+		}
+	}
+	for _, v := range s.Violations {
+		fmt.Fprintf(&b, "\n• %s", v)
+	}
+	if s.RunID != "" || len(s.Tags) > 0 {
+		fmt.Fprintf(&b, "\nrun %s", s.RunID)
+		if len(s.Tags) > 0 {
+			fmt.Fprintf(&b, " (%s)", FormatTags(s.Tags))
+		}
+	}
+	return b.String()
+}
+
+// Notify posts s to a webhook as {"text": ..., "summary": {...}}: Slack
+// incoming webhooks show the text, other receivers can read the fields.
+func Notify(url string, s Summary) error {
+	body, err := json.Marshal(map[string]any{"text": s.Text(), "summary": s})
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
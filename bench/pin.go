@@ -0,0 +1,25 @@
+package bench
+
+import (
+	"runtime"
+	"sync"
+)
+
+// PinWorkers, when set, locks each benchmark worker to its own OS thread
+// and binds that thread to CPU worker % GOMAXPROCS, so workers spread over
+// the P's the same way on every run.
+var PinWorkers bool
+
+var pinWarn sync.Once
+
+// Pin binds the calling worker goroutine when PinWorkers is set. The thread
+// stays locked until the goroutine exits, which then ends the thread too.
+func Pin(worker int) {
+	if !PinWorkers {
+		return
+	}
+	runtime.LockOSThread()
+	if err := setAffinity(worker % runtime.GOMAXPROCS(0)); err != nil {
+		pinWarn.Do(func() { Warnf("Worker pinning: %v (threads locked, CPUs not bound)", err) })
+	}
+}
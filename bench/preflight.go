@@ -0,0 +1,87 @@
+package bench
+
+import (
+	"context"
+	"time"
+)
+
+// MaxClockSkew is how far a server clock may be from the client's before
+// preflight warns; timestamps in timelines and slow-query reports assume
+// the two agree.
+const MaxClockSkew = time.Second
+
+// DatabaseCheck is what preflight found for one endpoint database.
+type DatabaseCheck struct {
+	Database string
+	Err      error         // could not connect or query
+	Latency  time.Duration // connect + first query
+	Table    bool          // benchmark table exists
+	Writable bool          // a no-op UPDATE on it is allowed
+	Skew     time.Duration // server clock minus client clock (0 = unmeasured)
+}
+
+// PreflightParallel is how many databases preflight checks at once.
+const PreflightParallel = 16
+
+// CheckDatabases runs check for every database, PreflightParallel at a time,
+// printing progress for long lists.
+func CheckDatabases(dbs []string, check func(db string) DatabaseCheck) []DatabaseCheck {
+	out := make([]DatabaseCheck, len(dbs))
+	RunWorkers(Unobserved(context.Background()), "Preflight", min(PreflightParallel, len(dbs)), func(worker int) []QueryResult {
+		for i := worker; i < len(dbs); i += PreflightParallel {
+			out[i] = check(dbs[i])
+		}
+		return nil
+	})
+	return out
+}
+
+// PrintPreflight prints the checks for one endpoint and returns how many
+// databases could not be reached. Only problems are listed per database.
+func PrintPreflight(endpoint string, checks []DatabaseCheck) (failed int) {
+	var missing, readOnly int
+	var slowest time.Duration
+	for _, c := range checks {
+		switch {
+		case c.Err != nil:
+			failed++
+			Failf("%s %s: %v", endpoint, c.Database, c.Err)
+		case !c.Table:
+			missing++
+		case !c.Writable:
+			readOnly++
+			Warnf("%s %s: benchmark table is not writable", endpoint, c.Database)
+		}
+		slowest = max(slowest, c.Latency)
+		if c.Skew > MaxClockSkew || c.Skew < -MaxClockSkew {
+			Warnf("%s %s: server clock is %s off the client's — timelines will not line up", endpoint, c.Database, c.Skew.Round(time.Millisecond))
+		}
+	}
+	ok := len(checks) - failed
+	if failed == 0 {
+		Okf("%s: %d/%d reachable (slowest %s)", endpoint, ok, len(checks), FmtDur(slowest))
+	} else {
+		Failf("%s: %d/%d reachable", endpoint, ok, len(checks))
+	}
+	if missing > 0 {
+		Warnf("%s: benchmark table missing in %d database(s) — seeding will need CREATE rights", endpoint, missing)
+	}
+	if readOnly > 0 {
+		Warnf("%s: %d database(s) are read-only for this user", endpoint, readOnly)
+	}
+	return failed
+}
+
+// PreflightFDs checks that the open-file limit covers need connections and
+// says whether it will be raised.
+func PreflightFDs(need int) {
+	limit, err := raiseFDLimit(0)
+	switch {
+	case err != nil:
+		Warnf("Open-file limit: %v", err)
+	case int(limit) >= need+64:
+		Okf("Open-file limit %d covers %d connections", limit, need)
+	default:
+		Warnf("Open-file limit %d is below the %d connections this run may open (raise it with ulimit -n)", limit, need)
+	}
+}
@@ -0,0 +1,65 @@
+package bench
+
+import "fmt"
+
+// DefaultPreparedStmts are the per-connection statement counts the prepared
+// test runs by default.
+var DefaultPreparedStmts = []int{100, 1_000, 10_000}
+
+// PreparedPoint is the prepared test outcome for one statement count. Direct
+// is nil when no direct endpoint was given.
+type PreparedPoint struct {
+	Stmts  int
+	Direct *BenchStats
+	Proxy  BenchStats
+}
+
+// PrintPrepared prints prepare and execute latency per statement count, and
+// flags a proxy whose execute latency grows with the number of statements it
+// has to track.
+func PrintPrepared(points []PreparedPoint) {
+	fmt.Println()
+	fmt.Println("╔═════════════════════════════════════════════════════════════╗")
+	fmt.Println("║  PREPARED STATEMENTS (p50 prepare / execute)                ║")
+	fmt.Println("╠════════════╦═══════════════════════╦════════════════════════╣")
+	fmt.Println("║  Stmts     ║  Direct               ║  Proxy                 ║")
+	fmt.Println("╠════════════╬═══════════════════════╬════════════════════════╣")
+	for _, p := range points {
+		direct := "-"
+		if p.Direct != nil {
+			direct = p50s(*p.Direct)
+		}
+		fmt.Printf("║  %-9d ║  %-20s ║  %-21s ║\n", p.Stmts, direct, p50s(p.Proxy))
+	}
+	fmt.Println("╚════════════╩═══════════════════════╩════════════════════════╝")
+
+	for _, p := range points {
+		if p.Proxy.Errors > 0 {
+			Warnf("%d errors through the proxy at %d statements — statements may be evicted or mixed up", p.Proxy.Errors, p.Stmts)
+		}
+	}
+	if len(points) < 2 {
+		return
+	}
+	small := findOp(points[0].Proxy, "execute").LatencyP50
+	large := findOp(points[len(points)-1].Proxy, "execute").LatencyP50
+	if small > 0 && large > 2*small {
+		Warnf("Proxy execute latency grows %.1fx from %d to %d statements — statement tracking may not scale",
+			float64(large)/float64(small), points[0].Stmts, points[len(points)-1].Stmts)
+	}
+}
+
+// p50s formats the prepare and execute p50 latencies of s.
+func p50s(s BenchStats) string {
+	return FmtDur(findOp(s, "prepare").LatencyP50) + " / " + FmtDur(findOp(s, "execute").LatencyP50)
+}
+
+// findOp returns the stats of op in s, zero if s has none.
+func findOp(s BenchStats, op string) OpStats {
+	for _, o := range s.Ops {
+		if o.Op == op {
+			return o
+		}
+	}
+	return OpStats{}
+}
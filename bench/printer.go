@@ -2,6 +2,8 @@ package bench
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -11,24 +13,55 @@ func PrintStats(s BenchStats) {
 	fmt.Printf("├─────────────────────────────────────────┤\n")
 	fmt.Printf("│  Queries:      %-24d│\n", s.Total)
 	fmt.Printf("│  Errors:       %-24d│\n", s.Errors)
+	if s.Ramp > 0 {
+		fmt.Printf("│  Ramp (unmeasured): %-18d│\n", s.Ramp)
+	}
+	if s.Deadlocks > 0 || s.LockTimeouts > 0 {
+		fmt.Printf("│  Deadlocks:    %-24d│\n", s.Deadlocks)
+		fmt.Printf("│  Lock timeouts:%-24s│\n", fmt.Sprintf(" %d", s.LockTimeouts))
+	}
+	if s.Serialization > 0 {
+		fmt.Printf("│  Serialization:%-24s│\n", fmt.Sprintf(" %d", s.Serialization))
+	}
 	fmt.Printf("│  Duration:     %-24s│\n", s.Duration.Round(time.Millisecond))
 	fmt.Printf("│  QPS:          %-24.1f│\n", s.QPS)
+	if s.Bytes > 0 {
+		fmt.Printf("│  MB/s:         %-24.2f│\n", s.MBps)
+	}
+	if s.Rows > 0 {
+		fmt.Printf("│  Rows/s:       %-24.0f│\n", s.RowsPerSec)
+	}
 	fmt.Printf("├─────────────────────────────────────────┤\n")
+	if s.FirstRowP50 > 0 {
+		fmt.Printf("│  1st row p50:  %-24s│\n", FmtDur(s.FirstRowP50))
+	}
 	fmt.Printf("│  Latency avg:  %-24s│\n", FmtDur(s.LatencyAvg))
 	fmt.Printf("│  Latency min:  %-24s│\n", FmtDur(s.LatencyMin))
 	fmt.Printf("│  Latency max:  %-24s│\n", FmtDur(s.LatencyMax))
-	fmt.Printf("│  Latency p50:  %-24s│\n", FmtDur(s.LatencyP50))
-	fmt.Printf("│  Latency p75:  %-24s│\n", FmtDur(s.LatencyP75))
-	fmt.Printf("│  Latency p90:  %-24s│\n", FmtDur(s.LatencyP90))
-	fmt.Printf("│  Latency p95:  %-24s│\n", FmtDur(s.LatencyP95))
-	fmt.Printf("│  Latency p99:  %-24s│\n", FmtDur(s.LatencyP99))
+	if len(s.Percentiles) > 0 {
+		for _, p := range s.Percentiles {
+			fmt.Printf("│  %-38s│\n", fmt.Sprintf("%-13s %s", "Latency "+FmtPct(p.P)+":", FmtDur(p.Latency)))
+		}
+	} else {
+		fmt.Printf("│  Latency p50:  %-24s│\n", FmtDur(s.LatencyP50))
+		fmt.Printf("│  Latency p75:  %-24s│\n", FmtDur(s.LatencyP75))
+		fmt.Printf("│  Latency p90:  %-24s│\n", FmtDur(s.LatencyP90))
+		fmt.Printf("│  Latency p95:  %-24s│\n", FmtDur(s.LatencyP95))
+		fmt.Printf("│  Latency p99:  %-24s│\n", FmtDur(s.LatencyP99))
+	}
+	printTrimmed(s.Trimmed)
+	printSLO(s.SLO)
+	if len(s.Ops) > 1 {
+		fmt.Printf("├─────────────────────────────────────────┤\n")
+		for _, o := range s.Ops {
+			fmt.Printf("│  %-14s%-24s│\n", o.Op+":", "p50 "+FmtDur(o.LatencyP50)+"  p99 "+FmtDur(o.LatencyP99))
+		}
+	}
 	fmt.Printf("└─────────────────────────────────────────┘\n")
 }
 
 func PrintComparison(proxy, direct BenchStats) {
-	overhead := proxy.LatencyP50 - direct.LatencyP50
-	overheadPct := float64(overhead) / float64(direct.LatencyP50) * 100
-	qpsDrop := (direct.QPS - proxy.QPS) / direct.QPS * 100
+	c := Compare(proxy, direct)
 
 	fmt.Printf("\n╔═════════════════════════════════════════════════════════════╗\n")
 	fmt.Printf("║  PROXY OVERHEAD COMPARISON                                 ║\n")
@@ -36,13 +69,23 @@ func PrintComparison(proxy, direct BenchStats) {
 	fmt.Printf("║  Metric           ║  Direct        ║  Through Proxy         ║\n")
 	fmt.Printf("╠═══════════════════╬════════════════╬════════════════════════╣\n")
 	fmt.Printf("║  QPS              ║  %-13.1f ║  %-21.1f ║\n", direct.QPS, proxy.QPS)
+	if direct.Bytes > 0 || proxy.Bytes > 0 {
+		fmt.Printf("║  MB/s             ║  %-13.2f ║  %-21.2f ║\n", direct.MBps, proxy.MBps)
+	}
+	if direct.Rows > 0 || proxy.Rows > 0 {
+		fmt.Printf("║  Rows/s           ║  %-13.0f ║  %-21.0f ║\n", direct.RowsPerSec, proxy.RowsPerSec)
+	}
+	if direct.Deadlocks+direct.LockTimeouts+proxy.Deadlocks+proxy.LockTimeouts > 0 {
+		fmt.Printf("║  Deadlocks        ║  %-13d ║  %-21d ║\n", direct.Deadlocks, proxy.Deadlocks)
+		fmt.Printf("║  Lock timeouts    ║  %-13d ║  %-21d ║\n", direct.LockTimeouts, proxy.LockTimeouts)
+	}
 	fmt.Printf("║  Latency avg      ║  %-13s ║  %-21s ║\n", FmtDur(direct.LatencyAvg), FmtDur(proxy.LatencyAvg))
 	fmt.Printf("║  Latency p50      ║  %-13s ║  %-21s ║\n", FmtDur(direct.LatencyP50), FmtDur(proxy.LatencyP50))
 	fmt.Printf("║  Latency p95      ║  %-13s ║  %-21s ║\n", FmtDur(direct.LatencyP95), FmtDur(proxy.LatencyP95))
 	fmt.Printf("║  Latency p99      ║  %-13s ║  %-21s ║\n", FmtDur(direct.LatencyP99), FmtDur(proxy.LatencyP99))
 	fmt.Printf("╠═══════════════════╩════════════════╩════════════════════════╣\n")
-	fmt.Printf("║  Proxy Overhead (p50):  %-35s ║\n", fmt.Sprintf("%s (%.1f%%)", FmtDur(overhead), overheadPct))
-	fmt.Printf("║  QPS Drop:              %-35s ║\n", fmt.Sprintf("%.1f%%", qpsDrop))
+	fmt.Printf("║  Proxy Overhead (p50):  %-35s ║\n", fmt.Sprintf("%s (%.1f%%)", FmtDur(c.OverheadP50), c.OverheadPct))
+	fmt.Printf("║  QPS Drop:              %-35s ║\n", fmt.Sprintf("%.1f%%", c.QPSDropPct))
 	fmt.Printf("╚═════════════════════════════════════════════════════════════╝\n")
 }
 
@@ -73,10 +116,87 @@ func PrintIsolation(baseline, noise BenchStats) {
 	fmt.Println("╚═════════════════════════════════════════════════════════════╝")
 }
 
+// PrintResultDiff compares two saved results, matching stats tables by label.
+func PrintResultDiff(before, after *Result) {
+	fmt.Printf("\nBefore: %s / %s — %s\n", before.DB, before.Test, before.Started.Local().Format("2006-01-02 15:04:05"))
+	fmt.Printf("After:  %s / %s — %s\n", after.DB, after.Test, after.Started.Local().Format("2006-01-02 15:04:05"))
+
+	for _, b := range before.Stats {
+		var a *BenchStats
+		for i := range after.Stats {
+			if after.Stats[i].Label == b.Label {
+				a = &after.Stats[i]
+				break
+			}
+		}
+		if a == nil {
+			fmt.Printf("\n  (%s: not present in second result)\n", b.Label)
+			continue
+		}
+
+		fmt.Printf("\n╔═════════════════════════════════════════════════════════════╗\n")
+		fmt.Printf("║  %-59s║\n", b.Label)
+		fmt.Printf("╠═══════════════════╦════════════╦════════════╦═══════════════╣\n")
+		fmt.Printf("║  Metric           ║  Before    ║  After     ║  Change       ║\n")
+		fmt.Printf("╠═══════════════════╬════════════╬════════════╬═══════════════╣\n")
+		fmt.Printf("║  QPS              ║  %-9.1f ║  %-9.1f ║  %-12s ║\n", b.QPS, a.QPS, pctChange(b.QPS, a.QPS))
+		for _, row := range []struct {
+			name string
+			b, a time.Duration
+		}{
+			{"Latency p50", b.LatencyP50, a.LatencyP50},
+			{"Latency p95", b.LatencyP95, a.LatencyP95},
+			{"Latency p99", b.LatencyP99, a.LatencyP99},
+		} {
+			fmt.Printf("║  %-16s ║  %-9s ║  %-9s ║  %-12s ║\n", row.name, FmtDur(row.b), FmtDur(row.a), pctChange(float64(row.b), float64(row.a)))
+		}
+		fmt.Printf("║  Errors           ║  %-9d ║  %-9d ║  %-12s ║\n", b.Errors, a.Errors, "")
+		fmt.Printf("╚═══════════════════╩════════════╩════════════╩═══════════════╝\n")
+	}
+
+	if before.Comparison != nil && after.Comparison != nil {
+		fmt.Printf("\n  Proxy overhead (p50): %s (%.1f%%) → %s (%.1f%%)\n",
+			FmtDur(before.Comparison.OverheadP50), before.Comparison.OverheadPct,
+			FmtDur(after.Comparison.OverheadP50), after.Comparison.OverheadPct)
+	}
+}
+
+func pctChange(before, after float64) string {
+	if before == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%+.1f%%", (after-before)/before*100)
+}
+
+// ShortVersion trims a version() banner to its leading product/version part.
+func ShortVersion(v string) string {
+	if i := strings.Index(v, ","); i > 0 {
+		v = v[:i]
+	}
+	return v
+}
+
+// WarnVersionMismatch flags overhead comparisons whose two sides ran against
+// different server versions.
+func WarnVersionMismatch(direct, proxy string) {
+	if direct == "" || proxy == "" || direct == proxy {
+		return
+	}
+	fmt.Println()
+	fmt.Println("  ⚠️  SERVER VERSION MISMATCH — direct and proxy paths hit different backends:")
+	fmt.Printf("     Direct: %s\n", ShortVersion(direct))
+	fmt.Printf("     Proxy:  %s\n", ShortVersion(proxy))
+}
+
+// FmtPct names a percentile: p50, p99.9.
+func FmtPct(p float64) string {
+	return "p" + strconv.FormatFloat(p, 'f', -1, 64)
+}
+
 func FmtDur(d time.Duration) string {
 	us := float64(d.Microseconds())
 	if us < 1000 {
 		return fmt.Sprintf("%.0fµs", us)
 	}
 	return fmt.Sprintf("%.2fms", us/1000)
-}
\ No newline at end of file
+}
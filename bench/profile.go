@@ -0,0 +1,64 @@
+package bench
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"path/filepath"
+	"runtime"
+	rpprof "runtime/pprof"
+)
+
+// ServePprof serves the standard /debug/pprof endpoints on addr for the
+// life of the process.
+func ServePprof(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	fmt.Printf("  pprof: http://%s/debug/pprof/\n", ln.Addr())
+	go http.Serve(ln, mux)
+	return nil
+}
+
+// CaptureProfiles starts a CPU profile into dir; the returned stop ends it
+// and writes a heap profile next to it, so both cover the measured run.
+func CaptureProfiles(dir string) (stop func(), err error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	cpuPath := filepath.Join(dir, "cpu.pprof")
+	f, err := os.Create(cpuPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := rpprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() {
+		rpprof.StopCPUProfile()
+		f.Close()
+		heapPath := filepath.Join(dir, "heap.pprof")
+		h, err := os.Create(heapPath)
+		if err != nil {
+			Warnf("Heap profile: %v", err)
+			return
+		}
+		defer h.Close()
+		runtime.GC() // up-to-date live heap
+		if err := rpprof.WriteHeapProfile(h); err != nil {
+			Warnf("Heap profile: %v", err)
+			return
+		}
+		Okf("Profiles written: %s, %s (go tool pprof <file>)", cpuPath, heapPath)
+	}, nil
+}
@@ -0,0 +1,90 @@
+package bench
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ProgressOut is where count-based runs draw their progress bar; nil turns
+// it off. cmd_run points it at the terminal, never at a pipe or file, since
+// the bar redraws itself with carriage returns.
+var ProgressOut io.Writer
+
+// ProgressInterval is how often the bar is redrawn.
+const ProgressInterval = 500 * time.Millisecond
+
+// progressWidth is the bar's length; progressCols pads each redraw so a
+// shorter line overwrites all of the one before it.
+const (
+	progressWidth = 24
+	progressCols  = 72
+)
+
+// Progress draws completed/total queries, throughput and an ETA while a
+// count-based run works through its queries. A nil *Progress, as
+// NewProgress returns when ProgressOut is unset, ignores every call.
+type Progress struct {
+	total int64
+	done  atomic.Int64
+	start time.Time
+	stop  chan struct{}
+	wg    sync.WaitGroup
+}
+
+// NewProgress starts drawing a bar for total queries.
+func NewProgress(total int) *Progress {
+	if ProgressOut == nil || total <= 0 {
+		return nil
+	}
+	p := &Progress{total: int64(total), start: time.Now(), stop: make(chan struct{})}
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		t := time.NewTicker(ProgressInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+				fmt.Fprintf(ProgressOut, "\r%-*s", progressCols, p.line())
+			case <-p.stop:
+				// Blank the bar so the tables that follow start clean
+				fmt.Fprintf(ProgressOut, "\r%*s\r", progressCols, "")
+				return
+			}
+		}
+	}()
+	return p
+}
+
+// Add counts n more queries as finished.
+func (p *Progress) Add(n int) {
+	if p != nil {
+		p.done.Add(int64(n))
+	}
+}
+
+// Done stops drawing and clears the bar.
+func (p *Progress) Done() {
+	if p == nil {
+		return
+	}
+	close(p.stop)
+	p.wg.Wait()
+}
+
+func (p *Progress) line() string {
+	done := min(p.done.Load(), p.total)
+	filled := int(done * progressWidth / p.total)
+	bar := strings.Repeat("#", filled) + strings.Repeat(".", progressWidth-filled)
+	elapsed := time.Since(p.start)
+	qps := float64(done) / elapsed.Seconds()
+	eta := "--"
+	if qps > 0 {
+		eta = time.Duration(float64(p.total-done) / qps * float64(time.Second)).Round(time.Second).String()
+	}
+	return fmt.Sprintf("  [%s] %d/%d %3.0f%%  %.0f q/s  ETA %s", bar, done, p.total, float64(done)*100/float64(p.total), qps, eta)
+}
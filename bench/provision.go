@@ -0,0 +1,81 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ProvisionTimeout bounds one tenant from create request to first
+// successful query.
+const ProvisionTimeout = 5 * time.Minute
+
+// ControlPlane creates and drops tenants; control.Client implements it.
+type ControlPlane interface {
+	CreateTenant(ctx context.Context, name string) (database string, err error)
+	WaitReady(ctx context.Context, name string) error
+	DeleteTenant(ctx context.Context, name string) error
+}
+
+// ProvisionTime is how long one fresh tenant took to come up.
+type ProvisionTime struct {
+	Tenant      string        `json:"tenant"`
+	Provisioned time.Duration `json:"provisioned_ns"` // create request to ready status
+	FirstQuery  time.Duration `json:"first_query_ns"` // create request to first successful query
+	Err         string        `json:"error,omitempty"`
+}
+
+// PrintProvision prints each tenant's provisioning times.
+func PrintProvision(times []ProvisionTime) {
+	fmt.Printf("\n┌─────────────────────────────────────────┐\n")
+	fmt.Printf("│  %-15s %-11s %-11s│\n", "Tenant", "Ready", "First query")
+	fmt.Printf("├─────────────────────────────────────────┤\n")
+	failed := 0
+	for _, t := range times {
+		if t.Err != "" {
+			failed++
+			fmt.Printf("│  %-15s %-23s│\n", t.Tenant, "failed")
+			continue
+		}
+		fmt.Printf("│  %-15s %-11s %-11s│\n", t.Tenant, FmtDur(t.Provisioned), FmtDur(t.FirstQuery))
+	}
+	fmt.Printf("└─────────────────────────────────────────┘\n")
+
+	if failed > 0 {
+		Failf("%d of %d tenants failed to provision", failed, len(times))
+	} else {
+		Okf("All %d tenants provisioned and answered queries", len(times))
+	}
+}
+
+// DropTenants deletes tenants through the control plane, reporting each.
+func DropTenants(ctx context.Context, cp ControlPlane, tenants []string) {
+	for _, name := range tenants {
+		if err := cp.DeleteTenant(ctx, name); err != nil {
+			Failf("%v", err)
+			continue
+		}
+		Okf("Dropped %s", name)
+	}
+}
+
+// ProvisionTenants creates names through the control plane and waits for
+// each, returning the databases to connect to. On error it returns the
+// tenants created so far so the caller can drop them.
+func ProvisionTenants(ctx context.Context, cp ControlPlane, names []string) (databases, created []string, err error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(len(names))*ProvisionTimeout)
+	defer cancel()
+	for _, name := range names {
+		db, err := cp.CreateTenant(ctx, name)
+		if err != nil {
+			return nil, created, err
+		}
+		created = append(created, name)
+		if err := cp.WaitReady(ctx, name); err != nil {
+			return nil, created, err
+		}
+		databases = append(databases, db)
+	}
+	Okf("Provisioned %d tenants", len(names))
+	return databases, created, nil
+}
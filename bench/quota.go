@@ -0,0 +1,48 @@
+package bench
+
+import "fmt"
+
+// QuotaReport is the outcome of pushing one tenant past its QPS quota while
+// bystander tenants run a steady load.
+type QuotaReport struct {
+	Quota     float64    `json:"quota_qps"`
+	Offered   float64    `json:"offered_qps"`
+	Missed    int        `json:"missed"` // queries skipped because every worker was busy
+	Tenant    BenchStats `json:"tenant"`
+	Baseline  BenchStats `json:"bystanders_alone"`
+	Pressured BenchStats `json:"bystanders_under_pressure"`
+}
+
+// PrintQuota prints how the proxy throttled the tenant — errors or queueing
+// — how close it held the quota, and whether bystanders noticed.
+func PrintQuota(r QuotaReport) {
+	achieved := r.Tenant.QPS
+	fmt.Println()
+	fmt.Println("╔═════════════════════════════════════════════════════════════╗")
+	fmt.Println("║  QUOTA ENFORCEMENT                                          ║")
+	fmt.Println("╠═════════════════════════════════════════════════════════════╣")
+	fmt.Printf("║  Quota / offered:     %-38s║\n", fmt.Sprintf("%.0f / %.0f QPS", r.Quota, r.Offered))
+	fmt.Printf("║  Achieved:            %-38s║\n", fmt.Sprintf("%.1f QPS (%.0f%% of quota)", achieved, achieved/r.Quota*100))
+	fmt.Printf("║  Throttled (errors):  %-38s║\n", fmt.Sprintf("%d of %d", r.Tenant.Errors, r.Tenant.Total))
+	fmt.Printf("║  Tenant p50 / p99:    %-38s║\n", FmtDur(r.Tenant.LatencyP50)+" / "+FmtDur(r.Tenant.LatencyP99))
+	fmt.Printf("║  Bystanders p50:      %-38s║\n", FmtDur(r.Baseline.LatencyP50)+" alone → "+FmtDur(r.Pressured.LatencyP50))
+	fmt.Println("╚═════════════════════════════════════════════════════════════╝")
+
+	switch {
+	case achieved > r.Quota*1.1:
+		Failf("Quota not enforced: %.1f QPS against a %.0f QPS quota", achieved, r.Quota)
+	case r.Tenant.Errors > 0:
+		Okf("Over-quota queries are rejected with errors")
+	default:
+		Okf("Over-quota queries are queued (no errors, latency absorbs the excess)")
+	}
+	if r.Missed > 0 {
+		Warnf("%d queries skipped because all workers were waiting — raise -concurrency", r.Missed)
+	}
+	if r.Baseline.LatencyP50 > 0 && r.Pressured.LatencyP50 > r.Baseline.LatencyP50*3/2 {
+		Warnf("Bystander p50 rose %s under pressure — throttling leaks onto other tenants",
+			pctChange(float64(r.Baseline.LatencyP50), float64(r.Pressured.LatencyP50)))
+	} else {
+		Okf("Bystander tenants unaffected")
+	}
+}
@@ -0,0 +1,116 @@
+package bench
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RampStep is one interval of a staged ramp: the tenants online during it
+// and the latency they saw, overall and per wave that had joined.
+type RampStep struct {
+	At      time.Duration   `json:"at_ns"`
+	Active  int             `json:"active_tenants"`
+	Queries int             `json:"queries"`
+	Errors  int             `json:"errors"`
+	QPS     float64         `json:"qps"`
+	P50     time.Duration   `json:"p50_ns"`
+	P99     time.Duration   `json:"p99_ns"`
+	WaveP50 []time.Duration `json:"wave_p50_ns"` // index = wave, joined waves only
+}
+
+// RampSteps splits per-tenant results of a ramp that started at start into
+// steps of every, tenant i having joined in wave i/perWave. Tenants with no
+// results never joined.
+func RampSteps(byTenant [][]QueryResult, perWave int, start time.Time, every, total time.Duration) []RampStep {
+	n := int((total + every - 1) / every)
+	steps := make([]RampStep, n)
+	all := make([][]time.Duration, n)
+	waves := make([][][]time.Duration, n)
+	for k := range steps {
+		steps[k].At = time.Duration(k) * every
+		waves[k] = make([][]time.Duration, min(k+1, (len(byTenant)+perWave-1)/perWave))
+	}
+	for t, results := range byTenant {
+		if len(results) == 0 {
+			continue
+		}
+		wave := t / perWave
+		for k := wave; k < n; k++ {
+			steps[k].Active++
+		}
+		for _, r := range results {
+			k := min(int(r.At.Sub(start)/every), n-1)
+			steps[k].Queries++
+			if r.Err != nil {
+				steps[k].Errors++
+				continue
+			}
+			all[k] = append(all[k], r.Duration)
+			if wave < len(waves[k]) {
+				waves[k][wave] = append(waves[k][wave], r.Duration)
+			}
+		}
+	}
+
+	for k := range steps {
+		width := min(every, total-steps[k].At)
+		steps[k].QPS = float64(len(all[k])) / width.Seconds()
+		sortDurations(all[k])
+		steps[k].P50 = pct(all[k], 50)
+		steps[k].P99 = pct(all[k], 99)
+		for _, w := range waves[k] {
+			sortDurations(w)
+			steps[k].WaveP50 = append(steps[k].WaveP50, pct(w, 50))
+		}
+	}
+	return steps
+}
+
+func sortDurations(d []time.Duration) {
+	sort.Slice(d, func(i, j int) bool { return d[i] < d[j] })
+}
+
+// PrintRamp charts p50 as tenants come online, then how each wave's p50
+// moved as later waves joined.
+func PrintRamp(steps []RampStep) {
+	var top time.Duration
+	for _, s := range steps {
+		top = max(top, s.P50)
+	}
+
+	fmt.Println()
+	fmt.Println("╔═════════════════════════════════════════════════════════════╗")
+	fmt.Println("║  STAGED RAMP                                                ║")
+	fmt.Println("╠═════════════════════════════════════════════════════════════╣")
+	fmt.Printf("║  %-7s %-7s %-7s %-8s %-8s %-16s ║\n", "At", "Tenants", "QPS", "p50", "p99", "p50")
+	for _, s := range steps {
+		bar := ""
+		if top > 0 {
+			bar = strings.Repeat("█", int(16*s.P50/top))
+		}
+		fmt.Printf("║  %-7s %-7d %-7.0f %-8s %-8s %-16s ║\n", s.At.Round(time.Second), s.Active, s.QPS,
+			FmtDur(s.P50), FmtDur(s.P99), bar)
+	}
+	fmt.Println("╚═════════════════════════════════════════════════════════════╝")
+
+	if len(steps) == 0 {
+		return
+	}
+	last := steps[len(steps)-1]
+	fmt.Println("  Wave p50, when it joined → at the end:")
+	for w, end := range last.WaveP50 {
+		if w >= len(steps) || w >= len(steps[w].WaveP50) {
+			break
+		}
+		fmt.Printf("    Wave %-3d %s → %s\n", w+1, FmtDur(steps[w].WaveP50[w]), FmtDur(end))
+	}
+	first, final := steps[0].P50, last.P50
+	if first > 0 && final > first*3/2 {
+		Warnf("p50 rose %s from %d to %d active tenants",
+			pctChange(float64(first), float64(final)), steps[0].Active, last.Active)
+	} else if first > 0 {
+		Okf("Latency held from %d to %d active tenants", steps[0].Active, last.Active)
+	}
+}
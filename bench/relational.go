@@ -0,0 +1,57 @@
+package bench
+
+import "fmt"
+
+// Shape of the relational dataset seeded with -relational: every account has
+// OrdersPerAccount orders and every order has ItemsPerOrder line items.
+// Orders and items carry explicit ids so any row can be derived from its id.
+const (
+	OrdersPerAccount = 2
+	ItemsPerOrder    = 3
+)
+
+var orderStatuses = []string{"pending", "paid", "shipped"}
+
+// OrdersTable is the orders table that sits next to the benchmark table.
+func (p BenchParams) OrdersTable() string { return p.TableName() + "_orders" }
+
+// ItemsTable is the order line items table that sits next to the benchmark table.
+func (p BenchParams) ItemsTable() string { return p.TableName() + "_order_items" }
+
+// IngestTable is the scratch table the ingest test loads into.
+func (p BenchParams) IngestTable() string { return p.TableName() + "_ingest" }
+
+// LagTable is the one-row table the replica test writes and polls.
+func (p BenchParams) LagTable() string { return p.TableName() + "_lag" }
+
+// OrderAccount is the account that order n belongs to.
+func OrderAccount(n int) int { return (n-1)/OrdersPerAccount + 1 }
+
+// OrderAmount is the deterministic amount of order n.
+func OrderAmount(n int) float64 { return float64(n*613%100000) / 100 }
+
+// OrderStatus is the deterministic status of order n.
+func OrderStatus(n int) string { return orderStatuses[n%len(orderStatuses)] }
+
+// ItemOrder is the order that line item n belongs to.
+func ItemOrder(n int) int { return (n-1)/ItemsPerOrder + 1 }
+
+// ItemSKU is the deterministic SKU of line item n.
+func ItemSKU(n int) string { return fmt.Sprintf("SKU-%05d", n*37%100000) }
+
+// ItemQty is the deterministic quantity of line item n.
+func ItemQty(n int) int { return n%5 + 1 }
+
+// ItemPrice is the deterministic unit price of line item n.
+func ItemPrice(n int) float64 { return float64(n*211%50000) / 100 }
+
+const payloadAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_"
+
+// Payload is the deterministic size-byte filler of row n for -row-bytes.
+func Payload(n, size int) string {
+	b := make([]byte, size)
+	for i := range b {
+		b[i] = payloadAlphabet[(n+i)%len(payloadAlphabet)]
+	}
+	return string(b)
+}
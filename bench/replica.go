@@ -0,0 +1,22 @@
+package bench
+
+import "fmt"
+
+// PrintLag prints the replica test summary: lag percentiles and the share
+// of reads that returned data older than the last write.
+func PrintLag(lag BenchStats, stale, reads int) {
+	pct := 0.0
+	if reads > 0 {
+		pct = float64(stale) / float64(reads) * 100
+	}
+	fmt.Println()
+	fmt.Printf("  Replication lag: p50 %s | p95 %s | p99 %s | max %s\n",
+		FmtDur(lag.LatencyP50), FmtDur(lag.LatencyP95), FmtDur(lag.LatencyP99), FmtDur(lag.LatencyMax))
+	fmt.Printf("  Stale reads:     %d of %d (%.1f%%)\n", stale, reads, pct)
+	switch {
+	case lag.Errors > 0:
+		Warnf("%d probes failed or never saw their write", lag.Errors)
+	case stale == 0:
+		Okf("Every write was visible on the first read — reads go to the primary or replication is synchronous")
+	}
+}
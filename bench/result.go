@@ -0,0 +1,119 @@
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Result is everything a single test invocation produced. Runners fill Stats
+// (and Comparison where it applies); main stamps the metadata.
+type Result struct {
+	DB           string            `json:"db"`
+	Test         string            `json:"test"`
+	Started      time.Time         `json:"started"`
+	Tags         map[string]string `json:"tags,omitempty"`
+	Manifest     Manifest          `json:"manifest"`
+	Stats        []BenchStats      `json:"stats"`
+	Comparison   *Comparison       `json:"comparison,omitempty"`    // overhead test only
+	Failover     *FailoverStats    `json:"failover,omitempty"`      // failover test only
+	ConnLimit    *ConnLimit        `json:"conn_limit,omitempty"`    // connlimit test only
+	Auth         []AuthStats       `json:"auth,omitempty"`          // auth test only
+	Quota        *QuotaReport      `json:"quota,omitempty"`         // quota test only
+	Idle         []IdleProbe       `json:"idle,omitempty"`          // idle test only
+	Soak         *SoakReport       `json:"soak,omitempty"`          // soak test only
+	Provision    []ProvisionTime   `json:"provision,omitempty"`     // provision test only
+	Churn        *ChurnReport      `json:"churn,omitempty"`         // churn test only
+	Ramp         []RampStep        `json:"ramp,omitempty"`          // scale test with -ramp-tenants
+	Client       *ClientStats      `json:"client,omitempty"`        // the bench process's own resource use
+	Server       *ServerStats      `json:"server,omitempty"`        // direct backend deltas with -server-stats
+	Waits        *WaitReport       `json:"waits,omitempty"`         // direct backend wait events with -wait-sample
+	Timeline     []TimelineBucket  `json:"timeline,omitempty"`      // with -server-stats
+	SlowQueries  []SlowQuery       `json:"slow_queries,omitempty"`  // with -slow-queries
+	Slowest      []SlowRequest     `json:"slowest,omitempty"`       // the -top-slow slowest requests
+	Workers      []WorkerStats     `json:"workers,omitempty"`       // per-worker counts and latency
+	Samples      []Sample          `json:"samples,omitempty"`       // the -sample-rate share of raw results
+	SampleGroups []SampleGroup     `json:"sample_groups,omitempty"` // per tenant and op, with -sample-rate
+	Histogram    *Histogram        `json:"histogram,omitempty"`     // every result, with -sample-rate
+	Container    *ContainerStats   `json:"container,omitempty"`     // proxy container with -docker-container
+	Suite        []*Result         `json:"suite,omitempty"`         // each test of -test all
+	Agents       []BenchStats      `json:"agents,omitempty"`        // each agent's share with -agents
+	Models       []ModelReport     `json:"models,omitempty"`        // each tenancy model of -compare-models
+}
+
+// Comparison is the direct-vs-proxy delta reported by the overhead test.
+type Comparison struct {
+	Direct      BenchStats    `json:"direct"`
+	Proxy       BenchStats    `json:"proxy"`
+	OverheadP50 time.Duration `json:"overhead_p50_ns"`
+	OverheadPct float64       `json:"overhead_pct"`
+	QPSDropPct  float64       `json:"qps_drop_pct"`
+}
+
+func Compare(proxy, direct BenchStats) Comparison {
+	c := Comparison{Direct: direct, Proxy: proxy}
+	c.OverheadP50 = proxy.LatencyP50 - direct.LatencyP50
+	if direct.LatencyP50 > 0 {
+		c.OverheadPct = float64(c.OverheadP50) / float64(direct.LatencyP50) * 100
+	}
+	if direct.QPS > 0 {
+		c.QPSDropPct = (direct.QPS - proxy.QPS) / direct.QPS * 100
+	}
+	return c
+}
+
+// WriteJSON writes the result, manifest included, as indented JSON.
+func WriteJSON(path string, r *Result) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// ReadJSON loads a result previously written by WriteJSON.
+func ReadJSON(path string) (*Result, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var r Result
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &r, nil
+}
+
+// FormatTags renders tags as a stable, sorted k=v list that ParseTags reads
+// back.
+func FormatTags(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + "=" + tags[k]
+	}
+	return strings.Join(parts, ",")
+}
+
+// ParseTags parses a comma-separated k=v list such as "env=staging,proxy=v1.4.2".
+func ParseTags(s string) (map[string]string, error) {
+	tags := map[string]string{}
+	if strings.TrimSpace(s) == "" {
+		return tags, nil
+	}
+	for _, kv := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(kv), "=")
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid tag %q (want key=value)", kv)
+		}
+		tags[k] = v
+	}
+	return tags, nil
+}
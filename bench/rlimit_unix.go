@@ -0,0 +1,22 @@
+//go:build unix
+
+package bench
+
+import "syscall"
+
+// raiseFDLimit raises the soft RLIMIT_NOFILE to want (capped at the hard
+// limit) and returns the resulting soft limit.
+func raiseFDLimit(want uint64) (uint64, error) {
+	var rl syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rl); err != nil {
+		return 0, err
+	}
+	if rl.Cur >= want {
+		return rl.Cur, nil
+	}
+	rl.Cur = min(want, rl.Max)
+	if err := syscall.Setrlimit(syscall.RLIMIT_NOFILE, &rl); err != nil {
+		return 0, err
+	}
+	return rl.Cur, nil
+}
@@ -1,13 +1,18 @@
 package bench
 
 import (
+	"context"
 	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // RunMultiple executes runFn N times, checks steady-state, returns median.
 // runFn receives the run index (0-based) and returns stats for that run.
-func RunMultiple(runs int, label string, runFn func(run int) BenchStats) BenchStats {
+// When ctx ends, the runs so far are summarized.
+func RunMultiple(ctx context.Context, runs int, label string, runFn func(run int) BenchStats) BenchStats {
 	if runs <= 1 {
 		return runFn(0)
 	}
@@ -17,23 +22,25 @@ func RunMultiple(runs int, label string, runFn func(run int) BenchStats) BenchSt
 	fmt.Printf("║  Methodology: median of %d runs, steady-state verified    ║\n", runs)
 	fmt.Printf("╚═══════════════════════════════════════════════════════════╝\n")
 
-	allRuns := make([]BenchStats, runs)
+	allRuns := make([]BenchStats, 0, runs)
 
-	for i := 0; i < runs; i++ {
+	for i := 0; i < runs && ctx.Err() == nil; i++ {
 		fmt.Printf("\n── Run %d/%d ──\n", i+1, runs)
-		allRuns[i] = runFn(i)
+		NextWorkerRun()
+		st := runFn(i)
+		allRuns = append(allRuns, st)
 
 		fmt.Printf("  Run %d: QPS=%.1f  p50=%s  p95=%s  errors=%d\n",
-			i+1, allRuns[i].QPS,
-			FmtDur(allRuns[i].LatencyP50),
-			FmtDur(allRuns[i].LatencyP95),
-			allRuns[i].Errors)
+			i+1, st.QPS, FmtDur(st.LatencyP50), FmtDur(st.LatencyP95), st.Errors)
 
 		// Cleanup pause between runs (not after last)
 		if i < runs-1 {
 			fmt.Print("  Cooling down (3s)...")
-			time.Sleep(3 * time.Second)
-			fmt.Println(" done")
+			if Sleep(ctx, 3*time.Second) {
+				fmt.Println(" done")
+			} else {
+				fmt.Println(" cancelled")
+			}
 		}
 	}
 
@@ -42,14 +49,14 @@ func RunMultiple(runs int, label string, runFn func(run int) BenchStats) BenchSt
 	fmt.Printf("\n── Steady-State Check ──\n")
 	fmt.Printf("  Max QPS deviation: %.1f%%\n", maxDev*100)
 	if steady {
-		fmt.Println("  ✅ PASSED (within ±5%)")
+		Okf("PASSED (within ±5%%)")
 	} else {
-		fmt.Printf("  ⚠️  FAILED (%.1f%% > 5%%) — results still reported as median\n", maxDev*100)
+		Warnf("FAILED (%.1f%% > 5%%) — results still reported as median", maxDev*100)
 	}
 
 	// Pick median
 	median := MedianStats(allRuns)
-	median.Label = label + " (median of " + fmt.Sprintf("%d", runs) + " runs)"
+	median.Label = label + " (median of " + fmt.Sprintf("%d", len(allRuns)) + " runs)"
 
 	// Summary table
 	fmt.Printf("\n╔═══════════════════════════════════════════════════════════╗\n")
@@ -69,4 +76,172 @@ func RunMultiple(runs int, label string, runFn func(run int) BenchStats) BenchSt
 	fmt.Println("  → = median (reported)")
 
 	return median
-}
\ No newline at end of file
+}
+
+// Sleep pauses for d and reports whether it did so in full; it returns
+// false as soon as ctx ends.
+func Sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Background runs fn on n goroutines until the returned stop is called;
+// stop waits for them and returns every result they recorded.
+func Background(n int, fn func() QueryResult) (stop func() []QueryResult) {
+	var (
+		mu      sync.Mutex
+		results []QueryResult
+		done    atomic.Bool
+		wg      sync.WaitGroup
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Pin(i)
+			var local []QueryResult
+			for !done.Load() {
+				local = append(local, fn())
+			}
+			Observe(local)
+			mu.Lock()
+			results = append(results, local...)
+			mu.Unlock()
+		}()
+	}
+	return func() []QueryResult {
+		done.Store(true)
+		wg.Wait()
+		return results
+	}
+}
+
+// Paced runs fn on n goroutines at qps queries per second, open loop, until
+// the returned stop is called. Queries due while every worker is busy are
+// skipped and counted as missed rather than queued client-side.
+func Paced(n int, qps float64, fn func() QueryResult) (stop func() (results []QueryResult, missed int)) {
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		done    = make(chan struct{})
+		tokens  = make(chan struct{}, n)
+		skipped int
+	)
+	go func() {
+		defer close(tokens)
+		start := time.Now()
+		tick := time.NewTicker(time.Millisecond)
+		defer tick.Stop()
+		sent := 0
+		for {
+			select {
+			case <-done:
+				return
+			case <-tick.C:
+			}
+			for due := int(qps * time.Since(start).Seconds()); sent < due; sent++ {
+				select {
+				case tokens <- struct{}{}:
+				default:
+					skipped++
+				}
+			}
+		}
+	}()
+
+	var results []QueryResult
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Pin(i)
+			var local []QueryResult
+			for range tokens {
+				local = append(local, fn())
+			}
+			Observe(local)
+			mu.Lock()
+			results = append(results, local...)
+			mu.Unlock()
+		}()
+	}
+	return func() ([]QueryResult, int) {
+		close(done)
+		wg.Wait()
+		return results, skipped
+	}
+}
+
+// Share splits total into n parts and returns part i's offset and size. The
+// first total%n parts get one more than the rest, so none of total is lost.
+func Share(total, n, i int) (offset, count int) {
+	q, r := total/n, total%n
+	count = q
+	if i < r {
+		count++
+	}
+	return i*q + min(i, r), count
+}
+
+// ShareDesc describes the part sizes Share gives: "N", or "N-N+1" when
+// total does not split evenly.
+func ShareDesc(total, n int) string {
+	if total%n == 0 {
+		return strconv.Itoa(total / n)
+	}
+	return fmt.Sprintf("%d-%d", total/n, total/n+1)
+}
+
+// RunWorkers runs fn on n goroutines, each returning its own results, and
+// returns them all with the wall time taken. Results are observed unless
+// ctx is Unobserved.
+func RunWorkers(ctx context.Context, label string, n int, fn func(worker int) []QueryResult) ([]QueryResult, time.Duration) {
+	var mu sync.Mutex
+	var results []QueryResult
+	start := time.Now()
+	Debugf("Starting %d workers", n)
+
+	var wg sync.WaitGroup
+	for w := 0; w < n; w++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			Pin(worker)
+			local := fn(worker)
+			if Observed(ctx) {
+				Observe(local)
+			}
+			ObserveWorker(label, worker, local)
+			Debugf("Worker %d finished: %d results", worker, len(local))
+			mu.Lock()
+			results = append(results, local...)
+			mu.Unlock()
+		}(w)
+	}
+	wg.Wait()
+	return results, time.Since(start)
+}
+
+// PrintErrors prints the first few failed results, prefixed by their op.
+func PrintErrors(results []QueryResult) {
+	shown := 0
+	for _, r := range results {
+		if r.Err == nil {
+			continue
+		}
+		if r.Op != "" {
+			Warnf("%s: %v", r.Op, r.Err)
+		} else {
+			Warnf("Error: %v", r.Err)
+		}
+		if shown++; shown == 5 {
+			return
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package bench
+
+import "testing"
+
+func TestShare(t *testing.T) {
+	tests := []struct {
+		total, n int
+		want     []int
+	}{
+		{10, 1, []int{10}},
+		{10, 5, []int{2, 2, 2, 2, 2}},
+		{10, 3, []int{4, 3, 3}},
+		{10, 4, []int{3, 3, 2, 2}},
+		{2, 5, []int{1, 1, 0, 0, 0}},
+		{0, 3, []int{0, 0, 0}},
+	}
+	for _, tt := range tests {
+		next, sum := 0, 0
+		for i, want := range tt.want {
+			offset, count := Share(tt.total, tt.n, i)
+			if offset != next || count != want {
+				t.Errorf("Share(%d, %d, %d) = %d, %d; want %d, %d", tt.total, tt.n, i, offset, count, next, want)
+			}
+			next += count
+			sum += count
+		}
+		if sum != tt.total {
+			t.Errorf("Share(%d, %d, i) parts add up to %d", tt.total, tt.n, sum)
+		}
+	}
+}
+
+func TestShareDesc(t *testing.T) {
+	tests := []struct {
+		total, n int
+		want     string
+	}{
+		{10, 5, "2"},
+		{10, 3, "3-4"},
+		{2, 5, "0-1"},
+	}
+	for _, tt := range tests {
+		if got := ShareDesc(tt.total, tt.n); got != tt.want {
+			t.Errorf("ShareDesc(%d, %d) = %q, want %q", tt.total, tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package bench
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Sample is one query kept by -sample-rate for analysis after the run.
+type Sample struct {
+	At       time.Time     `json:"at"`
+	Duration time.Duration `json:"duration_ns"`
+	Tenant   string        `json:"tenant,omitempty"`
+	Op       string        `json:"op,omitempty"`
+	Err      string        `json:"error,omitempty"`
+}
+
+// SampleGroup counts every result of one tenant and op, and how many of
+// them the sampler kept.
+type SampleGroup struct {
+	Tenant  string `json:"tenant,omitempty"`
+	Op      string `json:"op,omitempty"`
+	Results int64  `json:"results"`
+	Kept    int    `json:"kept"`
+}
+
+type sampleKey struct{ tenant, op string }
+
+// reservoir holds a uniform sample of at most the cap of the results
+// offered to it (algorithm R), so memory stays flat however long the run.
+type reservoir struct {
+	results int64 // every result of the group
+	offered int64 // those that passed the rate
+	kept    []Sample
+}
+
+var samples struct {
+	sync.Mutex
+	rate   float64
+	cap    int
+	groups map[sampleKey]*reservoir
+}
+
+// StartSamples begins keeping each result workers Observe with probability
+// rate, at most capacity per tenant and op (0 = no cap).
+func StartSamples(rate float64, capacity int) {
+	samples.Lock()
+	defer samples.Unlock()
+	samples.rate, samples.cap, samples.groups = rate, capacity, map[sampleKey]*reservoir{}
+}
+
+// Sampling reports whether -sample-rate is keeping samples, in which case
+// runners that support it stream their results instead of keeping them.
+func Sampling() bool {
+	samples.Lock()
+	defer samples.Unlock()
+	return samples.rate > 0
+}
+
+// StopSamples stops keeping results and returns those kept, in time order,
+// with each tenant and op's counts.
+func StopSamples() ([]Sample, []SampleGroup) {
+	samples.Lock()
+	groups := samples.groups
+	samples.rate, samples.groups = 0, nil
+	samples.Unlock()
+
+	var out []Sample
+	var counts []SampleGroup
+	for k, g := range groups {
+		out = append(out, g.kept...)
+		counts = append(counts, SampleGroup{Tenant: k.tenant, Op: k.op, Results: g.results, Kept: len(g.kept)})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].At.Before(out[j].At) })
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Tenant != counts[j].Tenant {
+			return counts[i].Tenant < counts[j].Tenant
+		}
+		return counts[i].Op < counts[j].Op
+	})
+	return out, counts
+}
+
+func offerSamples(tenant string, results []QueryResult) {
+	samples.Lock()
+	defer samples.Unlock()
+	if samples.rate <= 0 {
+		return
+	}
+	for _, r := range results {
+		k := sampleKey{tenant, r.Op}
+		g := samples.groups[k]
+		if g == nil {
+			g = &reservoir{}
+			samples.groups[k] = g
+		}
+		g.results++
+		if samples.rate < 1 && rand.Float64() >= samples.rate {
+			continue
+		}
+		g.offered++
+		s := Sample{At: r.At, Duration: r.Duration, Tenant: tenant, Op: r.Op}
+		if r.Err != nil {
+			s.Err = r.Err.Error()
+		}
+		switch {
+		case samples.cap <= 0 || len(g.kept) < samples.cap:
+			g.kept = append(g.kept, s)
+		default:
+			if i := rand.Int63n(g.offered); i < int64(samples.cap) {
+				g.kept[i] = s
+			}
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package bench
+
+import (
+	"math"
+	"runtime/metrics"
+	"time"
+)
+
+const schedMetric = "/sched/latencies:seconds"
+
+// readSched returns the runtime's cumulative histogram of how long
+// goroutines sat runnable before getting a P.
+func readSched() *metrics.Float64Histogram {
+	s := []metrics.Sample{{Name: schedMetric}}
+	metrics.Read(s)
+	if s[0].Value.Kind() != metrics.KindFloat64Histogram {
+		return nil
+	}
+	return s[0].Value.Float64Histogram()
+}
+
+// schedPercentiles returns p50, p99 and max scheduler latency between two
+// readSched snapshots, each as the upper bound of its bucket.
+func schedPercentiles(before, after *metrics.Float64Histogram) (p50, p99, maxLat time.Duration) {
+	if before == nil || after == nil {
+		return 0, 0, 0
+	}
+	counts := make([]uint64, len(after.Counts))
+	var total uint64
+	for i := range counts {
+		counts[i] = after.Counts[i] - before.Counts[i]
+		total += counts[i]
+	}
+	if total == 0 {
+		return 0, 0, 0
+	}
+	bound := func(i int) time.Duration {
+		b := after.Buckets[i+1]
+		if math.IsInf(b, 1) {
+			b = after.Buckets[i]
+		}
+		return time.Duration(b * float64(time.Second))
+	}
+	at := func(p float64) time.Duration {
+		want := uint64(math.Ceil(float64(total) * p / 100))
+		var seen uint64
+		for i, n := range counts {
+			if seen += n; seen >= want {
+				return bound(i)
+			}
+		}
+		return 0
+	}
+	for i := len(counts) - 1; i >= 0; i-- {
+		if counts[i] > 0 {
+			maxLat = bound(i)
+			break
+		}
+	}
+	return at(50), at(99), maxLat
+}
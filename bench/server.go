@@ -0,0 +1,142 @@
+package bench
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ServerCounter is how much one backend counter moved over the run. Gauges
+// such as connected threads also carry their value at the end.
+type ServerCounter struct {
+	Name  string `json:"name"`
+	Delta int64  `json:"delta"`
+	Gauge bool   `json:"gauge,omitempty"`
+	After int64  `json:"after,omitempty"`
+}
+
+// StatementDelta is one normalized statement's activity over the run.
+type StatementDelta struct {
+	Query     string        `json:"query"`
+	Calls     int64         `json:"calls"`
+	Rows      int64         `json:"rows"`
+	TotalTime time.Duration `json:"total_time_ns"`
+	BlksHit   int64         `json:"blks_hit,omitempty"`
+	BlksRead  int64         `json:"blks_read,omitempty"`
+}
+
+// ServerStats is the direct backend's counters diffed across the run, to
+// tell proxy overhead from work the backend itself did.
+type ServerStats struct {
+	Source     string           `json:"source"` // e.g. pg_stat_database
+	Counters   []ServerCounter  `json:"counters"`
+	Statements []StatementDelta `json:"statements,omitempty"` // top by total time
+	Note       string           `json:"note,omitempty"`       // why statements are missing
+	Events     []ServerEvent    `json:"events,omitempty"`     // checkpoints and autovacuum seen during the run
+}
+
+// TopStatements is how many statements ServerStats keeps.
+const TopStatements = 10
+
+// CounterDeltas diffs two snapshots of named counters, keeping names' order.
+func CounterDeltas(names []string, before, after map[string]int64) []ServerCounter {
+	out := make([]ServerCounter, 0, len(names))
+	for _, n := range names {
+		out = append(out, ServerCounter{Name: n, Delta: after[n] - before[n]})
+	}
+	return out
+}
+
+// GaugeValues reads named gauges from the closing snapshot.
+func GaugeValues(names []string, before, after map[string]int64) []ServerCounter {
+	out := CounterDeltas(names, before, after)
+	for i := range out {
+		out[i].Gauge, out[i].After = true, after[out[i].Name]
+	}
+	return out
+}
+
+// StatementDeltas diffs two per-statement snapshots keyed by statement id
+// and keeps the TopStatements that took the most time.
+func StatementDeltas(before, after map[string]StatementDelta) []StatementDelta {
+	var out []StatementDelta
+	for id, a := range after {
+		b := before[id]
+		d := StatementDelta{
+			Query:     a.Query,
+			Calls:     a.Calls - b.Calls,
+			Rows:      a.Rows - b.Rows,
+			TotalTime: a.TotalTime - b.TotalTime,
+			BlksHit:   a.BlksHit - b.BlksHit,
+			BlksRead:  a.BlksRead - b.BlksRead,
+		}
+		if d.Calls > 0 {
+			out = append(out, d)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].TotalTime > out[j].TotalTime })
+	if len(out) > TopStatements {
+		out = out[:TopStatements]
+	}
+	return out
+}
+
+// Counter returns the named counter's delta, or 0.
+func (s *ServerStats) Counter(name string) int64 {
+	for _, c := range s.Counters {
+		if c.Name == name {
+			return c.Delta
+		}
+	}
+	return 0
+}
+
+// PrintServer prints the backend counter deltas and top statements.
+func PrintServer(s *ServerStats) {
+	fmt.Printf("\n┌─────────────────────────────────────────┐\n")
+	fmt.Printf("│  %-39s│\n", "Backend ("+s.Source+")")
+	fmt.Printf("├─────────────────────────────────────────┤\n")
+	for _, c := range s.Counters {
+		v := fmt.Sprintf("%d", c.Delta)
+		if c.Gauge {
+			v = fmt.Sprintf("%d (%+d)", c.After, c.Delta)
+		}
+		fmt.Printf("│  %-24s %13s │\n", c.Name, v)
+	}
+	fmt.Printf("└─────────────────────────────────────────┘\n")
+	if hit, read := s.Counter("blks_hit"), s.Counter("blks_read"); hit+read > 0 {
+		fmt.Printf("  Buffer hit ratio: %.2f%%\n", float64(hit)/float64(hit+read)*100)
+	}
+	if req, reads := s.Counter("Innodb_buffer_pool_read_requests"), s.Counter("Innodb_buffer_pool_reads"); req > 0 {
+		fmt.Printf("  Buffer pool hit ratio: %.2f%%\n", float64(req-reads)/float64(req)*100)
+	}
+	if w := s.Counter("Innodb_row_lock_waits"); w > 0 {
+		Warnf("%d InnoDB row lock waits (%d ms waited) — contention on the backend", w, s.Counter("Innodb_row_lock_time"))
+	}
+	if len(s.Events) > 0 {
+		kinds := map[string]int{}
+		for _, e := range s.Events {
+			kinds[e.Kind]++
+		}
+		fmt.Printf("  Backend events: %d checkpoint(s), %d autovacuum run(s)\n", kinds["checkpoint"], kinds["autovacuum"])
+	}
+	if s.Counter("temp_bytes") > 0 {
+		Warnf("Backend spilled %d MB to temp files — work_mem, not the proxy, may explain slow queries", s.Counter("temp_bytes")/1e6)
+	}
+
+	if len(s.Statements) == 0 {
+		if s.Note != "" {
+			fmt.Printf("  Statements: %s\n", s.Note)
+		}
+		return
+	}
+	fmt.Printf("\n  Top statements by backend time:\n")
+	fmt.Printf("  %10s  %10s  %10s  %s\n", "calls", "total", "mean", "query")
+	for _, st := range s.Statements {
+		q := st.Query
+		if len(q) > 60 {
+			q = q[:57] + "..."
+		}
+		fmt.Printf("  %10d  %10s  %10s  %s\n", st.Calls, FmtDur(st.TotalTime), FmtDur(st.TotalTime/time.Duration(st.Calls)), q)
+	}
+}
@@ -0,0 +1,83 @@
+package bench
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// TenantRows draws a seed row count for each of n tenants from a log-normal
+// spread between lo and hi (±3σ in log space), clamped to that range. The
+// draw is fixed per tenant index, so reruns reuse the seeded data.
+func TenantRows(n, lo, hi int) []int {
+	r := rand.New(rand.NewSource(int64(n)))
+	mu := (math.Log(float64(lo)) + math.Log(float64(hi))) / 2
+	sigma := (math.Log(float64(hi)) - math.Log(float64(lo))) / 6
+	rows := make([]int, n)
+	for i := range rows {
+		v := int(math.Exp(mu + sigma*r.NormFloat64()))
+		rows[i] = min(max(v, lo), hi)
+	}
+	return rows
+}
+
+// PrintSizes groups tenants into quartiles by data size and prints the
+// median tenant p50/p99 of each, then compares the smallest quartile's p50
+// with the largest's.
+func PrintSizes(rows []int, perTenant []BenchStats) {
+	idx := make([]int, len(rows))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.Slice(idx, func(a, b int) bool { return rows[idx[a]] < rows[idx[b]] })
+
+	median := func(group []int, lat func(BenchStats) time.Duration) time.Duration {
+		var ds []time.Duration
+		for _, i := range group {
+			ds = append(ds, lat(perTenant[i]))
+		}
+		sort.Slice(ds, func(a, b int) bool { return ds[a] < ds[b] })
+		return ds[len(ds)/2]
+	}
+	p50 := func(s BenchStats) time.Duration { return s.LatencyP50 }
+	p99 := func(s BenchStats) time.Duration { return s.LatencyP99 }
+
+	fmt.Println()
+	fmt.Println("╔═════════════════════════════════════════════════════════════╗")
+	fmt.Println("║  TENANT DATA SIZES                                          ║")
+	fmt.Println("╠═════════════════════════════════════════════════════════════╣")
+	fmt.Printf("║  %-9s %-21s %7s %9s %9s ║\n", "Quartile", "Rows", "Tenants", "p50", "p99")
+	var groups [][]int
+	for q := 0; q < 4; q++ {
+		group := idx[q*len(idx)/4 : (q+1)*len(idx)/4]
+		if len(group) == 0 {
+			continue
+		}
+		groups = append(groups, group)
+		span := fmt.Sprintf("%d–%d", rows[group[0]], rows[group[len(group)-1]])
+		fmt.Printf("║  %-9s %-21s %7d %9s %9s ║\n", fmt.Sprintf("Q%d", q+1), span, len(group),
+			FmtDur(median(group, p50)), FmtDur(median(group, p99)))
+	}
+	fmt.Println("╚═════════════════════════════════════════════════════════════╝")
+	if len(groups) < 2 {
+		return
+	}
+
+	small, big := median(groups[0], p50), median(groups[len(groups)-1], p50)
+	if big > 0 && small*10 > big*8 {
+		Warnf("Smallest-quartile p50 %s is within 20%% of the largest quartile's %s", FmtDur(small), FmtDur(big))
+	} else {
+		Okf("Smallest-quartile p50 %s vs largest-quartile p50 %s", FmtDur(small), FmtDur(big))
+	}
+}
+
+// RowsFor is tenant i's seed row count: sizes[i] with heterogeneous sizes,
+// def otherwise.
+func RowsFor(sizes []int, i, def int) int {
+	if sizes == nil {
+		return def
+	}
+	return sizes[i]
+}
@@ -0,0 +1,87 @@
+package bench
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+// SkewPicker returns a function choosing a tenant index in [0,n) from a Zipf
+// distribution with exponent s (> 1), the first tenants the hottest. It is
+// not safe for concurrent use; give each worker its own.
+func SkewPicker(n int, s float64) func() int {
+	r := rand.New(rand.NewSource(rand.Int63()))
+	z := rand.NewZipf(r, s, 1, uint64(n-1))
+	return func() int { return int(z.Uint64()) }
+}
+
+// PrintSkew prints each tenant's share of a skewed load next to its
+// latency, hottest first, and whether the cold tenants were served as well
+// as the hot ones.
+func PrintSkew(perTenant []BenchStats) {
+	ranked := append([]BenchStats(nil), perTenant...)
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Total > ranked[j].Total })
+	var total int
+	for _, s := range ranked {
+		total += s.Total
+	}
+	if total == 0 {
+		return
+	}
+
+	fmt.Println()
+	fmt.Println("╔═════════════════════════════════════════════════════════════╗")
+	fmt.Println("║  SKEWED LOAD — PER-TENANT FAIRNESS                          ║")
+	fmt.Println("╠═════════════════════════════════════════════════════════════╣")
+	fmt.Printf("║  %-20s %8s %8s %9s %9s ║\n", "Tenant", "Share", "Queries", "p50", "p99")
+	row := func(s BenchStats) {
+		name := s.Label
+		if len(name) > 20 {
+			name = name[len(name)-20:]
+		}
+		fmt.Printf("║  %-20s %7.1f%% %8d %9s %9s ║\n", name,
+			float64(s.Total)/float64(total)*100, s.Total, FmtDur(s.LatencyP50), FmtDur(s.LatencyP99))
+	}
+	for i := 0; i < 5 && i < len(ranked); i++ {
+		row(ranked[i])
+	}
+	if len(ranked) > 8 {
+		fmt.Printf("║  %-59s║\n", "...")
+	}
+	for i := max(len(ranked)-3, 5); i < len(ranked); i++ {
+		row(ranked[i])
+	}
+	fmt.Println("╠═════════════════════════════════════════════════════════════╣")
+
+	// Tenants that saw too few queries for a meaningful p50 are left out
+	var fastest, slowest BenchStats
+	for _, s := range ranked {
+		if s.Total-s.Errors < 10 {
+			continue
+		}
+		if fastest.LatencyP50 == 0 || s.LatencyP50 < fastest.LatencyP50 {
+			fastest = s
+		}
+		if s.LatencyP50 > slowest.LatencyP50 {
+			slowest = s
+		}
+	}
+	if fastest.LatencyP50 == 0 {
+		fmt.Println("╚═════════════════════════════════════════════════════════════╝")
+		return
+	}
+	ratio := float64(slowest.LatencyP50) / float64(fastest.LatencyP50)
+	hotShare := float64(ranked[0].Total) / float64(total) * 100
+	fmt.Printf("║  Hottest tenant share:  %-36s║\n", fmt.Sprintf("%.1f%%", hotShare))
+	fmt.Printf("║  Fairness ratio:        %-36s║\n", fmt.Sprintf("%.1fx (slowest/fastest p50)", ratio))
+	fmt.Println("╠═════════════════════════════════════════════════════════════╣")
+	switch {
+	case ratio < 3.0:
+		fmt.Println("║  ✅ FAIR — cold tenants served as well as hot ones          ║")
+	case ratio < 5.0:
+		fmt.Println("║  ⚠️  MODERATE — latency depends on tenant load               ║")
+	default:
+		fmt.Println("║  ❌ UNFAIR — hot tenants degrade the others                  ║")
+	}
+	fmt.Println("╚═════════════════════════════════════════════════════════════╝")
+}
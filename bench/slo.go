@@ -0,0 +1,75 @@
+package bench
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// SLOBounds, when set, make ComputeStats report the share of queries within
+// each bound and an Apdex score targeting the first (smallest) bound.
+var SLOBounds []time.Duration
+
+// SLOBucket is the share of queries, failed ones included, that succeeded
+// within Bound.
+type SLOBucket struct {
+	Bound time.Duration `json:"bound_ns"`
+	Pct   float64       `json:"pct"`
+}
+
+// SLOReport is the -slo breakdown of one set of stats. Apdex counts queries
+// within ApdexT as satisfied, within 4×ApdexT as tolerating (half weight)
+// and slower or failed ones as frustrated.
+type SLOReport struct {
+	Buckets []SLOBucket   `json:"buckets"`
+	ApdexT  time.Duration `json:"apdex_t_ns"`
+	Apdex   float64       `json:"apdex"`
+}
+
+// sloReport builds the report for total queries, within counting the
+// successful ones at or below a bound. It returns nil without SLOBounds.
+func sloReport(total int, within func(time.Duration) int) *SLOReport {
+	if len(SLOBounds) == 0 || total == 0 {
+		return nil
+	}
+	r := &SLOReport{ApdexT: SLOBounds[0]}
+	for _, b := range SLOBounds {
+		r.Buckets = append(r.Buckets, SLOBucket{Bound: b, Pct: float64(within(b)) / float64(total) * 100})
+	}
+	satisfied, tolerating := within(r.ApdexT), within(4*r.ApdexT)
+	r.Apdex = (float64(satisfied) + float64(tolerating-satisfied)/2) / float64(total)
+	return r
+}
+
+// sortedWithin counts the durations in sorted that are at most d.
+func sortedWithin(sorted []time.Duration) func(time.Duration) int {
+	return func(d time.Duration) int {
+		return sort.Search(len(sorted), func(i int) bool { return sorted[i] > d })
+	}
+}
+
+// ApdexRating is the customary name for an Apdex score's band.
+func ApdexRating(score float64) string {
+	switch {
+	case score >= 0.94:
+		return "excellent"
+	case score >= 0.85:
+		return "good"
+	case score >= 0.70:
+		return "fair"
+	case score >= 0.50:
+		return "poor"
+	}
+	return "unacceptable"
+}
+
+func printSLO(r *SLOReport) {
+	if r == nil {
+		return
+	}
+	fmt.Printf("├─────────────────────────────────────────┤\n")
+	for _, b := range r.Buckets {
+		fmt.Printf("│  %-38s│\n", fmt.Sprintf("%-13s %.2f%%", "Within "+b.Bound.String()+":", b.Pct))
+	}
+	fmt.Printf("│  %-38s│\n", fmt.Sprintf("%-13s %.2f (%s)", "Apdex "+r.ApdexT.String()+":", r.Apdex, ApdexRating(r.Apdex)))
+}
@@ -0,0 +1,110 @@
+package bench
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SlowQuery is one of the slowest individual statements of a run.
+type SlowQuery struct {
+	At       time.Time     `json:"at"`
+	Duration time.Duration `json:"duration_ns"`
+	Server   string        `json:"server"` // host:port the statement went to
+	Tenant   string        `json:"tenant"` // database
+	SQL      string        `json:"sql"`
+	Params   []string      `json:"params,omitempty"`
+	Err      string        `json:"error,omitempty"`
+	Plan     string        `json:"plan,omitempty"` // EXPLAIN on the direct backend
+	Args     []any         `json:"-"`
+}
+
+// SlowLog keeps the N slowest statements it is offered.
+type SlowLog struct {
+	n       int
+	mu      sync.Mutex
+	queries []SlowQuery  // slowest first
+	floor   atomic.Int64 // fastest kept duration once full, to skip the lock
+}
+
+// Slow, when set, collects the slowest statements from connections opened
+// after it was set.
+var Slow *SlowLog
+
+func NewSlowLog(n int) *SlowLog {
+	return &SlowLog{n: n}
+}
+
+// Wants reports whether a statement taking d could make the log, so
+// callers can skip building the rest of it.
+func (l *SlowLog) Wants(d time.Duration) bool {
+	return int64(d) > l.floor.Load()
+}
+
+// Add offers a statement to the log.
+func (l *SlowLog) Add(q SlowQuery) {
+	if !l.Wants(q.Duration) {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	i := sort.Search(len(l.queries), func(i int) bool { return l.queries[i].Duration < q.Duration })
+	if i >= l.n {
+		return
+	}
+	l.queries = append(l.queries, SlowQuery{})
+	copy(l.queries[i+1:], l.queries[i:])
+	l.queries[i] = q
+	if len(l.queries) > l.n {
+		l.queries = l.queries[:l.n]
+	}
+	if len(l.queries) == l.n {
+		l.floor.Store(int64(l.queries[l.n-1].Duration))
+	}
+}
+
+// Queries returns the kept statements, slowest first.
+func (l *SlowLog) Queries() []SlowQuery {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]SlowQuery(nil), l.queries...)
+}
+
+// FormatArg renders a statement parameter for the report, truncating
+// payloads.
+func FormatArg(a any) string {
+	if b, ok := a.([]byte); ok {
+		return fmt.Sprintf("<%d bytes>", len(b))
+	}
+	s := fmt.Sprint(a)
+	if len(s) > 64 {
+		s = s[:61] + "..."
+	}
+	return s
+}
+
+// PrintSlow prints the slowest statements with their parameters and plans.
+func PrintSlow(queries []SlowQuery) {
+	if len(queries) == 0 {
+		return
+	}
+	fmt.Printf("\n── %d Slowest Queries ──\n", len(queries))
+	for i, q := range queries {
+		fmt.Printf("\n  #%d  %s  %s/%s  at %s\n", i+1, FmtDur(q.Duration), q.Server, q.Tenant, q.At.Local().Format("15:04:05.000"))
+		fmt.Printf("      %s\n", strings.Join(strings.Fields(q.SQL), " "))
+		if len(q.Params) > 0 {
+			fmt.Printf("      params: %s\n", strings.Join(q.Params, ", "))
+		}
+		if q.Err != "" {
+			fmt.Printf("      ✗ %s\n", q.Err)
+		}
+		for _, line := range strings.Split(strings.TrimRight(q.Plan, "\n"), "\n") {
+			if line != "" {
+				fmt.Printf("      │ %s\n", line)
+			}
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package bench
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SlowRequest is one of the slowest requests a benchmark worker made.
+type SlowRequest struct {
+	At       time.Time     `json:"at"`
+	Duration time.Duration `json:"duration_ns"`
+	Tenant   string        `json:"tenant,omitempty"`
+	Op       string        `json:"op,omitempty"`
+	Err      string        `json:"error,omitempty"`
+}
+
+var slowest struct {
+	sync.Mutex
+	n        int
+	requests []SlowRequest // slowest first
+}
+
+// StartSlowest begins keeping the n slowest requests workers Observe.
+func StartSlowest(n int) {
+	slowest.Lock()
+	defer slowest.Unlock()
+	slowest.n, slowest.requests = n, nil
+}
+
+// StopSlowest stops keeping requests and returns them, slowest first.
+func StopSlowest() []SlowRequest {
+	slowest.Lock()
+	defer slowest.Unlock()
+	out := slowest.requests
+	slowest.n, slowest.requests = 0, nil
+	return out
+}
+
+func offerSlowest(tenant string, results []QueryResult) {
+	slowest.Lock()
+	defer slowest.Unlock()
+	for _, r := range results {
+		if slowest.n == 0 {
+			return
+		}
+		if len(slowest.requests) == slowest.n && r.Duration <= slowest.requests[slowest.n-1].Duration {
+			continue
+		}
+		req := SlowRequest{At: r.At, Duration: r.Duration, Tenant: tenant, Op: r.Op}
+		if r.Err != nil {
+			req.Err = r.Err.Error()
+		}
+		i := sort.Search(len(slowest.requests), func(i int) bool { return slowest.requests[i].Duration < r.Duration })
+		slowest.requests = append(slowest.requests, SlowRequest{})
+		copy(slowest.requests[i+1:], slowest.requests[i:])
+		slowest.requests[i] = req
+		if len(slowest.requests) > slowest.n {
+			slowest.requests = slowest.requests[:slowest.n]
+		}
+	}
+}
+
+// PrintSlowest lists the slowest requests; tenant and op fill in for
+// requests that did not record their own.
+func PrintSlowest(requests []SlowRequest, tenant, op string) {
+	if len(requests) == 0 {
+		return
+	}
+	fmt.Printf("\n── %d Slowest Requests ──\n", len(requests))
+	fmt.Printf("  %-12s  %10s  %-24s  %-10s  %s\n", "at", "latency", "tenant", "op", "")
+	for _, r := range requests {
+		t, o := r.Tenant, r.Op
+		if t == "" {
+			t = tenant
+		}
+		if o == "" {
+			o = op
+		}
+		status := "✓"
+		if r.Err != "" {
+			status = "✗ " + r.Err
+		}
+		fmt.Printf("  %-12s  %10s  %-24s  %-10s  %s\n", r.At.Local().Format("15:04:05.000"), FmtDur(r.Duration), t, o, status)
+	}
+}
@@ -0,0 +1,89 @@
+package bench
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// SoakWindows is how many equal windows a soak run is split into.
+const SoakWindows = 10
+
+// SoakQueryTimeout bounds one soak query so a dead connection shows up as an
+// error instead of stalling its worker.
+const SoakQueryTimeout = 10 * time.Second
+
+// SoakWindow is one slice of a soak run, Start after its beginning.
+type SoakWindow struct {
+	Start   time.Duration `json:"start_ns"`
+	Queries int           `json:"queries"`
+	Errors  int           `json:"errors"`
+	P50     time.Duration `json:"p50_ns"`
+	P99     time.Duration `json:"p99_ns"`
+}
+
+// SoakReport is the outcome of holding connections open with sparse queries.
+type SoakReport struct {
+	Conns       int           `json:"conns"`
+	Interval    time.Duration `json:"interval_ns"`
+	Disconnects int           `json:"disconnects"`             // connections lost and reopened
+	FirstDrop   time.Duration `json:"first_drop_ns,omitempty"` // age of the first one lost
+	OldestConn  time.Duration `json:"oldest_conn_ns"`          // longest a connection stayed up
+	Drift       float64       `json:"drift"`                   // last window p50 / first window p50
+	Windows     []SoakWindow  `json:"windows"`
+}
+
+// SummarizeSoak buckets results into SoakWindows windows of a run that
+// started at start and lasted total, and fills r's windows and drift.
+func SummarizeSoak(r *SoakReport, results []QueryResult, start time.Time, total time.Duration) {
+	width := total / SoakWindows
+	if width <= 0 {
+		width = total
+	}
+	windows := make([]SoakWindow, SoakWindows)
+	lats := make([][]time.Duration, SoakWindows)
+	for i := range windows {
+		windows[i].Start = time.Duration(i) * width
+	}
+	for _, q := range results {
+		i := min(int(q.At.Sub(start)/width), SoakWindows-1)
+		windows[i].Queries++
+		if q.Err != nil {
+			windows[i].Errors++
+			continue
+		}
+		lats[i] = append(lats[i], q.Duration)
+	}
+	for i, l := range lats {
+		sort.Slice(l, func(a, b int) bool { return l[a] < l[b] })
+		windows[i].P50 = pct(l, 50)
+		windows[i].P99 = pct(l, 99)
+	}
+	r.Windows = windows
+	if first, last := windows[0].P50, windows[SoakWindows-1].P50; first > 0 && last > 0 {
+		r.Drift = float64(last) / float64(first)
+	}
+}
+
+// PrintSoak prints latency per window and whether connections aged well.
+func PrintSoak(r SoakReport) {
+	fmt.Printf("\n┌─────────────────────────────────────────┐\n")
+	fmt.Printf("│  %-9s %-7s %-6s %-7s %-7s│\n", "At", "Queries", "Errors", "p50", "p99")
+	fmt.Printf("├─────────────────────────────────────────┤\n")
+	for _, w := range r.Windows {
+		fmt.Printf("│  %-9s %-7d %-6d %-7s %-7s│\n", w.Start.Round(time.Second), w.Queries, w.Errors, FmtDur(w.P50), FmtDur(w.P99))
+	}
+	fmt.Printf("└─────────────────────────────────────────┘\n")
+
+	fmt.Printf("  Oldest connection: %s\n", r.OldestConn.Round(time.Second))
+	if r.Disconnects > 0 {
+		Failf("%d spurious disconnects, the first on a connection %s old", r.Disconnects, r.FirstDrop.Round(time.Second))
+	} else {
+		Okf("All %d connections stayed up", r.Conns)
+	}
+	if r.Drift > 1.5 {
+		Warnf("p50 drifted %.1fx from the first window to the last", r.Drift)
+	} else if r.Drift > 0 {
+		Okf("No latency drift (%.2fx)", r.Drift)
+	}
+}
@@ -1,29 +1,51 @@
 package bench
 
 import (
+	"context"
+	"errors"
 	"math"
 	"sort"
 	"time"
 )
 
+// Percentiles, when set, are reported by ComputeStats and PrintStats in
+// place of the fixed p50 to p99 rows.
+var Percentiles []float64
+
 func ComputeStats(label string, results []QueryResult, totalDuration time.Duration) BenchStats {
-	stats := BenchStats{Label: label, Total: len(results), Duration: totalDuration}
+	results, ramp := steady(measured(results))
+	stats := BenchStats{Label: label, Total: len(results), Duration: totalDuration, Ramp: ramp}
 
-	var durations []time.Duration
+	var durations, firstRows []time.Duration
 	for _, r := range results {
 		if r.Err != nil {
 			stats.Errors++
+			switch {
+			case errors.Is(r.Err, ErrDeadlock):
+				stats.Deadlocks++
+			case errors.Is(r.Err, ErrLockTimeout):
+				stats.LockTimeouts++
+			case errors.Is(r.Err, ErrSerialization):
+				stats.Serialization++
+			}
 			continue
 		}
 		durations = append(durations, r.Duration)
+		stats.Bytes += int64(r.Bytes)
+		stats.Rows += int64(r.Rows)
+		if r.FirstRow > 0 {
+			firstRows = append(firstRows, r.FirstRow)
+		}
 	}
 
+	stats.Ops = opStats(results)
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	stats.SLO = sloReport(len(results), sortedWithin(durations))
+
 	if len(durations) == 0 {
 		return stats
 	}
 
-	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
-
 	var sum time.Duration
 	for _, d := range durations {
 		sum += d
@@ -37,11 +59,90 @@ func ComputeStats(label string, results []QueryResult, totalDuration time.Durati
 	stats.LatencyP90 = pct(durations, 90)
 	stats.LatencyP95 = pct(durations, 95)
 	stats.LatencyP99 = pct(durations, 99)
+	for _, p := range Percentiles {
+		stats.Percentiles = append(stats.Percentiles, Percentile{P: p, Latency: pct(durations, p)})
+	}
+	stats.Trimmed = trimmed(durations)
 	stats.QPS = float64(len(durations)) / totalDuration.Seconds()
+	stats.MBps = float64(stats.Bytes) / 1e6 / totalDuration.Seconds()
+	stats.RowsPerSec = float64(stats.Rows) / totalDuration.Seconds()
+	if len(firstRows) > 0 {
+		sort.Slice(firstRows, func(i, j int) bool { return firstRows[i] < firstRows[j] })
+		stats.FirstRowP50 = pct(firstRows, 50)
+	}
 
 	return stats
 }
 
+// measured drops the queries that failed because the run was cancelled;
+// they say nothing about the database.
+func measured(results []QueryResult) []QueryResult {
+	for i, r := range results {
+		if r.Err != nil && errors.Is(r.Err, context.Canceled) {
+			out := append([]QueryResult(nil), results[:i]...)
+			for _, r := range results[i+1:] {
+				if r.Err == nil || !errors.Is(r.Err, context.Canceled) {
+					out = append(out, r)
+				}
+			}
+			return out
+		}
+	}
+	return results
+}
+
+// steady drops the queries marked by Window as falling in the warm-up or
+// cool-down window and returns how many there were.
+func steady(results []QueryResult) ([]QueryResult, int) {
+	for i, r := range results {
+		if r.Ramp {
+			out := append([]QueryResult(nil), results[:i]...)
+			for _, r := range results[i+1:] {
+				if !r.Ramp {
+					out = append(out, r)
+				}
+			}
+			return out, len(results) - len(out)
+		}
+	}
+	return results, 0
+}
+
+// opStats breaks results down by QueryResult.Op, in first-seen order. It
+// returns nil when no result carries an op.
+func opStats(results []QueryResult) []OpStats {
+	var order []string
+	byOp := map[string][]QueryResult{}
+	for _, r := range results {
+		if r.Op == "" {
+			continue
+		}
+		if _, ok := byOp[r.Op]; !ok {
+			order = append(order, r.Op)
+		}
+		byOp[r.Op] = append(byOp[r.Op], r)
+	}
+
+	var out []OpStats
+	for _, op := range order {
+		o := OpStats{Op: op, Total: len(byOp[op])}
+		var durations []time.Duration
+		for _, r := range byOp[op] {
+			if r.Err != nil {
+				o.Errors++
+				continue
+			}
+			durations = append(durations, r.Duration)
+		}
+		sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+		o.LatencyP50 = pct(durations, 50)
+		o.LatencyP95 = pct(durations, 95)
+		o.LatencyP99 = pct(durations, 99)
+		out = append(out, o)
+	}
+	return out
+}
+
 // MedianStats picks the median run by p50 latency from multiple runs.
 func MedianStats(runs []BenchStats) BenchStats {
 	if len(runs) == 1 {
@@ -87,4 +188,4 @@ func pct(sorted []time.Duration, p float64) time.Duration {
 		idx = len(sorted) - 1
 	}
 	return sorted[idx]
-}
\ No newline at end of file
+}
@@ -0,0 +1,44 @@
+package bench
+
+import "fmt"
+
+// DefaultStreamRows are the result sizes the stream test fetches by default.
+var DefaultStreamRows = []int{100, 10_000, 1_000_000}
+
+// StreamPoint is the stream test outcome for one result size. Direct is nil
+// when no direct endpoint was given.
+type StreamPoint struct {
+	Rows   int
+	Direct *BenchStats
+	Proxy  BenchStats
+}
+
+// PrintStreaming prints time-to-first-row and full streaming time per result
+// size, and flags a proxy whose first row arrives later as results grow —
+// the signature of buffering whole result sets.
+func PrintStreaming(points []StreamPoint) {
+	fmt.Println()
+	fmt.Println("╔═════════════════════════════════════════════════════════════╗")
+	fmt.Println("║  RESULT STREAMING (p50)                                     ║")
+	fmt.Println("╠════════════╦═══════════════════════╦════════════════════════╣")
+	fmt.Println("║  Rows      ║  Direct first / all   ║  Proxy first / all     ║")
+	fmt.Println("╠════════════╬═══════════════════════╬════════════════════════╣")
+	for _, p := range points {
+		direct := "-"
+		if p.Direct != nil {
+			direct = FmtDur(p.Direct.FirstRowP50) + " / " + FmtDur(p.Direct.LatencyP50)
+		}
+		proxy := FmtDur(p.Proxy.FirstRowP50) + " / " + FmtDur(p.Proxy.LatencyP50)
+		fmt.Printf("║  %-9d ║  %-20s ║  %-21s ║\n", p.Rows, direct, proxy)
+	}
+	fmt.Println("╚════════════╩═══════════════════════╩════════════════════════╝")
+
+	if len(points) < 2 {
+		return
+	}
+	small, large := points[0].Proxy, points[len(points)-1].Proxy
+	if small.FirstRowP50 > 0 && large.FirstRowP50 > 10*small.FirstRowP50 {
+		Warnf("Proxy first-row latency grows %.0fx from %d to %d rows — results may be buffered",
+			float64(large.FirstRowP50)/float64(small.FirstRowP50), points[0].Rows, points[len(points)-1].Rows)
+	}
+}
@@ -0,0 +1,71 @@
+package bench
+
+import "fmt"
+
+// Headline is the stats a result is judged by: the proxy side of a
+// comparison, otherwise its first stats.
+func (r *Result) Headline() (BenchStats, bool) {
+	if r.Comparison != nil {
+		return r.Comparison.Proxy, true
+	}
+	if len(r.Stats) > 0 {
+		return r.Stats[0], true
+	}
+	return BenchStats{}, false
+}
+
+// PrintSuite prints one line per test of a suite run with an overall verdict.
+func PrintSuite(results []*Result) {
+	fmt.Printf("\n╔═══════════════════════════════════════════════════════════╗\n")
+	fmt.Printf("║  SUITE SUMMARY                                            ║\n")
+	fmt.Printf("╠════════════╦══════════╦══════════╦══════════╦═════════════╣\n")
+	fmt.Printf("║ Test       ║   QPS    ║   p50    ║   p99    ║ Errors      ║\n")
+	fmt.Printf("╠════════════╬══════════╬══════════╬══════════╬═════════════╣\n")
+	var errors int
+	for _, r := range results {
+		st, ok := r.Headline()
+		if !ok {
+			fmt.Printf("║ %-10s ║ %8s ║ %8s ║ %8s ║ %-11s ║\n", r.Test, "-", "-", "-", "-")
+			continue
+		}
+		errors += st.Errors
+		fmt.Printf("║ %-10s ║ %8.1f ║ %8s ║ %8s ║ %-11d ║\n", r.Test, st.QPS, FmtDur(st.LatencyP50), FmtDur(st.LatencyP99), st.Errors)
+	}
+	fmt.Printf("╚════════════╩══════════╩══════════╩══════════╩═════════════╝\n")
+	for _, r := range results {
+		if r.Comparison != nil {
+			fmt.Printf("  Proxy overhead: %+.1f%% at p50 (%s)\n", r.Comparison.OverheadPct, FmtDur(r.Comparison.OverheadP50))
+		}
+	}
+	switch {
+	case len(results) == 0:
+		Failf("No test completed")
+	case errors == 0:
+		Okf("%d tests completed without errors", len(results))
+	default:
+		Warnf("%d tests completed with %d errors in total", len(results), errors)
+	}
+}
+
+// PrintMatrix prints one line per matrix cell, identified by its
+// matrix-* tags.
+func PrintMatrix(results []*Result) {
+	fmt.Printf("\n── Matrix Summary ──\n")
+	fmt.Printf("  %-16s %-8s %-11s %-9s %5s %10s %9s %9s %7s\n", "profile", "db", "test", "workload", "conc", "QPS", "p50", "p99", "errors")
+	for _, r := range results {
+		st, ok := r.Headline()
+		if !ok {
+			continue
+		}
+		fmt.Printf("  %-16s %-8s %-11s %-9s %5s %10.1f %9s %9s %7d\n",
+			orDash(r.Tags["matrix-profile"]), r.DB, r.Test, orDash(r.Tags["matrix-workload"]), orDash(r.Tags["matrix-concurrency"]),
+			st.QPS, FmtDur(st.LatencyP50), FmtDur(st.LatencyP99), st.Errors)
+	}
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
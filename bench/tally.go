@@ -0,0 +1,213 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// StreamBatch is how many results a worker holds before handing them on
+// while -sample-rate is sampling.
+const StreamBatch = 1024
+
+// Tally builds a run's stats from its results as they arrive instead of
+// from a slice of all of them, so a sampled run keeps memory flat however
+// long it goes. Latencies come from histograms, so they are within ~1%,
+// and trimmed latency is not reported.
+type Tally struct {
+	mu        sync.Mutex
+	stats     BenchStats // counters only
+	hist      *Histogram
+	firstRows *Histogram
+	ops       []string
+	byOp      map[string]*Histogram
+}
+
+func NewTally() *Tally {
+	return &Tally{hist: NewHistogram(), firstRows: NewHistogram(), byOp: map[string]*Histogram{}}
+}
+
+// Add counts results as ComputeStats would: cancelled queries are left out
+// and those Window marked Ramp are only counted as such.
+func (t *Tally) Add(results []QueryResult) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, r := range results {
+		if r.Err != nil && errors.Is(r.Err, context.Canceled) {
+			continue
+		}
+		if r.Ramp {
+			t.stats.Ramp++
+			continue
+		}
+		t.stats.Total++
+		t.hist.Record(r)
+		if r.Op != "" {
+			h := t.byOp[r.Op]
+			if h == nil {
+				h = NewHistogram()
+				t.byOp[r.Op] = h
+				t.ops = append(t.ops, r.Op)
+			}
+			h.Record(r)
+		}
+		if r.Err != nil {
+			t.stats.Errors++
+			switch {
+			case errors.Is(r.Err, ErrDeadlock):
+				t.stats.Deadlocks++
+			case errors.Is(r.Err, ErrLockTimeout):
+				t.stats.LockTimeouts++
+			case errors.Is(r.Err, ErrSerialization):
+				t.stats.Serialization++
+			}
+			continue
+		}
+		t.stats.Bytes += int64(r.Bytes)
+		t.stats.Rows += int64(r.Rows)
+		if r.FirstRow > 0 {
+			t.firstRows.Record(QueryResult{Duration: r.FirstRow})
+		}
+	}
+}
+
+// Stats reports the tally as ComputeStats would over totalDuration.
+func (t *Tally) Stats(label string, totalDuration time.Duration) BenchStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stats := t.stats
+	stats.Label, stats.Duration = label, totalDuration
+	for _, op := range t.ops {
+		h := t.byOp[op]
+		stats.Ops = append(stats.Ops, OpStats{Op: op, Total: int(h.N + h.Errors), Errors: int(h.Errors),
+			LatencyP50: h.Percentile(50), LatencyP95: h.Percentile(95), LatencyP99: h.Percentile(99)})
+	}
+	h := t.hist
+	stats.SLO = sloReport(stats.Total, func(d time.Duration) int { return int(h.Within(d)) })
+	if h.N == 0 {
+		return stats
+	}
+	latencyFrom(&stats, h)
+	stats.QPS = float64(h.N) / totalDuration.Seconds()
+	stats.MBps = float64(stats.Bytes) / 1e6 / totalDuration.Seconds()
+	stats.RowsPerSec = float64(stats.Rows) / totalDuration.Seconds()
+	if t.firstRows.N > 0 {
+		stats.FirstRowP50 = t.firstRows.Percentile(50)
+	}
+	return stats
+}
+
+// Sink collects the results of one run's workers. Normally it keeps them
+// all for ComputeStats; while Sampling, workers hand it batches that are
+// observed, tallied and dropped.
+type Sink struct {
+	observe bool
+	label   string
+	start   time.Time
+	params  BenchParams
+	keep    func(*QueryResult) bool // Window's, applied as batches arrive
+	tally   *Tally                  // nil unless streaming
+
+	mu      sync.Mutex
+	results []QueryResult
+	errs    []QueryResult // the first few, for PrintErrors
+}
+
+// NewSink starts collecting a run labelled label that started at start,
+// observing its results unless ctx is Unobserved.
+func NewSink(ctx context.Context, label string, start time.Time, params BenchParams) *Sink {
+	s := &Sink{observe: Observed(ctx), label: label, start: start, params: params}
+	if Sampling() {
+		s.tally, s.keep = NewTally(), windowFilter(start, params)
+	}
+	return s
+}
+
+// SinkWorker is one worker's side of a Sink.
+type SinkWorker struct {
+	sink   *Sink
+	id     int
+	tenant string
+	buf    []QueryResult
+}
+
+// Worker returns the handle worker id, running queries for tenant ("" for
+// none), adds its results through.
+func (s *Sink) Worker(id int, tenant string) *SinkWorker {
+	return &SinkWorker{sink: s, id: id, tenant: tenant}
+}
+
+// Add takes one result.
+func (w *SinkWorker) Add(r QueryResult) {
+	w.buf = append(w.buf, r)
+	if w.sink.tally != nil && len(w.buf) >= StreamBatch {
+		w.flush()
+	}
+}
+
+// Done hands on what the worker still holds; call it once it has finished.
+func (w *SinkWorker) Done() {
+	w.flush()
+}
+
+func (w *SinkWorker) flush() {
+	if len(w.buf) == 0 {
+		return
+	}
+	s := w.sink
+	if s.observe {
+		ObserveTenant(w.tenant, w.buf)
+	}
+	ObserveWorker(s.label, w.id, w.buf)
+	if s.tally == nil {
+		s.mu.Lock()
+		s.results = append(s.results, w.buf...)
+		s.mu.Unlock()
+		w.buf = nil
+		return
+	}
+	s.add(w.buf)
+	w.buf = w.buf[:0]
+}
+
+// AddResults takes results that are not a worker's, such as the
+// aggregation workers', without observing them.
+func (s *Sink) AddResults(results []QueryResult) {
+	if s.tally != nil {
+		s.add(results)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results = append(s.results, results...)
+}
+
+func (s *Sink) add(results []QueryResult) {
+	kept := results[:0:0]
+	for _, r := range results {
+		if s.keep(&r) {
+			kept = append(kept, r)
+		}
+	}
+	s.tally.Add(kept)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range kept {
+		if r.Err != nil && len(s.errs) < 5 {
+			s.errs = append(s.errs, r)
+		}
+	}
+}
+
+// Stats prints the first errors and returns the run's stats, elapsed being
+// how long it took including the drain.
+func (s *Sink) Stats(elapsed time.Duration) BenchStats {
+	if s.tally != nil {
+		PrintErrors(s.errs)
+		return s.tally.Stats(s.label, windowDuration(s.params, elapsed))
+	}
+	results, d := Window(s.results, s.start, s.params, elapsed)
+	PrintErrors(results)
+	return ComputeStats(s.label, results, d)
+}
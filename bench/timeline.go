@@ -0,0 +1,192 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// TimelineRows caps how many rows PrintTimeline shows; longer runs are
+// bucketed more coarsely.
+const TimelineRows = 60
+
+// ServerEvent is backend activity that can explain a latency spike.
+type ServerEvent struct {
+	At     time.Time `json:"at"`
+	Kind   string    `json:"kind"` // checkpoint, autovacuum
+	Detail string    `json:"detail,omitempty"`
+}
+
+// TimelineBucket is throughput and latency over one slice of the run, with
+// any backend events that fell inside it.
+type TimelineBucket struct {
+	Offset  time.Duration `json:"offset_ns"` // from the first query
+	Width   time.Duration `json:"width_ns"`
+	Queries int           `json:"queries"`
+	Errors  int           `json:"errors"`
+	QPS     float64       `json:"qps"`
+	P50     time.Duration `json:"p50_ns"`
+	P99     time.Duration `json:"p99_ns"`
+	Events  []string      `json:"events,omitempty"`
+	Spike   bool          `json:"spike,omitempty"` // p99 above twice the run's typical p99
+}
+
+type timelinePoint struct {
+	at  time.Time
+	dur time.Duration
+	err bool
+}
+
+var timeline struct {
+	sync.Mutex
+	on     bool
+	points []timelinePoint
+}
+
+// StartTimeline begins collecting what the benchmark workers Observe.
+func StartTimeline() {
+	timeline.Lock()
+	defer timeline.Unlock()
+	timeline.on, timeline.points = true, nil
+}
+
+type unobservedKey struct{}
+
+// Unobserved marks the queries run under ctx as a reference pass, such as
+// the direct endpoint's, which runners keep out of what Observe feeds: the
+// timeline, histogram, slowest requests and samples describe the proxy.
+func Unobserved(ctx context.Context) context.Context {
+	return context.WithValue(ctx, unobservedKey{}, true)
+}
+
+// Observed reports whether runners should Observe the queries run under ctx.
+func Observed(ctx context.Context) bool {
+	return ctx.Value(unobservedKey{}) == nil
+}
+
+// Observe adds a worker's results to the timeline and histogram when they
+// are running, and offers them to the slowest-request report and the
+// -sample-rate sampler.
+func Observe(results []QueryResult) {
+	ObserveTenant("", results)
+}
+
+// ObserveTenant is Observe for results that all went to one tenant.
+func ObserveTenant(tenant string, results []QueryResult) {
+	offerSlowest(tenant, results)
+	offerSamples(tenant, results)
+	recordHistogram(results)
+	recordTenant(tenant, results)
+	timeline.Lock()
+	defer timeline.Unlock()
+	if !timeline.on {
+		return
+	}
+	for _, r := range results {
+		timeline.points = append(timeline.points, timelinePoint{at: r.At, dur: r.Duration, err: r.Err != nil})
+	}
+}
+
+// StopTimeline ends collection and buckets what was observed into at most
+// TimelineRows slices, marking events and spikes.
+func StopTimeline(events []ServerEvent) []TimelineBucket {
+	timeline.Lock()
+	points := timeline.points
+	timeline.on, timeline.points = false, nil
+	timeline.Unlock()
+	if len(points) == 0 {
+		return nil
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].at.Before(points[j].at) })
+	first, last := points[0].at, points[len(points)-1].at
+	width := time.Second
+	for last.Sub(first) >= width*TimelineRows {
+		width *= 2
+	}
+
+	buckets := make([]TimelineBucket, int(last.Sub(first)/width)+1)
+	lat := make([][]time.Duration, len(buckets))
+	for i := range buckets {
+		buckets[i].Offset, buckets[i].Width = time.Duration(i)*width, width
+	}
+	for _, p := range points {
+		i := int(p.at.Sub(first) / width)
+		buckets[i].Queries++
+		if p.err {
+			buckets[i].Errors++
+			continue
+		}
+		lat[i] = append(lat[i], p.dur)
+	}
+	var p99s []time.Duration
+	for i := range buckets {
+		b := &buckets[i]
+		b.QPS = float64(b.Queries) / width.Seconds()
+		sort.Slice(lat[i], func(x, y int) bool { return lat[i][x] < lat[i][y] })
+		b.P50, b.P99 = pct(lat[i], 50), pct(lat[i], 99)
+		if b.P99 > 0 {
+			p99s = append(p99s, b.P99)
+		}
+	}
+	for _, e := range events {
+		i := int(e.At.Sub(first) / width)
+		if i < 0 || i >= len(buckets) {
+			continue
+		}
+		label := e.Kind
+		if e.Detail != "" {
+			label += " " + e.Detail
+		}
+		buckets[i].Events = append(buckets[i].Events, label)
+	}
+	sort.Slice(p99s, func(i, j int) bool { return p99s[i] < p99s[j] })
+	typical := pct(p99s, 50)
+	for i := range buckets {
+		buckets[i].Spike = typical > 0 && buckets[i].P99 > 2*typical
+	}
+	return buckets
+}
+
+// PrintTimeline prints throughput and latency over the run with backend
+// events beside the slice they landed in, and says which spikes they explain.
+func PrintTimeline(buckets []TimelineBucket) {
+	if len(buckets) == 0 {
+		return
+	}
+	fmt.Printf("\n── Timeline (%s per row) ──\n", buckets[0].Width)
+	fmt.Printf("  %8s  %9s  %9s  %9s  %s\n", "t", "QPS", "p50", "p99", "backend")
+	spikes, explained := 0, 0
+	for i, b := range buckets {
+		mark := " "
+		if b.Spike {
+			mark = "▲"
+		}
+		events := ""
+		for j, e := range b.Events {
+			if j > 0 {
+				events += ", "
+			}
+			events += e
+		}
+		fmt.Printf("  %8s  %9.1f  %9s  %8s%s  %s\n", b.Offset.Round(time.Second), b.QPS, FmtDur(b.P50), FmtDur(b.P99), mark, events)
+		if !b.Spike {
+			continue
+		}
+		spikes++
+		// An event in the slice before may still be running into this one
+		if len(b.Events) > 0 || (i > 0 && len(buckets[i-1].Events) > 0) {
+			explained++
+		}
+	}
+	fmt.Println("  ▲ = p99 above twice the run's typical p99")
+	switch {
+	case spikes == 0:
+		Okf("No latency spikes")
+	case explained == spikes:
+		Okf("All %d latency spikes coincide with backend checkpoints or autovacuum", spikes)
+	default:
+		Warnf("%d of %d latency spikes have no backend event nearby — look at the proxy", spikes-explained, spikes)
+	}
+}
@@ -0,0 +1,70 @@
+package bench
+
+import (
+	"fmt"
+	"time"
+)
+
+// TrimPct, when above zero, makes ComputeStats also report latency with
+// this percentage of the fastest and of the slowest queries left out.
+var TrimPct float64
+
+// TrimmedStats is latency over the queries left after trimming Pct% off
+// each end, so one network hiccup cannot dominate max or the top tail of a
+// short run.
+type TrimmedStats struct {
+	Pct         float64       `json:"pct"`
+	Dropped     int           `json:"dropped"` // from both ends together
+	LatencyAvg  time.Duration `json:"latency_avg_ns"`
+	LatencyMin  time.Duration `json:"latency_min_ns"`
+	LatencyMax  time.Duration `json:"latency_max_ns"`
+	LatencyP50  time.Duration `json:"latency_p50_ns"`
+	LatencyP99  time.Duration `json:"latency_p99_ns"`
+	Percentiles []Percentile  `json:"percentiles,omitempty"` // with -percentiles
+}
+
+// trimmed computes TrimmedStats from sorted latencies, or nil when TrimPct
+// is unset or too few queries ran to drop any.
+func trimmed(sorted []time.Duration) *TrimmedStats {
+	k := int(float64(len(sorted)) * TrimPct / 100)
+	if TrimPct <= 0 || k == 0 {
+		return nil
+	}
+	kept := sorted[k : len(sorted)-k]
+	var sum time.Duration
+	for _, d := range kept {
+		sum += d
+	}
+	t := &TrimmedStats{
+		Pct:        TrimPct,
+		Dropped:    2 * k,
+		LatencyAvg: sum / time.Duration(len(kept)),
+		LatencyMin: kept[0],
+		LatencyMax: kept[len(kept)-1],
+		LatencyP50: pct(kept, 50),
+		LatencyP99: pct(kept, 99),
+	}
+	for _, p := range Percentiles {
+		t.Percentiles = append(t.Percentiles, Percentile{P: p, Latency: pct(kept, p)})
+	}
+	return t
+}
+
+func printTrimmed(t *TrimmedStats) {
+	if t == nil {
+		return
+	}
+	fmt.Printf("├─────────────────────────────────────────┤\n")
+	fmt.Printf("│  %-38s│\n", fmt.Sprintf("Trimmed %g%% each end (%d dropped)", t.Pct, t.Dropped))
+	fmt.Printf("│  Latency avg:  %-24s│\n", FmtDur(t.LatencyAvg))
+	fmt.Printf("│  Latency min:  %-24s│\n", FmtDur(t.LatencyMin))
+	fmt.Printf("│  Latency max:  %-24s│\n", FmtDur(t.LatencyMax))
+	if len(t.Percentiles) > 0 {
+		for _, p := range t.Percentiles {
+			fmt.Printf("│  %-38s│\n", fmt.Sprintf("%-13s %s", "Latency "+FmtPct(p.P)+":", FmtDur(p.Latency)))
+		}
+		return
+	}
+	fmt.Printf("│  Latency p50:  %-24s│\n", FmtDur(t.LatencyP50))
+	fmt.Printf("│  Latency p99:  %-24s│\n", FmtDur(t.LatencyP99))
+}
@@ -1,42 +1,186 @@
 package bench
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 type ConnConfig struct {
-	Host     string
-	Port     int
-	User     string
-	Password string
-	Database string
+	Host       string
+	Port       int
+	User       string
+	Password   string
+	Database   string
+	DSN        string // full connection string; when set, Host/Port/User are ignored
+	SearchPath string // Postgres schema to start connections in ("" = server default)
+	Strategy   string // connection use: pool, per-query (a fresh connection each query) or single ("" = pool)
+}
+
+// IsSet reports whether the endpoint was configured at all.
+func (c ConnConfig) IsSet() bool {
+	return c.Host != "" || c.DSN != ""
 }
 
 type BenchParams struct {
-	Queries     int
-	Concurrency int
-	Warmup      int
-	SeedRows    int
-	Duration    time.Duration // 0 = use Queries count, >0 = time-based
-	Runs        int           // number of runs for median (0 = single run)
+	Queries        int
+	Concurrency    int
+	Warmup         int
+	SeedRows       int
+	Reseed         bool            // truncate and reseed deterministically before running
+	Duration       time.Duration   // 0 = use Queries count, >0 = time-based
+	WarmupWindow   time.Duration   // start of a timed run recorded but left out of stats
+	CooldownWindow time.Duration   // end of a timed run recorded but left out of stats
+	Runs           int             // number of runs for median (0 = single run)
+	Tenants        []string        // tenant databases for multi/isolation/scale (nil = built-in list)
+	Schema         string          // schema (Postgres) or database (MySQL) qualifying Table
+	Table          string          // benchmark table name ("" = accounts)
+	Workload       string          // query mix, see WorkloadDesc ("" = mixed)
+	RowBytes       int             // size of the payload filler column (0 = no payload)
+	Documents      bool            // also seed a JSON document column
+	StreamRows     []int           // result sizes for the stream test
+	StreamIters    int             // queries per result size in the stream test
+	PageSize       int             // rows per page in the page workload
+	IngestRows     int             // rows loaded per pass of the ingest test
+	IngestBatch    int             // rows per COPY / LOAD DATA statement
+	Notifications  int             // NOTIFYs sent per pass of the notify test
+	FetchSize      int             // rows per FETCH in the cursor test
+	CursorFetches  int             // FETCHes per cursor in the cursor test
+	SessionQueries int             // query+verify pairs per session in the session test
+	AggWorkers     int             // workers running aggregations alongside the workload
+	HotPct         int             // percentage of writes that are transfers between hot rows
+	HotRows        int             // size of the hot row set (ids 1..HotRows)
+	Relational     bool            // also seed the orders and order_items tables
+	Isolation      string          // isolation level of the conflict test: serializable or repeatable-read
+	LockKeys       int             // distinct keys locked by the advisory test
+	PreparedStmts  []int           // statements prepared per connection in the prepared test, one pass each
+	InParams       []int           // IN-list sizes in the inlist test, one pass each
+	CheckIntegrity bool            // snapshot the table around each run and check its invariants
+	LagProbes      int             // write-then-poll probes in the replica test
+	FailoverAt     time.Duration   // when the failover test triggers the failover
+	FailoverCmd    string          // command the failover test runs to trigger it ("" = manual)
+	MaxConns       int             // connections the connlimit test opens at most
+	AuthUsers      []string        // logins the auth test connects as ("" = the endpoint's user)
+	AuthConnects   int             // connects per user and endpoint in the auth test
+	QuotaQPS       float64         // the throttled tenant's configured quota in the quota test
+	BystanderQPS   float64         // steady load of each other tenant in the quota test
+	IdleIntervals  []time.Duration // idle times probed in the idle test
+	SoakInterval   time.Duration   // pause between queries on each connection in the soak test
+	HibernateAfter time.Duration   // idle time before each wake in the coldstart test
+	WakeCycles     int             // hibernate/wake cycles in the coldstart test
+	ProvisionCount int             // tenants created in the provision test
+	TenantPrefix   string          // name prefix of tenants the bench creates
+	ChurnRate      float64         // tenants joining per second in the churn test
+	ChurnQueries   int             // queries each churning tenant runs before leaving
+	TenantSkew     float64         // Zipf exponent spreading multi/scale load across tenants; 0 = even
+	SeedRowsMax    int             // when set, scale tenants get a log-normal row count between SeedRows and this
+	RampTenants    int             // scale tenants brought online per wave; 0 = all at once
+	RampEvery      time.Duration   // time between scale ramp waves
+	MaxClientConns int             // high-scale mode: cap on client connections, cycled through the tenants; 0 = a pool per tenant
+	CycleQueries   int             // queries per tenant visit in high-scale mode
+	MaxClientMem   int             // MiB of client heap before a high-scale run stops early
+	Cockroach      bool            // the pg driver talks to CockroachDB: explicit keys, restarts retried
+	KeyType        string          // CockroachDB primary key: serial (ids 1..N) or uuid ("" = serial)
+	AsOf           string          // CockroachDB reads AS OF SYSTEM TIME: "follower" or a staleness like 10s ("" = current)
+	Tenancy        string          // tenant layout of multi/isolation/scale: db (a database each), schema (a schema each in one database) or rls (a role each sharing one table); "" = db
+	TenantPassword string          // password of the per-tenant roles of -tenancy rls
+
+	// AuthPasswords are AuthUsers' passwords by login; a login without one
+	// uses the endpoint's. Never sent to agents or written out.
+	AuthPasswords map[string]string `json:"-"`
 }
 
 type QueryResult struct {
 	At       time.Time
 	Duration time.Duration
 	Err      error
+	Bytes    int           // payload bytes read or written, for MB/s
+	FirstRow time.Duration // time to the first row of a streamed result (0 = n/a)
+	Op       string        // operation kind, for per-operation breakdowns ("" = none)
+	Rows     int           // rows written by a bulk operation, for rows/s
+	Ramp     bool          // in the warm-up or cool-down window: recorded, not measured
 }
 
 type BenchStats struct {
-	Label      string
-	Total      int
-	Errors     int
-	Duration   time.Duration
-	QPS        float64
-	LatencyAvg time.Duration
-	LatencyMin time.Duration
-	LatencyMax time.Duration
-	LatencyP50 time.Duration
-	LatencyP75 time.Duration
-	LatencyP90 time.Duration
-	LatencyP95 time.Duration
-	LatencyP99 time.Duration
-}
\ No newline at end of file
+	Label         string        `json:"label"`
+	Total         int           `json:"total"`
+	Errors        int           `json:"errors"`
+	Duration      time.Duration `json:"duration_ns"`
+	QPS           float64       `json:"qps"`
+	Bytes         int64         `json:"bytes,omitempty"`
+	MBps          float64       `json:"mb_per_sec,omitempty"`
+	Rows          int64         `json:"rows,omitempty"`
+	RowsPerSec    float64       `json:"rows_per_sec,omitempty"`
+	FirstRowP50   time.Duration `json:"first_row_p50_ns,omitempty"`
+	Ops           []OpStats     `json:"ops,omitempty"`
+	Deadlocks     int           `json:"deadlocks,omitempty"`
+	LockTimeouts  int           `json:"lock_timeouts,omitempty"`
+	Serialization int           `json:"serialization_failures,omitempty"`
+	LostUpdates   int           `json:"lost_updates,omitempty"`
+	Violations    []string      `json:"integrity_violations,omitempty"`
+	Ramp          int           `json:"ramp_excluded,omitempty"`
+	LatencyAvg    time.Duration `json:"latency_avg_ns"`
+	LatencyMin    time.Duration `json:"latency_min_ns"`
+	LatencyMax    time.Duration `json:"latency_max_ns"`
+	LatencyP50    time.Duration `json:"latency_p50_ns"`
+	LatencyP75    time.Duration `json:"latency_p75_ns"`
+	LatencyP90    time.Duration `json:"latency_p90_ns"`
+	LatencyP95    time.Duration `json:"latency_p95_ns"`
+	LatencyP99    time.Duration `json:"latency_p99_ns"`
+	Percentiles   []Percentile  `json:"percentiles,omitempty"` // with -percentiles
+	SLO           *SLOReport    `json:"slo,omitempty"`         // with -slo
+	Trimmed       *TrimmedStats `json:"trimmed,omitempty"`     // with -trim
+}
+
+// Percentile is the latency at one of the percentiles asked for with
+// -percentiles.
+type Percentile struct {
+	P       float64       `json:"p"`
+	Latency time.Duration `json:"latency_ns"`
+}
+
+// OpStats is the latency of one operation kind within a workload.
+type OpStats struct {
+	Op         string        `json:"op"`
+	Total      int           `json:"total"`
+	Errors     int           `json:"errors"`
+	LatencyP50 time.Duration `json:"latency_p50_ns"`
+	LatencyP95 time.Duration `json:"latency_p95_ns"`
+	LatencyP99 time.Duration `json:"latency_p99_ns"`
+}
+
+// TableName is the benchmark table, defaulting to accounts.
+func (p BenchParams) TableName() string {
+	if p.Table == "" {
+		return "accounts"
+	}
+	return p.Table
+}
+
+// WorkloadDesc describes the query mix of params.Workload for headers.
+func (p BenchParams) WorkloadDesc() string {
+	switch p.Workload {
+	case "join":
+		return "50% read / 30% join / 20% write"
+	case "wide":
+		return fmt.Sprintf("80%% read / 20%% write, %d-byte rows", p.RowBytes)
+	case "page":
+		return fmt.Sprintf("50%% OFFSET / 50%% keyset pages of %d rows", p.PageSize)
+	case "agg":
+		return "GROUP BY / SUM aggregations"
+	case "upsert":
+		return "100% upsert (50% update path / 50% insert path)"
+	case "json":
+		return "40% containment / 40% path read / 20% partial update"
+	}
+	if _, ok := LookupWorkload(p.Workload); ok {
+		return "registered workload " + p.Workload
+	}
+	desc := "80% read / 20% write"
+	if p.HotPct > 0 {
+		desc += fmt.Sprintf(", %d%% of writes on %d hot rows", p.HotPct, p.HotRows)
+	}
+	if p.AggWorkers > 0 {
+		desc += fmt.Sprintf(" + %d aggregating", p.AggWorkers)
+	}
+	return desc
+}
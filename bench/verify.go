@@ -0,0 +1,51 @@
+package bench
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ResultSet is a query result reduced to comparable strings: column type
+// names and each row's values as the driver decoded them.
+type ResultSet struct {
+	Types []string
+	Rows  [][]string
+}
+
+// Diff describes the first difference between a and b, "" if they match.
+func (a ResultSet) Diff(b ResultSet) string {
+	if x, y := strings.Join(a.Types, ","), strings.Join(b.Types, ","); x != y {
+		return fmt.Sprintf("column types %s vs %s", x, y)
+	}
+	if len(a.Rows) != len(b.Rows) {
+		return fmt.Sprintf("%d rows vs %d", len(a.Rows), len(b.Rows))
+	}
+	for i := range a.Rows {
+		for j := range a.Rows[i] {
+			if a.Rows[i][j] != b.Rows[i][j] {
+				return fmt.Sprintf("row %d column %d: %s vs %s", i+1, j+1, a.Rows[i][j], b.Rows[i][j])
+			}
+		}
+	}
+	return ""
+}
+
+// PrintVerify prints the verify test verdict for n queries of which bad
+// differed between direct and proxy.
+func PrintVerify(n, bad int) {
+	if bad == 0 {
+		Okf("All %d queries returned identical results through the proxy", n)
+		return
+	}
+	Failf("%d of %d queries returned different results through the proxy", bad, n)
+}
+
+// PrintLeaks prints the leakage probe verdict for probes of which leaks saw
+// the wrong tenant's markers.
+func PrintLeaks(leaks, probes int) {
+	if leaks == 0 {
+		Okf("No tenant saw another tenant's marker (%d probes)", probes)
+		return
+	}
+	Failf("%d of %d probes saw the wrong tenant's markers", leaks, probes)
+}
@@ -0,0 +1,78 @@
+package bench
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// WaitEvent is how often active backends were seen in one wait state.
+// "CPU" means running with no wait event.
+type WaitEvent struct {
+	Event   string  `json:"event"` // wait_event_type:wait_event
+	Samples int     `json:"samples"`
+	Pct     float64 `json:"pct"`
+}
+
+// WaitReport is the wait-event distribution of active backends sampled
+// over a run.
+type WaitReport struct {
+	Polls     int         `json:"polls"`
+	AvgActive float64     `json:"avg_active"` // active backends per poll
+	Events    []WaitEvent `json:"events"`
+}
+
+// SummarizeWaits turns per-event counts from polls into a report, most
+// frequent first.
+func SummarizeWaits(polls int, counts map[string]int) *WaitReport {
+	r := &WaitReport{Polls: polls}
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	for ev, n := range counts {
+		r.Events = append(r.Events, WaitEvent{Event: ev, Samples: n, Pct: float64(n) / float64(total) * 100})
+	}
+	sort.Slice(r.Events, func(i, j int) bool {
+		if r.Events[i].Samples != r.Events[j].Samples {
+			return r.Events[i].Samples > r.Events[j].Samples
+		}
+		return r.Events[i].Event < r.Events[j].Event
+	})
+	if polls > 0 {
+		r.AvgActive = float64(total) / float64(polls)
+	}
+	return r
+}
+
+// PrintWaits prints the wait-event distribution and flags an IO-bound
+// backend.
+func PrintWaits(r *WaitReport) {
+	fmt.Printf("\n┌─────────────────────────────────────────┐\n")
+	fmt.Printf("│  %-39s│\n", "Backend wait events")
+	fmt.Printf("├─────────────────────────────────────────┤\n")
+	for i, e := range r.Events {
+		if i == 12 {
+			fmt.Printf("│  %-39s│\n", fmt.Sprintf("... %d more", len(r.Events)-i))
+			break
+		}
+		fmt.Printf("│  %-28s %8.1f%% │\n", e.Event, e.Pct)
+	}
+	fmt.Printf("├─────────────────────────────────────────┤\n")
+	fmt.Printf("│  Polls: %-8d Avg active: %-10.1f │\n", r.Polls, r.AvgActive)
+	fmt.Printf("└─────────────────────────────────────────┘\n")
+
+	if len(r.Events) == 0 {
+		Warnf("No active backends seen — the backend was idle, so latency is proxy- or network-side")
+		return
+	}
+	io := 0.0
+	for _, e := range r.Events {
+		if strings.HasPrefix(e.Event, "IO:") {
+			io += e.Pct
+		}
+	}
+	if io >= 30 {
+		Warnf("Backends spent %.0f%% of active samples waiting on IO — the backend, not the proxy, is the bottleneck", io)
+	}
+}
@@ -0,0 +1,75 @@
+package bench
+
+import "time"
+
+// Window policies for timed runs: queries still in flight at the deadline
+// either don't count (exclude) or count with the run stretched until the
+// last one returns (include, the behaviour before the policy existed).
+const (
+	WindowExclude = "exclude"
+	WindowInclude = "include"
+)
+
+// WindowPolicy is the policy Window applies.
+var WindowPolicy = WindowExclude
+
+// Window trims the results of a timed run that started at start, elapsed
+// being how long it actually took including the drain. It returns the
+// results that count and the duration to divide them by.
+//
+// Under WindowExclude only queries that completed by the deadline are kept;
+// under WindowInclude the late ones stay and stretch the window. Queries
+// that started in params.WarmupWindow or ended in params.CooldownWindow are
+// kept but marked Ramp, so ComputeStats leaves them out, and the duration
+// covers only the steady part in between.
+func Window(results []QueryResult, start time.Time, params BenchParams, elapsed time.Duration) ([]QueryResult, time.Duration) {
+	if params.Duration <= 0 {
+		return results, elapsed
+	}
+	keep := windowFilter(start, params)
+	kept := results[:0:0]
+	for _, r := range results {
+		if keep(&r) {
+			kept = append(kept, r)
+		}
+	}
+	return kept, windowDuration(params, elapsed)
+}
+
+// windowFilter reports whether Window keeps a result, marking it Ramp when
+// it falls in the warm-up or cool-down window. Queries end by the time the
+// run has elapsed, so only the deadline and the windows matter.
+func windowFilter(start time.Time, params BenchParams) func(*QueryResult) bool {
+	d := params.Duration
+	if d <= 0 {
+		return func(*QueryResult) bool { return true }
+	}
+	deadline, from := start.Add(d), start.Add(params.WarmupWindow)
+	return func(r *QueryResult) bool {
+		if r.At.IsZero() {
+			return true
+		}
+		done := r.At.Add(r.Duration)
+		if WindowPolicy == WindowExclude && done.After(deadline) {
+			return false
+		}
+		r.Ramp = r.At.Before(from) || (params.CooldownWindow > 0 && done.After(start.Add(d-params.CooldownWindow)))
+		return true
+	}
+}
+
+// windowDuration is the part of a timed run Window divides its results by.
+func windowDuration(params BenchParams, elapsed time.Duration) time.Duration {
+	d := params.Duration
+	if d <= 0 {
+		return elapsed
+	}
+	end := min(d, elapsed)
+	if WindowPolicy == WindowInclude {
+		end = elapsed
+	}
+	if params.CooldownWindow > 0 {
+		end = min(d-params.CooldownWindow, elapsed)
+	}
+	return max(end-params.WarmupWindow, 0)
+}
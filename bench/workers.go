@@ -0,0 +1,226 @@
+package bench
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// StragglerRatio flags a worker whose QPS is below this fraction of the
+// median worker's; a stuck connection shows up this way long before it
+// moves the overall numbers.
+const StragglerRatio = 0.5
+
+// WorkerStats is how much one benchmark worker got done in one pass, such
+// as the overhead test's direct or proxy pass; with -runs, in its last run.
+type WorkerStats struct {
+	Pass      string        `json:"pass,omitempty"`
+	Worker    int           `json:"worker"`
+	Queries   int           `json:"queries"`
+	Errors    int           `json:"errors"`
+	Busy      time.Duration `json:"busy_ns"` // first query start to last query end
+	QPS       float64       `json:"qps"`
+	P50       time.Duration `json:"p50_ns"`
+	P99       time.Duration `json:"p99_ns"`
+	Straggler bool          `json:"straggler,omitempty"`
+}
+
+type workerTally struct {
+	busy time.Duration
+	hist *Histogram
+}
+
+type workerKey struct {
+	pass   string
+	worker int
+}
+
+var workerLog struct {
+	sync.Mutex
+	on      bool
+	tallies map[workerKey]*workerTally
+	passes  []string       // in first-seen order
+	run     int            // bumped by NextWorkerRun
+	passRun map[string]int // the run each pass's tallies are from
+}
+
+// StartWorkers begins tallying what each worker reports to ObserveWorker.
+func StartWorkers() {
+	workerLog.Lock()
+	defer workerLog.Unlock()
+	workerLog.on, workerLog.tallies, workerLog.passes = true, map[workerKey]*workerTally{}, nil
+	workerLog.run, workerLog.passRun = 0, map[string]int{}
+}
+
+// NextWorkerRun starts a new run of a -runs series: the next results of a
+// pass replace its tallies instead of adding to them.
+func NextWorkerRun() {
+	workerLog.Lock()
+	defer workerLog.Unlock()
+	workerLog.run++
+}
+
+// ObserveWorker adds a batch of worker's results in pass to its tally. It
+// does not feed the timeline or histogram; callers still Observe the
+// results.
+func ObserveWorker(pass string, worker int, results []QueryResult) {
+	results = measured(results)
+	if len(results) == 0 {
+		return
+	}
+	first, last := results[0].At, results[0].At
+	for _, r := range results {
+		if r.At.Before(first) {
+			first = r.At
+		}
+		if end := r.At.Add(r.Duration); end.After(last) {
+			last = end
+		}
+	}
+	workerLog.Lock()
+	defer workerLog.Unlock()
+	if !workerLog.on {
+		return
+	}
+	run, seen := workerLog.passRun[pass]
+	if !seen {
+		workerLog.passes = append(workerLog.passes, pass)
+	}
+	if seen && run != workerLog.run {
+		for k := range workerLog.tallies {
+			if k.pass == pass {
+				delete(workerLog.tallies, k)
+			}
+		}
+	}
+	workerLog.passRun[pass] = workerLog.run
+	key := workerKey{pass, worker}
+	t := workerLog.tallies[key]
+	if t == nil {
+		t = &workerTally{hist: NewHistogram()}
+		workerLog.tallies[key] = t
+	}
+	t.busy += last.Sub(first)
+	for _, r := range results {
+		t.hist.Record(r)
+	}
+}
+
+// StopWorkers ends tallying and returns each worker's stats, pass by pass
+// in worker order, with stragglers marked among their pass. Passes of fewer
+// than two workers are left out.
+func StopWorkers() []WorkerStats {
+	workerLog.Lock()
+	tallies, passes := workerLog.tallies, workerLog.passes
+	workerLog.on, workerLog.tallies, workerLog.passes = false, nil, nil
+	workerLog.Unlock()
+
+	var out []WorkerStats
+	for _, pass := range passes {
+		out = append(out, passWorkers(pass, tallies)...)
+	}
+	return out
+}
+
+func passWorkers(pass string, tallies map[workerKey]*workerTally) []WorkerStats {
+	var out []WorkerStats
+	for k, t := range tallies {
+		if k.pass != pass {
+			continue
+		}
+		s := WorkerStats{
+			Pass:    pass,
+			Worker:  k.worker,
+			Queries: int(t.hist.N + t.hist.Errors),
+			Errors:  int(t.hist.Errors),
+			Busy:    t.busy,
+			P50:     t.hist.Percentile(50),
+			P99:     t.hist.Percentile(99),
+		}
+		if t.busy > 0 {
+			s.QPS = float64(s.Queries) / t.busy.Seconds()
+		}
+		out = append(out, s)
+	}
+	if len(out) < 2 {
+		return nil
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Worker < out[j].Worker })
+
+	median := medianWorkerQPS(out)
+	for i := range out {
+		out[i].Straggler = out[i].QPS < median*StragglerRatio
+	}
+	return out
+}
+
+func medianWorkerQPS(ws []WorkerStats) float64 {
+	qps := make([]float64, len(ws))
+	for i, w := range ws {
+		qps[i] = w.QPS
+	}
+	sort.Float64s(qps)
+	return qps[len(qps)/2]
+}
+
+// PrintWorkers summarises the spread of work between each pass's workers
+// and lists any stragglers.
+func PrintWorkers(ws []WorkerStats) {
+	for len(ws) > 0 {
+		n := 1
+		for n < len(ws) && ws[n].Pass == ws[0].Pass {
+			n++
+		}
+		printPassWorkers(ws[:n])
+		ws = ws[n:]
+	}
+}
+
+func printPassWorkers(ws []WorkerStats) {
+	title := fmt.Sprintf("Workers (%d)", len(ws))
+	if ws[0].Pass != "" {
+		title = fmt.Sprintf("Workers: %s (%d)", ws[0].Pass, len(ws))
+	}
+	if len(title) > 39 {
+		title = title[:39]
+	}
+	byQueries := append([]WorkerStats(nil), ws...)
+	sort.Slice(byQueries, func(i, j int) bool { return byQueries[i].Queries < byQueries[j].Queries })
+	var stragglers []WorkerStats
+	for _, w := range ws {
+		if w.Straggler {
+			stragglers = append(stragglers, w)
+		}
+	}
+
+	fmt.Printf("\n┌─────────────────────────────────────────┐\n")
+	fmt.Printf("│  %-39s│\n", title)
+	fmt.Printf("├─────────────────────────────────────────┤\n")
+	fmt.Printf("│  Queries min:  %-25d│\n", byQueries[0].Queries)
+	fmt.Printf("│  Queries med:  %-25d│\n", byQueries[len(byQueries)/2].Queries)
+	fmt.Printf("│  Queries max:  %-25d│\n", byQueries[len(byQueries)-1].Queries)
+	fmt.Printf("│  QPS median:   %-25.1f│\n", medianWorkerQPS(ws))
+	fmt.Printf("│  Stragglers:   %-25d│\n", len(stragglers))
+	if len(stragglers) > 0 {
+		fmt.Printf("├─────────────────────────────────────────┤\n")
+		for i, w := range stragglers {
+			if i == 10 {
+				fmt.Printf("│  %-39s│\n", fmt.Sprintf("... and %d more", len(stragglers)-i))
+				break
+			}
+			fmt.Printf("│  %-39s│\n", fmt.Sprintf("#%d: %d q, %.0f QPS, p99 %s", w.Worker, w.Queries, w.QPS, FmtDur(w.P99)))
+		}
+	}
+	fmt.Printf("└─────────────────────────────────────────┘\n")
+	if len(stragglers) > 0 {
+		Warnf("%d worker(s)%s ran below %.0f%% of the median worker's QPS (stuck connection?)", len(stragglers), inPass(ws[0].Pass), StragglerRatio*100)
+	}
+}
+
+func inPass(pass string) string {
+	if pass == "" {
+		return ""
+	}
+	return " of " + pass
+}
@@ -0,0 +1,63 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// BuiltinWorkloads are the -workload names the pg and my packages implement
+// themselves.
+var BuiltinWorkloads = []string{"mixed", "join", "wide", "page", "agg", "upsert", "json"}
+
+// WorkloadFunc runs one operation of a workload against db — a
+// *pgxpool.Pool on Postgres, a *sql.DB on MySQL and SQL Server — on ids 1..maxID.
+type WorkloadFunc func(ctx context.Context, db any, maxID int) QueryResult
+
+// WorkloadFactory builds a registered workload for one run against dbType
+// ("postgres", "mysql" or "mssql"), failing if it does not support that database.
+// params carries the table (Schema, TableName) and sizing flags.
+type WorkloadFactory func(dbType string, params BenchParams) (WorkloadFunc, error)
+
+var workloads = struct {
+	sync.RWMutex
+	m map[string]WorkloadFactory
+}{m: map[string]WorkloadFactory{}}
+
+// RegisterWorkload makes a custom workload selectable with -workload name,
+// so forks can benchmark their own schema without touching the runners.
+// Call it from an init function; it panics if the name is taken.
+func RegisterWorkload(name string, f WorkloadFactory) {
+	workloads.Lock()
+	defer workloads.Unlock()
+	for _, b := range BuiltinWorkloads {
+		if b == name {
+			panic(fmt.Sprintf("bench: workload %q is built in", name))
+		}
+	}
+	if _, dup := workloads.m[name]; dup {
+		panic(fmt.Sprintf("bench: workload %q registered twice", name))
+	}
+	workloads.m[name] = f
+}
+
+// LookupWorkload returns the factory registered under name.
+func LookupWorkload(name string) (WorkloadFactory, bool) {
+	workloads.RLock()
+	defer workloads.RUnlock()
+	f, ok := workloads.m[name]
+	return f, ok
+}
+
+// RegisteredWorkloads lists the registered names, sorted.
+func RegisteredWorkloads() []string {
+	workloads.RLock()
+	defer workloads.RUnlock()
+	names := make([]string, 0, len(workloads.m))
+	for name := range workloads.m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
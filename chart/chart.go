@@ -0,0 +1,123 @@
+package chart
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"tenantsdb-bench/bench"
+)
+
+// LatencyBins is how many bars the latency distribution has.
+const LatencyBins = 40
+
+// Latency renders the distribution of successful query latencies as bars
+// over log-spaced latency bins.
+func Latency(title string, h *bench.Histogram) []byte {
+	bins := h.Bins(LatencyBins)
+	c := newCanvas(title)
+	var most int64
+	for _, b := range bins {
+		most = max(most, b.Count)
+	}
+	y := c.yAxis(float64(most), "queries", func(v float64) string { return fmt.Sprintf("%.0f", v) })
+	w := float64(plotW) / float64(len(bins))
+	every := (len(bins) + 7) / 8
+	for i, b := range bins {
+		x := left + float64(i)*w
+		c.rect(x+1, y(float64(b.Count)), w-2, y(0)-y(float64(b.Count)), colorA)
+		if i%every == every-1 || i == len(bins)-1 {
+			c.text(x+w, top+plotH+18, "middle", bench.FmtDur(b.Upper), "")
+		}
+	}
+	c.text(left+plotW/2, height-14, "middle", "latency (log scale)", "")
+	return c.bytes()
+}
+
+// QPS renders throughput over the run from its timeline.
+func QPS(title string, buckets []bench.TimelineBucket) []byte {
+	c := newCanvas(title)
+	var most float64
+	for _, b := range buckets {
+		most = max(most, b.QPS)
+	}
+	y := c.yAxis(most, "QPS", func(v float64) string { return fmt.Sprintf("%.0f", v) })
+	end := buckets[len(buckets)-1].Offset + buckets[len(buckets)-1].Width
+	x := func(d time.Duration) float64 { return left + float64(d)/float64(end)*plotW }
+	xs, ys := make([]float64, len(buckets)), make([]float64, len(buckets))
+	for i, b := range buckets {
+		xs[i], ys[i] = x(b.Offset+b.Width/2), y(b.QPS)
+	}
+	c.polyline(xs, ys, colorA)
+	step := time.Duration(niceStep(end.Seconds(), 8) * float64(time.Second))
+	for t := time.Duration(0); t <= end; t += step {
+		c.text(x(t), top+plotH+18, "middle", fmt.Sprintf("%gs", t.Seconds()), "")
+	}
+	c.text(left+plotW/2, height-14, "middle", "time into run", "")
+	return c.bytes()
+}
+
+// Comparison renders direct vs proxy latency side by side for avg, p50,
+// p95 and p99.
+func Comparison(title string, cmp bench.Comparison) []byte {
+	c := newCanvas(title)
+	groups := []struct {
+		name          string
+		direct, proxy time.Duration
+	}{
+		{"avg", cmp.Direct.LatencyAvg, cmp.Proxy.LatencyAvg},
+		{"p50", cmp.Direct.LatencyP50, cmp.Proxy.LatencyP50},
+		{"p95", cmp.Direct.LatencyP95, cmp.Proxy.LatencyP95},
+		{"p99", cmp.Direct.LatencyP99, cmp.Proxy.LatencyP99},
+	}
+	var most time.Duration
+	for _, g := range groups {
+		most = max(most, g.direct, g.proxy)
+	}
+	y := c.yAxis(float64(most), "latency", func(v float64) string { return bench.FmtDur(time.Duration(v)) })
+	w := float64(plotW) / float64(len(groups))
+	for i, g := range groups {
+		x := left + float64(i)*w
+		bar := w * 0.3
+		c.rect(x+w*0.2, y(float64(g.direct)), bar, y(0)-y(float64(g.direct)), colorA)
+		c.rect(x+w*0.5, y(float64(g.proxy)), bar, y(0)-y(float64(g.proxy)), colorB)
+		c.text(x+w/2, top+plotH+18, "middle", g.name, "")
+	}
+	c.legend("direct", "proxy")
+	return c.bytes()
+}
+
+// Write renders the charts res and h have data for into dir and returns
+// the files written: latency.svg from h, qps.svg from res.Timeline and
+// comparison.svg from res.Comparison.
+func Write(dir string, res *bench.Result, h *bench.Histogram) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	title := res.DB + " " + res.Test
+	charts := map[string][]byte{}
+	if h != nil && h.N > 0 {
+		charts["latency.svg"] = Latency(title+": latency distribution", h)
+	}
+	if len(res.Timeline) > 0 {
+		charts["qps.svg"] = QPS(title+": throughput over time", res.Timeline)
+	}
+	if res.Comparison != nil {
+		charts["comparison.svg"] = Comparison(title+": direct vs proxy", *res.Comparison)
+	}
+
+	var written []string
+	for _, name := range []string{"latency.svg", "qps.svg", "comparison.svg"} {
+		data, ok := charts[name]
+		if !ok {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return written, err
+		}
+		written = append(written, path)
+	}
+	return written, nil
+}
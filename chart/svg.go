@@ -0,0 +1,106 @@
+// Package chart renders benchmark results as SVG charts for reports. It
+// draws with the standard library only, so the charts need nothing beyond
+// what the bench binary already links.
+package chart
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"math"
+)
+
+const (
+	width, height            = 800, 400
+	left, right, top, bottom = 80, 20, 50, 60
+	plotW, plotH             = width - left - right, height - top - bottom
+)
+
+// Colours for the first and second series of a chart.
+const (
+	colorA = "#4e79a7"
+	colorB = "#f28e2b"
+)
+
+type canvas struct {
+	b bytes.Buffer
+}
+
+func newCanvas(title string) *canvas {
+	c := &canvas{}
+	fmt.Fprintf(&c.b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="12">`+"\n", width, height, width, height)
+	c.rect(0, 0, width, height, "white")
+	c.text(width/2, 28, "middle", title, `font-size="16" font-weight="bold"`)
+	return c
+}
+
+func (c *canvas) text(x, y float64, anchor, s, attrs string) {
+	if attrs != "" {
+		attrs = " " + attrs
+	}
+	fmt.Fprintf(&c.b, `<text x="%.1f" y="%.1f" text-anchor="%s"%s>%s</text>`+"\n", x, y, anchor, attrs, html.EscapeString(s))
+}
+
+func (c *canvas) line(x1, y1, x2, y2 float64, stroke string) {
+	fmt.Fprintf(&c.b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s"/>`+"\n", x1, y1, x2, y2, stroke)
+}
+
+func (c *canvas) rect(x, y, w, h float64, fill string) {
+	fmt.Fprintf(&c.b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"/>`+"\n", x, y, w, h, fill)
+}
+
+func (c *canvas) polyline(xs, ys []float64, stroke string) {
+	c.b.WriteString(`<polyline fill="none" stroke-width="2" stroke="` + stroke + `" points="`)
+	for i := range xs {
+		fmt.Fprintf(&c.b, "%.1f,%.1f ", xs[i], ys[i])
+	}
+	c.b.WriteString("\"/>\n")
+}
+
+// yAxis draws horizontal grid lines with labels up to a nice round number
+// above maxV and returns the function mapping a value to its y.
+func (c *canvas) yAxis(maxV float64, label string, format func(float64) string) func(float64) float64 {
+	step := niceStep(maxV, 5)
+	ceil := step * math.Ceil(maxV/step)
+	if ceil == 0 {
+		ceil, step = 1, 1
+	}
+	y := func(v float64) float64 { return top + plotH - v/ceil*plotH }
+	for v := 0.0; v <= ceil+step/2; v += step {
+		c.line(left, y(v), left+plotW, y(v), "#e0e0e0")
+		c.text(left-8, y(v)+4, "end", format(v), "")
+	}
+	c.line(left, y(0), left+plotW, y(0), "#333")
+	c.text(16, top+plotH/2, "middle", label, fmt.Sprintf(`transform="rotate(-90 16 %d)"`, top+plotH/2))
+	return y
+}
+
+// legend names the series in the top right corner.
+func (c *canvas) legend(names ...string) {
+	x := float64(left + plotW - 110*len(names))
+	for i, n := range names {
+		c.rect(x+float64(110*i), top-14, 12, 12, []string{colorA, colorB}[i%2])
+		c.text(x+float64(110*i)+18, top-4, "start", n, "")
+	}
+}
+
+func (c *canvas) bytes() []byte {
+	c.b.WriteString("</svg>\n")
+	return c.b.Bytes()
+}
+
+// niceStep is a 1, 2 or 5 times a power of ten that divides maxV into about
+// n steps.
+func niceStep(maxV float64, n int) float64 {
+	if maxV <= 0 {
+		return 1
+	}
+	raw := maxV / float64(n)
+	mag := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, m := range []float64{1, 2, 5} {
+		if m*mag >= raw {
+			return m * mag
+		}
+	}
+	return 10 * mag
+}
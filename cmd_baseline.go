@@ -0,0 +1,151 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"tenantsdb-bench/bench"
+)
+
+const defaultBaselineDir = ".tdb-baselines"
+
+// toleranceFlags are the limits shared by check and run -baseline.
+type toleranceFlags struct {
+	qpsDrop, p50Rise, p99Rise, overhead *float64
+	errorRise                           *int
+}
+
+func addToleranceFlags(fs *flag.FlagSet) *toleranceFlags {
+	d := bench.DefaultTolerances
+	return &toleranceFlags{
+		qpsDrop:   fs.Float64("max-qps-drop", d.QPSDropPct, "Fail when QPS is more than this % below the baseline (-1 = don't check)"),
+		p50Rise:   fs.Float64("max-p50-rise", d.P50RisePct, "Fail when p50 is more than this % above the baseline (-1 = don't check)"),
+		p99Rise:   fs.Float64("max-p99-rise", d.P99RisePct, "Fail when p99 is more than this % above the baseline (-1 = don't check)"),
+		overhead:  fs.Float64("max-overhead-rise", d.Overhead, "Fail when proxy overhead is more than this many percentage points above the baseline (-1 = don't check)"),
+		errorRise: fs.Int("max-error-rise", d.ErrorRise, "Fail when there are more than this many errors beyond the baseline's (-1 = don't check)"),
+	}
+}
+
+func (t *toleranceFlags) tolerances() bench.Tolerances {
+	return bench.Tolerances{
+		QPSDropPct: *t.qpsDrop,
+		P50RisePct: *t.p50Rise,
+		P99RisePct: *t.p99Rise,
+		ErrorRise:  *t.errorRise,
+		Overhead:   *t.overhead,
+	}
+}
+
+// runBaseline implements `baseline save` and `baseline list`.
+func runBaseline(args []string) {
+	if len(args) == 0 || (args[0] != "save" && args[0] != "list") {
+		fmt.Println("Usage: tdb-bench baseline save [flags] <result.json>")
+		fmt.Println("       tdb-bench baseline list [flags]")
+		os.Exit(1)
+	}
+	sub, args := args[0], args[1:]
+
+	if sub == "list" {
+		cmd := newFlagSet("baseline list", "")
+		dir := cmd.String("dir", defaultBaselineDir, "Baseline directory")
+		cmd.Parse(args)
+		files, _ := filepath.Glob(filepath.Join(*dir, "*.json"))
+		if len(files) == 0 {
+			fmt.Printf("No baselines in %s\n", *dir)
+			return
+		}
+		for _, f := range files {
+			r, err := bench.ReadJSON(f)
+			if err != nil {
+				bench.Warnf("%v", err)
+				continue
+			}
+			line := fmt.Sprintf("  %-28s %s", strings.TrimSuffix(filepath.Base(f), ".json"), r.Started.Local().Format("2006-01-02 15:04"))
+			if r.Manifest.RunID != "" {
+				line += "  run " + r.Manifest.RunID
+			}
+			if st, ok := r.Headline(); ok {
+				line += fmt.Sprintf("  QPS=%.1f p50=%s p99=%s", st.QPS, bench.FmtDur(st.LatencyP50), bench.FmtDur(st.LatencyP99))
+			}
+			fmt.Println(line)
+		}
+		return
+	}
+
+	cmd := newFlagSet("baseline save", "<result.json>")
+	dir := cmd.String("dir", defaultBaselineDir, "Baseline directory")
+	cmd.Parse(args)
+	if cmd.NArg() != 1 {
+		cmd.Usage()
+		fail("baseline save takes exactly one results file")
+	}
+	r, err := bench.ReadJSON(cmd.Arg(0))
+	if err != nil {
+		fail("%v", err)
+	}
+	if _, ok := r.Headline(); !ok && len(r.Suite) == 0 {
+		fail("%s has no stats to compare against", cmd.Arg(0))
+	}
+	if err := os.MkdirAll(*dir, 0o755); err != nil {
+		fail("%v", err)
+	}
+	path := bench.BaselinePath(*dir, r.DB, r.Test)
+	if err := bench.WriteJSON(path, r); err != nil {
+		fail("%v", err)
+	}
+	bench.Okf("Baseline for %s/%s saved to %s", r.DB, r.Test, path)
+}
+
+// runCheck implements `check`: compare a result with its baseline and exit
+// non-zero if it falls outside the tolerances.
+func runCheck(args []string) {
+	cmd := newFlagSet("check", "<result.json>")
+	dir := cmd.String("dir", defaultBaselineDir, "Baseline directory")
+	baseline := cmd.String("baseline", "", "Baseline file to compare with (default: the one saved in -dir for the result's db and test)")
+	tol := addToleranceFlags(cmd)
+	plain := cmd.Bool("plain", false, "ASCII-only output: no box drawing, emoji or other Unicode symbols")
+	cmd.Parse(args)
+	stopPlain := usePlain(*plain)
+	defer stopPlain()
+	if cmd.NArg() != 1 {
+		cmd.Usage()
+		fail("check takes exactly one results file")
+	}
+	r, err := bench.ReadJSON(cmd.Arg(0))
+	if err != nil {
+		fail("%v", err)
+	}
+	path := *baseline
+	if path == "" {
+		path = bench.BaselinePath(*dir, r.DB, r.Test)
+	}
+	if !checkBaseline(path, r, tol.tolerances()) {
+		stopPlain()
+		os.Exit(1)
+	}
+}
+
+// checkBaseline prints r against the baseline at path and the verdict,
+// reporting whether r passed.
+func checkBaseline(path string, r *bench.Result, tol bench.Tolerances) bool {
+	base, err := bench.ReadJSON(path)
+	if err != nil {
+		bench.Failf("Baseline: %v", err)
+		return false
+	}
+	bench.PrintResultDiff(base, r)
+	failed := bench.CheckBaseline(base, r, tol)
+	fmt.Println()
+	for _, f := range failed {
+		bench.Failf("%s", f)
+	}
+	if len(failed) > 0 {
+		bench.Failf("FAIL — %d check(s) outside tolerance of %s", len(failed), path)
+		return false
+	}
+	bench.Okf("PASS — within tolerance of %s", path)
+	return true
+}
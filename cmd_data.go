@@ -0,0 +1,189 @@
+package main
+
+import (
+	"os"
+	"sync"
+
+	"tenantsdb-bench/bench"
+	"tenantsdb-bench/ms"
+	"tenantsdb-bench/my"
+	"tenantsdb-bench/pg"
+)
+
+// runSeed implements `tdb-bench seed`: create and populate the benchmark
+// table on every target tenant without measuring anything.
+func runSeed(args []string) {
+	cmd := newFlagSet("seed", "-proxy-host <host> [flags]")
+	conn := addConnFlags(cmd)
+	table := addTableFlags(cmd)
+	seedRows := cmd.Int("seed-rows", 10000, "Rows to insert for test data")
+	reseed := cmd.Bool("reseed", false, "Truncate the table and reseed rows 1..seed-rows deterministically")
+	relational := cmd.Bool("relational", false, "Also seed orders and order_items tables with foreign keys to the benchmark table")
+	rowBytes := cmd.Int("row-bytes", 0, "Size of a payload filler column seeded into every row (0 = none)")
+	docs := cmd.Bool("docs", false, "Also seed a JSON document column (jsonb on Postgres)")
+	tenants := cmd.String("tenants", "", "Comma-separated tenant databases, or \"all\" for the built-in bench list (default: -proxy-db only)")
+	parallel := cmd.Int("parallel", 1, "Tenants to process concurrently")
+	logs := addLogFlags(cmd)
+	parseFlags(cmd, args)
+	logs.apply()
+	conn.requireProxy(cmd)
+
+	params := bench.BenchParams{SeedRows: *seedRows, Reseed: *reseed, Relational: *relational, RowBytes: *rowBytes, Documents: *docs, Cockroach: *conn.dbType == "cockroach"}
+	table.apply(&params)
+
+	var seed func(bench.ConnConfig, bench.BenchParams) error
+	switch *conn.dbType {
+	case "postgres", "cockroach":
+		seed = pg.RunSeed
+	case "mysql":
+		seed = my.RunSeed
+	case "mssql":
+		seed = ms.RunSeed
+	default:
+		fail("database type '%s' not yet implemented", *conn.dbType)
+	}
+
+	ok := forTargets(conn, dataTenants(*conn.dbType, *tenants), *parallel, "Seeding", func(cfg bench.ConnConfig) error {
+		return seed(cfg, params)
+	})
+	if !ok {
+		os.Exit(1)
+	}
+}
+
+// runClean implements `tdb-bench clean`: truncate (or drop) the benchmark
+// table on every target tenant.
+func runClean(args []string) {
+	cmd := newFlagSet("clean", "-proxy-host <host> [flags]")
+	conn := addConnFlags(cmd)
+	tenants := cmd.String("tenants", "", "Comma-separated tenant databases, or \"all\" for the built-in bench list (default: -proxy-db only)")
+	parallel := cmd.Int("parallel", 1, "Tenants to process concurrently")
+	drop := cmd.Bool("drop", false, "DROP the table instead of truncating it")
+	table := addTableFlags(cmd)
+	logs := addLogFlags(cmd)
+	parseFlags(cmd, args)
+	logs.apply()
+	conn.requireProxy(cmd)
+
+	params := bench.BenchParams{Cockroach: *conn.dbType == "cockroach"}
+	table.apply(&params)
+
+	var clean func(bench.ConnConfig, bench.BenchParams, bool) error
+	switch *conn.dbType {
+	case "postgres", "cockroach":
+		clean = pg.RunClean
+	case "mysql":
+		clean = my.RunClean
+	case "mssql":
+		clean = ms.RunClean
+	default:
+		fail("database type '%s' not yet implemented", *conn.dbType)
+	}
+
+	verb := "Truncating"
+	if *drop {
+		verb = "Dropping"
+	}
+	ok := forTargets(conn, dataTenants(*conn.dbType, *tenants), *parallel, verb, func(cfg bench.ConnConfig) error {
+		return clean(cfg, params, *drop)
+	})
+	if !ok {
+		os.Exit(1)
+	}
+}
+
+// dataTenants expands the seed/clean -tenants value.
+func dataTenants(dbType, s string) []string {
+	if s != "all" {
+		return tenantList(s)
+	}
+	switch dbType {
+	case "postgres", "cockroach":
+		return pg.TenantList()
+	case "mysql":
+		return my.TenantList()
+	case "mssql":
+		return ms.TenantList()
+	}
+	return nil
+}
+
+// forEndpoints applies fn to the proxy endpoint and, when configured, the
+// direct endpoint, reporting each outcome. It returns false if any failed.
+func forEndpoints(conn *connFlags, verb string, fn func(bench.ConnConfig) error) bool {
+	return forTargets(conn, nil, 1, verb, fn)
+}
+
+// forTargets is forEndpoints over a tenant list: each tenant is applied to
+// the proxy (and direct, when configured) endpoint, up to parallel at once.
+// A nil list means the endpoints' own databases.
+func forTargets(conn *connFlags, tenants []string, parallel int, verb string, fn func(bench.ConnConfig) error) bool {
+	type target struct {
+		name string
+		cfg  bench.ConnConfig
+	}
+	endpoints := []target{{"proxy", conn.proxy()}}
+	if conn.hasDirect() {
+		endpoints = append(endpoints, target{"direct", conn.direct()})
+	}
+
+	var targets []target
+	if len(tenants) == 0 {
+		targets = endpoints
+	} else {
+		for _, t := range tenants {
+			for _, ep := range endpoints {
+				ep.cfg.Database = t
+				targets = append(targets, ep)
+			}
+		}
+	}
+
+	if parallel < 1 {
+		parallel = 1
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, parallel)
+	failed := 0
+
+	for _, tg := range targets {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(tg target) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			name := tg.cfg.Database
+			switch {
+			case name != "":
+			case tg.cfg.DSN != "":
+				name = "database from DSN"
+			default:
+				name = "default database"
+			}
+			if parallel == 1 {
+				bench.Stepf("%s %s (%s)...", verb, name, tg.name)
+			}
+			err := fn(tg.cfg)
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err != nil:
+				failed++
+				bench.Failf("%s (%s): %v", name, tg.name, err)
+			case parallel == 1:
+				bench.Okf("Done")
+			default:
+				bench.Okf("%s (%s)", name, tg.name)
+			}
+		}(tg)
+	}
+	wg.Wait()
+
+	if len(targets) > 1 {
+		bench.Infof("%d/%d succeeded", len(targets)-failed, len(targets))
+	}
+	return failed == 0
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"tenantsdb-bench/bench"
+	"tenantsdb-bench/ms"
+	"tenantsdb-bench/my"
+	"tenantsdb-bench/pg"
+)
+
+// runDoctor implements `tdb-bench doctor`: walk each endpoint through DNS,
+// TCP, TLS, authentication and a trivial query, step by step, without
+// benchmarking anything.
+func runDoctor(args []string) {
+	cmd := newFlagSet("doctor", "-proxy-host <host> [flags]")
+	conn := addConnFlags(cmd)
+	table := addTableFlags(cmd)
+	logs := addLogFlags(cmd)
+	parseFlags(cmd, args)
+	logs.apply()
+	conn.requireProxy(cmd)
+
+	var params bench.BenchParams
+	table.apply(&params)
+
+	var check func(bench.ConnConfig, bench.BenchParams) error
+	var address func(bench.ConnConfig) (string, int, error)
+	var probe bench.TLSProbe
+	switch *conn.dbType {
+	case "postgres", "cockroach":
+		check, address, probe = pg.RunDoctor, pg.Address, pg.ProbeTLS
+	case "mysql":
+		check, address, probe = my.RunDoctor, my.Address, my.ProbeTLS
+	case "mssql":
+		check, address, probe = ms.RunDoctor, ms.Address, ms.ProbeTLS
+	default:
+		fail("database type '%s' not yet implemented", *conn.dbType)
+	}
+
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Println("  Connectivity Check")
+	fmt.Println("═══════════════════════════════════════════")
+	ok := forEndpoints(conn, "Checking", func(cfg bench.ConnConfig) error {
+		host, port, err := address(cfg)
+		if err != nil {
+			return fmt.Errorf("dsn: %w", err)
+		}
+		if err := bench.DiagnoseNetwork(host, port, probe); err != nil {
+			return err
+		}
+		return check(cfg, params)
+	})
+	if !ok {
+		os.Exit(1)
+	}
+}
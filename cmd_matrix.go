@@ -0,0 +1,181 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"tenantsdb-bench/bench"
+)
+
+// matrixSpec is the matrix section of a -config file:
+//
+//	matrix:
+//	  profiles: [pg-staging, mysql-staging]
+//	  tests: [throughput, multi]
+//	  concurrency: [10, 50, 100]
+//	  workloads: [mixed, join]
+//	  flags:
+//	    duration: 30
+//
+// Every combination runs as its own `run` with that profile; flags apply to
+// every cell. An empty dimension keeps the profile's own setting.
+type matrixSpec struct {
+	Profiles    []string       `yaml:"profiles"`
+	Tests       []string       `yaml:"tests"`
+	Concurrency []int          `yaml:"concurrency"`
+	Workloads   []string       `yaml:"workloads"`
+	Flags       map[string]any `yaml:"flags"`
+}
+
+// matrixCell is one combination of a matrix.
+type matrixCell struct {
+	profile, test, workload string
+	concurrency             int
+}
+
+func (c matrixCell) tags() string {
+	tags := []string{"matrix-profile=" + c.profile, "matrix-test=" + c.test, "matrix-workload=" + c.workload}
+	if c.concurrency > 0 {
+		tags = append(tags, "matrix-concurrency="+strconv.Itoa(c.concurrency))
+	}
+	return strings.Join(tags, ",")
+}
+
+// runMatrix implements `tdb-bench matrix -config <file>`: run every cell of
+// the file's matrix section in turn, each as a separate run of this binary,
+// and print one summary of all of them.
+func runMatrix(args []string) {
+	cmd := newFlagSet("matrix", "-config <file> [flags]")
+	cfgPath := cmd.String("config", "", "YAML config file with profiles and a matrix section")
+	jsonPath := cmd.String("json", "", "Write every cell's results as one JSON file")
+	keepGoing := cmd.Bool("keep-going", true, "Run the remaining cells after one fails")
+	cmd.Parse(args)
+	if *cfgPath == "" {
+		cmd.Usage()
+		fail("matrix needs -config")
+	}
+
+	spec, err := loadMatrix(*cfgPath)
+	if err != nil {
+		fail("%v", err)
+	}
+	cells := spec.cells()
+	exe, err := os.Executable()
+	if err != nil {
+		fail("%v", err)
+	}
+	dir, err := os.MkdirTemp("", "tdb-bench-matrix-")
+	if err != nil {
+		fail("%v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	bench.Infof("Matrix: %d cells", len(cells))
+	matrix := &bench.Result{Test: "matrix", Started: time.Now()}
+	var failed []string
+	for i, c := range cells {
+		bench.Stepf("Cell %d/%d: %s", i+1, len(cells), c.tags())
+		out := filepath.Join(dir, fmt.Sprintf("cell%03d.json", i+1))
+		run := exec.Command(exe, spec.args(*cfgPath, c, out)...)
+		run.Stdout, run.Stderr = os.Stdout, os.Stderr
+		err := run.Run()
+		var res *bench.Result
+		if err == nil {
+			res, err = bench.ReadJSON(out)
+		}
+		if err != nil {
+			bench.Failf("Cell %d failed: %v", i+1, err)
+			failed = append(failed, c.tags())
+			if !*keepGoing {
+				break
+			}
+			continue
+		}
+		matrix.Suite = append(matrix.Suite, res)
+	}
+
+	bench.PrintMatrix(matrix.Suite)
+	for _, f := range failed {
+		bench.Failf("Failed: %s", f)
+	}
+	if *jsonPath != "" {
+		if err := bench.WriteJSON(*jsonPath, matrix); err != nil {
+			bench.Warnf("JSON: %v", err)
+		} else {
+			fmt.Println()
+			bench.Okf("Results written to %s", *jsonPath)
+		}
+	}
+	if len(failed) > 0 {
+		os.Exit(1)
+	}
+}
+
+func loadMatrix(path string) (*matrixSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	var cf struct {
+		Matrix *matrixSpec `yaml:"matrix"`
+	}
+	if err := yaml.Unmarshal(data, &cf); err != nil {
+		return nil, fmt.Errorf("config %s: %w", path, err)
+	}
+	if cf.Matrix == nil {
+		return nil, fmt.Errorf("config %s: no matrix section", path)
+	}
+	return cf.Matrix, nil
+}
+
+// cells expands the matrix, profiles outermost.
+func (m *matrixSpec) cells() []matrixCell {
+	profiles, tests, workloads := orEmpty(m.Profiles), orEmpty(m.Tests), orEmpty(m.Workloads)
+	conc := m.Concurrency
+	if len(conc) == 0 {
+		conc = []int{0}
+	}
+	var cells []matrixCell
+	for _, p := range profiles {
+		for _, t := range tests {
+			for _, w := range workloads {
+				for _, c := range conc {
+					cells = append(cells, matrixCell{profile: p, test: t, workload: w, concurrency: c})
+				}
+			}
+		}
+	}
+	return cells
+}
+
+// args builds the run command line for a cell.
+func (m *matrixSpec) args(cfgPath string, c matrixCell, out string) []string {
+	args := []string{"run", "-config", cfgPath, "-json", out, "-tags", c.tags()}
+	if c.profile != "" {
+		args = append(args, "-profile", c.profile)
+	}
+	if c.test != "" {
+		args = append(args, "-test", c.test)
+	}
+	if c.workload != "" {
+		args = append(args, "-workload", c.workload)
+	}
+	if c.concurrency > 0 {
+		args = append(args, "-concurrency", strconv.Itoa(c.concurrency))
+	}
+	return append(args, flagArgs(m.Flags)...)
+}
+
+func orEmpty(vals []string) []string {
+	if len(vals) == 0 {
+		return []string{""}
+	}
+	return vals
+}
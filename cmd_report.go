@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+
+	"tenantsdb-bench/bench"
+)
+
+// runReport implements `tdb-bench report <results.json>`.
+func runReport(args []string) {
+	cmd := newFlagSet("report", "<results.json>")
+	plain := cmd.Bool("plain", false, "ASCII-only output: no box drawing, emoji or other Unicode symbols")
+	cmd.Parse(args)
+	defer usePlain(*plain)()
+	if cmd.NArg() != 1 {
+		cmd.Usage()
+		fail("report takes exactly one results file")
+	}
+
+	res, err := bench.ReadJSON(cmd.Arg(0))
+	if err != nil {
+		fail("%v", err)
+	}
+
+	m := res.Manifest
+	fmt.Printf("%s / %s — %s\n", res.DB, res.Test, res.Started.Local().Format("2006-01-02 15:04:05"))
+	fmt.Printf("tdb-bench %s (%s) on %s\n", m.ShortRevision(), m.GoVersion, m.Hostname)
+	if m.RunID != "" {
+		fmt.Printf("Run:            %s", m.RunID)
+		if len(res.Tags) > 0 {
+			fmt.Printf(" (%s)", bench.FormatTags(res.Tags))
+		}
+		fmt.Println()
+	}
+	if m.ProxyVersion != "" {
+		fmt.Printf("Proxy server:   %s\n", bench.ShortVersion(m.ProxyVersion))
+	}
+	if m.BackendVersion != "" {
+		fmt.Printf("Direct server:  %s\n", bench.ShortVersion(m.BackendVersion))
+	}
+
+	for _, st := range res.Stats {
+		bench.PrintStats(st)
+	}
+	for _, st := range res.Agents {
+		fmt.Printf("  Agent %s: QPS=%.1f  p50=%s  p99=%s  errors=%d\n",
+			st.Label, st.QPS, bench.FmtDur(st.LatencyP50), bench.FmtDur(st.LatencyP99), st.Errors)
+	}
+	for _, r := range res.Suite {
+		fmt.Printf("\n▶ %s\n", r.Test)
+		for _, st := range r.Stats {
+			bench.PrintStats(st)
+		}
+		if r.Comparison != nil {
+			bench.PrintComparison(r.Comparison.Proxy, r.Comparison.Direct)
+		}
+	}
+	if res.Test == "matrix" {
+		bench.PrintMatrix(res.Suite)
+	} else if len(res.Suite) > 0 {
+		bench.PrintSuite(res.Suite)
+	}
+	if len(res.Auth) > 0 {
+		bench.PrintAuth(res.Auth)
+	}
+	if len(res.Models) > 0 {
+		bench.PrintModels(res.Models)
+	}
+	if res.Comparison != nil {
+		bench.PrintComparison(res.Comparison.Proxy, res.Comparison.Direct)
+	}
+	bench.PrintSlowest(res.Slowest, "", "")
+	bench.PrintSlow(res.SlowQueries)
+	bench.PrintTimeline(res.Timeline)
+	if res.Container != nil {
+		bench.PrintContainer(res.Container)
+	}
+	if res.Server != nil {
+		bench.PrintServer(res.Server)
+	}
+	if res.Waits != nil {
+		bench.PrintWaits(res.Waits)
+	}
+	if res.Client != nil {
+		bench.PrintClient(*res.Client)
+	}
+}
+
+// runCompare implements `tdb-bench compare <before.json> <after.json>`.
+func runCompare(args []string) {
+	cmd := newFlagSet("compare", "<before.json> <after.json>")
+	plain := cmd.Bool("plain", false, "ASCII-only output: no box drawing, emoji or other Unicode symbols")
+	cmd.Parse(args)
+	defer usePlain(*plain)()
+	if cmd.NArg() != 2 {
+		cmd.Usage()
+		fail("compare takes exactly two results files")
+	}
+
+	before, err := bench.ReadJSON(cmd.Arg(0))
+	if err != nil {
+		fail("%v", err)
+	}
+	after, err := bench.ReadJSON(cmd.Arg(1))
+	if err != nil {
+		fail("%v", err)
+	}
+	bench.PrintResultDiff(before, after)
+}
@@ -0,0 +1,1076 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"tenantsdb-bench/bench"
+	"tenantsdb-bench/chart"
+	"tenantsdb-bench/control"
+	"tenantsdb-bench/docker"
+	"tenantsdb-bench/grafana"
+	"tenantsdb-bench/history"
+	"tenantsdb-bench/ms"
+	"tenantsdb-bench/my"
+	"tenantsdb-bench/pg"
+)
+
+// errMaxRuntime is the cancellation cause when -max-runtime runs out.
+var errMaxRuntime = errors.New("-max-runtime reached")
+
+// maxRuntimeGrace is how long past -max-runtime the run may take to report
+// and tear down before the process exits anyway.
+const maxRuntimeGrace = time.Minute
+
+// runBench implements `tdb-bench run`.
+func runBench(args []string) {
+	cmd := newFlagSet("run", "-proxy-host <host> [flags]")
+	conn := addConnFlags(cmd)
+	table := addTableFlags(cmd)
+
+	testType := cmd.String("test", "overhead", "Test type: overhead, throughput, multi, isolation, scale, stream, ingest, session, temptable, savepoint, conflict, prepared, inlist, verify, leakage, replica, failover, connlimit, auth, quota, idle, soak, coldstart, provision, churn, notify, cursor, advisory (Postgres), or all (overhead, throughput, multi, isolation and scale in turn)")
+	workload := cmd.String("workload", "mixed", "Query mix: mixed (80% point read / 20% update), join (adds orders/order_items joins; implies -relational) wide (reads/rewrites -row-bytes payloads), page (OFFSET vs keyset pagination), agg (GROUP BY/SUM reports), upsert (ON CONFLICT / ON DUPLICATE KEY UPDATE) or json (document containment, path reads, partial updates; implies -docs), or one registered with bench.RegisterWorkload")
+	docs := cmd.Bool("docs", false, "Also seed a JSON document column (jsonb on Postgres)")
+	asOf := cmd.String("as-of", "", "CockroachDB: run the workload's reads AS OF SYSTEM TIME, \"follower\" or a staleness such as 10s (\"\" = current)")
+	hotPct := cmd.Int("hot-pct", 0, "Percentage of mixed-workload writes that are transfers between hot rows (0 = off)")
+	hotRows := cmd.Int("hot-rows", 10, "Number of hot rows (ids 1..N) targeted by -hot-pct and -test conflict")
+	lockKeys := cmd.Int("lock-keys", 10, "Distinct advisory lock keys contended in -test advisory")
+	checkIntegrity := cmd.Bool("check-integrity", false, "Check row counts, NULLs and the balance sum before and after each run (overhead/throughput/isolation/multi)")
+	lagProbes := cmd.Int("lag-probes", 200, "Write-then-read probes in -test replica")
+	failoverAt := cmd.Int("failover-at", 10, "Seconds into -test failover at which to trigger the failover")
+	failoverCmd := cmd.String("failover-cmd", "", "Shell command that triggers the failover in -test failover, e.g. 'docker restart pg' (default: prompt to do it by hand)")
+	maxConns := cmd.Int("max-conns", 500, "Most connections -test connlimit opens while looking for the limit")
+	authUsers := cmd.String("auth-users", "", "Comma-separated logins -test auth connects as, e.g. one per auth method the server is set up with; passwords come from TDB_AUTH_PASS_<LOGIN> or -password-file, else the endpoint's (default: the endpoint's user)")
+	authConnects := cmd.Int("auth-connects", 50, "Connections -test auth opens per user and endpoint")
+	quotaQPS := cmd.Float64("quota-qps", 0, "QPS quota configured in the proxy for the first tenant in -test quota, which offers twice that")
+	bystanderQPS := cmd.Float64("bystander-qps", 50, "Steady QPS of each other tenant in -test quota")
+	idleIntervals := cmd.String("idle-intervals", "30,120,300,600", "Comma-separated idle seconds probed by -test idle")
+	soakInterval := cmd.Int("soak-interval", 60, "Seconds between queries on each connection in -test soak")
+	hibernateAfter := cmd.Int("hibernate-after", 300, "Seconds -test coldstart idles before each wake-up; set past the platform's hibernation threshold")
+	wakeCycles := cmd.Int("wake-cycles", 5, "Hibernate/wake cycles in -test coldstart")
+	apiURL := cmd.String("api-url", "", "TenantsDB management API base URL, for -test provision")
+	apiToken := cmd.String("api-token", "", "Management API token (default: $TDB_API_TOKEN)")
+	provisionCount := cmd.Int("provision-count", 5, "Fresh tenants created in -test provision")
+	autoProv := cmd.String("auto-provision", "", "Create the tenants the test needs before the run and drop them after: api (management API) or sql (CREATE DATABASE through the proxy endpoint, for direct mode)")
+	keepTenants := cmd.Bool("keep-tenants", false, "Leave -auto-provision tenants in place after the run")
+	tenantPrefix := cmd.String("tenant-prefix", "tdbbench", "Name prefix of tenants the bench creates")
+	connStrategy := cmd.String("conn-strategy", "pool", "How each tenant's client uses connections in overhead, throughput, multi, isolation and scale: pool (up to 10 reused), per-query (a fresh connection for every query) or single (one connection its workers share)")
+	compareModels := cmd.Bool("compare-models", false, "Run multi and isolation once per tenancy model (db, schema, and rls on Postgres), each with its own tenants, and print a table comparing their overhead, fairness and isolation impact; replaces -test")
+	tenancy := cmd.String("tenancy", "db", "Tenant layout for -test multi, isolation and scale: db (a database each), schema (a schema each in the proxy endpoint's database, Postgres; created for the run) or rls (a role each sharing one row-level-security table there, Postgres; created for the run)")
+	churnRate := cmd.Float64("churn-rate", 2, "Tenants joining per second in -test churn")
+	churnQueries := cmd.Int("churn-queries", 20, "Queries each churning tenant runs before disconnecting in -test churn")
+	tenantSkew := cmd.Float64("tenant-skew", 0, "Zipf exponent (> 1, e.g. 1.2) spreading multi/scale load across tenants so a few dominate; 0 = even split")
+	seedRowsMax := cmd.Int("seed-rows-max", 0, "Give each scale tenant a log-normal row count between -seed-rows and this (0 = all -seed-rows)")
+	rampTenants := cmd.Int("ramp-tenants", 0, "Bring scale tenants online in waves of this many during a timed run (0 = all at once)")
+	rampEvery := cmd.Int("ramp-every", 30, "Seconds between -ramp-tenants waves")
+	tenantCount := cmd.Int("tenant-count", 0, "Extend the built-in tenant list to this many tenants (e.g. 1000 for the scale test)")
+	maxClientConns := cmd.Int("max-client-conns", 0, "High-scale mode for -test scale: hold at most this many client connections and cycle them through the tenants (0 = a pool per tenant)")
+	cycleQueries := cmd.Int("cycle-queries", 50, "Queries per tenant visit in -max-client-conns mode")
+	maxClientMem := cmd.Int("max-client-mem", 2048, "MiB of client heap after which a -max-client-conns run stops early")
+	gomaxprocs := cmd.Int("gomaxprocs", 0, "Go scheduler P's for the bench process (0 = runtime default, one per CPU)")
+	pinWorkers := cmd.Bool("pin-workers", false, "Lock each worker to an OS thread bound to CPU worker % GOMAXPROCS (Linux)")
+	pprofAddr := cmd.String("pprof-addr", "", "Serve /debug/pprof on this address (e.g. localhost:6060) while the bench runs")
+	profileDir := cmd.String("profile-dir", "", "Capture CPU and heap profiles of the bench client over the run into this directory")
+	serverStats := cmd.Bool("server-stats", false, "Diff the direct backend's statistics views across the run (needs -direct-*)")
+	waitSample := cmd.Int("wait-sample", 0, "Poll pg_stat_activity on the direct backend every N ms and summarize wait events (0 = off, Postgres)")
+	slowQueries := cmd.Int("slow-queries", 0, "Record the N slowest individual statements with parameters and tenant (0 = off, Postgres)")
+	explainSlow := cmd.Bool("explain", false, "EXPLAIN (without ANALYZE) each -slow-queries statement on the direct backend")
+	topSlow := cmd.Int("top-slow", 10, "List the N slowest requests with time, tenant and operation after the run (0 = off)")
+	dockerContainer := cmd.String("docker-container", "", "Sample CPU, memory and network of this proxy container through the Docker API during the run")
+	dockerHost := cmd.String("docker-host", "", "Docker daemon for -docker-container (default $DOCKER_HOST, else "+docker.DefaultHost+")")
+	local := cmd.Bool("local", false, "Start a throwaway backend container (and -local-proxy-image) with the docker CLI, run against it and remove it after")
+	localImage := cmd.String("local-image", "", "Backend image for -local (default postgres:16-alpine or mysql:8.4)")
+	localProxyImage := cmd.String("local-proxy-image", "", "Proxy image to start with -local; it reaches the backend as host \"db\"")
+	localProxyPort := cmd.Int("local-proxy-port", 0, "Port the -local-proxy-image listens on (default: the backend's port)")
+	localProxyEnv := cmd.String("local-proxy-env", "", "Comma-separated KEY=value environment for -local-proxy-image")
+	skipPreflight := cmd.Bool("skip-preflight", false, "Skip the connectivity, table, clock and open-file checks before the run")
+	dryRun := cmd.Bool("dry-run", false, "Print the execution plan (tenants, concurrency, length, workload, seeding) and exit without connecting")
+	agents := cmd.String("agents", "", "Comma-separated host:port of tdb-bench agents to shard the workload across (throughput only)")
+	agentToken := cmd.String("agent-token", os.Getenv("TDB_BENCH_TOKEN"), "Shared secret the -agents were started with (default $TDB_BENCH_TOKEN)")
+	notifyURL := cmd.String("notify-url", "", "Slack or generic webhook that gets a summary (test, QPS, p50/p99, overhead, verdict) when the run ends")
+	notifyOn := cmd.String("notify-on", "finish", "When to post to -notify-url: finish (every run) or violation (only failed runs and broken -max-* thresholds)")
+	maxP99 := cmd.Int("max-p99", 0, "Threshold: p99 latency in ms the run must stay under (0 = none)")
+	maxOverhead := cmd.Float64("max-overhead", 0, "Threshold: proxy overhead at p50 in % the overhead test must stay under (0 = none)")
+	grafanaURL := cmd.String("grafana-url", "", "Grafana base URL to post a run-start/run-end annotation to")
+	grafanaToken := cmd.String("grafana-token", "", "Grafana service account token (default $GRAFANA_TOKEN)")
+	grafanaDashboard := cmd.String("grafana-dashboard", "", "Dashboard UID to scope the annotation to (default: organization-wide)")
+	maxRuntime := cmd.Int("max-runtime", 0, "Seconds the whole invocation may take, setup, seeding, warmup and cooldowns included; the run is then cancelled and reported (0 = unbounded)")
+	warmupWindow := cmd.Int("warmup-window", 0, "Timed runs: seconds at the start whose queries are recorded but left out of stats")
+	cooldownWindow := cmd.Int("cooldown-window", 0, "Timed runs: seconds at the end whose queries are recorded but left out of stats")
+	window := cmd.String("window", bench.WindowExclude, "Timed runs: exclude queries still running at the deadline, or include them and stretch the window (exclude, include)")
+	percentiles := cmd.String("percentiles", "", "Comma-separated latency percentiles to report instead of p50/p75/p90/p95/p99, e.g. 50,90,99,99.9,99.99")
+	slo := cmd.String("slo", "", "Comma-separated latency bounds, e.g. 1ms,5ms,25ms: report the share of queries within each and an Apdex score for the first")
+	trim := cmd.Float64("trim", 0, "Also report latency with this percentage of the fastest and slowest queries dropped, e.g. 0.1 (0 = off)")
+	charts := cmd.String("charts", "", "Directory to write SVG charts to: latency distribution, QPS over time and direct vs proxy")
+	sampleRate := cmd.Float64("sample-rate", 0, "Fraction of individual query results to keep and write to -json, e.g. 0.01; the exported latency histogram still counts every query (0 = none)")
+	sampleCap := cmd.Int("sample-cap", 100000, "Most -sample-rate results kept per tenant and op; beyond it a uniform reservoir sample is kept (0 = no cap)")
+	baselineDir := cmd.String("baseline", "", "Check the result against the baseline saved in this directory by 'baseline save' and exit 1 outside tolerance")
+	tol := addToleranceFlags(cmd)
+	isolation := cmd.String("isolation", "serializable", "Isolation level for -test conflict: serializable or repeatable-read")
+	aggWorkers := cmd.Int("agg-workers", 0, "Of -concurrency, workers running GROUP BY/SUM reports alongside the workload (overhead/throughput)")
+	pageSize := cmd.Int("page-size", 20, "Rows per page for -workload page")
+	rowBytes := cmd.Int("row-bytes", 0, "Size of a payload filler column seeded into every row (0 = none; -workload wide defaults to 1024)")
+	relational := cmd.Bool("relational", false, "Also seed orders and order_items tables with foreign keys to the benchmark table")
+
+	queries := cmd.Int("queries", 10000, "Number of queries (count-based mode)")
+	concurrency := cmd.Int("concurrency", 10, "Concurrent connections")
+	warmup := cmd.Int("warmup", 100, "Warmup queries before measuring")
+	seedRows := cmd.Int("seed-rows", 10000, "Rows to insert for test data")
+	reseed := cmd.Bool("reseed", false, "Truncate the table and reseed rows 1..seed-rows deterministically before running")
+	duration := cmd.Int("duration", 0, "Run duration in seconds (0 = use query count)")
+	runs := cmd.Int("runs", 1, "Number of runs for median calculation (1 = single run)")
+	ingestRows := cmd.Int("ingest-rows", 100000, "Rows loaded per pass of -test ingest")
+	ingestBatch := cmd.Int("ingest-batch", 10000, "Rows per COPY / LOAD DATA statement in -test ingest")
+	notifications := cmd.Int("notifications", 1000, "NOTIFYs sent per pass of -test notify")
+	fetchSize := cmd.Int("fetch-size", 100, "Rows per FETCH in -test cursor")
+	cursorFetches := cmd.Int("cursor-fetches", 10, "FETCHes per cursor in -test cursor")
+	sessionQueries := cmd.Int("session-queries", 10, "Query+verify pairs per session in -test session (reads per session in -test temptable, transactions in -test savepoint, locks in -test advisory)")
+	streamRows := cmd.String("stream-rows", "100,10000,1000000", "Comma-separated result sizes for -test stream (seed-rows is raised to the largest)")
+	preparedStmts := cmd.String("prepared-stmts", "100,1000,10000", "Comma-separated statements prepared per connection for -test prepared")
+	inParams := cmd.String("in-params", "10,100,1000", "Comma-separated IN-list sizes for -test inlist")
+	streamIters := cmd.Int("stream-iters", 5, "Queries per result size for -test stream")
+	tenants := cmd.String("tenants", "", "Comma-separated tenant databases for multi/isolation/scale/leakage (default: built-in bench list)")
+
+	jsonPath := cmd.String("json", "", "Write results (with run manifest) as JSON to this file")
+	historyPath := cmd.String("history", "", "Append results to this SQLite history file")
+	tagList := cmd.String("tags", "", "Comma-separated key=value tags recorded with the results")
+	logs := addLogFlags(cmd)
+	showProgress := cmd.Bool("progress", true, "Draw a progress bar with an ETA during count-based runs (only when stdout is a terminal)")
+	plain := cmd.Bool("plain", false, "ASCII-only output: no box drawing, emoji or other Unicode symbols")
+	logFile := cmd.String("log-file", "", "Also write all output, result tables included, to this file with the start time added to its name (run.log becomes run-20060102-150405.log)")
+	runID := cmd.String("run-id", "", "Identifier recorded with the results, history, notifications and annotations (default: start time plus a random suffix)")
+
+	parseFlags(cmd, args)
+	invoked := time.Now()
+	if *showProgress && logs.human() && isTerminal(os.Stdout) {
+		// The bar goes to the terminal itself, past -log-file and -plain
+		bench.ProgressOut = os.Stdout
+	}
+	closeOutput := func() {}
+	if *logFile != "" {
+		stop, err := teeOutput(logFileName(*logFile, invoked))
+		if err != nil {
+			fail("-log-file: %v", err)
+		}
+		closeOutput = stop
+	}
+	if *plain {
+		stopPlain, closeLog := usePlain(true), closeOutput
+		closeOutput = func() { stopPlain(); closeLog() }
+	}
+	defer closeOutput()
+	logs.apply()
+	if *maxRuntime < 0 {
+		fail("-max-runtime cannot be negative")
+	}
+	if *maxRuntime > 0 {
+		// Backstop for steps that cannot be cancelled, such as a hung
+		// connect during setup or teardown
+		time.AfterFunc(time.Duration(*maxRuntime)*time.Second+maxRuntimeGrace, func() {
+			fail("-max-runtime of %ds exceeded by %s, giving up", *maxRuntime, maxRuntimeGrace)
+		})
+	}
+	if *local {
+		if _, ok := localBackends[*conn.dbType]; !ok {
+			fail("-local does not support %s", *conn.dbType)
+		}
+		// The local backend holds no bench tenants; make them when the test needs several
+		if *autoProv == "" && *localProxyImage == "" && tenantsNeeded(*testType) > 1 {
+			*autoProv = "sql"
+		}
+	}
+	if !*local {
+		conn.requireProxy(cmd)
+	}
+
+	tags, err := bench.ParseTags(*tagList)
+	if err != nil {
+		fail("%v", err)
+	}
+	manifest := bench.NewManifest(cmd)
+	if *runID != "" {
+		if strings.ContainsFunc(*runID, func(r rune) bool { return r <= ' ' || r == ',' }) {
+			fail("-run-id cannot contain spaces or commas")
+		}
+		manifest.RunID = *runID
+	}
+	fmt.Printf("tdb-bench %s (%s, %s/%s) on %s\n",
+		manifest.ShortRevision(), manifest.GoVersion, manifest.OS, manifest.Arch, manifest.Hostname)
+	fmt.Printf("Run: %s", manifest.RunID)
+	if len(tags) > 0 {
+		fmt.Printf(" (%s)", bench.FormatTags(tags))
+	}
+	fmt.Println()
+
+	proxyCfg := conn.proxy()
+	directCfg := conn.direct()
+	// -local fills in the endpoints once its containers are up
+	hasDirect := conn.hasDirect() || *local
+
+	params := bench.BenchParams{
+		Queries:        *queries,
+		Concurrency:    *concurrency,
+		Warmup:         *warmup,
+		SeedRows:       *seedRows,
+		Reseed:         *reseed,
+		Duration:       time.Duration(*duration) * time.Second,
+		WarmupWindow:   time.Duration(*warmupWindow) * time.Second,
+		CooldownWindow: time.Duration(*cooldownWindow) * time.Second,
+		Runs:           *runs,
+		Tenants:        tenantList(*tenants),
+		Workload:       *workload,
+		Relational:     *relational || *workload == "join",
+		RowBytes:       *rowBytes,
+		Documents:      *docs || *workload == "json",
+		StreamIters:    *streamIters,
+		PageSize:       *pageSize,
+		IngestRows:     *ingestRows,
+		IngestBatch:    *ingestBatch,
+		Notifications:  *notifications,
+		FetchSize:      *fetchSize,
+		CursorFetches:  *cursorFetches,
+		SessionQueries: *sessionQueries,
+		AggWorkers:     *aggWorkers,
+		HotPct:         *hotPct,
+		HotRows:        *hotRows,
+		Isolation:      *isolation,
+		LockKeys:       *lockKeys,
+		CheckIntegrity: *checkIntegrity,
+		LagProbes:      *lagProbes,
+		FailoverAt:     time.Duration(*failoverAt) * time.Second,
+		FailoverCmd:    *failoverCmd,
+		MaxConns:       *maxConns,
+		AuthUsers:      tenantList(*authUsers),
+		AuthConnects:   *authConnects,
+		QuotaQPS:       *quotaQPS,
+		BystanderQPS:   *bystanderQPS,
+		SoakInterval:   time.Duration(*soakInterval) * time.Second,
+		HibernateAfter: time.Duration(*hibernateAfter) * time.Second,
+		WakeCycles:     *wakeCycles,
+		ProvisionCount: *provisionCount,
+		TenantPrefix:   *tenantPrefix,
+		ChurnRate:      *churnRate,
+		ChurnQueries:   *churnQueries,
+		TenantSkew:     *tenantSkew,
+		SeedRowsMax:    *seedRowsMax,
+		RampTenants:    *rampTenants,
+		RampEvery:      time.Duration(*rampEvery) * time.Second,
+		MaxClientConns: *maxClientConns,
+		CycleQueries:   *cycleQueries,
+		MaxClientMem:   *maxClientMem,
+		Cockroach:      *conn.dbType == "cockroach",
+		AsOf:           *asOf,
+		Tenancy:        *tenancy,
+	}
+	table.apply(&params)
+	if params.AsOf != "" {
+		if !params.Cockroach {
+			fail("-as-of needs -db cockroach")
+		}
+		if d, err := time.ParseDuration(params.AsOf); params.AsOf != "follower" && (err != nil || d <= 0) {
+			fail("-as-of must be \"follower\" or a positive duration")
+		}
+	}
+
+	switch params.Tenancy {
+	case "db":
+	case "schema":
+		if *conn.dbType != "postgres" && *conn.dbType != "cockroach" {
+			fail("-tenancy schema is Postgres-only")
+		}
+		if *testType != "multi" && *testType != "isolation" && *testType != "scale" {
+			fail("-tenancy schema supports -test multi, isolation and scale")
+		}
+		if params.Schema != "" {
+			fail("-tenancy schema puts each tenant in its own schema; drop -schema")
+		}
+		if *autoProv == "api" {
+			fail("-tenancy schema creates its own schemas; drop -auto-provision api")
+		}
+		*autoProv = "schema"
+	case "rls":
+		if *conn.dbType != "postgres" {
+			fail("-tenancy rls is Postgres-only")
+		}
+		if *testType != "multi" && *testType != "isolation" && *testType != "scale" {
+			fail("-tenancy rls supports -test multi, isolation and scale")
+		}
+		if params.Schema != "" || params.Relational {
+			fail("-tenancy rls shares one accounts table; drop -schema, -relational and -workload join")
+		}
+		if *conn.proxyDSN != "" {
+			fail("-tenancy rls connects as each tenant's role; use -proxy-host and friends instead of -proxy-dsn")
+		}
+		if *autoProv == "api" {
+			fail("-tenancy rls creates its own roles; drop -auto-provision api")
+		}
+		*autoProv = "rls"
+	default:
+		fail("-tenancy must be db, schema or rls")
+	}
+
+	switch params.Workload {
+	case "mixed", "join", "agg", "upsert", "json":
+	case "page":
+		if params.PageSize <= 0 {
+			fail("-page-size must be positive")
+		}
+	case "wide":
+		if params.RowBytes <= 0 {
+			params.RowBytes = 1024
+		}
+	default:
+		f, ok := bench.LookupWorkload(params.Workload)
+		if !ok {
+			fail("unknown workload: %s", params.Workload)
+		}
+		if _, err := f(*conn.dbType, params); err != nil {
+			fail("-workload %s: %v", params.Workload, err)
+		}
+	}
+	if params.HotPct > 0 && (params.HotPct > 100 || params.HotRows < 2 || params.HotRows > params.SeedRows) {
+		fail("-hot-pct needs a percentage up to 100 and -hot-rows between 2 and -seed-rows")
+	}
+	for _, login := range params.AuthUsers {
+		if strings.Contains(login, ":") {
+			fail("-auth-users takes logins only; set each password in TDB_AUTH_PASS_<LOGIN> or -password-file")
+		}
+		if pass, ok := conn.authPassword(login); ok {
+			if params.AuthPasswords == nil {
+				params.AuthPasswords = map[string]string{}
+			}
+			params.AuthPasswords[login] = pass
+		}
+	}
+	if params.AggWorkers < 0 || params.AggWorkers >= params.Concurrency {
+		fail("-agg-workers must be between 0 and -concurrency - 1")
+	}
+
+	if params.Duration > 0 {
+		fmt.Printf("Mode: time-based (%ds per run, in-flight at deadline: %sd", *duration, *window)
+		if *warmupWindow > 0 || *cooldownWindow > 0 {
+			fmt.Printf(", first %ds / last %ds unmeasured", *warmupWindow, *cooldownWindow)
+		}
+	} else {
+		fmt.Printf("Mode: count-based (%d queries per run", params.Queries)
+	}
+	if params.Runs > 1 {
+		fmt.Printf(", %d runs, median reported)\n", params.Runs)
+	} else {
+		fmt.Println(", single run)")
+	}
+	if params.Cockroach {
+		reads := "current"
+		if params.AsOf != "" {
+			reads = "AS OF SYSTEM TIME " + params.AsOf
+		}
+		fmt.Printf("CockroachDB: %s keys, restarts retried, reads %s\n", params.KeyType, reads)
+	}
+
+	if *testType == "stream" {
+		params.StreamRows, err = intList(*streamRows)
+		if err != nil || len(params.StreamRows) == 0 {
+			fail("invalid -stream-rows %q", *streamRows)
+		}
+		if largest := slices.Max(params.StreamRows); largest > params.SeedRows {
+			bench.Infof("Seed rows: raised to %d for the largest result", largest)
+			params.SeedRows = largest
+		}
+	}
+
+	if *testType == "prepared" {
+		params.PreparedStmts, err = intList(*preparedStmts)
+		if err != nil || len(params.PreparedStmts) == 0 || slices.Min(params.PreparedStmts) <= 0 {
+			fail("invalid -prepared-stmts %q", *preparedStmts)
+		}
+	}
+
+	if *testType == "idle" {
+		secs, err := intList(*idleIntervals)
+		if err != nil || len(secs) == 0 {
+			fail("invalid -idle-intervals %q", *idleIntervals)
+		}
+		slices.Sort(secs)
+		for _, s := range secs {
+			params.IdleIntervals = append(params.IdleIntervals, time.Duration(s)*time.Second)
+		}
+	}
+
+	if *testType == "inlist" {
+		params.InParams, err = intList(*inParams)
+		if err != nil || len(params.InParams) == 0 || slices.Min(params.InParams) <= 0 {
+			fail("invalid -in-params %q", *inParams)
+		}
+		if slices.Max(params.InParams) > params.SeedRows {
+			fail("-in-params cannot exceed -seed-rows")
+		}
+	}
+
+	if *testType == "ingest" && (params.IngestRows <= 0 || params.IngestBatch <= 0) {
+		fail("-ingest-rows and -ingest-batch must be positive")
+	}
+
+	if *testType == "cursor" && (params.FetchSize <= 0 || params.CursorFetches <= 0) {
+		fail("-fetch-size and -cursor-fetches must be positive")
+	}
+
+	if (*testType == "session" || *testType == "temptable" || *testType == "savepoint" || *testType == "advisory") && params.SessionQueries <= 0 {
+		fail("-session-queries must be positive")
+	}
+
+	if *testType == "failover" && (params.Duration <= 0 || params.FailoverAt <= 0 || params.FailoverAt >= params.Duration) {
+		fail("-test failover needs -duration and a -failover-at inside it")
+	}
+
+	if *testType == "connlimit" && params.MaxConns <= 0 {
+		fail("-max-conns must be positive")
+	}
+
+	if *testType == "coldstart" && (params.HibernateAfter <= 0 || params.WakeCycles <= 0) {
+		fail("-hibernate-after and -wake-cycles must be positive")
+	}
+
+	if *testType == "auth" && params.AuthConnects <= 0 {
+		fail("-auth-connects must be positive")
+	}
+
+	if *testType == "soak" && (params.Duration <= 0 || params.SoakInterval <= 0 || params.SoakInterval >= params.Duration) {
+		fail("-test soak needs -duration (e.g. 4h) and a shorter positive -soak-interval")
+	}
+
+	var api *control.Client
+	if *apiURL != "" {
+		token := *apiToken
+		if token == "" {
+			token = os.Getenv("TDB_API_TOKEN")
+		}
+		api = control.New(*apiURL, token, *conn.dbType)
+	}
+	if *testType == "provision" && (api == nil || params.ProvisionCount <= 0) {
+		fail("-test provision needs -api-url and a positive -provision-count")
+	}
+
+	if *tenantCount > 0 && len(params.Tenants) == 0 {
+		switch *conn.dbType {
+		case "postgres", "cockroach":
+			params.Tenants = pg.TenantNames(*tenantCount)
+		case "mysql":
+			params.Tenants = my.TenantNames(*tenantCount)
+		case "mssql":
+			params.Tenants = ms.TenantNames(*tenantCount)
+		}
+	}
+
+	// The db model's databases: -tenants as given, else created like -auto-provision
+	var dbProv string
+	if *compareModels {
+		switch {
+		case *conn.dbType != "postgres" && *conn.dbType != "cockroach":
+			fail("-compare-models needs -db postgres or cockroach")
+		case params.Tenancy != "db":
+			fail("-compare-models runs every tenancy model; drop -tenancy")
+		case params.Schema != "" || params.Relational:
+			fail("-compare-models needs the schema and rls models' single accounts table; drop -schema, -relational and -workload join")
+		case *conn.dbType == "postgres" && *conn.proxyDSN != "":
+			fail("-compare-models connects as each rls tenant's role; use -proxy-host and friends instead of -proxy-dsn")
+		case *autoProv == "api" && api == nil:
+			fail("-auto-provision api needs -api-url")
+		}
+		dbProv, *autoProv = *autoProv, ""
+		if dbProv == "" && len(params.Tenants) == 0 {
+			dbProv = "sql"
+		}
+		*testType = "models"
+	}
+
+	switch *connStrategy {
+	case "pool":
+	case "per-query", "single":
+		switch *testType {
+		case "overhead", "throughput", "multi", "isolation", "scale", "all", "suite", "models":
+		default:
+			fail("-conn-strategy %s supports -test overhead, throughput, multi, isolation and scale", *connStrategy)
+		}
+		if params.MaxClientConns > 0 {
+			fail("-max-client-conns manages its own connections; drop -conn-strategy")
+		}
+		// Both endpoints, so the overhead test compares like with like
+		proxyCfg.Strategy, directCfg.Strategy = *connStrategy, *connStrategy
+		bench.Infof("Connections: %s", map[string]string{
+			"per-query": "a fresh one for every query",
+			"single":    "one per tenant, shared by its workers",
+		}[*connStrategy])
+	default:
+		fail("-conn-strategy must be pool, per-query or single")
+	}
+
+	if params.MaxClientConns > 0 {
+		if params.CycleQueries <= 0 || params.MaxClientMem <= 0 {
+			fail("-cycle-queries and -max-client-mem must be positive")
+		}
+		if params.RampTenants > 0 || params.TenantSkew > 0 {
+			fail("-max-client-conns cannot be combined with -ramp-tenants or -tenant-skew")
+		}
+	}
+
+	if params.RampTenants > 0 {
+		if params.Duration <= 0 || params.RampEvery <= 0 {
+			fail("-ramp-tenants needs -duration and a positive -ramp-every")
+		}
+		if params.TenantSkew > 0 {
+			fail("-ramp-tenants and -tenant-skew cannot be combined")
+		}
+	}
+
+	if params.SeedRowsMax != 0 && params.SeedRowsMax <= params.SeedRows {
+		fail("-seed-rows-max must be above -seed-rows")
+	}
+
+	if params.TenantSkew != 0 && params.TenantSkew <= 1 {
+		fail("-tenant-skew must be 0 (even) or greater than 1")
+	}
+
+	if *testType == "churn" {
+		if params.ChurnRate <= 0 || params.ChurnQueries <= 0 {
+			fail("-churn-rate and -churn-queries must be positive")
+		}
+		if len(params.Tenants) == 1 {
+			fail("-test churn needs at least two -tenants")
+		}
+	}
+
+	if *testType == "quota" && (params.QuotaQPS <= 0 || params.BystanderQPS <= 0) {
+		fail("-test quota needs a positive -quota-qps and -bystander-qps")
+	}
+
+	if *testType == "replica" && params.LagProbes <= 0 {
+		fail("-lag-probes must be positive")
+	}
+
+	if *testType == "advisory" && params.LockKeys <= 0 {
+		fail("-lock-keys must be positive")
+	}
+
+	if *testType == "conflict" {
+		if params.Isolation != "serializable" && params.Isolation != "repeatable-read" {
+			fail("unknown isolation level: %s", params.Isolation)
+		}
+		if params.HotRows < 1 || params.HotRows > params.SeedRows {
+			fail("-hot-rows must be between 1 and -seed-rows")
+		}
+	}
+
+	if *gomaxprocs < 0 {
+		fail("-gomaxprocs cannot be negative")
+	}
+	if *gomaxprocs > 0 {
+		runtime.GOMAXPROCS(*gomaxprocs)
+	}
+	bench.PinWorkers = *pinWorkers
+	if *gomaxprocs > 0 || *pinWorkers {
+		bench.Infof("GOMAXPROCS: %d (of %d CPUs), workers pinned: %v", runtime.GOMAXPROCS(0), runtime.NumCPU(), *pinWorkers)
+	}
+
+	if *serverStats && !hasDirect {
+		fail("-server-stats requires -direct-* flags for the backend to read")
+	}
+
+	if *waitSample < 0 || (*waitSample > 0 && !hasDirect) {
+		fail("-wait-sample needs a positive interval and -direct-* flags for the backend to poll")
+	}
+	if *waitSample > 0 && *conn.dbType != "postgres" {
+		fail("-wait-sample is Postgres-only")
+	}
+
+	if *topSlow < 0 {
+		fail("-top-slow cannot be negative")
+	}
+	if *slowQueries < 0 || (*slowQueries > 0 && *conn.dbType != "postgres") {
+		fail("-slow-queries needs a positive count and is Postgres-only")
+	}
+	if *explainSlow && (*slowQueries == 0 || !hasDirect) {
+		fail("-explain needs -slow-queries and -direct-* flags for the backend to explain on")
+	}
+
+	agentList := tenantList(*agents)
+	if len(agentList) > 0 {
+		if !distributedTests[*testType] {
+			fail("-agents only supports -test throughput")
+		}
+		if params.Concurrency < len(agentList) {
+			fail("-concurrency must be at least the number of -agents")
+		}
+		if params.Runs > 1 {
+			fail("-agents cannot be combined with -runs")
+		}
+		if *agentToken == "" {
+			fail("-agents needs -agent-token (or $TDB_BENCH_TOKEN), the secret the agents were started with")
+		}
+		if proxyCfg.DSN != "" {
+			fail("-agents takes the proxy as -proxy-host/-proxy-port/-proxy-user/-proxy-db; agents read its password themselves, and a -proxy-dsn would carry it")
+		}
+		for i := range agentList {
+			_, agg := bench.Share(params.AggWorkers, len(agentList), i)
+			if _, conc := bench.Share(params.Concurrency, len(agentList), i); agg >= conc {
+				fail("-agg-workers leaves an agent without workload workers; raise -concurrency")
+			}
+		}
+	}
+	if *notifyOn != "finish" && *notifyOn != "violation" {
+		fail("unknown -notify-on: %s", *notifyOn)
+	}
+	if *maxP99 < 0 || *maxOverhead < 0 {
+		fail("-max-p99 and -max-overhead cannot be negative")
+	}
+	notify := func(s bench.Summary) {
+		if *notifyURL == "" || (*notifyOn == "violation" && s.Verdict == "passed") {
+			return
+		}
+		if err := bench.Notify(*notifyURL, s); err != nil {
+			bench.Warnf("Notify: %v", err)
+		} else {
+			bench.Okf("Summary posted to -notify-url")
+		}
+	}
+	if *percentiles != "" {
+		ps, err := percentileList(*percentiles)
+		if err != nil || len(ps) == 0 {
+			fail("invalid -percentiles %q", *percentiles)
+		}
+		slices.Sort(ps)
+		bench.Percentiles = slices.Compact(ps)
+	}
+	if *slo != "" {
+		bounds, err := durationList(*slo)
+		if err != nil || len(bounds) == 0 {
+			fail("invalid -slo %q", *slo)
+		}
+		slices.Sort(bounds)
+		bench.SLOBounds = slices.Compact(bounds)
+	}
+	if *sampleRate < 0 || *sampleRate > 1 {
+		fail("-sample-rate must be between 0 and 1")
+	}
+	if *sampleCap < 0 {
+		fail("-sample-cap cannot be negative")
+	}
+	if *trim < 0 || *trim >= 50 {
+		fail("-trim must be between 0 and 50 (percent dropped from each end)")
+	}
+	bench.TrimPct = *trim
+	if *trim > 0 && *sampleRate > 0 {
+		bench.Warnf("-sample-rate streams results into a histogram, so -trim is not applied and no trimmed stats are reported")
+	}
+	if *warmupWindow < 0 || *cooldownWindow < 0 {
+		fail("-warmup-window and -cooldown-window cannot be negative")
+	}
+	if (*warmupWindow > 0 || *cooldownWindow > 0) && *warmupWindow+*cooldownWindow >= *duration {
+		fail("-warmup-window and -cooldown-window need a -duration longer than both together")
+	}
+	if *window != bench.WindowExclude && *window != bench.WindowInclude {
+		fail("-window must be exclude or include")
+	}
+	bench.WindowPolicy = *window
+	if *testType == "overhead" && !hasDirect {
+		fail("overhead test requires -direct-* flags for comparison")
+	}
+	if *testType == "verify" && !hasDirect {
+		fail("verify test requires -direct-* flags to compare against")
+	}
+
+	if *dryRun && *compareModels {
+		printPlan(conn, *testType, dbProv, params)
+		return
+	}
+	if *dryRun {
+		printPlan(conn, *testType, *autoProv, params)
+		return
+	}
+
+	// Containers start only once every flag has been checked
+	var stack *localStack
+	if *local {
+		port := *localProxyPort
+		if port == 0 {
+			port = localBackends[*conn.dbType].port
+		}
+		var env []string
+		if *localProxyEnv != "" {
+			env = strings.Split(*localProxyEnv, ",")
+		}
+		stack = startLocal(conn, *localImage, *localProxyImage, port, env)
+		defer stack.stop()
+		strategy := proxyCfg.Strategy
+		proxyCfg, directCfg = conn.proxy(), conn.direct()
+		proxyCfg.Strategy, directCfg.Strategy = strategy, strategy
+	}
+
+	teardown := func() {}
+	switch *autoProv {
+	case "":
+	case "api":
+		if api == nil {
+			fail("-auto-provision api needs -api-url")
+		}
+		teardown = autoProvision(api, *testType, *keepTenants, &params, &proxyCfg)
+	case "sql":
+		var tenants interface {
+			bench.ControlPlane
+			Close()
+		}
+		var err error
+		switch *conn.dbType {
+		case "postgres", "cockroach":
+			tenants, err = pg.NewSQLTenants(proxyCfg)
+		case "mysql":
+			tenants, err = my.NewSQLTenants(proxyCfg)
+		case "mssql":
+			tenants, err = ms.NewSQLTenants(proxyCfg)
+		default:
+			fail("-auto-provision sql is not supported for %s", *conn.dbType)
+		}
+		if err != nil {
+			fail("-auto-provision sql: %v", err)
+		}
+		drop := autoProvision(tenants, *testType, *keepTenants, &params, &proxyCfg)
+		teardown = func() {
+			drop()
+			tenants.Close()
+		}
+	case "schema":
+		tenants, err := pg.NewSchemaTenants(proxyCfg)
+		if err != nil {
+			fail("-tenancy schema: %v", err)
+		}
+		drop := autoProvision(tenants, *testType, *keepTenants, &params, &proxyCfg)
+		teardown = func() {
+			drop()
+			tenants.Close()
+		}
+	case "rls":
+		tenants, err := pg.NewRLSTenants(proxyCfg, params)
+		if err != nil {
+			fail("-tenancy rls: %v", err)
+		}
+		params.TenantPassword = tenants.Password
+		drop := autoProvision(tenants, *testType, *keepTenants, &params, &proxyCfg)
+		teardown = func() {
+			drop()
+			tenants.Close()
+		}
+	default:
+		fail("unknown -auto-provision mode: %s", *autoProv)
+	}
+
+	// Drop provisioned tenants even if the run fails from here on
+	drop, dropped := teardown, false
+	teardown = func() {
+		if !dropped {
+			dropped = true
+			drop()
+		}
+	}
+	cleanups = append(cleanups, teardown)
+
+	if !*skipPreflight {
+		runPreflight(*conn.dbType, *testType, proxyCfg, directCfg, conn.hasDirect(), params)
+	}
+
+	if *pprofAddr != "" {
+		if err := bench.ServePprof(*pprofAddr); err != nil {
+			fail("-pprof-addr: %v", err)
+		}
+	}
+	stopProfiles := func() {}
+	if *profileDir != "" {
+		stop, err := bench.CaptureProfiles(*profileDir)
+		if err != nil {
+			fail("-profile-dir: %v", err)
+		}
+		stopProfiles = stop
+	}
+
+	stopServer := func() *bench.ServerStats { return nil }
+	if *serverStats {
+		var stop func() *bench.ServerStats
+		var err error
+		switch *conn.dbType {
+		case "postgres":
+			stop, err = pg.WatchServer(directCfg)
+		case "mysql":
+			stop, err = my.WatchServer(directCfg)
+		default:
+			err = fmt.Errorf("not supported for %s", *conn.dbType)
+		}
+		if err != nil {
+			fail("-server-stats: %v", err)
+		}
+		stopServer = stop
+		bench.StartTimeline()
+	}
+
+	stopWaits := func() *bench.WaitReport { return nil }
+	if *waitSample > 0 {
+		stop, err := pg.SampleWaits(directCfg, time.Duration(*waitSample)*time.Millisecond)
+		if err != nil {
+			fail("-wait-sample: %v", err)
+		}
+		stopWaits = stop
+	}
+
+	stopContainer := func() *bench.ContainerStats { return nil }
+	if *dockerContainer != "" {
+		host := *dockerHost
+		if host == "" {
+			host = os.Getenv("DOCKER_HOST")
+		}
+		if host == "" {
+			host = docker.DefaultHost
+		}
+		dc, err := docker.New(host, *dockerContainer)
+		if err != nil {
+			fail("-docker-host: %v", err)
+		}
+		stop, err := bench.MonitorContainer(*dockerContainer, dc)
+		if err != nil {
+			fail("-docker-container: %v", err)
+		}
+		stopContainer = stop
+	}
+
+	// Set last so the watchers' own connections are not traced
+	if *slowQueries > 0 {
+		bench.Slow = bench.NewSlowLog(*slowQueries)
+	}
+
+	endAnnotation := func(string) {}
+	if *grafanaURL != "" {
+		token := *grafanaToken
+		if token == "" {
+			token = os.Getenv("GRAFANA_TOKEN")
+		}
+		gc := grafana.New(*grafanaURL, token, *grafanaDashboard)
+		annTags := []string{"tdb-bench", *conn.dbType, *testType, "run:" + manifest.RunID}
+		for k, v := range tags {
+			annTags = append(annTags, k+":"+v)
+		}
+		slices.Sort(annTags[4:])
+		desc := fmt.Sprintf("tdb-bench %s %s/%s (%s)", manifest.RunID, *conn.dbType, *testType, planSummary(params))
+		id, err := gc.Start(context.Background(), time.Now(), annTags, desc)
+		if err != nil {
+			bench.Warnf("Grafana: %v", err)
+		} else {
+			bench.Okf("Grafana annotation started")
+			endAnnotation = func(outcome string) {
+				if err := gc.End(context.Background(), id, time.Now(), desc+"\n"+outcome); err != nil {
+					bench.Warnf("Grafana: %v", err)
+				}
+			}
+		}
+	}
+
+	bench.StartSlowest(*topSlow)
+	bench.StartWorkers()
+	bench.StartSamples(*sampleRate, *sampleCap)
+	if *charts != "" || *sampleRate > 0 {
+		bench.StartHistogram()
+	}
+	// The timeline keeps a point per query, which -sample-rate is meant to avoid
+	if *charts != "" && !*serverStats {
+		bench.StartTimeline()
+	}
+
+	// The first SIGINT/SIGTERM cancels the run so it stops promptly and still
+	// reports and tears down; a second one kills the process.
+	ctx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	stack.release()
+	if *maxRuntime > 0 {
+		var cancel context.CancelCauseFunc
+		ctx, cancel = context.WithCancelCause(ctx)
+		deadline := time.AfterFunc(time.Until(invoked.Add(time.Duration(*maxRuntime)*time.Second)), func() { cancel(errMaxRuntime) })
+		defer deadline.Stop()
+	}
+	go func() {
+		<-ctx.Done()
+		stopSignals()
+	}()
+
+	started := time.Now()
+	stopMonitor := bench.MonitorClient()
+	var res *bench.Result
+	switch {
+	case len(agentList) > 0:
+		res = runDistributed(ctx, agentList, *agentToken, *conn.dbType, *testType, proxyCfg, params)
+	case *compareModels:
+		res = runModels(ctx, *conn.dbType, proxyCfg, directCfg, params, api, dbProv, *keepTenants)
+	case *testType == "all" || *testType == "suite":
+		res = runSuite(ctx, *conn.dbType, proxyCfg, directCfg, conn.hasDirect(), params, api)
+	default:
+		res = runTest(ctx, *conn.dbType, *testType, proxyCfg, directCfg, params, api)
+	}
+
+	client := stopMonitor()
+	timedOut := context.Cause(ctx) == errMaxRuntime
+	interrupted := ctx.Err() != nil
+	stopSignals()
+	switch {
+	case timedOut:
+		fmt.Println()
+		bench.Warnf("-max-runtime of %ds reached: results cover the run up to it", *maxRuntime)
+	case interrupted:
+		fmt.Println()
+		bench.Warnf("Interrupted: results cover the run up to the signal")
+	}
+	stopProfiles()
+	server := stopServer()
+	container := stopContainer()
+	var events []bench.ServerEvent
+	if server != nil {
+		events = server.Events
+	}
+	timeline := bench.StopTimeline(events)
+	slowest := bench.StopSlowest()
+	workers := bench.StopWorkers()
+	hist := bench.StopHistogram()
+	samples, sampleGroups := bench.StopSamples()
+	var slow []bench.SlowQuery
+	if bench.Slow != nil {
+		slow = bench.Slow.Queries()
+		bench.Slow = nil
+		if *explainSlow {
+			pg.ExplainSlow(directCfg, slow)
+		}
+	}
+	waits := stopWaits()
+	teardown()
+	stack.stop()
+
+	if res == nil {
+		endAnnotation("❌ failed")
+		notify(bench.Summary{RunID: manifest.RunID, DB: *conn.dbType, Test: *testType, Verdict: "failed", Tags: tags})
+		fail("%s test did not complete", *testType)
+	}
+	if container != nil {
+		qps := 0.0
+		switch {
+		case res.Comparison != nil:
+			qps = res.Comparison.Proxy.QPS
+		case len(res.Stats) > 0:
+			qps = res.Stats[0].QPS
+		}
+		container.SetQPS(qps)
+		bench.PrintContainer(container)
+		res.Container = container
+	}
+	op := params.Workload
+	if op == "" {
+		op = "mixed"
+	}
+	bench.PrintSlowest(slowest, proxyCfg.Database, op)
+	res.Slowest = slowest
+	bench.PrintWorkers(workers)
+	res.Workers = workers
+	if *sampleRate > 0 {
+		fmt.Println()
+		bench.Okf("Sampled %d of %d results (-sample-rate %g)", len(samples), hist.N+hist.Errors, *sampleRate)
+		res.Samples, res.SampleGroups, res.Histogram = samples, sampleGroups, hist
+	}
+	bench.PrintSlow(slow)
+	res.SlowQueries = slow
+	bench.PrintTimeline(timeline)
+	res.Timeline = timeline
+	if server != nil {
+		bench.PrintServer(server)
+		res.Server = server
+	}
+	if waits != nil {
+		bench.PrintWaits(waits)
+		res.Waits = waits
+	}
+	bench.PrintClient(client)
+	res.Client = &client
+	res.DB = *conn.dbType
+	res.Test = *testType
+	res.Started = started
+	res.Tags = tags
+	manifest.ProxyVersion = res.Manifest.ProxyVersion
+	manifest.BackendVersion = res.Manifest.BackendVersion
+	res.Manifest = manifest
+
+	if *charts != "" {
+		files, err := chart.Write(*charts, res, hist)
+		if err != nil {
+			bench.Warnf("Charts: %v", err)
+		} else if len(files) > 0 {
+			fmt.Println()
+			bench.Okf("Charts written to %s", strings.Join(files, ", "))
+		}
+	}
+
+	summary := bench.Summarize(res, time.Duration(*maxP99)*time.Millisecond, *maxOverhead)
+	for _, v := range summary.Violations {
+		bench.Failf("Threshold violated: %s", v)
+	}
+	endAnnotation(summary.Text())
+	notify(summary)
+
+	if *jsonPath != "" {
+		if err := bench.WriteJSON(*jsonPath, res); err != nil {
+			bench.Warnf("JSON: %v", err)
+		} else {
+			fmt.Println()
+			bench.Okf("Results written to %s", *jsonPath)
+		}
+	}
+
+	// Broken -max-* thresholds fail the run as a baseline miss does
+	passed := len(summary.Violations) == 0
+	if *baselineDir != "" {
+		bench.Stepf("Baseline check")
+		passed = checkBaseline(bench.BaselinePath(*baselineDir, res.DB, res.Test), res, tol.tolerances()) && passed
+	}
+
+	if *historyPath != "" {
+		runs := []*bench.Result{res}
+		if len(res.Suite) > 0 {
+			runs = res.Suite
+		}
+		for _, r := range runs {
+			r.Tags = tags
+			r.Manifest.RunID = manifest.RunID
+		}
+		if err := appendHistory(*historyPath, runs); err != nil {
+			bench.Warnf("History: %v", err)
+		} else {
+			fmt.Println()
+			bench.Okf("Results appended to %s", *historyPath)
+		}
+	}
+	if timedOut || !passed {
+		closeOutput()
+		os.Exit(1)
+	}
+}
+
+// appendHistory adds runs to the history file at path.
+func appendHistory(path string, runs []*bench.Result) error {
+	store, err := history.Open(path)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	for _, r := range runs {
+		if err := store.Append(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
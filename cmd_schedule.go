@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"tenantsdb-bench/bench"
+	"tenantsdb-bench/history"
+)
+
+// runSchedule implements `tdb-bench schedule`: run the same benchmark on a
+// cron schedule, append every run to the history store and alert when the
+// trend check finds a regression.
+func runSchedule(args []string) {
+	cmd := newFlagSet("schedule", "-cron <spec> [flags] -- <run flags>")
+	spec := cmd.String("cron", "@daily", "When to run: five cron fields (minute hour day month weekday) or @hourly/@daily/@weekly/@monthly")
+	historyPath := cmd.String("history", "results.db", "SQLite history file every run is appended to")
+	last := cmd.Int("last", 10, "Number of most recent runs the trend check considers")
+	threshold := cmd.Float64("threshold", 10, "Regression threshold (% for p99, points for overhead)")
+	alertCmd := cmd.String("alert-cmd", "", "Shell command run on a regression, with the alert on stdin and in $TDB_BENCH_ALERT")
+	now := cmd.Bool("now", false, "Also run once immediately at startup")
+	cmd.Parse(args)
+
+	cron, err := parseCron(*spec)
+	if err != nil {
+		fail("%v", err)
+	}
+	runArgs := cmd.Args()
+	for _, a := range runArgs {
+		if name := strings.TrimLeft(strings.SplitN(a, "=", 2)[0], "-"); name == "history" || name == "json" {
+			fail("-%s is set by schedule; pass it before --", name)
+		}
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		fail("%v", err)
+	}
+
+	bench.Infof("Scheduling `run %s` at %q, history in %s", strings.Join(runArgs, " "), *spec, *historyPath)
+	if *now {
+		scheduledRun(exe, runArgs, *historyPath, *last, *threshold, *alertCmd)
+	}
+	for {
+		at := cron.next(time.Now())
+		if at.IsZero() {
+			fail("cron %q never fires", *spec)
+		}
+		bench.Stepf("Next run at %s", at.Format("2006-01-02 15:04"))
+		time.Sleep(time.Until(at))
+		scheduledRun(exe, runArgs, *historyPath, *last, *threshold, *alertCmd)
+	}
+}
+
+// scheduledRun runs the benchmark once and checks every test it produced
+// for regressions. A failed run is alerted on too.
+func scheduledRun(exe string, runArgs []string, historyPath string, last int, threshold float64, alertCmd string) {
+	bench.Stepf("Scheduled run, %s", time.Now().Format("2006-01-02 15:04:05"))
+	dir, err := os.MkdirTemp("", "tdb-bench-schedule-")
+	if err != nil {
+		bench.Failf("%v", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	out := filepath.Join(dir, "result.json")
+
+	run := exec.Command(exe, append([]string{"run", "-history", historyPath, "-json", out}, runArgs...)...)
+	run.Stdout, run.Stderr = os.Stdout, os.Stderr
+	err = run.Run()
+	var res *bench.Result
+	if err == nil {
+		res, err = bench.ReadJSON(out)
+	}
+	if err != nil {
+		bench.Failf("Scheduled run failed: %v", err)
+		alert(alertCmd, fmt.Sprintf("tdb-bench: scheduled run failed: %v", err))
+		return
+	}
+
+	store, err := history.Open(historyPath)
+	if err != nil {
+		bench.Warnf("History: %v", err)
+		return
+	}
+	defer store.Close()
+
+	tests := []*bench.Result{res}
+	if len(res.Suite) > 0 {
+		tests = res.Suite
+	}
+	for _, r := range tests {
+		runs, err := store.Recent(r.DB, r.Test, last)
+		if err != nil {
+			bench.Warnf("History: %v", err)
+			continue
+		}
+		trends := history.Analyze(runs, threshold)
+		if !history.PrintTrend(runs, trends, threshold) {
+			continue
+		}
+		var lines []string
+		for _, t := range trends {
+			if t.Regressed {
+				latest, base, change := t.Format()
+				lines = append(lines, fmt.Sprintf("  %s: %s → %s (%s)", t.Metric, base, latest, change))
+			}
+		}
+		alert(alertCmd, fmt.Sprintf("tdb-bench: %s/%s regressed\n%s", r.DB, r.Test, strings.Join(lines, "\n")))
+	}
+}
+
+// alert prints msg and hands it to the -alert-cmd, if any.
+func alert(cmdline, msg string) {
+	bench.Failf("ALERT: %s", msg)
+	if cmdline == "" {
+		return
+	}
+	c := exec.Command("sh", "-c", cmdline)
+	c.Stdin = strings.NewReader(msg + "\n")
+	c.Env = append(os.Environ(), "TDB_BENCH_ALERT="+msg)
+	c.Stdout, c.Stderr = os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		bench.Warnf("Alert command: %v", err)
+	}
+}
@@ -0,0 +1,358 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"tenantsdb-bench/bench"
+)
+
+// apiRun is one run started through the HTTP API.
+type apiRun struct {
+	ID       string     `json:"id"`
+	Args     []string   `json:"args"`
+	State    string     `json:"state"` // running, passed, failed, stopped
+	ExitCode int        `json:"exit_code"`
+	Started  time.Time  `json:"started"`
+	Finished *time.Time `json:"finished,omitempty"`
+
+	cmd     *exec.Cmd
+	stopped bool
+	dir     string
+}
+
+type apiServer struct {
+	mu      sync.Mutex
+	runs    map[string]*apiRun
+	seq     int
+	dir     string
+	maxRuns int
+	exe     string
+}
+
+// runServe implements `tdb-bench serve`: an HTTP API that starts runs from
+// JSON flags, reports their state and hands back their results, for driving
+// the bench from a release pipeline.
+func runServe(args []string) {
+	cmd := newFlagSet("serve", "[-listen :7080] [flags]")
+	listen := cmd.String("listen", "127.0.0.1:7080", "Address to serve the API on; other than loopback needs -token")
+	dir := cmd.String("dir", "", "Directory for run logs and results (default: a new temp dir)")
+	token := cmd.String("token", os.Getenv("TDB_BENCH_TOKEN"), "Bearer token required on every request (default $TDB_BENCH_TOKEN; empty = none)")
+	maxRuns := cmd.Int("max-runs", 1, "Runs allowed at the same time")
+	cmd.Parse(args)
+
+	if *maxRuns < 1 {
+		fail("-max-runs must be at least 1")
+	}
+	if *token == "" && !isLoopback(*listen) {
+		fail("-listen %s is reachable from other hosts; set -token (or $TDB_BENCH_TOKEN) to serve on it", *listen)
+	}
+	if *dir == "" {
+		d, err := os.MkdirTemp("", "tdb-bench-serve-")
+		if err != nil {
+			fail("%v", err)
+		}
+		*dir = d
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		fail("%v", err)
+	}
+	s := &apiServer{runs: map[string]*apiRun{}, dir: *dir, maxRuns: *maxRuns, exe: exe}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /runs", s.start)
+	mux.HandleFunc("GET /runs", s.list)
+	mux.HandleFunc("GET /runs/{id}", s.status)
+	mux.HandleFunc("GET /runs/{id}/result", s.result)
+	mux.HandleFunc("GET /runs/{id}/log", s.log)
+	mux.HandleFunc("POST /runs/{id}/stop", s.stop)
+
+	var h http.Handler = mux
+	if *token != "" {
+		h = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer "+*token {
+				apiError(w, http.StatusUnauthorized, "missing or wrong bearer token")
+				return
+			}
+			mux.ServeHTTP(w, r)
+		})
+	}
+
+	bench.Infof("API listening on %s (runs in %s)", *listen, *dir)
+	srv := &http.Server{Addr: *listen, Handler: h, ReadHeaderTimeout: 10 * time.Second, ReadTimeout: time.Minute}
+	if err := srv.ListenAndServe(); err != nil {
+		fail("serve: %v", err)
+	}
+}
+
+// start takes {"flags": {"test": "throughput", "proxy-host": "...", ...}}
+// with the same flag names as `run`.
+func (s *apiServer) start(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Flags map[string]any `json:"flags"`
+	}
+	dec := json.NewDecoder(r.Body)
+	dec.UseNumber() // keep 1000000 from becoming 1e+06 on the command line
+	if err := dec.Decode(&req); err != nil {
+		apiError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if _, ok := req.Flags["json"]; ok {
+		apiError(w, http.StatusBadRequest, "json is set by the API; fetch /runs/{id}/result instead")
+		return
+	}
+	for name := range req.Flags {
+		if !apiFlags[name] {
+			apiError(w, http.StatusBadRequest, fmt.Sprintf("-%s can't be set through the API", name))
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	running := 0
+	for _, run := range s.runs {
+		if run.State == "running" {
+			running++
+		}
+	}
+	if running >= s.maxRuns {
+		apiError(w, http.StatusConflict, fmt.Sprintf("%d run(s) already in progress", running))
+		return
+	}
+
+	s.seq++
+	id := strconv.Itoa(s.seq)
+	run := &apiRun{ID: id, State: "running", Started: time.Now(), dir: filepath.Join(s.dir, id)}
+	if err := os.MkdirAll(run.dir, 0o755); err != nil {
+		apiError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	run.Args = append([]string{"run", "-json", filepath.Join(run.dir, "result.json")}, flagArgs(req.Flags)...)
+	logFile, err := os.Create(filepath.Join(run.dir, "output.log"))
+	if err != nil {
+		apiError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	run.cmd = exec.Command(s.exe, run.Args...)
+	run.cmd.Env = runEnv(req.Flags)
+	run.cmd.Stdout, run.cmd.Stderr = logFile, logFile
+	if err := run.cmd.Start(); err != nil {
+		logFile.Close()
+		apiError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.runs[id] = run
+	go s.wait(run, logFile)
+
+	writeJSON(w, http.StatusCreated, run)
+}
+
+func (s *apiServer) wait(run *apiRun, logFile *os.File) {
+	err := run.cmd.Wait()
+	logFile.Close()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	run.Finished = &now
+	var exit *exec.ExitError
+	switch {
+	case run.stopped:
+		run.State = "stopped"
+	case err == nil:
+		run.State = "passed"
+	default:
+		run.State = "failed"
+	}
+	if errors.As(err, &exit) {
+		run.ExitCode = exit.ExitCode()
+	} else if err != nil {
+		run.ExitCode = -1
+	}
+}
+
+func (s *apiServer) list(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	runs := make([]*apiRun, 0, len(s.runs))
+	for _, run := range s.runs {
+		runs = append(runs, run)
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].Started.Before(runs[j].Started) })
+	writeJSON(w, http.StatusOK, runs)
+}
+
+func (s *apiServer) status(w http.ResponseWriter, r *http.Request) {
+	if run := s.lookup(w, r); run != nil {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		writeJSON(w, http.StatusOK, run)
+	}
+}
+
+func (s *apiServer) result(w http.ResponseWriter, r *http.Request) {
+	run := s.lookup(w, r)
+	if run == nil {
+		return
+	}
+	s.mu.Lock()
+	state := run.State
+	s.mu.Unlock()
+	if state == "running" {
+		apiError(w, http.StatusConflict, "run is still in progress")
+		return
+	}
+	data, err := os.ReadFile(filepath.Join(run.dir, "result.json"))
+	if err != nil {
+		apiError(w, http.StatusNotFound, "run "+state+" without results; see /runs/"+run.ID+"/log")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func (s *apiServer) log(w http.ResponseWriter, r *http.Request) {
+	if run := s.lookup(w, r); run != nil {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		http.ServeFile(w, r, filepath.Join(run.dir, "output.log"))
+	}
+}
+
+// stop interrupts a run; the process gets 10s to exit before it is killed.
+func (s *apiServer) stop(w http.ResponseWriter, r *http.Request) {
+	run := s.lookup(w, r)
+	if run == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if run.State != "running" {
+		apiError(w, http.StatusConflict, "run already "+run.State)
+		return
+	}
+	run.stopped = true
+	run.cmd.Process.Signal(os.Interrupt)
+	proc := run.cmd.Process
+	time.AfterFunc(10*time.Second, func() { proc.Kill() })
+	writeJSON(w, http.StatusOK, run)
+}
+
+func (s *apiServer) lookup(w http.ResponseWriter, r *http.Request) *apiRun {
+	s.mu.Lock()
+	run := s.runs[r.PathValue("id")]
+	s.mu.Unlock()
+	if run == nil {
+		apiError(w, http.StatusNotFound, "no run "+r.PathValue("id"))
+	}
+	return run
+}
+
+// apiFlags are the `run` flags a request may set: the benchmark and its
+// endpoints. Flags that run commands, read or write local files, open
+// listeners or start containers stay with whoever starts the server.
+var apiFlags = map[string]bool{}
+
+func init() {
+	for _, name := range strings.Fields(`
+		db proxy-host proxy-port proxy-user proxy-pass proxy-db proxy-dsn
+		direct-host direct-port direct-user direct-pass direct-db direct-dsn
+		table schema table-suffix crdb-keys v q log-format
+		test workload docs as-of hot-pct hot-rows lock-keys check-integrity lag-probes
+		failover-at max-conns auth-users auth-connects quota-qps bystander-qps
+		idle-intervals soak-interval hibernate-after wake-cycles api-url api-token
+		provision-count auto-provision keep-tenants tenant-prefix conn-strategy
+		compare-models tenancy churn-rate churn-queries tenant-skew seed-rows-max
+		ramp-tenants ramp-every tenant-count max-client-conns cycle-queries
+		max-client-mem gomaxprocs pin-workers server-stats wait-sample slow-queries
+		explain top-slow skip-preflight dry-run max-p99 max-overhead max-runtime
+		warmup-window cooldown-window window percentiles slo trim sample-rate
+		sample-cap isolation agg-workers page-size row-bytes relational queries
+		concurrency warmup seed-rows reseed duration runs ingest-rows ingest-batch
+		notifications fetch-size cursor-fetches session-queries stream-rows
+		prepared-stmts in-params stream-iters tenants tags progress plain run-id`) {
+		apiFlags[name] = true
+	}
+}
+
+// endpointFlags point a run at hosts other than the ones the server was
+// set up for.
+var endpointFlags = []string{"proxy-host", "proxy-port", "proxy-dsn", "direct-host", "direct-port", "direct-dsn", "api-url"}
+
+// secretEnv are the credentials a run picks up from the environment, with
+// the TDB_AUTH_PASS_<LOGIN> passwords of -auth-users.
+var secretEnv = []string{"TDB_PROXY_PASS", "TDB_DIRECT_PASS", "TDB_API_TOKEN", "GRAFANA_TOKEN"}
+
+// runEnv is the environment of a run started with flags: the server's, less
+// its own token and, when flags name an endpoint, less every credential, so
+// a caller can't have them sent to a host of its choosing.
+func runEnv(flags map[string]any) []string {
+	drop := map[string]bool{"TDB_BENCH_TOKEN": true}
+	for _, name := range endpointFlags {
+		if _, ok := flags[name]; ok {
+			for _, key := range secretEnv {
+				drop[key] = true
+			}
+			break
+		}
+	}
+	var env []string
+	for _, kv := range os.Environ() {
+		key, _, _ := strings.Cut(kv, "=")
+		if !drop[key] && !(drop["TDB_PROXY_PASS"] && strings.HasPrefix(key, "TDB_AUTH_PASS_")) {
+			env = append(env, kv)
+		}
+	}
+	return env
+}
+
+// isLoopback reports whether listen only accepts connections from this host.
+func isLoopback(listen string) bool {
+	host, _, err := net.SplitHostPort(listen)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// flagArgs turns {"name": value} into -name=value arguments, sorted so the
+// command line is stable.
+func flagArgs(flags map[string]any) []string {
+	keys := make([]string, 0, len(flags))
+	for k := range flags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	args := make([]string, 0, len(keys))
+	for _, k := range keys {
+		args = append(args, fmt.Sprintf("-%s=%v", k, flags[k]))
+	}
+	return args
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+func apiError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, map[string]string{"error": msg})
+}
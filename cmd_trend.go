@@ -0,0 +1,40 @@
+package main
+
+import (
+	"os"
+
+	"tenantsdb-bench/bench"
+	"tenantsdb-bench/history"
+)
+
+// runTrend implements `tdb-bench trend`: it reads the history store and
+// reports whether proxy overhead or p99 regressed over the last N runs.
+func runTrend(args []string) {
+	cmd := newFlagSet("trend", "[flags]")
+	historyPath := cmd.String("history", "results.db", "SQLite history file")
+	dbType := cmd.String("db", "postgres", "Database type to analyze")
+	testType := cmd.String("test", "overhead", "Test type to analyze")
+	last := cmd.Int("last", 10, "Number of most recent runs to consider")
+	threshold := cmd.Float64("threshold", 10, "Regression threshold (% for p99, points for overhead)")
+	cmd.Parse(args)
+
+	store, err := history.OpenExisting(*historyPath)
+	if err != nil {
+		fail("%v", err)
+	}
+	defer store.Close()
+
+	runs, err := store.Recent(*dbType, *testType, *last)
+	if err != nil {
+		fail("%v", err)
+	}
+	if len(runs) == 0 {
+		bench.Failf("No %s/%s runs in %s", *dbType, *testType, *historyPath)
+		os.Exit(1)
+	}
+
+	trends := history.Analyze(runs, *threshold)
+	if history.PrintTrend(runs, trends, *threshold) {
+		os.Exit(1)
+	}
+}
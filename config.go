@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"tenantsdb-bench/bench"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configFile is the -config YAML layout:
+//
+//	default: staging
+//	profiles:
+//	  staging:
+//	    db: postgres
+//	    proxy:
+//	      host: 10.0.0.5
+//	      port: 5432
+//	      database: bench_pg__bench01
+//	    workload:
+//	      concurrency: 50
+//	    tenants: [bench_pg__bench01, bench_pg__bench02]
+//
+// Profile keys are flag names. Nested sections are joined with "-" when that
+// names a flag (proxy.host → -proxy-host); otherwise the section is only
+// grouping and the leaf key is the flag (workload.concurrency → -concurrency).
+// Settings a command has no flag for are ignored, so one profile can serve
+// every subcommand, but one a letter or two off a flag is warned about.
+// Unknown top-level keys are an error.
+type configFile struct {
+	Default  string                    `yaml:"default"`
+	Profiles map[string]map[string]any `yaml:"profiles"`
+	Matrix   any                       `yaml:"matrix"` // read by matrix
+}
+
+// parseFlags parses args and then fills every flag the user did not set on
+// the command line from the selected config profile, so flags always win.
+func parseFlags(fs *flag.FlagSet, args []string) {
+	fs.Parse(args)
+
+	cfgFlag := fs.Lookup("config")
+	if cfgFlag == nil || cfgFlag.Value.String() == "" {
+		return
+	}
+	profile := fs.Lookup("profile").Value.String()
+
+	settings, err := loadProfile(cfgFlag.Value.String(), profile)
+	if err != nil {
+		fail("%v", err)
+	}
+
+	explicit := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+
+	for _, s := range settings {
+		name := s.flagName(fs)
+		if name == "" {
+			if near := s.nearFlag(fs); near != "" {
+				bench.Warnf("config: %s is not a %s flag, ignored (did you mean %s?)", strings.Join(s.path, "."), fs.Name(), near)
+			}
+			continue
+		}
+		if explicit[name] {
+			continue
+		}
+		if err := fs.Set(name, s.value); err != nil {
+			fail("config: %s: %v", strings.Join(s.path, "."), err)
+		}
+	}
+}
+
+type setting struct {
+	path  []string
+	value string
+}
+
+// keyAliases lets section keys use the spelled-out names.
+var keyAliases = map[string]string{
+	"database": "db",
+	"password": "pass",
+}
+
+// flagName resolves a setting to a flag of fs ("" if none matches).
+func (s setting) flagName(fs *flag.FlagSet) string {
+	if alias, ok := keyAliases[s.path[len(s.path)-1]]; ok && len(s.path) > 1 {
+		path := append(append([]string(nil), s.path[:len(s.path)-1]...), alias)
+		if joined := strings.Join(path, "-"); fs.Lookup(joined) != nil {
+			return joined
+		}
+	}
+	if joined := strings.Join(s.path, "-"); fs.Lookup(joined) != nil {
+		return joined
+	}
+	if leaf := s.path[len(s.path)-1]; fs.Lookup(leaf) != nil {
+		return leaf
+	}
+	return ""
+}
+
+// nearFlag returns the flag of fs a setting naming none was likely meant
+// for: one edit away, or two for names longer than four letters.
+func (s setting) nearFlag(fs *flag.FlagSet) string {
+	keys := []string{s.path[len(s.path)-1]}
+	if len(s.path) > 1 {
+		keys = append(keys, strings.Join(s.path, "-"))
+	}
+	best, bestDist := "", 3
+	fs.VisitAll(func(f *flag.Flag) {
+		limit := 2
+		if len(f.Name) <= 4 {
+			limit = 1
+		}
+		for _, k := range keys {
+			if d := editDistance(k, f.Name); d <= limit && d < bestDist {
+				best, bestDist = f.Name, d
+			}
+		}
+	})
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func loadProfile(path, name string) ([]setting, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	var cf configFile
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cf); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("config %s: %w", path, err)
+	}
+
+	if name == "" {
+		name = cf.Default
+	}
+	if name == "" && len(cf.Profiles) == 1 {
+		for n := range cf.Profiles {
+			name = n
+		}
+	}
+	profile, ok := cf.Profiles[name]
+	if !ok {
+		var names []string
+		for n := range cf.Profiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("config %s: no profile %q (have: %s)", path, name, strings.Join(names, ", "))
+	}
+
+	var out []setting
+	flatten(nil, profile, &out)
+	return out, nil
+}
+
+func flatten(prefix []string, m map[string]any, out *[]setting) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		path := append(append([]string(nil), prefix...), k)
+		switch v := m[k].(type) {
+		case map[string]any:
+			flatten(path, v, out)
+		case []any:
+			parts := make([]string, len(v))
+			for i, item := range v {
+				parts[i] = fmt.Sprint(item)
+			}
+			*out = append(*out, setting{path, strings.Join(parts, ",")})
+		case nil:
+		default:
+			*out = append(*out, setting{path, fmt.Sprint(v)})
+		}
+	}
+}
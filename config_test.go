@@ -0,0 +1,131 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testConfig = `
+default: staging
+profiles:
+  staging:
+    db: postgres
+    proxy:
+      host: 10.0.0.5
+      port: 5432
+      database: bench_pg__bench01
+    workload:
+      concurrency: 50
+    tenants: [a, b]
+  local:
+    db: mysql
+`
+
+func writeConfig(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "bench.yaml")
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func testFlags() *flag.FlagSet {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	addConnFlags(fs)
+	fs.Int("concurrency", 10, "")
+	fs.String("tenants", "", "")
+	return fs
+}
+
+func TestParseFlagsProfile(t *testing.T) {
+	path := writeConfig(t, testConfig)
+	fs := testFlags()
+	parseFlags(fs, []string{"-config", path, "-proxy-port", "6432"})
+
+	want := map[string]string{
+		"db":          "postgres",
+		"proxy-host":  "10.0.0.5",
+		"proxy-port":  "6432", // the command line wins
+		"proxy-db":    "bench_pg__bench01",
+		"concurrency": "50",
+		"tenants":     "a,b",
+	}
+	for name, v := range want {
+		if got := fs.Lookup(name).Value.String(); got != v {
+			t.Errorf("-%s = %q, want %q", name, got, v)
+		}
+	}
+}
+
+func TestLoadProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		profile string
+		want    string // "db" setting, or the error's text
+		wantErr bool
+	}{
+		{name: "default", config: testConfig, want: "postgres"},
+		{name: "named", config: testConfig, profile: "local", want: "mysql"},
+		{name: "only profile", config: "profiles:\n  one:\n    db: mysql\n", want: "mysql"},
+		{name: "missing profile", config: testConfig, profile: "prod", want: `no profile "prod" (have: local, staging)`, wantErr: true},
+		{name: "unknown top-level key", config: "profile:\n  one:\n    db: mysql\n", want: "field profile not found", wantErr: true},
+		{name: "matrix section", config: testConfig + "matrix:\n  tests: [throughput]\n", want: "postgres"},
+	}
+	for _, tt := range tests {
+		settings, err := loadProfile(writeConfig(t, tt.config), tt.profile)
+		if tt.wantErr {
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("%s: err = %v, want one containing %q", tt.name, err, tt.want)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		got := ""
+		for _, s := range settings {
+			if strings.Join(s.path, ".") == "db" {
+				got = s.value
+			}
+		}
+		if got != tt.want {
+			t.Errorf("%s: db = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSettingFlagName(t *testing.T) {
+	fs := testFlags()
+	tests := []struct {
+		path     string
+		flagName string
+		near     string
+	}{
+		{path: "proxy.host", flagName: "proxy-host"},
+		{path: "proxy.database", flagName: "proxy-db"},
+		{path: "direct.password", flagName: "direct-pass"},
+		{path: "workload.concurrency", flagName: "concurrency"},
+		{path: "workload.concurency", near: "concurrency"},
+		{path: "proxy.hots", near: "proxy-host"},
+		{path: "queries"},
+		{path: "seed-rows"},
+	}
+	for _, tt := range tests {
+		s := setting{path: strings.Split(tt.path, ".")}
+		if got := s.flagName(fs); got != tt.flagName {
+			t.Errorf("%s: flagName = %q, want %q", tt.path, got, tt.flagName)
+		}
+		if tt.flagName != "" {
+			continue
+		}
+		if got := s.nearFlag(fs); got != tt.near {
+			t.Errorf("%s: nearFlag = %q, want %q", tt.path, got, tt.near)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+// Package control talks to the TenantsDB management API to create and drop
+// tenants, so provisioning can be benchmarked and bench tenants need not be
+// made by hand.
+//
+// The client expects a JSON REST API under the base URL:
+//
+//	POST   /tenants         {"name": "...", "db_type": "postgres"} → tenant
+//	GET    /tenants/{name}  → tenant
+//	DELETE /tenants/{name}
+//
+// where a tenant is {"name": "...", "database": "...", "status": "..."}.
+// Requests carry "Authorization: Bearer <token>".
+package control
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// PollInterval is how often WaitReady checks a tenant's status.
+const PollInterval = 500 * time.Millisecond
+
+// Tenant is a tenant as the API describes it. Database is the name to
+// connect to through the proxy.
+type Tenant struct {
+	Name     string `json:"name"`
+	Database string `json:"database"`
+	Status   string `json:"status"`
+}
+
+// APIError is a non-2xx response.
+type APIError struct {
+	Code int
+	Msg  string
+}
+
+func (e *APIError) Error() string { return e.Msg }
+
+// Client is a management API client for one project.
+type Client struct {
+	BaseURL string
+	Token   string
+	DBType  string
+	HTTP    *http.Client
+}
+
+func New(baseURL, token, dbType string) *Client {
+	return &Client{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Token:   token,
+		DBType:  dbType,
+		HTTP:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// CreateTenant asks for a new tenant and returns the database to connect to
+// once it is ready (the tenant name if the API does not say).
+func (c *Client) CreateTenant(ctx context.Context, name string) (string, error) {
+	var t Tenant
+	body := map[string]string{"name": name, "db_type": c.DBType}
+	if err := c.do(ctx, http.MethodPost, "/tenants", body, &t); err != nil {
+		return "", fmt.Errorf("create tenant %s: %w", name, err)
+	}
+	if t.Database == "" {
+		return name, nil
+	}
+	return t.Database, nil
+}
+
+// WaitReady polls the tenant until its status is ready or active, failing
+// on a failed/error status or when ctx ends.
+func (c *Client) WaitReady(ctx context.Context, name string) error {
+	for {
+		var t Tenant
+		if err := c.do(ctx, http.MethodGet, "/tenants/"+url.PathEscape(name), nil, &t); err != nil {
+			return fmt.Errorf("tenant %s status: %w", name, err)
+		}
+		switch strings.ToLower(t.Status) {
+		case "ready", "active", "running":
+			return nil
+		case "failed", "error":
+			return fmt.Errorf("tenant %s provisioning failed", name)
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("tenant %s still %q: %w", name, t.Status, ctx.Err())
+		case <-time.After(PollInterval):
+		}
+	}
+}
+
+// DeleteTenant drops the tenant. A tenant that is already gone is not an
+// error.
+func (c *Client) DeleteTenant(ctx context.Context, name string) error {
+	err := c.do(ctx, http.MethodDelete, "/tenants/"+url.PathEscape(name), nil, nil)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("delete tenant %s: %w", name, err)
+	}
+	return nil
+}
+
+// do sends one request and decodes a JSON response into out (if non-nil).
+func (c *Client) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &APIError{Code: resp.StatusCode, Msg: fmt.Sprintf("%s %s: %s %s", method, path, resp.Status, strings.TrimSpace(string(msg)))}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSpec is a five-field cron schedule: minute hour day-of-month month
+// day-of-week, each *, a number, a range a-b, a step */n or a-b/n, or a
+// comma-separated list of those.
+type cronSpec struct {
+	fields         [5]map[int]bool
+	domAny, dowAny bool
+}
+
+var cronBounds = [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 6}}
+
+var cronAliases = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+}
+
+func parseCron(s string) (*cronSpec, error) {
+	if alias, ok := cronAliases[s]; ok {
+		s = alias
+	}
+	parts := strings.Fields(s)
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("cron %q: want 5 fields (minute hour day month weekday)", s)
+	}
+	c := &cronSpec{domAny: parts[2] == "*", dowAny: parts[4] == "*"}
+	for i, p := range parts {
+		set, err := cronField(p, cronBounds[i][0], cronBounds[i][1])
+		if err != nil {
+			return nil, fmt.Errorf("cron %q: %w", s, err)
+		}
+		c.fields[i] = set
+	}
+	if c.fields[4][7] {
+		c.fields[4][0] = true // 7 is Sunday too
+	}
+	return c, nil
+}
+
+func cronField(s string, lo, hi int) (map[int]bool, error) {
+	set := map[int]bool{}
+	for _, part := range strings.Split(s, ",") {
+		rng, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("bad step in %q", part)
+			}
+			rng, step = part[:i], n
+		}
+		from, to := lo, hi
+		if rng != "*" {
+			a, b, isRange := strings.Cut(rng, "-")
+			var err error
+			if from, err = strconv.Atoi(a); err != nil {
+				return nil, fmt.Errorf("bad value %q", part)
+			}
+			to = from
+			if isRange {
+				if to, err = strconv.Atoi(b); err != nil {
+					return nil, fmt.Errorf("bad range %q", part)
+				}
+			} else if step > 1 {
+				to = hi
+			}
+		}
+		if hi == 6 && to == 7 {
+			hi = 7
+		}
+		if from < lo || to > hi || from > to {
+			return nil, fmt.Errorf("%q out of range %d-%d", part, lo, hi)
+		}
+		for v := from; v <= to; v += step {
+			set[v] = true
+		}
+	}
+	return set, nil
+}
+
+// next returns the first minute after t the schedule fires, or the zero
+// time when it fires on none of the next eight years' days (February 30).
+// It walks the calendar a day at a time and the day's hours and minutes in
+// wall-clock time, so a minute a DST change skips is not fired and one it
+// repeats fires once.
+func (c *cronSpec) next(t time.Time) time.Time {
+	after := t.Truncate(time.Minute)
+	loc := t.Location()
+	y, m, d := t.Date()
+	for i := 0; i < 8*366; i++ {
+		day := time.Date(y, m, d+i, 0, 0, 0, 0, loc)
+		if !c.fields[3][int(day.Month())] || !c.dayMatches(day) {
+			continue
+		}
+		for h := 0; h < 24; h++ {
+			if !c.fields[1][h] {
+				continue
+			}
+			for minute := 0; minute < 60; minute++ {
+				if !c.fields[0][minute] {
+					continue
+				}
+				at := time.Date(day.Year(), day.Month(), day.Day(), h, minute, 0, 0, loc)
+				if at.Hour() == h && at.Minute() == minute && at.After(after) {
+					return at
+				}
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches follows cron: when both day fields are restricted, either may match.
+func (c *cronSpec) dayMatches(t time.Time) bool {
+	dom, dow := c.fields[2][t.Day()], c.fields[4][int(t.Weekday())]
+	switch {
+	case c.domAny && c.dowAny:
+		return true
+	case c.domAny:
+		return dow
+	case c.dowAny:
+		return dom
+	}
+	return dom || dow
+}
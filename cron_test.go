@@ -0,0 +1,111 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		spec    string
+		field   int
+		want    []int
+		wantErr bool
+	}{
+		{spec: "*/15 * * * *", field: 0, want: []int{0, 15, 30, 45}},
+		{spec: "5-20/5 * * * *", field: 0, want: []int{5, 10, 15, 20}},
+		{spec: "10/20 * * * *", field: 0, want: []int{10, 30, 50}},
+		{spec: "0 1,3,5-6 * * *", field: 1, want: []int{1, 3, 5, 6}},
+		{spec: "0 0 * * 7", field: 4, want: []int{0, 7}},
+		{spec: "0 0 * * 5-7", field: 4, want: []int{0, 5, 6, 7}},
+		{spec: "@daily", field: 1, want: []int{0}},
+		{spec: "@monthly", field: 2, want: []int{1}},
+		{spec: "* * * *", wantErr: true},
+		{spec: "60 * * * *", wantErr: true},
+		{spec: "* 24 * * *", wantErr: true},
+		{spec: "* * 0 * *", wantErr: true},
+		{spec: "* * * 13 *", wantErr: true},
+		{spec: "* * * * 8", wantErr: true},
+		{spec: "*/0 * * * *", wantErr: true},
+		{spec: "5-1 * * * *", wantErr: true},
+		{spec: "a * * * *", wantErr: true},
+	}
+	for _, tt := range tests {
+		c, err := parseCron(tt.spec)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseCron(%q): want an error", tt.spec)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseCron(%q): %v", tt.spec, err)
+			continue
+		}
+		got := c.fields[tt.field]
+		if len(got) != len(tt.want) {
+			t.Errorf("parseCron(%q) field %d = %v, want %v", tt.spec, tt.field, got, tt.want)
+			continue
+		}
+		for _, v := range tt.want {
+			if !got[v] {
+				t.Errorf("parseCron(%q) field %d = %v, want %v", tt.spec, tt.field, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("no time zone data: %v", err)
+	}
+	utc := func(s string) time.Time {
+		at, err := time.Parse("2006-01-02 15:04", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return at
+	}
+	ny := func(s string) time.Time {
+		at, err := time.ParseInLocation("2006-01-02 15:04", s, newYork)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return at
+	}
+
+	tests := []struct {
+		name string
+		spec string
+		from time.Time
+		want time.Time
+	}{
+		{"next minute", "* * * * *", utc("2026-03-10 12:00").Add(30 * time.Second), utc("2026-03-10 12:01")},
+		{"strictly after", "0 * * * *", utc("2026-03-10 12:00"), utc("2026-03-10 13:00")},
+		{"step", "*/20 * * * *", utc("2026-03-10 12:21"), utc("2026-03-10 12:40")},
+		{"hour rolls the day", "30 2 * * *", utc("2026-03-10 12:00"), utc("2026-03-11 02:30")},
+		{"month", "0 0 1 * *", utc("2026-12-15 00:00"), utc("2027-01-01 00:00")},
+		{"day of month only", "0 0 13 * *", utc("2026-03-01 00:00"), utc("2026-03-13 00:00")},
+		{"day of week only", "0 0 * * 5", utc("2026-03-01 00:00"), utc("2026-03-06 00:00")},
+		{"day of month or week", "0 0 13 * 5", utc("2026-03-07 00:00"), utc("2026-03-13 00:00")},
+		{"week matches before month", "0 0 20 * 1", utc("2026-03-01 00:00"), utc("2026-03-02 00:00")},
+		{"sunday as 7", "0 0 * * 7", utc("2026-03-02 00:00"), utc("2026-03-08 00:00")},
+		{"february 29", "0 0 29 2 *", utc("2026-03-01 00:00"), utc("2028-02-29 00:00")},
+		{"february 29 on a weekday", "0 0 29 2 1", utc("2028-03-01 00:00"), utc("2029-02-05 00:00")},
+		{"never", "0 0 30 2 *", utc("2026-01-01 00:00"), time.Time{}},
+		{"DST gap skipped", "30 2 * * *", ny("2026-03-08 00:00"), ny("2026-03-09 02:30")},
+		{"DST overlap fires once", "30 1 * * *", ny("2026-11-01 01:45"), ny("2026-11-02 01:30")},
+		{"across DST", "0 12 * * *", ny("2026-03-07 13:00"), ny("2026-03-08 12:00")},
+	}
+	for _, tt := range tests {
+		c, err := parseCron(tt.spec)
+		if err != nil {
+			t.Fatalf("%s: parseCron(%q): %v", tt.name, tt.spec, err)
+		}
+		if got := c.next(tt.from); !got.Equal(tt.want) {
+			t.Errorf("%s: next(%q, %s) = %s, want %s", tt.name, tt.spec, tt.from, got, tt.want)
+		}
+	}
+}
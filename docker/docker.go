@@ -0,0 +1,116 @@
+// Package docker reads container resource usage from the Docker Engine API,
+// so the proxy's CPU and memory can be charted next to its throughput.
+//
+// Only GET /containers/{name}/stats is used, over the daemon's unix socket
+// or a tcp:// host as in DOCKER_HOST.
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"tenantsdb-bench/bench"
+)
+
+// DefaultHost is the daemon socket used when neither -docker-host nor
+// DOCKER_HOST is set.
+const DefaultHost = "unix:///var/run/docker.sock"
+
+// Client samples one container.
+type Client struct {
+	Container string
+	base      string
+	http      *http.Client
+}
+
+// New returns a client for container on the daemon at host (unix:// or
+// tcp://).
+func New(host, container string) (*Client, error) {
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("docker host %q: %w", host, err)
+	}
+	c := &Client{Container: container, http: &http.Client{Timeout: 10 * time.Second}}
+	switch u.Scheme {
+	case "unix":
+		c.base = "http://docker"
+		c.http.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", u.Path)
+			},
+		}
+	case "tcp", "http":
+		c.base = "http://" + u.Host
+	default:
+		return nil, fmt.Errorf("docker host %q: unsupported scheme %q", host, u.Scheme)
+	}
+	return c, nil
+}
+
+// stats is the part of the stats response the bench reads.
+type stats struct {
+	CPUStats struct {
+		CPUUsage struct {
+			TotalUsage uint64 `json:"total_usage"`
+		} `json:"cpu_usage"`
+		OnlineCPUs int `json:"online_cpus"`
+	} `json:"cpu_stats"`
+	MemoryStats struct {
+		Usage uint64            `json:"usage"`
+		Limit uint64            `json:"limit"`
+		Stats map[string]uint64 `json:"stats"`
+	} `json:"memory_stats"`
+	Networks map[string]struct {
+		RxBytes uint64 `json:"rx_bytes"`
+		TxBytes uint64 `json:"tx_bytes"`
+	} `json:"networks"`
+}
+
+// Sample reads the container's cumulative CPU time and network bytes and its
+// current memory use (page cache excluded, as `docker stats` shows it).
+func (c *Client) Sample(ctx context.Context) (bench.ContainerSample, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		c.base+"/containers/"+url.PathEscape(c.Container)+"/stats?stream=false&one-shot=true", nil)
+	if err != nil {
+		return bench.ContainerSample{}, err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return bench.ContainerSample{}, fmt.Errorf("docker stats %s: %w", c.Container, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return bench.ContainerSample{}, fmt.Errorf("docker stats %s: %s: %s", c.Container, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	var st stats
+	if err := json.NewDecoder(resp.Body).Decode(&st); err != nil {
+		return bench.ContainerSample{}, fmt.Errorf("docker stats %s: %w", c.Container, err)
+	}
+
+	s := bench.ContainerSample{
+		At:     time.Now(),
+		CPU:    time.Duration(st.CPUStats.CPUUsage.TotalUsage),
+		Cores:  st.CPUStats.OnlineCPUs,
+		Memory: st.MemoryStats.Usage,
+	}
+	// cgroup v2 reports inactive_file, v1 total_inactive_file
+	for _, k := range []string{"inactive_file", "total_inactive_file"} {
+		if v, ok := st.MemoryStats.Stats[k]; ok && v < s.Memory {
+			s.Memory -= v
+			break
+		}
+	}
+	for _, n := range st.Networks {
+		s.RxBytes += n.RxBytes
+		s.TxBytes += n.TxBytes
+	}
+	return s, nil
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"tenantsdb-bench/bench"
+	"tenantsdb-bench/ms"
+	"tenantsdb-bench/my"
+	"tenantsdb-bench/pg"
+)
+
+// printPlan prints what a run would do — endpoints, tenants, concurrency,
+// length, workload and seeding — without connecting to anything.
+func printPlan(conn *connFlags, test, autoProv string, params bench.BenchParams) {
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Printf("  Dry Run: %s test on %s\n", test, *conn.dbType)
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Printf("  Proxy:               %s\n", endpointDesc(conn.proxy()))
+	if test == "models" {
+		fmt.Printf("  Tenancy models:      %s (multi, then isolation, each)\n", strings.Join(tenancyModels(*conn.dbType), ", "))
+	}
+	if conn.hasDirect() {
+		fmt.Printf("  Direct:              %s\n", endpointDesc(conn.direct()))
+	}
+
+	tenants := params.Tenants
+	source := "-tenants"
+	n := tenantsNeeded(test)
+	switch {
+	case len(tenants) > 0:
+	case params.Tenancy == "schema":
+		for i := 1; i <= n; i++ {
+			tenants = append(tenants, fmt.Sprintf("%s%03d", params.TenantPrefix, i))
+		}
+		source = "schemas in " + conn.proxy().Database + ", created for the run, dropped after"
+	case params.Tenancy == "rls":
+		for i := 1; i <= n; i++ {
+			tenants = append(tenants, fmt.Sprintf("%s%03d", params.TenantPrefix, i))
+		}
+		source = "roles sharing " + params.TableName() + " in " + conn.proxy().Database + ", created for the run, dropped after"
+	case test == "models":
+		for i := 1; i <= n; i++ {
+			tenants = append(tenants, fmt.Sprintf("%s%03d", params.TenantPrefix, i))
+		}
+		source = "created for each model, dropped before the next"
+	case autoProv != "":
+		for i := 1; i <= n; i++ {
+			tenants = append(tenants, fmt.Sprintf("%s%03d", params.TenantPrefix, i))
+		}
+		source = "created by -auto-provision " + autoProv + ", dropped after"
+	case n > 1 && *conn.dbType == "mysql":
+		tenants, source = my.TenantNames(n), "built-in list"
+	case n > 1 && *conn.dbType == "mssql":
+		tenants, source = ms.TenantNames(n), "built-in list"
+	case n > 1:
+		tenants, source = pg.TenantNames(n), "built-in list"
+	}
+
+	if len(tenants) > 0 {
+		fmt.Printf("  Tenants:             %d (%s)\n", len(tenants), source)
+		fmt.Printf("                       %s\n", abbreviate(tenants))
+	} else {
+		fmt.Printf("  Tenant:              the proxy endpoint's database\n")
+	}
+
+	perTenant := len(tenants) > 1
+	switch {
+	case perTenant && params.MaxClientConns > 0:
+		fmt.Printf("  Client connections:  %d, cycling through tenants %d queries at a time\n", params.MaxClientConns, params.CycleQueries)
+	case perTenant:
+		conc := max(params.Concurrency/len(tenants), 1)
+		fmt.Printf("  Concurrency/tenant:  %d\n", conc)
+		fmt.Printf("  Total concurrency:   %d\n", conc*len(tenants))
+	default:
+		fmt.Printf("  Concurrency:         %d\n", params.Concurrency)
+	}
+	switch {
+	case params.Duration > 0:
+		fmt.Printf("  Duration:            %s\n", params.Duration)
+	case perTenant:
+		q := max(params.Queries/len(tenants), 10)
+		fmt.Printf("  Queries/tenant:      %d (%d total)\n", q, q*len(tenants))
+	default:
+		fmt.Printf("  Queries:             %d\n", params.Queries)
+	}
+	if params.RampTenants > 0 {
+		fmt.Printf("  Ramp:                %d tenants every %s\n", params.RampTenants, params.RampEvery)
+	}
+	if params.TenantSkew > 0 {
+		fmt.Printf("  Tenant skew:         Zipf s=%.2f\n", params.TenantSkew)
+	}
+	if test == "auth" {
+		logins := "the endpoint's user"
+		if len(params.AuthUsers) > 0 {
+			logins = strings.Join(params.AuthUsers, ", ")
+		}
+		fmt.Printf("  Auth:                %d connects each as %s\n", params.AuthConnects, logins)
+	}
+	fmt.Printf("  Warmup:              %d queries\n", params.Warmup)
+	if params.Runs > 1 {
+		fmt.Printf("  Runs:                %d (median reported)\n", params.Runs)
+	}
+	fmt.Printf("  Workload:            %s\n", params.WorkloadDesc())
+
+	seed := fmt.Sprintf("%d rows", params.SeedRows)
+	if params.SeedRowsMax > 0 && len(tenants) > 0 {
+		rows := slices.Clone(bench.TenantRows(len(tenants), params.SeedRows, params.SeedRowsMax))
+		slices.Sort(rows)
+		seed = fmt.Sprintf("%d–%d rows per tenant (median %d)", rows[0], rows[len(rows)-1], rows[len(rows)/2])
+	}
+	if params.Reseed {
+		seed += ", truncated and reseeded first"
+	} else {
+		seed += ", topped up if short"
+	}
+	fmt.Printf("  Seeding:             %s: %s\n", params.TableName(), seed)
+	fmt.Println("\n  Dry run: nothing was connected to.")
+}
+
+// planSummary is the one-line form of the plan, for annotations.
+func planSummary(params bench.BenchParams) string {
+	length := fmt.Sprintf("%d queries", params.Queries)
+	if params.Duration > 0 {
+		length = params.Duration.String()
+	}
+	workload := params.Workload
+	if workload == "" {
+		workload = "mixed"
+	}
+	return fmt.Sprintf("concurrency %d, %s, %s workload, %d seed rows", params.Concurrency, length, workload, params.SeedRows)
+}
+
+// endpointDesc describes an endpoint without its password.
+func endpointDesc(c bench.ConnConfig) string {
+	if c.DSN != "" {
+		return "DSN"
+	}
+	if c.Host == "" {
+		return "container started by -local"
+	}
+	return fmt.Sprintf("%s@%s:%d/%s", c.User, c.Host, c.Port, c.Database)
+}
+
+// abbreviate lists a few names from each end of a long list.
+func abbreviate(names []string) string {
+	if len(names) <= 6 {
+		return strings.Join(names, ", ")
+	}
+	return strings.Join(names[:3], ", ") + ", …, " + strings.Join(names[len(names)-2:], ", ")
+}
@@ -0,0 +1,347 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"tenantsdb-bench/bench"
+)
+
+// connFlags are the endpoint flags shared by every command that talks to a
+// database.
+type connFlags struct {
+	dbType *string
+
+	proxyHost *string
+	proxyPort *int
+	proxyUser *string
+	proxyPass *string
+	proxyDB   *string
+	proxyDSN  *string
+
+	directHost *string
+	directPort *int
+	directUser *string
+	directPass *string
+	directDB   *string
+	directDSN  *string
+
+	passwordFile  *string
+	filePasswords map[string]string // loaded lazily from passwordFile
+}
+
+func addConnFlags(fs *flag.FlagSet) *connFlags {
+	fs.String("config", "", "YAML config file with named profiles (flags override it)")
+	fs.String("profile", "", "Profile to use from -config (default: the file's default)")
+
+	return &connFlags{
+		dbType: fs.String("db", "postgres", "Database type: postgres, cockroach, mysql, mssql, mongodb, redis"),
+
+		proxyHost: fs.String("proxy-host", "", "Proxy host"),
+		proxyPort: fs.Int("proxy-port", 0, "Proxy port"),
+		proxyUser: fs.String("proxy-user", "", "Project ID"),
+		proxyPass: fs.String("proxy-pass", "", "Proxy password"),
+		proxyDB:   fs.String("proxy-db", "", "Database name"),
+		proxyDSN:  fs.String("proxy-dsn", "", "Full proxy connection string (postgres:// URL, MySQL DSN or sqlserver:// URL); replaces the other -proxy-* flags except -proxy-db"),
+
+		directHost: fs.String("direct-host", "", "Direct DB host"),
+		directPort: fs.Int("direct-port", 0, "Direct DB port"),
+		directUser: fs.String("direct-user", "", "Direct DB user"),
+		directPass: fs.String("direct-pass", "", "Direct DB password"),
+		directDB:   fs.String("direct-db", "", "Direct DB name"),
+		directDSN:  fs.String("direct-dsn", "", "Full direct connection string (postgres:// URL, MySQL DSN or sqlserver:// URL); replaces the other -direct-* flags except -direct-db"),
+
+		passwordFile: fs.String("password-file", "", "File with TDB_PROXY_PASS=/TDB_DIRECT_PASS= lines (a lone line is the proxy password)"),
+	}
+}
+
+func (c *connFlags) proxy() bench.ConnConfig {
+	return bench.ConnConfig{
+		Host:     *c.proxyHost,
+		Port:     *c.proxyPort,
+		User:     *c.proxyUser,
+		Password: c.password("proxy", *c.proxyPass),
+		Database: *c.proxyDB,
+		DSN:      *c.proxyDSN,
+	}
+}
+
+func (c *connFlags) direct() bench.ConnConfig {
+	return bench.ConnConfig{
+		Host:     *c.directHost,
+		Port:     *c.directPort,
+		User:     *c.directUser,
+		Password: c.password("direct", *c.directPass),
+		Database: *c.directDB,
+		DSN:      *c.directDSN,
+	}
+}
+
+// password resolves an endpoint's password so it need not appear on the
+// command line: the flag (or config profile) wins, then the TDB_<ROLE>_PASS
+// environment variable, then -password-file.
+func (c *connFlags) password(role, flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	key := "TDB_" + strings.ToUpper(role) + "_PASS"
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	if *c.passwordFile == "" {
+		return ""
+	}
+	if c.filePasswords == nil {
+		pw, err := readPasswordFile(*c.passwordFile)
+		if err != nil {
+			fail("%v", err)
+		}
+		c.filePasswords = pw
+	}
+	return c.filePasswords[key]
+}
+
+// authPassword resolves the password of an -auth-users login: the
+// TDB_AUTH_PASS_<LOGIN> environment variable (the login upper-cased, other
+// than letters and digits as _), then -password-file. ok is false without
+// one, so the endpoint's password applies.
+func (c *connFlags) authPassword(login string) (pass string, ok bool) {
+	key := "TDB_AUTH_PASS_" + strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' {
+			return r - 'a' + 'A'
+		}
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, login)
+	if v := os.Getenv(key); v != "" {
+		return v, true
+	}
+	if *c.passwordFile == "" {
+		return "", false
+	}
+	if c.filePasswords == nil {
+		pw, err := readPasswordFile(*c.passwordFile)
+		if err != nil {
+			fail("%v", err)
+		}
+		c.filePasswords = pw
+	}
+	pass, ok = c.filePasswords[key]
+	return pass, ok
+}
+
+// readPasswordFile parses KEY=value lines (blank lines and # comments
+// skipped). A file holding a single bare line is taken as the proxy password.
+func readPasswordFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("password file: %w", err)
+	}
+
+	out := map[string]string{}
+	var bare []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if k, v, ok := strings.Cut(line, "="); ok && strings.HasPrefix(k, "TDB_") {
+			out[strings.TrimSpace(k)] = strings.TrimSpace(v)
+			continue
+		}
+		bare = append(bare, line)
+	}
+	if len(bare) == 1 && len(out) == 0 {
+		out["TDB_PROXY_PASS"] = bare[0]
+	} else if len(bare) > 0 {
+		return nil, fmt.Errorf("password file %s: expected KEY=value lines", path)
+	}
+	return out, nil
+}
+
+// tenantList splits a -tenants value ("" = the built-in list).
+func tenantList(s string) []string {
+	var out []string
+	for _, t := range strings.Split(s, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// intList parses a comma-separated list of positive integers.
+func intList(s string) ([]int, error) {
+	var out []int
+	for _, f := range strings.Split(s, ",") {
+		if f = strings.TrimSpace(f); f == "" {
+			continue
+		}
+		n, err := strconv.Atoi(f)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid number %q", f)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+// percentileList parses -percentiles, e.g. 50,90,99,99.9,99.99.
+func percentileList(s string) ([]float64, error) {
+	var out []float64
+	for _, f := range strings.Split(s, ",") {
+		if f = strings.TrimSpace(f); f == "" {
+			continue
+		}
+		p, err := strconv.ParseFloat(f, 64)
+		if err != nil || p <= 0 || p > 100 {
+			return nil, fmt.Errorf("invalid percentile %q", f)
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// durationList parses a comma-separated list of Go durations such as 1ms,5ms.
+func durationList(s string) ([]time.Duration, error) {
+	var out []time.Duration
+	for _, f := range strings.Split(s, ",") {
+		if f = strings.TrimSpace(f); f == "" {
+			continue
+		}
+		d, err := time.ParseDuration(f)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid duration %q", f)
+		}
+		out = append(out, d)
+	}
+	return out, nil
+}
+
+func (c *connFlags) hasDirect() bool {
+	return *c.directHost != "" || *c.directDSN != ""
+}
+
+// requireProxy exits with the command's usage when no proxy endpoint is set.
+func (c *connFlags) requireProxy(fs *flag.FlagSet) {
+	if *c.proxyHost == "" && *c.proxyDSN == "" {
+		fmt.Println("Error: -proxy-host (or -proxy-dsn) is required")
+		fmt.Println()
+		fs.Usage()
+		os.Exit(1)
+	}
+}
+
+// tableFlags name the benchmark table so runs can coexist with tenant data
+// and with each other.
+type tableFlags struct {
+	table  *string
+	schema *string
+	suffix *string
+	keys   *string
+}
+
+func addTableFlags(fs *flag.FlagSet) *tableFlags {
+	return &tableFlags{
+		table:  fs.String("table", "accounts", "Benchmark table name"),
+		schema: fs.String("schema", "", "Schema (Postgres) or database (MySQL) qualifying -table"),
+		suffix: fs.String("table-suffix", "", "Append _<suffix> to -table; \"auto\" picks a unique per-run suffix"),
+		keys:   fs.String("crdb-keys", "serial", "Primary key of a table created on -db cockroach: serial (ids 1..N) or uuid (random, id a unique index)"),
+	}
+}
+
+// apply sets the table fields of params, resolving an "auto" suffix.
+func (t *tableFlags) apply(params *bench.BenchParams) {
+	params.Schema = *t.schema
+	params.Table = *t.table
+	if *t.keys != "serial" && *t.keys != "uuid" {
+		fail("-crdb-keys must be serial or uuid")
+	}
+	params.KeyType = *t.keys
+	switch *t.suffix {
+	case "":
+	case "auto":
+		params.Table += fmt.Sprintf("_%s_%d", time.Now().Format("20060102150405"), os.Getpid())
+	default:
+		params.Table += "_" + *t.suffix
+	}
+	if params.Table != "accounts" || params.Schema != "" {
+		name := params.Table
+		if params.Schema != "" {
+			name = params.Schema + "." + name
+		}
+		fmt.Printf("Table: %s\n", name)
+	}
+}
+
+// logFlags pick how much progress is logged and in what format. Result
+// tables are printed either way.
+type logFlags struct {
+	verbose, quiet *bool
+	format         *string
+}
+
+func addLogFlags(fs *flag.FlagSet) *logFlags {
+	return &logFlags{
+		verbose: fs.Bool("v", false, "Log debug detail as well as progress"),
+		quiet:   fs.Bool("q", false, "Log only warnings and errors"),
+		format:  fs.String("log-format", "text", "Progress log format: text (stdout) or json (stderr, one object per line)"),
+	}
+}
+
+// human reports whether progress is logged as text at info level or below,
+// the only case where a redrawn progress bar fits in.
+func (l *logFlags) human() bool {
+	return *l.format == "text" && !*l.quiet
+}
+
+// apply installs the logger the flags describe.
+func (l *logFlags) apply() {
+	if *l.verbose && *l.quiet {
+		fail("-v and -q cannot be combined")
+	}
+	level := slog.LevelInfo
+	switch {
+	case *l.verbose:
+		level = slog.LevelDebug
+	case *l.quiet:
+		level = slog.LevelWarn
+	}
+	switch *l.format {
+	case "text":
+		bench.SetLogger(slog.New(bench.NewHumanHandler(os.Stdout, level)))
+	case "json":
+		bench.SetLogger(slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: level})))
+	default:
+		fail("unknown -log-format %q (text or json)", *l.format)
+	}
+}
+
+// newFlagSet returns a flag set whose usage names the subcommand.
+func newFlagSet(name, synopsis string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Printf("Usage: tdb-bench %s %s\n\nFlags:\n", name, synopsis)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// cleanups run, last registered first, before fail exits, so -local
+// containers and provisioned tenants are not left behind.
+var cleanups []func()
+
+func fail(format string, args ...any) {
+	fmt.Printf("Error: "+format+"\n", args...)
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		cleanups[i]()
+	}
+	os.Exit(1)
+}
@@ -0,0 +1,88 @@
+// Package grafana posts benchmark windows as annotations through Grafana's
+// HTTP API, so they line up with the proxy's dashboards:
+//
+//	POST  /api/annotations       {"time": ms, "tags": [...], "text": "..."} → {"id": n}
+//	PATCH /api/annotations/{id}  {"timeEnd": ms, "text": "..."}
+//
+// Requests carry "Authorization: Bearer <service account token>".
+package grafana
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Client annotates one Grafana instance, optionally scoped to a dashboard.
+type Client struct {
+	BaseURL   string
+	Token     string
+	Dashboard string // dashboard UID; "" = an organization-wide annotation
+	HTTP      *http.Client
+}
+
+func New(baseURL, token, dashboard string) *Client {
+	return &Client{
+		BaseURL:   strings.TrimRight(baseURL, "/"),
+		Token:     token,
+		Dashboard: dashboard,
+		HTTP:      &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Start posts an annotation beginning at start and returns its ID for End.
+func (c *Client) Start(ctx context.Context, start time.Time, tags []string, text string) (int64, error) {
+	body := map[string]any{"time": start.UnixMilli(), "tags": tags, "text": text}
+	if c.Dashboard != "" {
+		body["dashboardUID"] = c.Dashboard
+	}
+	var out struct {
+		ID int64 `json:"id"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/api/annotations", body, &out); err != nil {
+		return 0, fmt.Errorf("start annotation: %w", err)
+	}
+	return out.ID, nil
+}
+
+// End turns the annotation into a region ending at end and replaces its text.
+func (c *Client) End(ctx context.Context, id int64, end time.Time, text string) error {
+	body := map[string]any{"timeEnd": end.UnixMilli(), "text": text}
+	if err := c.do(ctx, http.MethodPatch, fmt.Sprintf("/api/annotations/%d", id), body, nil); err != nil {
+		return fmt.Errorf("end annotation: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,193 @@
+// Package history keeps an append-only SQLite log of benchmark results so
+// regressions can be spotted across runs.
+package history
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"time"
+
+	"tenantsdb-bench/bench"
+
+	_ "modernc.org/sqlite"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id           INTEGER PRIMARY KEY AUTOINCREMENT,
+	at           TIMESTAMP NOT NULL,
+	db_type      TEXT NOT NULL,
+	test         TEXT NOT NULL,
+	tags         TEXT NOT NULL DEFAULT '',
+	run_uid      TEXT NOT NULL DEFAULT '',
+	overhead_pct REAL
+);
+CREATE TABLE IF NOT EXISTS stats (
+	run_id      INTEGER NOT NULL REFERENCES runs(id),
+	label       TEXT NOT NULL,
+	total       INTEGER NOT NULL,
+	errors      INTEGER NOT NULL,
+	duration_us INTEGER NOT NULL,
+	qps         REAL NOT NULL,
+	avg_us      INTEGER NOT NULL,
+	min_us      INTEGER NOT NULL,
+	max_us      INTEGER NOT NULL,
+	p50_us      INTEGER NOT NULL,
+	p75_us      INTEGER NOT NULL,
+	p90_us      INTEGER NOT NULL,
+	p95_us      INTEGER NOT NULL,
+	p99_us      INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS runs_db_test ON runs (db_type, test, at);
+`
+
+// Store is an open results history database.
+type Store struct {
+	db *sql.DB
+}
+
+// Run is one stored benchmark invocation.
+type Run struct {
+	ID          int64
+	At          time.Time
+	DB          string
+	Test        string
+	Tags        string
+	RunID       string   // the run's -run-id
+	OverheadPct *float64 // nil unless the run was an overhead test
+	Stats       []bench.BenchStats
+}
+
+// Open opens the history at path, creating it when missing. Times are
+// stored as SQLite's own "YYYY-MM-DD HH:MM:SS" text, which sorts by time.
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path+"?_time_format=sqlite")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("history schema: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// OpenExisting opens the history at path for a command that only reads
+// it, failing when there is none instead of creating an empty one.
+func OpenExisting(path string) (*Store, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("history: %w", err)
+	}
+	return Open(path)
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Append records a result and all of its stats tables.
+func (s *Store) Append(r *bench.Result) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var overhead any
+	if r.Comparison != nil {
+		overhead = r.Comparison.OverheadPct
+	}
+	res, err := tx.Exec(`INSERT INTO runs (at, db_type, test, tags, run_uid, overhead_pct) VALUES (?, ?, ?, ?, ?, ?)`,
+		r.Started.UTC(), r.DB, r.Test, bench.FormatTags(r.Tags), r.Manifest.RunID, overhead)
+	if err != nil {
+		return fmt.Errorf("history insert run: %w", err)
+	}
+	runID, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	for _, st := range r.Stats {
+		_, err := tx.Exec(`INSERT INTO stats (run_id, label, total, errors, duration_us, qps,
+				avg_us, min_us, max_us, p50_us, p75_us, p90_us, p95_us, p99_us)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			runID, st.Label, st.Total, st.Errors, st.Duration.Microseconds(), st.QPS,
+			st.LatencyAvg.Microseconds(), st.LatencyMin.Microseconds(), st.LatencyMax.Microseconds(),
+			st.LatencyP50.Microseconds(), st.LatencyP75.Microseconds(), st.LatencyP90.Microseconds(),
+			st.LatencyP95.Microseconds(), st.LatencyP99.Microseconds())
+		if err != nil {
+			return fmt.Errorf("history insert stats: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// Recent returns the last n runs of a db/test pair, oldest first.
+func (s *Store) Recent(dbType, test string, n int) ([]Run, error) {
+	rows, err := s.db.Query(`SELECT id, at, db_type, test, tags, run_uid, overhead_pct FROM runs
+		WHERE db_type = ? AND test = ? ORDER BY at DESC, id DESC LIMIT ?`, dbType, test, n)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []Run
+	for rows.Next() {
+		var r Run
+		var overhead sql.NullFloat64
+		if err := rows.Scan(&r.ID, &r.At, &r.DB, &r.Test, &r.Tags, &r.RunID, &overhead); err != nil {
+			return nil, err
+		}
+		if overhead.Valid {
+			v := overhead.Float64
+			r.OverheadPct = &v
+		}
+		runs = append(runs, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range runs {
+		if runs[i].Stats, err = s.stats(runs[i].ID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Oldest first reads naturally as a timeline
+	for i, j := 0, len(runs)-1; i < j; i, j = i+1, j-1 {
+		runs[i], runs[j] = runs[j], runs[i]
+	}
+	return runs, nil
+}
+
+func (s *Store) stats(runID int64) ([]bench.BenchStats, error) {
+	rows, err := s.db.Query(`SELECT label, total, errors, duration_us, qps,
+			avg_us, min_us, max_us, p50_us, p75_us, p90_us, p95_us, p99_us
+		FROM stats WHERE run_id = ? ORDER BY rowid`, runID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []bench.BenchStats
+	for rows.Next() {
+		var st bench.BenchStats
+		var dur, avg, min, max, p50, p75, p90, p95, p99 int64
+		if err := rows.Scan(&st.Label, &st.Total, &st.Errors, &dur, &st.QPS,
+			&avg, &min, &max, &p50, &p75, &p90, &p95, &p99); err != nil {
+			return nil, err
+		}
+		st.Duration = us(dur)
+		st.LatencyAvg, st.LatencyMin, st.LatencyMax = us(avg), us(min), us(max)
+		st.LatencyP50, st.LatencyP75, st.LatencyP90 = us(p50), us(p75), us(p90)
+		st.LatencyP95, st.LatencyP99 = us(p95), us(p99)
+		out = append(out, st)
+	}
+	return out, rows.Err()
+}
+
+func us(v int64) time.Duration {
+	return time.Duration(v) * time.Microsecond
+}
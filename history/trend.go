@@ -0,0 +1,129 @@
+package history
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"tenantsdb-bench/bench"
+)
+
+const overheadMetric = "Proxy overhead (p50)"
+
+// Trend compares the newest run's value of one metric against the median of
+// the runs before it.
+type Trend struct {
+	Metric    string
+	Latest    float64
+	Baseline  float64
+	Change    float64 // percent for latencies, percentage points for overhead
+	Regressed bool
+}
+
+// Analyze reports the proxy overhead and per-label p99 trends across runs
+// (oldest first). A p99 regresses when it is more than threshold percent above
+// the baseline; overhead regresses when it grows by more than threshold points.
+func Analyze(runs []Run, threshold float64) []Trend {
+	if len(runs) < 2 {
+		return nil
+	}
+	latest := runs[len(runs)-1]
+	prior := runs[:len(runs)-1]
+
+	var trends []Trend
+
+	if latest.OverheadPct != nil {
+		var base []float64
+		for _, r := range prior {
+			if r.OverheadPct != nil {
+				base = append(base, *r.OverheadPct)
+			}
+		}
+		if len(base) > 0 {
+			t := Trend{Metric: overheadMetric, Latest: *latest.OverheadPct, Baseline: median(base)}
+			t.Change = t.Latest - t.Baseline
+			t.Regressed = t.Change > threshold
+			trends = append(trends, t)
+		}
+	}
+
+	for _, st := range latest.Stats {
+		var base []float64
+		for _, r := range prior {
+			for _, p := range r.Stats {
+				if p.Label == st.Label {
+					base = append(base, float64(p.LatencyP99))
+				}
+			}
+		}
+		if len(base) == 0 {
+			continue
+		}
+		t := Trend{Metric: st.Label + " p99", Latest: float64(st.LatencyP99), Baseline: median(base)}
+		if t.Baseline > 0 {
+			t.Change = (t.Latest - t.Baseline) / t.Baseline * 100
+		}
+		t.Regressed = t.Change > threshold
+		trends = append(trends, t)
+	}
+	return trends
+}
+
+// PrintTrend prints the run timeline followed by the trend verdicts and
+// reports whether anything regressed.
+func PrintTrend(runs []Run, trends []Trend, threshold float64) bool {
+	fmt.Println()
+	fmt.Println("╔═════════════════════════════════════════════════════════════╗")
+	fmt.Printf("║  RESULTS HISTORY (last %d runs)%-30s║\n", len(runs), "")
+	fmt.Println("╠══════════════════════╦══════════╦══════════╦════════════════╣")
+	fmt.Println("║  When                ║   QPS    ║   p99    ║  Overhead      ║")
+	fmt.Println("╠══════════════════════╬══════════╬══════════╬════════════════╣")
+	for _, r := range runs {
+		var qps float64
+		var p99 time.Duration
+		if len(r.Stats) > 0 {
+			last := r.Stats[len(r.Stats)-1]
+			qps, p99 = last.QPS, last.LatencyP99
+		}
+		overhead := "-"
+		if r.OverheadPct != nil {
+			overhead = fmt.Sprintf("%.1f%%", *r.OverheadPct)
+		}
+		fmt.Printf("║  %-19s ║ %8.1f ║ %8s ║  %-13s ║\n",
+			r.At.Local().Format("2006-01-02 15:04:05"), qps, bench.FmtDur(p99), overhead)
+	}
+	fmt.Println("╚══════════════════════╩══════════╩══════════╩════════════════╝")
+
+	if len(trends) == 0 {
+		fmt.Println("  Not enough comparable runs for trend analysis (need at least 2)")
+		return false
+	}
+
+	regressed := false
+	fmt.Printf("\n── Trend (latest vs median of previous, threshold %.1f) ──\n", threshold)
+	for _, t := range trends {
+		latest, base, change := t.Format()
+		verdict := "✅ OK"
+		if t.Regressed {
+			verdict = "❌ REGRESSED"
+			regressed = true
+		}
+		fmt.Printf("  %-40s %10s → %-10s (%s)  %s\n", t.Metric, base, latest, change, verdict)
+	}
+	return regressed
+}
+
+// Format renders the latest value, the baseline and the change in the
+// metric's own units.
+func (t Trend) Format() (latest, base, change string) {
+	if t.Metric == overheadMetric {
+		return fmt.Sprintf("%.1f%%", t.Latest), fmt.Sprintf("%.1f%%", t.Baseline), fmt.Sprintf("%+.1f pts", t.Change)
+	}
+	return bench.FmtDur(time.Duration(t.Latest)), bench.FmtDur(time.Duration(t.Baseline)), fmt.Sprintf("%+.1f%%", t.Change)
+}
+
+func median(vals []float64) float64 {
+	s := append([]float64(nil), vals...)
+	sort.Float64s(s)
+	return s[len(s)/2]
+}
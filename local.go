@@ -0,0 +1,218 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"tenantsdb-bench/bench"
+	"tenantsdb-bench/my"
+	"tenantsdb-bench/pg"
+)
+
+// localBackends are the images -local starts per database type, with the
+// port they listen on and the environment that sets up a bench database.
+var localBackends = map[string]struct {
+	image string
+	port  int
+	user  string
+	env   []string
+}{
+	"postgres": {"postgres:16-alpine", 5432, "postgres", []string{"POSTGRES_PASSWORD=" + localPassword, "POSTGRES_DB=bench"}},
+	"mysql":    {"mysql:8.4", 3306, "root", []string{"MYSQL_ROOT_PASSWORD=" + localPassword, "MYSQL_DATABASE=bench"}},
+}
+
+const (
+	localPassword = "bench"
+	// localReadyTimeout covers image pulls and first-start initialization
+	localReadyTimeout = 3 * time.Minute
+)
+
+// localStack is the throwaway containers of a -local run.
+type localStack struct {
+	mu         sync.Mutex
+	network    string
+	containers []string
+	signals    chan os.Signal
+	released   chan struct{}
+}
+
+// startLocal starts a backend container (and the proxy image, if given) on
+// a private Docker network through the docker CLI, waits until they accept
+// connections and points conn at them: direct at the backend, proxy at the
+// proxy or, without one, at the backend too. The proxy container reaches
+// the backend as host "db" on its native port.
+func startLocal(conn *connFlags, image, proxyImage string, proxyPort int, proxyEnv []string) *localStack {
+	b, ok := localBackends[*conn.dbType]
+	if !ok {
+		fail("-local does not support %s", *conn.dbType)
+	}
+	if image != "" {
+		b.image = image
+	}
+	s := &localStack{}
+	cleanups = append(cleanups, s.stop)
+	s.watch()
+
+	bench.Stepf("Starting local %s (%s)...", *conn.dbType, b.image)
+	network := fmt.Sprintf("tdb-bench-%d", os.Getpid())
+	s.mu.Lock()
+	s.network = network
+	s.mu.Unlock()
+	if _, err := dockerCLI("network", "create", network); err != nil {
+		fail("-local: %v", err)
+	}
+	db := s.run("db", b.image, b.port, b.env)
+	backend := bench.ConnConfig{Host: "127.0.0.1", Port: db, User: b.user, Password: localPassword, Database: "bench"}
+	s.wait("backend", *conn.dbType, backend)
+
+	*conn.directHost, *conn.directPort = backend.Host, backend.Port
+	*conn.directUser, *conn.directPass, *conn.directDB = backend.User, backend.Password, backend.Database
+	*conn.directDSN = ""
+	proxy := backend
+	if proxyImage != "" {
+		bench.Stepf("Starting local proxy (%s)...", proxyImage)
+		proxy.Port = s.run("proxy", proxyImage, proxyPort, proxyEnv)
+		s.wait("proxy", *conn.dbType, proxy)
+	} else {
+		bench.Warnf("No -local-proxy-image: proxy and direct both point at the local backend")
+	}
+	*conn.proxyHost, *conn.proxyPort = proxy.Host, proxy.Port
+	*conn.proxyUser, *conn.proxyPass, *conn.proxyDB = proxy.User, proxy.Password, proxy.Database
+	*conn.proxyDSN = ""
+	return s
+}
+
+// run starts a detached container on the stack's network, publishes port
+// on a free loopback port and returns that port.
+func (s *localStack) run(alias, image string, port int, env []string) int {
+	name := s.network + "-" + alias
+	args := []string{"run", "-d", "--rm", "--name", name, "--network", s.network, "--network-alias", alias,
+		"-p", fmt.Sprintf("127.0.0.1::%d", port)}
+	for _, e := range env {
+		args = append(args, "-e", e)
+	}
+	// Registered first so an interrupt during docker run still removes it
+	s.mu.Lock()
+	s.containers = append(s.containers, name)
+	s.mu.Unlock()
+	if _, err := dockerCLI(append(args, image)...); err != nil {
+		fail("-local: %v", err)
+	}
+
+	out, err := dockerCLI("port", name, fmt.Sprintf("%d/tcp", port))
+	if err != nil {
+		fail("-local: %v", err)
+	}
+	// One line per address family, e.g. 127.0.0.1:49153
+	fields := strings.Fields(out)
+	if len(fields) == 0 {
+		fail("-local: docker port %s: no published port", name)
+	}
+	_, p, err := net.SplitHostPort(fields[0])
+	if err != nil {
+		fail("-local: docker port %s: %q", name, out)
+	}
+	hostPort, _ := strconv.Atoi(p)
+	bench.Okf("%s on 127.0.0.1:%d", name, hostPort)
+	return hostPort
+}
+
+// wait polls until cfg accepts connections.
+func (s *localStack) wait(what, dbType string, cfg bench.ConnConfig) {
+	start := time.Now()
+	for {
+		err := ping(dbType, cfg)
+		if err == nil {
+			bench.Okf("%s ready in %s", what, time.Since(start).Round(time.Second))
+			return
+		}
+		if time.Since(start) > localReadyTimeout {
+			fail("-local: %s not ready after %s: %v", what, localReadyTimeout, err)
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+// watch removes the stack and exits on SIGINT or SIGTERM until release:
+// startup and readiness polling come before the run's own handler.
+func (s *localStack) watch() {
+	s.signals = make(chan os.Signal, 1)
+	s.released = make(chan struct{})
+	signal.Notify(s.signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-s.signals:
+			fail("-local: interrupted")
+		case <-s.released:
+		}
+	}()
+}
+
+// release hands signals over to the run's handler, which tears the stack
+// down through stop.
+func (s *localStack) release() {
+	if s == nil || s.released == nil {
+		return
+	}
+	signal.Stop(s.signals)
+	close(s.released)
+	s.released = nil
+}
+
+// stop removes the stack's containers and network. It is safe to call more
+// than once and from the signal watcher.
+func (s *localStack) stop() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.network == "" {
+		return
+	}
+	bench.Stepf("Removing local containers...")
+	for _, c := range s.containers {
+		if _, err := dockerCLI("rm", "-f", c); err != nil {
+			bench.Warnf("%v", err)
+		}
+	}
+	if _, err := dockerCLI("network", "rm", s.network); err != nil {
+		bench.Warnf("%v", err)
+	}
+	s.containers, s.network = nil, ""
+}
+
+func ping(dbType string, cfg bench.ConnConfig) error {
+	switch dbType {
+	case "postgres":
+		pool, err := pg.Connect(cfg, "disable")
+		if err != nil {
+			return err
+		}
+		pool.Close()
+	case "mysql":
+		db, err := my.Connect(cfg)
+		if err != nil {
+			return err
+		}
+		db.Close()
+	}
+	return nil
+}
+
+// dockerCLI runs the docker CLI and returns its trimmed output.
+func dockerCLI(args ...string) (string, error) {
+	out, err := exec.Command("docker", args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("docker %s: %v: %s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+
+	"tenantsdb-bench/bench"
+	"tenantsdb-bench/control"
+	"tenantsdb-bench/pg"
+)
+
+// tenancyModels are the layouts -compare-models runs on dbType, in order;
+// the first is the baseline of the overhead column.
+func tenancyModels(dbType string) []string {
+	if dbType == "cockroach" {
+		return []string{"db", "schema"}
+	}
+	return []string{"db", "schema", "rls"}
+}
+
+// runModels runs multi and isolation once per tenancy model, provisioning
+// each model's tenants the way -tenancy would and dropping them before the
+// next model starts. The database-per-tenant model uses -tenants as given,
+// or creates its databases through dbProv (api or sql).
+func runModels(ctx context.Context, dbType string, proxyCfg, directCfg bench.ConnConfig, params bench.BenchParams, api *control.Client, dbProv string, keep bool) *bench.Result {
+	res := &bench.Result{}
+	models := tenancyModels(dbType)
+	for i, model := range models {
+		if ctx.Err() != nil {
+			break
+		}
+		bench.Stepf("Tenancy model %d/%d: %s", i+1, len(models), model)
+		p, cfg := params, proxyCfg
+		p.Tenancy = model
+
+		var cp bench.ControlPlane
+		closeCP := func() {}
+		switch model {
+		case "db":
+			switch {
+			case dbProv == "api":
+				cp = api
+			case dbProv == "sql":
+				tenants, err := pg.NewSQLTenants(cfg)
+				if err != nil {
+					fail("-compare-models db: %v", err)
+				}
+				cp, closeCP = tenants, tenants.Close
+			}
+		case "schema":
+			tenants, err := pg.NewSchemaTenants(cfg)
+			if err != nil {
+				fail("-compare-models schema: %v", err)
+			}
+			cp, closeCP = tenants, tenants.Close
+		case "rls":
+			tenants, err := pg.NewRLSTenants(cfg, p)
+			if err != nil {
+				fail("-compare-models rls: %v", err)
+			}
+			cp, closeCP = tenants, tenants.Close
+			p.TenantPassword = tenants.Password
+		}
+		drop := func() {}
+		if cp != nil {
+			if model != "db" {
+				// Each model names its tenants <prefix>001..; -tenants are databases
+				p.Tenants = nil
+			}
+			drop = autoProvision(cp, "models", keep, &p, &cfg)
+		}
+
+		report := bench.ModelReport{Model: model}
+		bench.StartTenantLatency()
+		multi := runTest(ctx, dbType, "multi", cfg, directCfg, p, api)
+		report.Fairness = bench.StopTenantLatency()
+		if multi != nil && len(multi.Stats) > 0 {
+			report.Multi = &multi.Stats[0]
+			res.Manifest.ProxyVersion = multi.Manifest.ProxyVersion
+		}
+		if ctx.Err() == nil {
+			if iso := runTest(ctx, dbType, "isolation", cfg, directCfg, p, api); iso != nil && len(iso.Stats) == 2 {
+				report.Alone, report.Noise = &iso.Stats[0], &iso.Stats[1]
+			}
+		}
+		drop()
+		closeCP()
+		res.Models = append(res.Models, report)
+	}
+	bench.PrintModels(res.Models)
+	return res
+}
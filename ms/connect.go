@@ -0,0 +1,216 @@
+package ms
+
+import (
+	"context"
+	"database/sql"
+	"math/rand"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"tenantsdb-bench/bench"
+
+	mssql "github.com/microsoft/go-mssqldb"
+	"github.com/microsoft/go-mssqldb/msdsn"
+)
+
+// Connect opens a pool to SQL Server. Without a DSN the connection is
+// unencrypted, like the Postgres runners' sslmode=disable.
+func Connect(c bench.ConnConfig) (*sql.DB, error) {
+	cfg, err := parseConfig(c)
+	if err != nil {
+		return nil, err
+	}
+
+	db := sql.OpenDB(mssql.NewConnectorConfig(cfg))
+	db.SetMaxOpenConns(10)
+	db.SetMaxIdleConns(5)
+	db.SetConnMaxLifetime(30 * time.Minute)
+	switch c.Strategy {
+	case "per-query":
+		// No idle connections: each query dials anew
+		db.SetMaxIdleConns(0)
+	case "single":
+		db.SetMaxOpenConns(1)
+		db.SetMaxIdleConns(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	bench.Debugf("Connected to %s:%d/%s", cfg.Host, cfg.Port, cfg.Database)
+	return db, nil
+}
+
+// parseConfig builds the driver config from c's DSN (any form go-mssqldb
+// accepts) or from its discrete fields.
+func parseConfig(c bench.ConnConfig) (msdsn.Config, error) {
+	dsn := c.DSN
+	if dsn == "" {
+		u := url.URL{
+			Scheme: "sqlserver",
+			User:   url.UserPassword(c.User, c.Password),
+			Host:   net.JoinHostPort(c.Host, strconv.Itoa(c.Port)),
+			RawQuery: url.Values{
+				"database":     {c.Database},
+				"encrypt":      {"disable"},
+				"dial timeout": {"30"},
+			}.Encode(),
+		}
+		dsn = u.String()
+	}
+	cfg, err := msdsn.Parse(dsn)
+	if err != nil {
+		return cfg, err
+	}
+	if c.DSN != "" {
+		// Tenant loops override the database; env/file passwords fill a gap
+		if c.Database != "" {
+			cfg.Database = c.Database
+		}
+		if cfg.Password == "" {
+			cfg.Password = c.Password
+		}
+	}
+	return cfg, nil
+}
+
+// ServerVersion returns the first line of SELECT @@VERSION for the
+// connection. Through the proxy this is whatever backend the proxy routed
+// the tenant to.
+func ServerVersion(db *sql.DB) (string, error) {
+	var v string
+	err := db.QueryRowContext(context.Background(), "SELECT @@VERSION").Scan(&v)
+	v, _, _ = strings.Cut(v, "\n")
+	return strings.TrimSpace(v), err
+}
+
+// detectVersion prints the server version and returns it ("" if unknown).
+func detectVersion(db *sql.DB) string {
+	v, err := ServerVersion(db)
+	if err != nil {
+		bench.Warnf("Version check failed: %v", err)
+		return ""
+	}
+	bench.Infof("Server version: %s", bench.ShortVersion(v))
+	return v
+}
+
+// RunQueries runs a fixed number of queries (count-based mode).
+func RunQueries(ctx context.Context, db *sql.DB, params bench.BenchParams, label string) bench.BenchStats {
+	maxID := params.SeedRows
+	q := newQueries(params)
+	op := oltpOp(params)
+
+	// Warmup
+	bench.Infof("Warming up (%d queries)...", params.Warmup)
+	for i := 0; i < params.Warmup; i++ {
+		id := rand.Intn(maxID) + 1
+		db.QueryRowContext(ctx, q.selectByID, id).Scan(new(int), new(string), new(float64))
+	}
+
+	// Benchmark
+	bench.Infof("Running %d queries (%d concurrent)...", params.Queries, params.Concurrency)
+
+	workers := params.Concurrency - params.AggWorkers
+	progress := bench.NewProgress(params.Queries)
+	start := time.Now()
+	sink := bench.NewSink(ctx, label, start, params)
+
+	// Aggregation workers run alongside until the counted queries are done
+	stopAgg := bench.Background(params.AggWorkers, func() bench.QueryResult {
+		return aggQuery(ctx, db, q, maxID)
+	})
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(workerID int) {
+			defer wg.Done()
+			bench.Pin(workerID)
+			_, count := bench.Share(params.Queries, workers, workerID)
+
+			out := sink.Worker(workerID, "")
+			for i := 0; i < count; i++ {
+				out.Add(op(ctx, db, q, maxID))
+				progress.Add(1)
+			}
+			out.Done()
+		}(w)
+	}
+	wg.Wait()
+	progress.Done()
+
+	totalDuration := time.Since(start)
+	sink.AddResults(stopAgg())
+	return sink.Stats(totalDuration)
+}
+
+// RunQueriesTimed runs queries for a fixed duration (time-based mode).
+// Returns results collected during the duration window.
+func RunQueriesTimed(ctx context.Context, db *sql.DB, params bench.BenchParams, label string) bench.BenchStats {
+	if params.Duration <= 0 {
+		return RunQueries(ctx, db, params, label)
+	}
+
+	maxID := params.SeedRows
+	q := newQueries(params)
+	op := oltpOp(params)
+
+	// Warmup
+	bench.Infof("Warming up (%d queries)...", params.Warmup)
+	for i := 0; i < params.Warmup; i++ {
+		id := rand.Intn(maxID) + 1
+		db.QueryRowContext(ctx, q.selectByID, id).Scan(new(int), new(string), new(float64))
+	}
+
+	bench.Infof("Running for %s (%d concurrent)...", params.Duration, params.Concurrency)
+
+	var stopped atomic.Bool
+
+	start := time.Now()
+	sink := bench.NewSink(ctx, label, start, params)
+	stopAgg := bench.Background(params.AggWorkers, func() bench.QueryResult {
+		return aggQuery(ctx, db, q, maxID)
+	})
+
+	// Stop signal after duration
+	time.AfterFunc(params.Duration, func() { stopped.Store(true) })
+
+	var wg sync.WaitGroup
+	for w := 0; w < params.Concurrency-params.AggWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			bench.Pin(w)
+			out := sink.Worker(w, "")
+			for !stopped.Load() && ctx.Err() == nil {
+				out.Add(op(ctx, db, q, maxID))
+			}
+			out.Done()
+		}()
+	}
+	wg.Wait()
+
+	sink.AddResults(stopAgg())
+	return sink.Stats(time.Since(start))
+}
+
+// PickRunner returns the right runner based on params.Duration, checking
+// data integrity around it when asked.
+func PickRunner(ctx context.Context, db *sql.DB, params bench.BenchParams, label string) bench.BenchStats {
+	return withIntegrity(ctx, []*sql.DB{db}, []string{params.TableName()}, params, func() bench.BenchStats {
+		if params.Duration > 0 {
+			return RunQueriesTimed(ctx, db, params, label)
+		}
+		return RunQueries(ctx, db, params, label)
+	})
+}
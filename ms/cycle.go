@@ -0,0 +1,141 @@
+package ms
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"tenantsdb-bench/bench"
+)
+
+// runScaleCycled is the high-scale form of the scale test: instead of a
+// pool per tenant the client holds at most params.MaxClientConns
+// connections, each worker visiting the tenants in turn for
+// params.CycleQueries queries, so a thousand tenants fit the client's file
+// descriptors and memory.
+func runScaleCycled(ctx context.Context, proxyCfg bench.ConnConfig, tenants []string, params bench.BenchParams, sizes []int) *bench.Result {
+	workers := bench.GuardFDs(min(params.MaxClientConns, params.Concurrency))
+	exceeded, stopGuard := bench.GuardMemory(params.MaxClientMem)
+	defer stopGuard()
+	fmt.Printf("  Cycling %d tenants over %d client connections, %d queries per visit\n\n",
+		len(tenants), workers, params.CycleQueries)
+
+	bench.Stepf("[1/3] Connecting through TenantsDB proxy...")
+	cfg := proxyCfg
+	cfg.Database = tenants[0]
+	db, err := Connect(cfg)
+	if err != nil {
+		bench.Failf("Proxy connection failed: %v", err)
+		return nil
+	}
+	res := &bench.Result{}
+	res.Manifest.ProxyVersion = detectVersion(db)
+	db.Close()
+
+	// visit opens a one-connection handle on tenant t
+	visit := func(t int) (*sql.DB, error) {
+		c := proxyCfg
+		c.Database = tenants[t]
+		d, err := Connect(c)
+		if err == nil {
+			d.SetMaxOpenConns(1)
+		}
+		return d, err
+	}
+
+	bench.Stepf("[2/3] Seeding %d tenants (%d at a time)...", len(tenants), workers)
+	live := make([]bool, len(tenants))
+	var seeded atomic.Int64
+	bench.RunWorkers(ctx, "Seeding", workers, func(worker int) []bench.QueryResult {
+		for t := worker; t < len(tenants); t += workers {
+			p, err := visit(t)
+			if err == nil {
+				tp := params
+				tp.SeedRows = bench.RowsFor(sizes, t, params.SeedRows)
+				err = PrepareData(ctx, p, tp)
+				p.Close()
+			}
+			if err != nil {
+				bench.Failf("%s: %v", tenants[t], err)
+				continue
+			}
+			live[t] = true
+			if n := seeded.Add(1); n%100 == 0 {
+				bench.Infof("Seeded: %d/%d", n, len(tenants))
+			}
+		}
+		return nil
+	})
+	if seeded.Load() == 0 {
+		bench.Failf("No tenant could be seeded")
+		return nil
+	}
+	bench.Okf("%d tenants seeded", seeded.Load())
+
+	bench.Stepf("[3/3] Running scale benchmark...")
+	q := newQueries(params)
+	op := workloadOp(params)
+	start := time.Now()
+	var deadline time.Time
+	if params.Duration > 0 {
+		deadline = start.Add(params.Duration)
+	}
+	var issued atomic.Int64
+	done := func() bool {
+		select {
+		case <-exceeded:
+			return true
+		default:
+		}
+		if !deadline.IsZero() {
+			return time.Now().After(deadline)
+		}
+		return issued.Load() >= int64(params.Queries)
+	}
+	// claim reserves a query of the count, so workers never run past it
+	claim := func() bool {
+		return !deadline.IsZero() || issued.Add(1) <= int64(params.Queries)
+	}
+
+	var mu sync.Mutex
+	byTenant := make([][]bench.QueryResult, len(tenants))
+	bench.RunWorkers(ctx, "Scale Test", workers, func(worker int) []bench.QueryResult {
+		for i := worker * len(tenants) / workers; !done(); i++ {
+			t := i % len(tenants)
+			if !live[t] {
+				continue
+			}
+			var local []bench.QueryResult
+			qStart := time.Now()
+			p, err := visit(t)
+			if err != nil {
+				local = append(local, bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err, Op: "connect"})
+			} else {
+				for n := 0; n < params.CycleQueries && !done() && claim(); n++ {
+					local = append(local, op(ctx, p, q, bench.RowsFor(sizes, t, params.SeedRows)))
+				}
+				p.Close()
+			}
+			mu.Lock()
+			byTenant[t] = append(byTenant[t], local...)
+			mu.Unlock()
+		}
+		return nil
+	})
+	elapsed := time.Since(start)
+	_, totalDuration := bench.Window(nil, start, params, elapsed)
+
+	tResults := make([]tenantStats, len(tenants))
+	for i, t := range tenants {
+		kept, _ := bench.Window(byTenant[i], start, params, elapsed)
+		tResults[i] = tenantStats{Name: t, Results: kept}
+		live[i] = live[i] && len(byTenant[i]) > 0
+	}
+	stats := computeScaleStats(tResults, live, tenants, totalDuration, workers, sizes)
+	bench.PrintStats(stats)
+	res.Stats = append(res.Stats, stats)
+	return res
+}
@@ -0,0 +1,138 @@
+package ms
+
+import (
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+
+	"tenantsdb-bench/bench"
+)
+
+// TDS values used by ProbeTLS.
+const (
+	tdsPrelogin         = 0x12 // packet type
+	tdsHeaderLen        = 8
+	preloginVersion     = 0x00 // option tokens
+	preloginEncrypt     = 0x01
+	preloginEnd         = 0xff
+	encryptNotSupported = 0x02
+)
+
+// Address returns the host and port cfg connects to, from its DSN if set.
+func Address(c bench.ConnConfig) (string, int, error) {
+	if c.DSN == "" {
+		return c.Host, c.Port, nil
+	}
+	cfg, err := parseConfig(c)
+	if err != nil {
+		return "", 0, err
+	}
+	return cfg.Host, int(cfg.Port), nil
+}
+
+// ProbeTLS sends a PRELOGIN offering encryption and, unless the server
+// answers that it has none, completes a TLS handshake tunnelled in TDS
+// packets without verifying the certificate.
+func ProbeTLS(c net.Conn, host string) (*tls.Conn, error) {
+	// VERSION and ENCRYPTION tokens, the terminator, then their data
+	payload := []byte{
+		preloginVersion, 0, 11, 0, 6,
+		preloginEncrypt, 0, 17, 0, 1,
+		preloginEnd,
+		0, 0, 0, 0, 0, 0, // client version
+		0, // ENCRYPT_OFF: encryption supported but not required
+	}
+	if err := writePacket(c, payload); err != nil {
+		return nil, err
+	}
+	reply, err := readPacket(c)
+	if err != nil {
+		return nil, err
+	}
+	encrypt, err := preloginOption(reply, preloginEncrypt)
+	if err != nil {
+		return nil, err
+	}
+	if encrypt == encryptNotSupported {
+		return nil, nil
+	}
+
+	tc := tls.Client(&tdsConn{Conn: c}, &tls.Config{ServerName: host, InsecureSkipVerify: true})
+	if err := tc.Handshake(); err != nil {
+		return nil, err
+	}
+	return tc, nil
+}
+
+// preloginOption returns the first byte of option opt in a PRELOGIN reply.
+func preloginOption(reply []byte, opt byte) (byte, error) {
+	for i := 0; i+5 <= len(reply) && reply[i] != preloginEnd; i += 5 {
+		if reply[i] != opt {
+			continue
+		}
+		off := int(binary.BigEndian.Uint16(reply[i+1:]))
+		if binary.BigEndian.Uint16(reply[i+3:]) == 0 || off >= len(reply) {
+			break
+		}
+		return reply[off], nil
+	}
+	return 0, fmt.Errorf("PRELOGIN reply has no option %d", opt)
+}
+
+// writePacket sends payload as one PRELOGIN packet.
+func writePacket(w io.Writer, payload []byte) error {
+	pkt := make([]byte, tdsHeaderLen+len(payload))
+	pkt[0], pkt[1] = tdsPrelogin, 0x01 // type, end of message
+	binary.BigEndian.PutUint16(pkt[2:], uint16(len(pkt)))
+	pkt[6] = 1 // packet id
+	copy(pkt[tdsHeaderLen:], payload)
+	_, err := w.Write(pkt)
+	return err
+}
+
+// readPacket reads one TDS packet's payload.
+func readPacket(r io.Reader) ([]byte, error) {
+	var hdr [tdsHeaderLen]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, err
+	}
+	n := int(binary.BigEndian.Uint16(hdr[2:]))
+	if n < tdsHeaderLen {
+		return nil, fmt.Errorf("bad TDS packet length %d", n)
+	}
+	payload := make([]byte, n-tdsHeaderLen)
+	_, err := io.ReadFull(r, payload)
+	return payload, err
+}
+
+// tdsConn carries the TLS handshake inside PRELOGIN packets, as SQL Server
+// expects until login.
+type tdsConn struct {
+	net.Conn
+	left int // payload bytes left in the packet being read
+}
+
+func (c *tdsConn) Write(b []byte) (int, error) {
+	if err := writePacket(c.Conn, b); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (c *tdsConn) Read(b []byte) (int, error) {
+	for c.left == 0 {
+		var hdr [tdsHeaderLen]byte
+		if _, err := io.ReadFull(c.Conn, hdr[:]); err != nil {
+			return 0, err
+		}
+		c.left = int(binary.BigEndian.Uint16(hdr[2:])) - tdsHeaderLen
+		if c.left < 0 {
+			return 0, fmt.Errorf("bad TDS packet length %d", c.left+tdsHeaderLen)
+		}
+	}
+	n, err := c.Conn.Read(b[:min(len(b), c.left)])
+	c.left -= n
+	return n, err
+}
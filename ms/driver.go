@@ -0,0 +1,41 @@
+package ms
+
+import (
+	"context"
+	"fmt"
+
+	"tenantsdb-bench/bench"
+)
+
+// Driver runs the SQL Server tests behind bench.NewRunner and the run
+// command: the standard suite of overhead, throughput, multi, isolation and
+// scale.
+type Driver struct{}
+
+func (Driver) Name() string { return "mssql" }
+
+// Run runs one test. A nil result with ErrIncomplete means the runner
+// stopped early and printed why.
+func (d Driver) Run(ctx context.Context, test string, proxyCfg, directCfg bench.ConnConfig, params bench.BenchParams) (*bench.Result, error) {
+	var res *bench.Result
+	switch test {
+	case "overhead":
+		res = RunOverhead(ctx, proxyCfg, directCfg, params)
+	case "throughput":
+		res = RunThroughput(ctx, proxyCfg, params)
+	case "multi":
+		res = RunMultiTenant(ctx, proxyCfg, params)
+	case "isolation":
+		res = RunIsolation(ctx, proxyCfg, params)
+	case "scale":
+		res = RunScale(ctx, proxyCfg, params)
+	case "notify", "cursor", "advisory":
+		return nil, fmt.Errorf("the %s test is Postgres-only: %w", test, bench.ErrUnknownTest)
+	default:
+		return nil, fmt.Errorf("the %s test is not implemented for SQL Server: %w", test, bench.ErrUnknownTest)
+	}
+	if res == nil {
+		return nil, bench.ErrIncomplete
+	}
+	return res, nil
+}
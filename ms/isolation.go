@@ -0,0 +1,147 @@
+package ms
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"tenantsdb-bench/bench"
+)
+
+func RunIsolation(ctx context.Context, proxyCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
+	victim := proxyCfg.Database
+	noisy := []string{
+		"bench_mssql__bench02", "bench_mssql__bench03", "bench_mssql__bench04",
+		"bench_mssql__bench05", "bench_mssql__bench06", "bench_mssql__bench07",
+		"bench_mssql__bench08", "bench_mssql__bench09", "bench_mssql__bench10",
+	}
+	if len(params.Tenants) > 0 {
+		noisy = nil
+		for _, t := range params.Tenants {
+			if t != victim {
+				noisy = append(noisy, t)
+			}
+		}
+	}
+
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Println("  SQL Server Noisy Neighbor Isolation Test")
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Printf("  Victim tenant: %s\n", victim)
+	fmt.Printf("  Noisy tenants: %d (each hammering with writes)\n\n", len(noisy))
+
+	// Connect victim
+	bench.Stepf("[1/3] Connecting victim tenant...")
+	victimCfg := proxyCfg
+	victimCfg.Database = victim
+	victimDB, err := Connect(victimCfg)
+	if err != nil {
+		bench.Failf("Failed: %v", err)
+		return nil
+	}
+	defer victimDB.Close()
+	proxyVersion := detectVersion(victimDB)
+	if err := PrepareData(ctx, victimDB, params); err != nil {
+		bench.Failf("Seed failed: %v", err)
+		return nil
+	}
+	bench.Okf("Victim ready")
+
+	// Connect noisy tenants
+	bench.Stepf("[2/3] Connecting noisy tenants...")
+	noisyDBs := make([]*sql.DB, len(noisy))
+	for i, t := range noisy {
+		cfg := proxyCfg
+		cfg.Database = t
+		db, err := Connect(cfg)
+		if err != nil {
+			bench.Failf("%s failed: %v", t, err)
+			return nil
+		}
+		defer db.Close()
+		noisyDBs[i] = db
+
+		if err := PrepareData(ctx, db, params); err != nil {
+			bench.Failf("Seed %s failed: %v", t, err)
+			return nil
+		}
+	}
+	bench.Okf("All noisy tenants ready")
+
+	bench.Stepf("[3/3] Running isolation test...")
+	maxID := params.SeedRows
+	q := newQueries(params)
+	victimConc := 5
+
+	victimParams := bench.BenchParams{
+		Queries:     params.Queries,
+		Concurrency: victimConc,
+		Warmup:      params.Warmup,
+		SeedRows:    params.SeedRows,
+		Duration:    params.Duration,
+	}
+
+	// ── Phase 1: Victim alone ──
+	fmt.Println("\n── Phase 1: Victim alone (no noise) ──")
+	var baselineStats bench.BenchStats
+	if params.Runs > 1 {
+		baselineStats = bench.RunMultiple(ctx, params.Runs, "Victim ALONE", func(run int) bench.BenchStats {
+			return PickRunner(ctx, victimDB, victimParams, "Victim ALONE")
+		})
+	} else {
+		baselineStats = PickRunner(ctx, victimDB, victimParams, "Victim ALONE")
+	}
+	bench.PrintStats(baselineStats)
+
+	// ── Phase 2: Victim under noise ──
+	fmt.Println("\n── Phase 2: Starting noisy neighbors ──")
+	bench.Infof("Launching %d noisy tenants (heavy writes)...", len(noisy))
+
+	stopNoise := make(chan struct{})
+	var noiseWg sync.WaitGroup
+
+	for _, db := range noisyDBs {
+		for w := 0; w < 5; w++ {
+			noiseWg.Add(1)
+			go func(d *sql.DB) {
+				defer noiseWg.Done()
+				for {
+					select {
+					case <-stopNoise:
+						return
+					default:
+						id := rand.Intn(maxID) + 1
+						delta := rand.Float64()*200 - 100
+						d.ExecContext(ctx, q.update, delta, id)
+					}
+				}
+			}(db)
+		}
+	}
+
+	bench.Sleep(ctx, 2*time.Second)
+	bench.Okf("Noise running (%d tenants × 5 concurrent = %d writers)", len(noisy), len(noisy)*5)
+
+	fmt.Println("\n── Measuring victim under noise ──")
+	var noiseStats bench.BenchStats
+	if params.Runs > 1 {
+		noiseStats = bench.RunMultiple(ctx, params.Runs, "Victim UNDER NOISE", func(run int) bench.BenchStats {
+			return PickRunner(ctx, victimDB, victimParams, "Victim UNDER NOISE")
+		})
+	} else {
+		noiseStats = PickRunner(ctx, victimDB, victimParams, "Victim UNDER NOISE")
+	}
+	bench.PrintStats(noiseStats)
+
+	close(stopNoise)
+	noiseWg.Wait()
+
+	bench.PrintIsolation(baselineStats, noiseStats)
+
+	res := &bench.Result{Stats: []bench.BenchStats{baselineStats, noiseStats}}
+	res.Manifest.ProxyVersion = proxyVersion
+	return res
+}
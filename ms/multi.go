@@ -0,0 +1,166 @@
+package ms
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"tenantsdb-bench/bench"
+)
+
+func RunMultiTenant(ctx context.Context, proxyCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
+	tenants := []string{
+		"bench_mssql__bench01", "bench_mssql__bench02", "bench_mssql__bench03",
+		"bench_mssql__bench04", "bench_mssql__bench05", "bench_mssql__bench06",
+		"bench_mssql__bench07", "bench_mssql__bench08", "bench_mssql__bench09",
+		"bench_mssql__bench10",
+	}
+	if len(params.Tenants) > 0 {
+		tenants = params.Tenants
+	}
+
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Println("  SQL Server Multi-Tenant Benchmark")
+	fmt.Println("═══════════════════════════════════════════")
+	if params.Duration > 0 {
+		fmt.Printf("  Tenants: %d | Duration: %s | Concurrency: %d\n\n",
+			len(tenants), params.Duration, params.Concurrency)
+	} else {
+		fmt.Printf("  Tenants: %d | Total queries: %d | Total concurrency: %d\n",
+			len(tenants), params.Queries, params.Concurrency)
+		fmt.Printf("  Per tenant: %s queries, %d concurrent\n\n",
+			bench.ShareDesc(params.Queries, len(tenants)), params.Concurrency/len(tenants))
+	}
+
+	pools := make([]*sql.DB, len(tenants))
+	var proxyVersion string
+	for i, t := range tenants {
+		cfg := proxyCfg
+		cfg.Database = t
+		bench.Infof("[%d/%d] Connecting to %s...", i+1, len(tenants), t)
+		db, err := Connect(cfg)
+		if err != nil {
+			bench.Failf("Failed: %v", err)
+			return nil
+		}
+		defer db.Close()
+		pools[i] = db
+		if i == 0 {
+			proxyVersion = detectVersion(db)
+		}
+
+		if err := PrepareData(ctx, db, params); err != nil {
+			bench.Failf("Seed failed: %v", err)
+			return nil
+		}
+	}
+	bench.Okf("All tenants connected and seeded")
+	fmt.Println()
+
+	fmt.Println("── Running multi-tenant benchmark ──")
+
+	runOnce := func(run int) bench.BenchStats {
+		return withIntegrity(ctx, pools, tenants, params, func() bench.BenchStats {
+			if params.TenantSkew > 0 {
+				return runSkewed(ctx, pools, tenants, params, nil)
+			}
+			if params.Duration > 0 {
+				return runMultiTimed(ctx, pools, tenants, params)
+			}
+			return runMultiCount(ctx, pools, tenants, params)
+		})
+	}
+
+	var stats bench.BenchStats
+	if params.Runs > 1 {
+		stats = bench.RunMultiple(ctx, params.Runs,
+			fmt.Sprintf("Multi-Tenant (%d tenants)", len(tenants)), runOnce)
+	} else {
+		stats = runOnce(0)
+	}
+	bench.PrintStats(stats)
+
+	res := &bench.Result{Stats: []bench.BenchStats{stats}}
+	res.Manifest.ProxyVersion = proxyVersion
+	return res
+}
+
+func runMultiCount(ctx context.Context, pools []*sql.DB, tenants []string, params bench.BenchParams) bench.BenchStats {
+	concPerTenant := params.Concurrency / len(tenants)
+	if concPerTenant < 1 {
+		concPerTenant = 1
+	}
+
+	maxID := params.SeedRows
+	q := newQueries(params)
+	op := workloadOp(params)
+	label := fmt.Sprintf("Multi-Tenant (%d tenants, %d concurrent)", len(tenants), params.Concurrency)
+
+	progress := bench.NewProgress(params.Queries)
+	start := time.Now()
+	sink := bench.NewSink(ctx, label, start, params)
+	var wg sync.WaitGroup
+
+	for t := 0; t < len(tenants); t++ {
+		db := pools[t]
+		_, tenantQueries := bench.Share(params.Queries, len(tenants), t)
+
+		for w := 0; w < concPerTenant; w++ {
+			wg.Add(1)
+			_, workerQueries := bench.Share(tenantQueries, concPerTenant, w)
+
+			go func(d *sql.DB, count int) {
+				defer wg.Done()
+
+				out := sink.Worker(t*concPerTenant+w, tenants[t])
+				for i := 0; i < count; i++ {
+					out.Add(op(ctx, d, q, maxID))
+					progress.Add(1)
+				}
+				out.Done()
+			}(db, workerQueries)
+		}
+	}
+	wg.Wait()
+	progress.Done()
+
+	return sink.Stats(time.Since(start))
+}
+
+func runMultiTimed(ctx context.Context, pools []*sql.DB, tenants []string, params bench.BenchParams) bench.BenchStats {
+	concPerTenant := params.Concurrency / len(tenants)
+	if concPerTenant < 1 {
+		concPerTenant = 1
+	}
+	maxID := params.SeedRows
+	q := newQueries(params)
+	op := workloadOp(params)
+
+	var stopped atomic.Bool
+
+	start := time.Now()
+	sink := bench.NewSink(ctx, fmt.Sprintf("Multi-Tenant (%d tenants, %d concurrent)", len(tenants), params.Concurrency), start, params)
+	time.AfterFunc(params.Duration, func() { stopped.Store(true) })
+
+	var wg sync.WaitGroup
+	for t := 0; t < len(tenants); t++ {
+		db := pools[t]
+		for w := 0; w < concPerTenant; w++ {
+			wg.Add(1)
+			go func(d *sql.DB) {
+				defer wg.Done()
+				out := sink.Worker(t*concPerTenant+w, tenants[t])
+				for !stopped.Load() && ctx.Err() == nil {
+					out.Add(op(ctx, d, q, maxID))
+				}
+				out.Done()
+			}(db)
+		}
+	}
+	wg.Wait()
+
+	return sink.Stats(time.Since(start))
+}
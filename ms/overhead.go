@@ -0,0 +1,128 @@
+package ms
+
+import (
+	"context"
+	"fmt"
+
+	"tenantsdb-bench/bench"
+)
+
+func RunOverhead(ctx context.Context, proxyCfg, directCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Println("  SQL Server Proxy Overhead Benchmark")
+	fmt.Println("═══════════════════════════════════════════")
+	if params.Duration > 0 {
+		fmt.Printf("  Duration: %s | Concurrency: %d | Workload: %s\n\n", params.Duration, params.Concurrency, params.WorkloadDesc())
+	} else {
+		fmt.Printf("  Queries: %d | Concurrency: %d | Workload: %s\n\n", params.Queries, params.Concurrency, params.WorkloadDesc())
+	}
+
+	// Connect direct
+	bench.Stepf("[1/4] Connecting directly to SQL Server...")
+	directDB, err := Connect(directCfg)
+	if err != nil {
+		bench.Failf("Direct connection failed: %v", err)
+		return nil
+	}
+	defer directDB.Close()
+	backendVersion := detectVersion(directDB)
+	bench.Okf("Connected")
+
+	// Seed data direct
+	bench.Stepf("[2/4] Seeding test data (direct)...")
+	if err := PrepareData(ctx, directDB, params); err != nil {
+		bench.Failf("Seed failed: %v", err)
+		return nil
+	}
+	bench.Okf("Data ready")
+
+	// Connect proxy
+	bench.Stepf("[3/4] Connecting through TenantsDB proxy...")
+	proxyDB, err := Connect(proxyCfg)
+	if err != nil {
+		bench.Failf("Proxy connection failed: %v", err)
+		return nil
+	}
+	defer proxyDB.Close()
+	proxyVersion := detectVersion(proxyDB)
+	bench.Okf("Connected")
+	bench.WarnVersionMismatch(backendVersion, proxyVersion)
+
+	// Run benchmarks
+	bench.Stepf("[4/4] Running benchmarks...")
+
+	var directStats, proxyStats bench.BenchStats
+	if params.Runs > 1 {
+		directStats = bench.RunMultiple(ctx, params.Runs, "Direct SQL Server", func(run int) bench.BenchStats {
+			return PickRunner(bench.Unobserved(ctx), directDB, params, "Direct SQL Server")
+		})
+		bench.PrintStats(directStats)
+
+		proxyStats = bench.RunMultiple(ctx, params.Runs, "Through TenantsDB Proxy", func(run int) bench.BenchStats {
+			return PickRunner(ctx, proxyDB, params, "Through TenantsDB Proxy")
+		})
+		bench.PrintStats(proxyStats)
+
+		bench.PrintComparison(proxyStats, directStats)
+	} else {
+		fmt.Println("\n── Direct SQL Server ──")
+		directStats = PickRunner(bench.Unobserved(ctx), directDB, params, "Direct SQL Server")
+		bench.PrintStats(directStats)
+
+		fmt.Println("\n── Through TenantsDB Proxy ──")
+		proxyStats = PickRunner(ctx, proxyDB, params, "Through TenantsDB Proxy")
+		bench.PrintStats(proxyStats)
+
+		bench.PrintComparison(proxyStats, directStats)
+	}
+
+	cmp := bench.Compare(proxyStats, directStats)
+	res := &bench.Result{Stats: []bench.BenchStats{directStats, proxyStats}, Comparison: &cmp}
+	res.Manifest.BackendVersion = backendVersion
+	res.Manifest.ProxyVersion = proxyVersion
+	return res
+}
+
+func RunThroughput(ctx context.Context, proxyCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Println("  SQL Server Throughput Benchmark")
+	fmt.Println("═══════════════════════════════════════════")
+	if params.Duration > 0 {
+		fmt.Printf("  Duration: %s | Concurrency: %d\n\n", params.Duration, params.Concurrency)
+	} else {
+		fmt.Printf("  Queries: %d | Concurrency: %d\n\n", params.Queries, params.Concurrency)
+	}
+
+	bench.Stepf("[1/3] Connecting through TenantsDB proxy...")
+	db, err := Connect(proxyCfg)
+	if err != nil {
+		bench.Failf("Connection failed: %v", err)
+		return nil
+	}
+	defer db.Close()
+	proxyVersion := detectVersion(db)
+	bench.Okf("Connected")
+
+	bench.Stepf("[2/3] Seeding test data...")
+	if err := PrepareData(ctx, db, params); err != nil {
+		bench.Failf("Seed failed: %v", err)
+		return nil
+	}
+	bench.Okf("Data ready")
+
+	bench.Stepf("[3/3] Running benchmark...")
+
+	var stats bench.BenchStats
+	if params.Runs > 1 {
+		stats = bench.RunMultiple(ctx, params.Runs, "SQL Server Throughput (via Proxy)", func(run int) bench.BenchStats {
+			return PickRunner(ctx, db, params, "SQL Server Throughput (via Proxy)")
+		})
+	} else {
+		stats = PickRunner(ctx, db, params, "SQL Server Throughput (via Proxy)")
+	}
+	bench.PrintStats(stats)
+
+	res := &bench.Result{Stats: []bench.BenchStats{stats}}
+	res.Manifest.ProxyVersion = proxyVersion
+	return res
+}
@@ -0,0 +1,451 @@
+package ms
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"tenantsdb-bench/bench"
+)
+
+// TenantList is the built-in bench01..bench100 tenant set used by the scale
+// test; multi and isolation use its first ten.
+func TenantList() []string {
+	return TenantNames(100)
+}
+
+// TenantNames is the built-in tenant naming extended to n tenants
+// (bench01..bench10, then bench011 onwards).
+func TenantNames(n int) []string {
+	var tenants []string
+	for i := 1; i <= min(n, 10); i++ {
+		tenants = append(tenants, fmt.Sprintf("bench_mssql__bench%02d", i))
+	}
+	for i := 11; i <= n; i++ {
+		tenants = append(tenants, fmt.Sprintf("bench_mssql__bench%03d", i))
+	}
+	return tenants
+}
+
+type tenantStats struct {
+	Name    string
+	Stats   bench.BenchStats
+	Results []bench.QueryResult
+}
+
+func RunScale(ctx context.Context, proxyCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
+	tenants := TenantList()
+	if len(params.Tenants) > 0 {
+		tenants = params.Tenants
+	}
+	concPerTenant := params.Concurrency / len(tenants)
+	if concPerTenant < 1 {
+		concPerTenant = 1
+	}
+	totalConc := concPerTenant * len(tenants)
+
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Printf("  SQL Server Scale Benchmark (%d Tenants)\n", len(tenants))
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Printf("  Tenants:             %d\n", len(tenants))
+	fmt.Printf("  Concurrency/tenant:  %d\n", concPerTenant)
+	fmt.Printf("  Total concurrency:   %d\n", totalConc)
+	if params.Duration > 0 {
+		fmt.Printf("  Duration:            %s\n", params.Duration)
+	} else {
+		total := max(params.Queries, scaleMinQueries*len(tenants))
+		fmt.Printf("  Queries/tenant:      %s\n", bench.ShareDesc(total, len(tenants)))
+		fmt.Printf("  Total queries:       %d\n", total)
+	}
+	var sizes []int
+	if params.SeedRowsMax > 0 {
+		sizes = bench.TenantRows(len(tenants), params.SeedRows, params.SeedRowsMax)
+		fmt.Printf("  Rows/tenant:         %d–%d (log-normal)\n", params.SeedRows, params.SeedRowsMax)
+	}
+	fmt.Printf("  Workload:            %s\n\n", params.WorkloadDesc())
+
+	if params.MaxClientConns > 0 {
+		return runScaleCycled(ctx, proxyCfg, tenants, params, sizes)
+	}
+
+	// ── Phase 1: Connect all tenants ──
+	bench.Stepf("[1/3] Connecting all tenants...")
+	dbs := make([]*sql.DB, len(tenants))
+	var connectFailed int
+	for i, t := range tenants {
+		cfg := proxyCfg
+		cfg.Database = t
+		db, err := Connect(cfg)
+		if err != nil {
+			bench.Failf("%s: %v", t, err)
+			connectFailed++
+			continue
+		}
+		dbs[i] = db
+		if (i+1)%20 == 0 || i == len(tenants)-1 {
+			bench.Infof("Connected: %d/%d", i+1-connectFailed, len(tenants))
+		}
+	}
+	defer func() {
+		for _, db := range dbs {
+			if db != nil {
+				db.Close()
+			}
+		}
+	}()
+	if connectFailed > 0 {
+		bench.Warnf("%d tenants failed to connect", connectFailed)
+	}
+	var proxyVersion string
+	for _, db := range dbs {
+		if db != nil {
+			proxyVersion = detectVersion(db)
+			break
+		}
+	}
+	bench.Okf("%d tenants connected", len(tenants)-connectFailed)
+
+	// ── Phase 2: Seed all tenants ──
+	bench.Stepf("[2/3] Seeding data (parallel)...")
+	var seedWg sync.WaitGroup
+	var seedFailed int
+	var seedMu sync.Mutex
+	for i, db := range dbs {
+		if db == nil {
+			continue
+		}
+		seedWg.Add(1)
+		go func(d *sql.DB, idx int) {
+			defer seedWg.Done()
+			tp := params
+			tp.SeedRows = bench.RowsFor(sizes, idx, params.SeedRows)
+			if err := PrepareData(ctx, d, tp); err != nil {
+				seedMu.Lock()
+				seedFailed++
+				seedMu.Unlock()
+			}
+		}(db, i)
+	}
+	seedWg.Wait()
+	if seedFailed > 0 {
+		bench.Warnf("%d tenants failed to seed", seedFailed)
+	}
+	bench.Okf("All tenants seeded")
+	fmt.Println()
+
+	// ── Phase 3: Run scale benchmark ──
+	bench.Stepf("[3/3] Running scale benchmark...")
+	fmt.Println()
+
+	var ramp []bench.RampStep
+	runOnce := func(run int) bench.BenchStats {
+		if params.RampTenants > 0 {
+			var stats bench.BenchStats
+			stats, ramp = scaleRunRamp(ctx, dbs, tenants, params, concPerTenant, totalConc, sizes)
+			return stats
+		}
+		if params.TenantSkew > 0 {
+			return runSkewed(ctx, dbs, tenants, params, sizes)
+		}
+		if params.Duration > 0 {
+			return scaleRunTimed(ctx, dbs, tenants, params, concPerTenant, totalConc, sizes)
+		}
+		return scaleRunCount(ctx, dbs, tenants, params, concPerTenant, totalConc, sizes)
+	}
+
+	var stats bench.BenchStats
+	if params.Runs > 1 {
+		stats = bench.RunMultiple(ctx, params.Runs, fmt.Sprintf("Scale (%d tenants)", len(tenants)), runOnce)
+	} else {
+		stats = runOnce(0)
+	}
+	bench.PrintStats(stats)
+
+	res := &bench.Result{Stats: []bench.BenchStats{stats}, Ramp: ramp}
+	res.Manifest.ProxyVersion = proxyVersion
+	return res
+}
+
+// scaleMinQueries is the fewest queries each tenant runs in a count-based
+// scale test, however small -queries is.
+const scaleMinQueries = 10
+
+func scaleRunCount(ctx context.Context, dbs []*sql.DB, tenants []string, params bench.BenchParams, concPerTenant, totalConc int, sizes []int) bench.BenchStats {
+	q := newQueries(params)
+	op := workloadOp(params)
+	total := max(params.Queries, scaleMinQueries*len(tenants))
+
+	tResults := make([]tenantStats, len(tenants))
+	for i, t := range tenants {
+		_, n := bench.Share(total, len(tenants), i)
+		tResults[i] = tenantStats{
+			Name:    t,
+			Results: make([]bench.QueryResult, n),
+		}
+	}
+
+	queued := 0
+	for t := range tenants {
+		if dbs[t] != nil {
+			queued += len(tResults[t].Results)
+		}
+	}
+	progress := bench.NewProgress(queued)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+
+	for t := 0; t < len(tenants); t++ {
+		db := dbs[t]
+		if db == nil {
+			continue
+		}
+
+		for w := 0; w < concPerTenant; w++ {
+			wg.Add(1)
+			workerOffset, workerQueries := bench.Share(len(tResults[t].Results), concPerTenant, w)
+
+			go func(tIdx int, d *sql.DB, offset, count int) {
+				defer wg.Done()
+
+				for i := 0; i < count; i++ {
+					idx := offset + i
+					tResults[tIdx].Results[idx] = op(ctx, d, q, bench.RowsFor(sizes, tIdx, params.SeedRows))
+					progress.Add(1)
+				}
+				bench.ObserveWorker("Scale Test", tIdx*concPerTenant+w, tResults[tIdx].Results[offset:offset+count])
+			}(t, db, workerOffset, workerQueries)
+		}
+	}
+	wg.Wait()
+	progress.Done()
+
+	totalDuration := time.Since(start)
+	return computeScaleStats(tResults, connected(dbs), tenants, totalDuration, totalConc, sizes)
+}
+
+func scaleRunTimed(ctx context.Context, dbs []*sql.DB, tenants []string, params bench.BenchParams, concPerTenant, totalConc int, sizes []int) bench.BenchStats {
+	q := newQueries(params)
+	op := workloadOp(params)
+
+	type tenantCollector struct {
+		mu      sync.Mutex
+		results []bench.QueryResult
+	}
+	collectors := make([]tenantCollector, len(tenants))
+
+	var stopped atomic.Bool
+	start := time.Now()
+	time.AfterFunc(params.Duration, func() { stopped.Store(true) })
+
+	var wg sync.WaitGroup
+	for t := 0; t < len(tenants); t++ {
+		db := dbs[t]
+		if db == nil {
+			continue
+		}
+
+		for w := 0; w < concPerTenant; w++ {
+			wg.Add(1)
+			go func(tIdx int, d *sql.DB) {
+				defer wg.Done()
+				var local []bench.QueryResult
+
+				for !stopped.Load() && ctx.Err() == nil {
+					local = append(local, op(ctx, d, q, bench.RowsFor(sizes, tIdx, params.SeedRows)))
+				}
+
+				bench.ObserveWorker("Scale Test", tIdx*concPerTenant+w, local)
+
+				collectors[tIdx].mu.Lock()
+				collectors[tIdx].results = append(collectors[tIdx].results, local...)
+				collectors[tIdx].mu.Unlock()
+			}(t, db)
+		}
+	}
+	wg.Wait()
+
+	elapsed := time.Since(start)
+	_, totalDuration := bench.Window(nil, start, params, elapsed)
+
+	tResults := make([]tenantStats, len(tenants))
+	for i, t := range tenants {
+		kept, _ := bench.Window(collectors[i].results, start, params, elapsed)
+		tResults[i] = tenantStats{Name: t, Results: kept}
+	}
+
+	return computeScaleStats(tResults, connected(dbs), tenants, totalDuration, totalConc, sizes)
+}
+
+// scaleRunRamp is the timed run with tenants brought online in waves of
+// params.RampTenants every params.RampEvery; tenants whose wave would start
+// after the run ends never join. It charts latency per step.
+func scaleRunRamp(ctx context.Context, dbs []*sql.DB, tenants []string, params bench.BenchParams, concPerTenant, totalConc int, sizes []int) (bench.BenchStats, []bench.RampStep) {
+	q := newQueries(params)
+	op := workloadOp(params)
+	byTenant := make([][]bench.QueryResult, len(tenants))
+	joined := append([]*sql.DB(nil), dbs...)
+	var mu sync.Mutex
+
+	start := time.Now()
+	deadline := start.Add(params.Duration)
+	var wg sync.WaitGroup
+	for t := range tenants {
+		joinAt := start.Add(time.Duration(t/params.RampTenants) * params.RampEvery)
+		if !joinAt.Before(deadline) {
+			joined[t] = nil
+		}
+		if joined[t] == nil {
+			continue
+		}
+		if t%params.RampTenants == 0 {
+			time.AfterFunc(time.Until(joinAt), func() {
+				bench.Infof("Wave %d: %d tenants online", t/params.RampTenants+1, min(t+params.RampTenants, len(tenants)))
+			})
+		}
+		for w := 0; w < concPerTenant; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				bench.Sleep(ctx, time.Until(joinAt))
+				var local []bench.QueryResult
+				for time.Now().Before(deadline) && ctx.Err() == nil {
+					local = append(local, op(ctx, joined[t], q, bench.RowsFor(sizes, t, params.SeedRows)))
+				}
+				bench.ObserveWorker("Scale Test", t*concPerTenant+w, local)
+				mu.Lock()
+				byTenant[t] = append(byTenant[t], local...)
+				mu.Unlock()
+			}()
+		}
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+	_, totalDuration := bench.Window(nil, start, params, elapsed)
+	for i := range byTenant {
+		byTenant[i], _ = bench.Window(byTenant[i], start, params, elapsed)
+	}
+
+	steps := bench.RampSteps(byTenant, params.RampTenants, start, params.RampEvery, params.Duration)
+	bench.PrintRamp(steps)
+	tResults := make([]tenantStats, len(tenants))
+	for i, t := range tenants {
+		tResults[i] = tenantStats{Name: t, Results: byTenant[i]}
+	}
+	return computeScaleStats(tResults, connected(joined), tenants, totalDuration, totalConc, sizes), steps
+}
+
+// connected reports which tenants have a connection.
+func connected(dbs []*sql.DB) []bool {
+	live := make([]bool, len(dbs))
+	for i, p := range dbs {
+		live[i] = p != nil
+	}
+	return live
+}
+
+func computeScaleStats(tResults []tenantStats, live []bool, tenants []string, totalDuration time.Duration, totalConc int, sizes []int) bench.BenchStats {
+	for _, t := range tResults {
+		bench.ObserveTenant(t.Name, t.Results)
+	}
+	var allResults []bench.QueryResult
+	var totalErrors int
+	var tenantP50s []float64
+	var rows []int
+	var perTenant []bench.BenchStats
+	var byTenant [][]bench.QueryResult
+
+	for i := range tResults {
+		if !live[i] {
+			continue
+		}
+		tResults[i].Stats = bench.ComputeStats(tResults[i].Name, tResults[i].Results, totalDuration)
+		allResults = append(allResults, tResults[i].Results...)
+		byTenant = append(byTenant, tResults[i].Results)
+		totalErrors += tResults[i].Stats.Errors
+		tenantP50s = append(tenantP50s, float64(tResults[i].Stats.LatencyP50.Microseconds()))
+		rows = append(rows, bench.RowsFor(sizes, i, 0))
+		perTenant = append(perTenant, tResults[i].Stats)
+	}
+
+	overall := bench.ComputeStats(
+		fmt.Sprintf("Scale Test (%d tenants, %d total concurrent)", len(tenants), totalConc),
+		allResults, totalDuration,
+	)
+
+	if len(tenantP50s) > 0 {
+		sort.Float64s(tenantP50s)
+
+		fastestP50 := time.Duration(tenantP50s[0]) * time.Microsecond
+		slowestP50 := time.Duration(tenantP50s[len(tenantP50s)-1]) * time.Microsecond
+		medianP50 := time.Duration(tenantP50s[len(tenantP50s)/2]) * time.Microsecond
+
+		type ranked struct {
+			name string
+			p50  time.Duration
+		}
+		var ranking []ranked
+		for i := range tResults {
+			if !live[i] {
+				continue
+			}
+			ranking = append(ranking, ranked{tResults[i].Name, tResults[i].Stats.LatencyP50})
+		}
+		sort.Slice(ranking, func(i, j int) bool { return ranking[i].p50 > ranking[j].p50 })
+
+		fairnessRatio := float64(slowestP50) / float64(fastestP50)
+
+		fmt.Println()
+		fmt.Println("╔═════════════════════════════════════════════════════════════╗")
+		fmt.Printf("║  %-59s║\n", fmt.Sprintf("SCALE TEST RESULTS (%d TENANTS)", len(tenants)))
+		fmt.Println("╠═════════════════════════════════════════════════════════════╣")
+		fmt.Printf("║  Total Queries:     %-39d║\n", overall.Total)
+		fmt.Printf("║  Total Errors:      %-39d║\n", totalErrors)
+		fmt.Printf("║  Total Duration:    %-39s║\n", totalDuration.Round(time.Millisecond))
+		fmt.Printf("║  Overall QPS:       %-39.1f║\n", overall.QPS)
+		fmt.Printf("║  Overall p50:       %-39s║\n", bench.FmtDur(overall.LatencyP50))
+		fmt.Printf("║  Overall p95:       %-39s║\n", bench.FmtDur(overall.LatencyP95))
+		fmt.Printf("║  Overall p99:       %-39s║\n", bench.FmtDur(overall.LatencyP99))
+		fmt.Println("╠═════════════════════════════════════════════════════════════╣")
+		fmt.Println("║  TENANT FAIRNESS                                           ║")
+		fmt.Println("╠═════════════════════════════════════════════════════════════╣")
+		fmt.Printf("║  Fastest tenant p50:  %-37s║\n", bench.FmtDur(fastestP50))
+		fmt.Printf("║  Median tenant p50:   %-37s║\n", bench.FmtDur(medianP50))
+		fmt.Printf("║  Slowest tenant p50:  %-37s║\n", bench.FmtDur(slowestP50))
+		fmt.Printf("║  Fairness ratio:      %-37s║\n", fmt.Sprintf("%.1fx (slowest/fastest)", fairnessRatio))
+		fmt.Println("╠═════════════════════════════════════════════════════════════╣")
+		fmt.Println("║  TOP 5 SLOWEST TENANTS                                     ║")
+		fmt.Println("╠═════════════════════════════════════════════════════════════╣")
+		for i := 0; i < 5 && i < len(ranking); i++ {
+			short := ranking[i].name
+			if len(short) > 20 {
+				short = short[len(short)-20:]
+			}
+			fmt.Printf("║  #%d  %-20s  p50: %-23s║\n", i+1, short, bench.FmtDur(ranking[i].p50))
+		}
+		fmt.Println("╠═════════════════════════════════════════════════════════════╣")
+
+		if fairnessRatio < 3.0 {
+			fmt.Println("║  ✅ FAIR — all tenants within 3x of each other              ║")
+		} else if fairnessRatio < 5.0 {
+			fmt.Println("║  ⚠️  MODERATE — some tenants slower than others              ║")
+		} else {
+			fmt.Println("║  ❌ UNFAIR — significant latency spread between tenants      ║")
+		}
+		fmt.Println("╚═════════════════════════════════════════════════════════════╝")
+	}
+	if first, rest := bench.SplitFirst(byTenant); len(first) > 0 {
+		bench.PrintFirstQueries(
+			bench.ComputeStats("First query per tenant", first, totalDuration),
+			bench.ComputeStats("Steady state", rest, totalDuration))
+	}
+	if sizes != nil && len(perTenant) > 0 {
+		bench.PrintSizes(rows, perTenant)
+	}
+
+	return overall
+}
@@ -0,0 +1,76 @@
+package ms
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"tenantsdb-bench/bench"
+)
+
+// runSkewed is the -tenant-skew form of the multi and scale runs: instead
+// of fixed workers per tenant, params.Concurrency shared workers each pick
+// the tenant of every query from a Zipf distribution, so a few tenants
+// dominate. It prints per-tenant fairness (and with heterogeneous sizes,
+// latency by size) and returns the overall stats.
+func runSkewed(ctx context.Context, pools []*sql.DB, tenants []string, params bench.BenchParams, sizes []int) bench.BenchStats {
+	// Scale tenants that failed to connect are left out
+	var live []*sql.DB
+	var names []string
+	var rows []int
+	for i, p := range pools {
+		if p != nil {
+			live = append(live, p)
+			names = append(names, tenants[i])
+			rows = append(rows, bench.RowsFor(sizes, i, params.SeedRows))
+		}
+	}
+	pools, tenants = live, names
+
+	q := newQueries(params)
+	op := workloadOp(params)
+	start := time.Now()
+	var deadline time.Time
+	if params.Duration > 0 {
+		deadline = start.Add(params.Duration)
+	}
+
+	var mu sync.Mutex
+	byTenant := make([][]bench.QueryResult, len(tenants))
+	_, elapsed := bench.RunWorkers(ctx, "Skewed", params.Concurrency, func(worker int) []bench.QueryResult {
+		pick := bench.SkewPicker(len(tenants), params.TenantSkew)
+		_, perWorker := bench.Share(params.Queries, params.Concurrency, worker)
+		local := make([][]bench.QueryResult, len(tenants))
+		for i := 0; ; i++ {
+			if deadline.IsZero() && i >= perWorker || !deadline.IsZero() && time.Now().After(deadline) {
+				break
+			}
+			t := pick()
+			local[t] = append(local[t], op(ctx, pools[t], q, rows[t]))
+		}
+		mu.Lock()
+		for t, r := range local {
+			byTenant[t] = append(byTenant[t], r...)
+		}
+		mu.Unlock()
+		return nil
+	})
+
+	var all []bench.QueryResult
+	perTenant := make([]bench.BenchStats, len(tenants))
+	_, total := bench.Window(nil, start, params, elapsed)
+	for t, r := range byTenant {
+		r, _ = bench.Window(r, start, params, elapsed)
+		perTenant[t] = bench.ComputeStats(tenants[t], r, total)
+		all = append(all, r...)
+	}
+	bench.PrintSkew(perTenant)
+	if sizes != nil {
+		bench.PrintSizes(rows, perTenant)
+	}
+	return bench.ComputeStats(
+		fmt.Sprintf("Skewed (%d tenants, zipf %.2f, %d concurrent)", len(tenants), params.TenantSkew, params.Concurrency),
+		all, total)
+}
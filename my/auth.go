@@ -0,0 +1,194 @@
+package my
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"tenantsdb-bench/bench"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// sniffConn keeps what the server sends during the handshake, which names
+// the auth plugin it asks for.
+type sniffConn struct {
+	net.Conn
+	seen []byte
+}
+
+func (c *sniffConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if len(c.seen) < 8192 && n > 0 {
+		c.seen = append(c.seen, p[:n]...)
+	}
+	return n, err
+}
+
+// authMethod names the auth plugin the server settled on: the greeting's,
+// or an auth switch's, with caching_sha2_password's fast (cached) or full
+// path when the server says which.
+func authMethod(stream []byte) string {
+	var method string
+	for first := true; len(stream) >= 4; first = false {
+		n := int(stream[0]) | int(stream[1])<<8 | int(stream[2])<<16
+		if len(stream) < 4+n || n == 0 {
+			break
+		}
+		p := stream[4 : 4+n]
+		stream = stream[4+n:]
+		switch {
+		case first:
+			method = greetingPlugin(p)
+		case p[0] == 0xfe && n > 1: // auth switch request
+			method = cstring(p[1:])
+		case p[0] == 0x01 && n == 2 && method == "caching_sha2_password":
+			switch p[1] {
+			case 3:
+				method += " (fast)"
+			case 4:
+				method += " (full)"
+			}
+		case p[0] == 0x00 || p[0] == 0xff: // OK or error: auth is over
+			return method
+		}
+	}
+	return method
+}
+
+// greetingPlugin reads the auth plugin name off a protocol 10 greeting.
+func greetingPlugin(p []byte) string {
+	if len(p) == 0 || p[0] != 10 {
+		return ""
+	}
+	end := bytes.IndexByte(p[1:], 0)
+	if end < 0 {
+		return ""
+	}
+	// Connection id, auth data part 1 and a filler follow the version
+	pos := 1 + end + 1 + 4 + 8 + 1
+	// Capabilities, charset, status, more capabilities, auth data length, reserved
+	if len(p) < pos+18 {
+		return ""
+	}
+	authLen := int(p[pos+7])
+	pos += 18 + max(13, authLen-8)
+	if pos >= len(p) {
+		return ""
+	}
+	return cstring(p[pos:])
+}
+
+func cstring(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(b)
+}
+
+// RunAuth times params.AuthConnects fresh connections per user through the
+// proxy, and directly when directCfg is set, reading the auth plugin each
+// server asked for off the wire, so what auth pass-through adds shows per
+// method.
+func RunAuth(ctx context.Context, proxyCfg, directCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Println("  MySQL Authentication Latency Test")
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Printf("  %d connects per user, without TLS\n\n", params.AuthConnects)
+
+	names, cfgs := []string{"proxy"}, []bench.ConnConfig{proxyCfg}
+	if directCfg.IsSet() {
+		names, cfgs = append(names, "direct"), append(cfgs, directCfg)
+	}
+
+	res := &bench.Result{}
+	for i, name := range names {
+		bench.Stepf("[%d/%d] Connecting to the %s endpoint...", i+1, len(names), name)
+		base, err := configOf(cfgs[i])
+		if err != nil {
+			bench.Failf("%s config: %v", name, err)
+			return nil
+		}
+		// Plaintext, so auth switches and caching_sha2 replies can be read
+		base.TLS, base.TLSConfig = nil, "false"
+
+		users := params.AuthUsers
+		if len(users) == 0 {
+			users = []string{base.User}
+		}
+		for _, user := range users {
+			cfg := base.Clone()
+			cfg.User, cfg.Passwd = user, params.AuthPassword(user, base.Passwd)
+			start := time.Now()
+			results, dials, method := timeConnects(ctx, cfg, params.AuthConnects)
+			a := bench.SummarizeAuth(name, cfg.User, method, results, dials)
+			if a.Errors < a.Connects {
+				bench.Okf("%s: %s, connect p50 %s", cfg.User, orUnknown(method), bench.FmtDur(a.P50))
+			} else {
+				bench.Failf("%s: %s", cfg.User, a.FirstErr)
+			}
+			res.Auth = append(res.Auth, a)
+			res.Stats = append(res.Stats, bench.ComputeStats(
+				fmt.Sprintf("%s %s (%s)", name, cfg.User, orUnknown(method)), results, time.Since(start)))
+		}
+	}
+
+	bench.PrintAuth(res.Auth)
+	return res
+}
+
+// timeConnects opens and closes n connections with cfg, timing each from
+// dial to ready for queries, and returns the TCP dial times of those that
+// succeeded and the plugin the server settled on.
+func timeConnects(ctx context.Context, cfg *mysql.Config, n int) ([]bench.QueryResult, []time.Duration, string) {
+	var sniff *sniffConn
+	var dialed time.Duration
+	dialer := &net.Dialer{KeepAlive: 5 * time.Minute}
+	cfg.DialFunc = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		start := time.Now()
+		c, err := dialer.DialContext(ctx, network, addr)
+		dialed = time.Since(start)
+		if err != nil {
+			return nil, err
+		}
+		sniff = &sniffConn{Conn: c}
+		return sniff, nil
+	}
+	connector, err := mysql.NewConnector(cfg)
+	if err != nil {
+		return []bench.QueryResult{{At: time.Now(), Err: err, Op: "connect"}}, nil, ""
+	}
+
+	var results []bench.QueryResult
+	var dials []time.Duration
+	var method string
+	for i := 0; i < n && ctx.Err() == nil; i++ {
+		sniff = nil
+		start := time.Now()
+		cctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		conn, err := connector.Connect(cctx)
+		took := time.Since(start)
+		cancel()
+		results = append(results, bench.QueryResult{At: start, Duration: took, Err: err, Op: "connect"})
+		if err == nil {
+			dials = append(dials, dialed)
+			conn.Close()
+		}
+		if sniff != nil {
+			if m := authMethod(sniff.seen); m != "" {
+				method = m
+			}
+		}
+	}
+	return results, dials, method
+}
+
+// orUnknown is method, or "unknown" when the server named none.
+func orUnknown(method string) string {
+	if method == "" {
+		return "unknown"
+	}
+	return method
+}
@@ -0,0 +1,40 @@
+package my
+
+import (
+	"context"
+	"database/sql"
+
+	"tenantsdb-bench/bench"
+)
+
+// SQLTenants is a bench.ControlPlane that makes each tenant a database on
+// the server behind cfg, for running multi-tenant tests without the
+// management API.
+type SQLTenants struct {
+	db *sql.DB
+}
+
+func NewSQLTenants(cfg bench.ConnConfig) (*SQLTenants, error) {
+	db, err := Connect(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &SQLTenants{db: db}, nil
+}
+
+func (s *SQLTenants) Close() { s.db.Close() }
+
+// CreateTenant creates the database; one left over from an earlier run is
+// reused.
+func (s *SQLTenants) CreateTenant(ctx context.Context, name string) (string, error) {
+	_, err := s.db.ExecContext(ctx, "CREATE DATABASE IF NOT EXISTS "+quoteIdent(name))
+	return name, err
+}
+
+// WaitReady returns at once: CREATE DATABASE is done when it returns.
+func (s *SQLTenants) WaitReady(ctx context.Context, name string) error { return nil }
+
+func (s *SQLTenants) DeleteTenant(ctx context.Context, name string) error {
+	_, err := s.db.ExecContext(ctx, "DROP DATABASE IF EXISTS "+quoteIdent(name))
+	return err
+}
@@ -0,0 +1,151 @@
+package my
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"tenantsdb-bench/bench"
+)
+
+// RunChurn keeps the first tenth of the tenants connected and busy while the
+// rest join at params.ChurnRate per second, run params.ChurnQueries queries
+// and disconnect, comparing the long-lived tenants with and without churn.
+func RunChurn(ctx context.Context, proxyCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
+	tenants := TenantList()
+	if len(params.Tenants) > 0 {
+		tenants = params.Tenants
+	}
+	stableN := max(len(tenants)/10, 1)
+	stable, churners := tenants[:stableN], tenants[stableN:]
+	phase := params.Duration
+	if phase <= 0 {
+		phase = 30 * time.Second
+	}
+	perTenant := max(params.Concurrency/stableN, 1)
+
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Println("  MySQL Tenant Churn Test")
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Printf("  Long-lived: %d (%d workers each) | Churning: %d at %.1f/s, %d queries each | %s per phase\n\n",
+		len(stable), perTenant, len(churners), params.ChurnRate, params.ChurnQueries, phase)
+
+	bench.Stepf("[1/3] Connecting and seeding tenants...")
+	res := &bench.Result{}
+	pools := make([]*sql.DB, len(stable))
+	for i, t := range tenants {
+		cfg := proxyCfg
+		cfg.Database = t
+		pool, err := Connect(cfg)
+		if err != nil {
+			bench.Failf("%s: %v", t, err)
+			return nil
+		}
+		if i == 0 {
+			res.Manifest.ProxyVersion = detectVersion(pool)
+		}
+		if err := PrepareData(ctx, pool, params); err != nil {
+			pool.Close()
+			bench.Failf("Seed %s failed: %v", t, err)
+			return nil
+		}
+		if i < stableN {
+			pools[i] = pool
+			defer pool.Close()
+		} else {
+			pool.Close()
+		}
+	}
+	bench.Okf("%d tenants seeded, %d kept connected", len(tenants), len(stable))
+
+	bench.Stepf("[2/3] Long-lived tenants alone for %s...", phase)
+	baseline := bench.ComputeStats("Long-lived alone", stableLoad(ctx, "Long-lived alone", pools, params, perTenant, phase), phase)
+	bench.PrintStats(baseline)
+
+	bench.Stepf("[3/3] Long-lived tenants under churn for %s...", phase)
+	var mu sync.Mutex
+	var churnResults []bench.QueryResult
+	report := bench.ChurnReport{Rate: params.ChurnRate}
+	done, exited := make(chan struct{}), make(chan struct{})
+	var churnWg sync.WaitGroup
+	go func() {
+		defer close(exited)
+		tick := time.NewTicker(time.Duration(float64(time.Second) / params.ChurnRate))
+		defer tick.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-tick.C:
+			}
+			report.Sessions++
+			churnWg.Add(1)
+			go func(tenant string) {
+				defer churnWg.Done()
+				out, ok := churnSession(ctx, proxyCfg, params, tenant)
+				mu.Lock()
+				churnResults = append(churnResults, out...)
+				if !ok {
+					report.Failed++
+				}
+				mu.Unlock()
+			}(churners[rand.Intn(len(churners))])
+		}
+	}()
+	stableResults := stableLoad(ctx, "Long-lived under churn", pools, params, perTenant, phase)
+	close(done)
+	<-exited
+	churnWg.Wait()
+
+	bench.PrintErrors(churnResults)
+	report.Baseline = baseline
+	report.Churned = bench.ComputeStats("Long-lived under churn", stableResults, phase)
+	report.Churners = bench.ComputeStats("Churning tenants", churnResults, phase)
+	bench.PrintStats(report.Churned)
+	bench.PrintStats(report.Churners)
+	bench.PrintChurn(report)
+	res.Stats = append(res.Stats, report.Baseline, report.Churned, report.Churners)
+	res.Churn = &report
+	return res
+}
+
+// stableLoad runs the workload on perTenant workers per pool for d, as
+// the pass label.
+func stableLoad(ctx context.Context, label string, pools []*sql.DB, params bench.BenchParams, perTenant int, d time.Duration) []bench.QueryResult {
+	q := newQueries(params)
+	op := workloadOp(params)
+	deadline := time.Now().Add(d)
+	results, _ := bench.RunWorkers(ctx, label, len(pools)*perTenant, func(worker int) []bench.QueryResult {
+		pool := pools[worker/perTenant]
+		var local []bench.QueryResult
+		for time.Now().Before(deadline) && ctx.Err() == nil {
+			local = append(local, op(ctx, pool, q, params.SeedRows))
+		}
+		return local
+	})
+	return results
+}
+
+// churnSession connects to tenant, runs params.ChurnQueries queries and
+// disconnects; ok is false if it could not connect.
+func churnSession(ctx context.Context, proxyCfg bench.ConnConfig, params bench.BenchParams, tenant string) (out []bench.QueryResult, ok bool) {
+	cfg := proxyCfg
+	cfg.Database = tenant
+	qStart := time.Now()
+	pool, err := Connect(cfg)
+	out = append(out, bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err, Op: "connect"})
+	if err != nil {
+		return out, false
+	}
+	defer pool.Close()
+
+	q := newQueries(params)
+	op := workloadOp(params)
+	for i := 0; i < params.ChurnQueries; i++ {
+		out = append(out, op(ctx, pool, q, params.SeedRows))
+	}
+	return out, true
+}
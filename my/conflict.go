@@ -0,0 +1,137 @@
+package my
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"tenantsdb-bench/bench"
+)
+
+// RunConflict runs read-modify-write transactions on the hot rows at
+// params.Isolation, retrying deadlocks and serialization failures, through
+// the proxy and (when configured) direct, and compares failure rates and
+// lost updates.
+func RunConflict(ctx context.Context, proxyCfg, directCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Println("  MySQL Isolation Conflict Test")
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Printf("  Transactions: %d | Concurrency: %d | %s on %d hot rows\n\n",
+		params.Queries, params.Concurrency, params.Isolation, params.HotRows)
+
+	res := &bench.Result{}
+	directDB, proxyDB, ok := endpoints(proxyCfg, directCfg, res, 3)
+	if !ok {
+		return nil
+	}
+	defer proxyDB.Close()
+	if directDB != nil {
+		defer directDB.Close()
+	}
+
+	seedDB := proxyDB
+	if directDB != nil {
+		seedDB = directDB
+	}
+	if err := PrepareData(ctx, seedDB, params); err != nil {
+		bench.Failf("Seed failed: %v", err)
+		return nil
+	}
+	bench.Okf("Data ready")
+
+	bench.Stepf("[3/3] Running conflicting transactions...")
+	var directStats *bench.BenchStats
+	if directDB != nil {
+		fmt.Println("\n── Direct MySQL ──")
+		s := bench.RunMultiple(ctx, params.Runs, "Direct "+params.Isolation, func(run int) bench.BenchStats {
+			return conflictPass(bench.Unobserved(ctx), directDB, params, "Direct "+params.Isolation)
+		})
+		bench.PrintStats(s)
+		res.Stats = append(res.Stats, s)
+		directStats = &s
+	}
+
+	fmt.Println("\n── Through TenantsDB Proxy ──")
+	proxyStats := bench.RunMultiple(ctx, params.Runs, "Proxy "+params.Isolation, func(run int) bench.BenchStats {
+		return conflictPass(ctx, proxyDB, params, "Proxy "+params.Isolation)
+	})
+	bench.PrintStats(proxyStats)
+	res.Stats = append(res.Stats, proxyStats)
+
+	// InnoDB REPEATABLE READ reads a snapshot without locking, so concurrent
+	// increments legitimately overwrite each other; SERIALIZABLE locks the read
+	bench.PrintConflicts(directStats, proxyStats, params.Isolation == "repeatable-read")
+	if directStats != nil {
+		bench.PrintComparison(proxyStats, *directStats)
+		cmp := bench.Compare(proxyStats, *directStats)
+		res.Comparison = &cmp
+	}
+	return res
+}
+
+// conflictPass runs about params.Queries increments of random hot rows on
+// params.Concurrency workers. Every attempt is one result; the hot rows'
+// balance must grow by exactly the number of commits, and any shortfall is
+// reported as LostUpdates.
+func conflictPass(ctx context.Context, db *sql.DB, params bench.BenchParams, label string) bench.BenchStats {
+	q := newQueries(params)
+	iso := sql.LevelSerializable
+	if params.Isolation == "repeatable-read" {
+		iso = sql.LevelRepeatableRead
+	}
+	sum := "SELECT COALESCE(SUM(balance), 0) FROM " + q.table + " WHERE id BETWEEN 1 AND ?"
+
+	var before float64
+	if err := db.QueryRowContext(ctx, sum, params.HotRows).Scan(&before); err != nil {
+		bench.Warnf("Hot row sum: %v", err)
+	}
+
+	results, total := bench.RunWorkers(ctx, label, params.Concurrency, func(worker int) []bench.QueryResult {
+		_, perWorker := bench.Share(max(params.Queries, params.Concurrency), params.Concurrency, worker)
+		var local []bench.QueryResult
+		for i := 0; i < perWorker; i++ {
+			id := rand.Intn(params.HotRows) + 1
+			for attempt := 0; attempt <= bench.ConflictRetries; attempt++ {
+				qStart := time.Now()
+				err := classify(increment(ctx, db, q, iso, id))
+				local = append(local, bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err, Op: "txn"})
+				if !errors.Is(err, bench.ErrSerialization) && !errors.Is(err, bench.ErrDeadlock) {
+					break
+				}
+			}
+		}
+		return local
+	})
+	bench.PrintErrors(results)
+	stats := bench.ComputeStats(label, results, total)
+
+	var after float64
+	if err := db.QueryRowContext(ctx, sum, params.HotRows).Scan(&after); err != nil {
+		bench.Warnf("Hot row sum: %v", err)
+		return stats
+	}
+	stats.LostUpdates = max(stats.Total-stats.Errors-int(after-before+0.5), 0)
+	return stats
+}
+
+// increment reads a row's balance and writes it back plus one, in one
+// transaction at iso.
+func increment(ctx context.Context, db *sql.DB, q queries, iso sql.IsolationLevel, id int) error {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: iso})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var balance float64
+	if err := tx.QueryRowContext(ctx, "SELECT balance FROM "+q.table+" WHERE id = ?", id).Scan(&balance); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE "+q.table+" SET balance = ? WHERE id = ?", balance+1, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -11,20 +11,30 @@ import (
 
 	"tenantsdb-bench/bench"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 )
 
 func Connect(c bench.ConnConfig) (*sql.DB, error) {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true&interpolateParams=true&allowCleartextPasswords=true&timeout=30s",
-		c.User, c.Password, c.Host, c.Port, c.Database)
+	cfg, err := configOf(c)
+	if err != nil {
+		return nil, err
+	}
 
-	db, err := sql.Open("mysql", dsn)
+	db, err := sql.Open("mysql", cfg.FormatDSN())
 	if err != nil {
 		return nil, err
 	}
 	db.SetMaxOpenConns(10)
 	db.SetMaxIdleConns(5)
 	db.SetConnMaxLifetime(30 * time.Minute)
+	switch c.Strategy {
+	case "per-query":
+		// No idle connections: each query dials anew
+		db.SetMaxIdleConns(0)
+	case "single":
+		db.SetMaxOpenConns(1)
+		db.SetMaxIdleConns(1)
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -33,206 +43,189 @@ func Connect(c bench.ConnConfig) (*sql.DB, error) {
 		db.Close()
 		return nil, err
 	}
+	bench.Debugf("Connected to %s:%d/%s", c.Host, c.Port, c.Database)
 	return db, nil
 }
 
-func SeedData(db *sql.DB, rows int) error {
-	ctx := context.Background()
-
-	// Check if table already exists and seeded
-	var count int
-	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM accounts").Scan(&count); err == nil {
-		if count >= rows {
-			fmt.Printf("  Data already seeded (%d rows)\n", count)
-			return nil
-		}
-		fmt.Printf("  Table exists with %d rows, seeding more...\n", count)
+// configOf is the driver config for c: its DSN with c's database and
+// password filled in, or one built from its fields.
+func configOf(c bench.ConnConfig) (*mysql.Config, error) {
+	if c.DSN == "" {
+		return mysql.ParseDSN(fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true&interpolateParams=true&allowCleartextPasswords=true&timeout=30s",
+			c.User, c.Password, c.Host, c.Port, c.Database))
 	}
-
-	// Create table if not exists (only works on direct connections, blocked by proxy DDL guard)
-	_, err := db.ExecContext(ctx, `
-		CREATE TABLE IF NOT EXISTS accounts (
-			id INT AUTO_INCREMENT PRIMARY KEY,
-			name VARCHAR(255) NOT NULL,
-			balance DECIMAL(15,2) NOT NULL
-		)
-	`)
+	cfg, err := mysql.ParseDSN(c.DSN)
 	if err != nil {
-		return fmt.Errorf("create table: %w", err)
+		return nil, err
 	}
-
-	err = db.QueryRowContext(ctx, "SELECT COUNT(*) FROM accounts").Scan(&count)
-	if err != nil {
-		return fmt.Errorf("seed check: %w", err)
+	// Tenant loops override the database; env/file passwords fill a gap
+	if c.Database != "" {
+		cfg.DBName = c.Database
 	}
-	if count >= rows {
-		fmt.Printf("  Data already seeded (%d rows)\n", count)
-		return nil
+	if cfg.Passwd == "" {
+		cfg.Passwd = c.Password
 	}
+	return cfg, nil
+}
 
-	fmt.Printf("  Seeding %d rows...\n", rows)
+// ServerVersion returns SELECT @@version for the connection. Through the proxy
+// this is whatever backend the proxy routed the tenant to.
+func ServerVersion(db *sql.DB) (string, error) {
+	var v string
+	err := db.QueryRowContext(context.Background(), "SELECT @@version").Scan(&v)
+	return v, err
+}
 
-	// Batch insert 500 at a time
-	batchSize := 500
-	for i := 0; i < rows; i += batchSize {
-		end := i + batchSize
-		if end > rows {
-			end = rows
-		}
+// detectVersion prints the server version and returns it ("" if unknown).
+func detectVersion(db *sql.DB) string {
+	v, err := ServerVersion(db)
+	if err != nil {
+		bench.Warnf("Version check failed: %v", err)
+		return ""
+	}
+	bench.Infof("Server version: %s", bench.ShortVersion(v))
+	return v
+}
 
-		query := "INSERT INTO accounts (name, balance) VALUES "
-		vals := make([]interface{}, 0, (end-i)*2)
-		for j := i; j < end; j++ {
-			if j > i {
-				query += ","
-			}
-			query += "(?,?)"
-			vals = append(vals, fmt.Sprintf("user_%d", j+1), rand.Float64()*10000)
+// endpoints connects through the proxy and, when directCfg is set, directly,
+// printing steps 1 and 2 of steps and recording versions in res. direct is
+// nil without a direct endpoint; the caller closes both.
+func endpoints(proxyCfg, directCfg bench.ConnConfig, res *bench.Result, steps int) (direct, proxy *sql.DB, ok bool) {
+	if directCfg.IsSet() {
+		bench.Stepf("[1/%d] Connecting directly to MySQL...", steps)
+		d, err := Connect(directCfg)
+		if err != nil {
+			bench.Failf("Direct connection failed: %v", err)
+			return nil, nil, false
 		}
+		direct = d
+		res.Manifest.BackendVersion = detectVersion(d)
+		bench.Okf("Connected")
+	} else {
+		bench.Stepf("[1/%d] No direct endpoint, measuring the proxy only", steps)
+	}
 
-		if _, err := db.ExecContext(ctx, query, vals...); err != nil {
-			return fmt.Errorf("seed batch at %d: %w", i, err)
+	bench.Stepf("[2/%d] Connecting through TenantsDB proxy...", steps)
+	d, err := Connect(proxyCfg)
+	if err != nil {
+		bench.Failf("Proxy connection failed: %v", err)
+		if direct != nil {
+			direct.Close()
 		}
+		return nil, nil, false
 	}
-	return nil
+	res.Manifest.ProxyVersion = detectVersion(d)
+	bench.Okf("Connected")
+	return direct, d, true
 }
 
 // RunQueries runs a fixed number of queries (count-based mode).
-func RunQueries(db *sql.DB, params bench.BenchParams, label string) bench.BenchStats {
-	ctx := context.Background()
+func RunQueries(ctx context.Context, db *sql.DB, params bench.BenchParams, label string) bench.BenchStats {
 	maxID := params.SeedRows
+	q := newQueries(params)
+	op := oltpOp(params)
 
 	// Warmup
-	fmt.Printf("  Warming up (%d queries)...\n", params.Warmup)
+	bench.Infof("Warming up (%d queries)...", params.Warmup)
 	for i := 0; i < params.Warmup; i++ {
 		id := rand.Intn(maxID) + 1
-		db.QueryRowContext(ctx, "SELECT id, name, balance FROM accounts WHERE id = ?", id).Scan(new(int), new(string), new(float64))
+		db.QueryRowContext(ctx, q.selectByID, id).Scan(new(int), new(string), new(float64))
 	}
 
 	// Benchmark
-	fmt.Printf("  Running %d queries (%d concurrent)...\n", params.Queries, params.Concurrency)
-
-	results := make([]bench.QueryResult, params.Queries)
-	queriesPerWorker := params.Queries / params.Concurrency
+	bench.Infof("Running %d queries (%d concurrent)...", params.Queries, params.Concurrency)
 
+	workers := params.Concurrency - params.AggWorkers
+	progress := bench.NewProgress(params.Queries)
 	start := time.Now()
+	sink := bench.NewSink(ctx, label, start, params)
+
+	// Aggregation workers run alongside until the counted queries are done
+	stopAgg := bench.Background(params.AggWorkers, func() bench.QueryResult {
+		return aggQuery(ctx, db, q, maxID)
+	})
 
 	var wg sync.WaitGroup
-	for w := 0; w < params.Concurrency; w++ {
+	for w := 0; w < workers; w++ {
 		wg.Add(1)
 		go func(workerID int) {
 			defer wg.Done()
-			offset := workerID * queriesPerWorker
-
-			for i := 0; i < queriesPerWorker; i++ {
-				idx := offset + i
-				qStart := time.Now()
-
-				if rand.Intn(100) < 80 {
-					id := rand.Intn(maxID) + 1
-					var rID int
-					var rName string
-					var rBalance float64
-					err := db.QueryRowContext(ctx, "SELECT id, name, balance FROM accounts WHERE id = ?", id).Scan(&rID, &rName, &rBalance)
-					results[idx] = bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err}
-				} else {
-					id := rand.Intn(maxID) + 1
-					delta := rand.Float64()*200 - 100
-					_, err := db.ExecContext(ctx, "UPDATE accounts SET balance = balance + ? WHERE id = ?", delta, id)
-					results[idx] = bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err}
-				}
+			bench.Pin(workerID)
+			_, count := bench.Share(params.Queries, workers, workerID)
+
+			out := sink.Worker(workerID, "")
+			for i := 0; i < count; i++ {
+				out.Add(op(ctx, db, q, maxID))
+				progress.Add(1)
 			}
+			out.Done()
 		}(w)
 	}
 	wg.Wait()
+	progress.Done()
 
 	totalDuration := time.Since(start)
-
-	errCount := 0
-	for _, r := range results {
-		if r.Err != nil && errCount < 5 {
-			fmt.Printf("  ⚠ Error: %v\n", r.Err)
-			errCount++
-		}
-	}
-
-	return bench.ComputeStats(label, results, totalDuration)
+	sink.AddResults(stopAgg())
+	return sink.Stats(totalDuration)
 }
 
 // RunQueriesTimed runs queries for a fixed duration (time-based mode).
-func RunQueriesTimed(db *sql.DB, params bench.BenchParams, label string) bench.BenchStats {
+// Returns results collected during the duration window.
+func RunQueriesTimed(ctx context.Context, db *sql.DB, params bench.BenchParams, label string) bench.BenchStats {
 	if params.Duration <= 0 {
-		return RunQueries(db, params, label)
+		return RunQueries(ctx, db, params, label)
 	}
 
-	ctx := context.Background()
 	maxID := params.SeedRows
+	q := newQueries(params)
+	op := oltpOp(params)
 
 	// Warmup
-	fmt.Printf("  Warming up (%d queries)...\n", params.Warmup)
+	bench.Infof("Warming up (%d queries)...", params.Warmup)
 	for i := 0; i < params.Warmup; i++ {
 		id := rand.Intn(maxID) + 1
-		db.QueryRowContext(ctx, "SELECT id, name, balance FROM accounts WHERE id = ?", id).Scan(new(int), new(string), new(float64))
+		db.QueryRowContext(ctx, q.selectByID, id).Scan(new(int), new(string), new(float64))
 	}
 
-	fmt.Printf("  Running for %s (%d concurrent)...\n", params.Duration, params.Concurrency)
+	bench.Infof("Running for %s (%d concurrent)...", params.Duration, params.Concurrency)
 
-	var mu sync.Mutex
-	var results []bench.QueryResult
 	var stopped atomic.Bool
 
 	start := time.Now()
+	sink := bench.NewSink(ctx, label, start, params)
+	stopAgg := bench.Background(params.AggWorkers, func() bench.QueryResult {
+		return aggQuery(ctx, db, q, maxID)
+	})
+
+	// Stop signal after duration
 	time.AfterFunc(params.Duration, func() { stopped.Store(true) })
 
 	var wg sync.WaitGroup
-	for w := 0; w < params.Concurrency; w++ {
+	for w := 0; w < params.Concurrency-params.AggWorkers; w++ {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			var local []bench.QueryResult
-
-			for !stopped.Load() {
-				qStart := time.Now()
-
-				if rand.Intn(100) < 80 {
-					id := rand.Intn(maxID) + 1
-					var rID int
-					var rName string
-					var rBalance float64
-					err := db.QueryRowContext(ctx, "SELECT id, name, balance FROM accounts WHERE id = ?", id).Scan(&rID, &rName, &rBalance)
-					local = append(local, bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err})
-				} else {
-					id := rand.Intn(maxID) + 1
-					delta := rand.Float64()*200 - 100
-					_, err := db.ExecContext(ctx, "UPDATE accounts SET balance = balance + ? WHERE id = ?", delta, id)
-					local = append(local, bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err})
-				}
+			bench.Pin(w)
+			out := sink.Worker(w, "")
+			for !stopped.Load() && ctx.Err() == nil {
+				out.Add(op(ctx, db, q, maxID))
 			}
-
-			mu.Lock()
-			results = append(results, local...)
-			mu.Unlock()
+			out.Done()
 		}()
 	}
 	wg.Wait()
 
-	totalDuration := time.Since(start)
+	sink.AddResults(stopAgg())
+	return sink.Stats(time.Since(start))
+}
 
-	errCount := 0
-	for _, r := range results {
-		if r.Err != nil && errCount < 5 {
-			fmt.Printf("  ⚠ Error: %v\n", r.Err)
-			errCount++
+// PickRunner returns the right runner based on params.Duration, checking
+// data integrity around it when asked.
+func PickRunner(ctx context.Context, db *sql.DB, params bench.BenchParams, label string) bench.BenchStats {
+	return withIntegrity(ctx, []*sql.DB{db}, []string{params.TableName()}, params, func() bench.BenchStats {
+		if params.Duration > 0 {
+			return RunQueriesTimed(ctx, db, params, label)
 		}
-	}
-
-	return bench.ComputeStats(label, results, totalDuration)
+		return RunQueries(ctx, db, params, label)
+	})
 }
-
-// PickRunner returns the right runner based on params.Duration.
-func PickRunner(db *sql.DB, params bench.BenchParams, label string) bench.BenchStats {
-	if params.Duration > 0 {
-		return RunQueriesTimed(db, params, label)
-	}
-	return RunQueries(db, params, label)
-}
\ No newline at end of file
@@ -0,0 +1,82 @@
+package my
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"tenantsdb-bench/bench"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// RunConnLimit opens and holds connections through the proxy, one at a
+// time, until one is refused or params.MaxConns are open, and reports how
+// the refusal looked and how connect latency changed near the limit.
+func RunConnLimit(ctx context.Context, proxyCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Println("  MySQL Connection Limit Test")
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Printf("  Up to %d connections, held open\n\n", params.MaxConns)
+
+	res := &bench.Result{}
+	bench.Stepf("[1/2] Connecting through TenantsDB proxy...")
+	db, err := Connect(proxyCfg)
+	if err != nil {
+		bench.Failf("Proxy connection failed: %v", err)
+		return nil
+	}
+	defer db.Close()
+	res.Manifest.ProxyVersion = detectVersion(db)
+	db.SetMaxOpenConns(params.MaxConns)
+	bench.Okf("Connected")
+
+	bench.Stepf("[2/2] Opening connections...")
+	var conns []*sql.Conn
+	defer func() {
+		for _, c := range conns {
+			c.Close()
+		}
+	}()
+
+	var limit bench.ConnLimit
+	var results []bench.QueryResult
+	start := time.Now()
+	for i := 0; i < params.MaxConns; i++ {
+		qStart := time.Now()
+		cctx, cancel := context.WithTimeout(ctx, bench.ConnLimitTimeout)
+		conn, err := db.Conn(cctx)
+		if err == nil {
+			conns = append(conns, conn)
+			_, err = conn.ExecContext(cctx, "SELECT 1")
+		}
+		hung := cctx.Err() != nil
+		cancel()
+		results = append(results, bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err, Op: "connect"})
+
+		if err != nil {
+			limit.Rejected = true
+			limit.RejectErr = err.Error()
+			limit.RejectAfter = time.Since(qStart)
+			limit.Hung = hung
+			var myErr *mysql.MySQLError
+			if errors.As(err, &myErr) {
+				limit.RejectCode = fmt.Sprintf("error %d", myErr.Number)
+			}
+			break
+		}
+		if (i+1)%50 == 0 {
+			bench.Infof("Open: %d", i+1)
+		}
+	}
+
+	bench.SummarizeConnLimit(&limit, results)
+	stats := bench.ComputeStats("Connect + first query", results, time.Since(start))
+	bench.PrintStats(stats)
+	bench.PrintConnLimit(limit)
+	res.Stats = append(res.Stats, stats)
+	res.ConnLimit = &limit
+	return res
+}
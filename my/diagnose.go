@@ -0,0 +1,92 @@
+package my
+
+import (
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+
+	"tenantsdb-bench/bench"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// Capability flags used by ProbeTLS.
+const (
+	clientLongPassword     = 0x00000001
+	clientProtocol41       = 0x00000200
+	clientSSL              = 0x00000800
+	clientSecureConnection = 0x00008000
+)
+
+// Address returns the host and port cfg connects to, from its DSN if set.
+func Address(c bench.ConnConfig) (string, int, error) {
+	if c.DSN == "" {
+		return c.Host, c.Port, nil
+	}
+	cfg, err := mysql.ParseDSN(c.DSN)
+	if err != nil {
+		return "", 0, err
+	}
+	host, p, err := net.SplitHostPort(cfg.Addr)
+	if err != nil {
+		return "", 0, err
+	}
+	port, err := strconv.Atoi(p)
+	return host, port, err
+}
+
+// ProbeTLS reads the server greeting and, if it offers TLS, sends an
+// SSLRequest and completes a TLS handshake without verifying the
+// certificate.
+func ProbeTLS(c net.Conn, host string) (*tls.Conn, error) {
+	greeting, err := readPacket(c)
+	if err != nil {
+		return nil, err
+	}
+	if len(greeting) > 3 && greeting[0] == 0xff {
+		// ERR packet, e.g. host not allowed to connect
+		return nil, fmt.Errorf("server refused: %s", greeting[3:])
+	}
+	// protocol version, NUL-terminated server version, connection id,
+	// 8 bytes of auth data and a filler, then the low capability flags
+	i := 1
+	for i < len(greeting) && greeting[i] != 0 {
+		i++
+	}
+	i += 1 + 4 + 8 + 1
+	if i+2 > len(greeting) {
+		return nil, fmt.Errorf("short server greeting")
+	}
+	if binary.LittleEndian.Uint16(greeting[i:])&clientSSL == 0 {
+		return nil, nil
+	}
+
+	req := make([]byte, 4+32)
+	req[0], req[3] = 32, 1 // payload length, sequence id
+	binary.LittleEndian.PutUint32(req[4:], clientLongPassword|clientProtocol41|clientSSL|clientSecureConnection)
+	binary.LittleEndian.PutUint32(req[8:], 1<<24)
+	req[12] = 45 // utf8mb4_general_ci
+	if _, err := c.Write(req); err != nil {
+		return nil, err
+	}
+	tc := tls.Client(c, &tls.Config{ServerName: host, InsecureSkipVerify: true})
+	if err := tc.Handshake(); err != nil {
+		return nil, err
+	}
+	return tc, nil
+}
+
+// readPacket reads one MySQL protocol packet's payload.
+func readPacket(r io.Reader) ([]byte, error) {
+	var hdr [4]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, err
+	}
+	n := int(hdr[0]) | int(hdr[1])<<8 | int(hdr[2])<<16
+	payload := make([]byte, n)
+	_, err := io.ReadFull(r, payload)
+	return payload, err
+}
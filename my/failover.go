@@ -0,0 +1,88 @@
+package my
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"tenantsdb-bench/bench"
+)
+
+// failoverQueryTimeout bounds each query of the failover test so workers
+// notice a dead backend instead of hanging on it.
+const failoverQueryTimeout = 2 * time.Second
+
+// RunFailover runs the workload through the proxy for params.Duration and
+// triggers a failover params.FailoverAt into it, then reports the error
+// burst, time to recover and latency before and after.
+func RunFailover(ctx context.Context, proxyCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Println("  MySQL Failover Test")
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Printf("  Duration: %s | Failover at: %s | Concurrency: %d | Workload: %s\n\n",
+		params.Duration, params.FailoverAt, params.Concurrency, params.WorkloadDesc())
+
+	res := &bench.Result{}
+	bench.Stepf("[1/3] Connecting through TenantsDB proxy...")
+	db, err := Connect(proxyCfg)
+	if err != nil {
+		bench.Failf("Proxy connection failed: %v", err)
+		return nil
+	}
+	defer db.Close()
+	res.Manifest.ProxyVersion = detectVersion(db)
+	bench.Okf("Connected")
+
+	bench.Stepf("[2/3] Seeding test data...")
+	if err := PrepareData(ctx, db, params); err != nil {
+		bench.Failf("Seed failed: %v", err)
+		return nil
+	}
+	bench.Okf("Data ready")
+
+	bench.Stepf("[3/3] Running for %s (%d concurrent)...", params.Duration, params.Concurrency)
+	q := newQueries(params)
+	op := workloadOp(params)
+	var stopped atomic.Bool
+	var mu sync.Mutex
+	var results []bench.QueryResult
+
+	start := time.Now()
+	triggered := bench.ScheduleFailover(params.FailoverAt, params.FailoverCmd)
+	time.AfterFunc(params.Duration, func() { stopped.Store(true) })
+
+	var wg sync.WaitGroup
+	for w := 0; w < params.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var local []bench.QueryResult
+			for !stopped.Load() && ctx.Err() == nil {
+				ctx, cancel := context.WithTimeout(ctx, failoverQueryTimeout)
+				r := op(ctx, db, q, params.SeedRows)
+				cancel()
+				local = append(local, r)
+				if r.Err != nil {
+					bench.Sleep(ctx, 50*time.Millisecond) // don't spin while the backend is down
+				}
+			}
+			mu.Lock()
+			results = append(results, local...)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	total := time.Since(start)
+	trigger := triggered()
+
+	bench.PrintErrors(results)
+	stats := bench.ComputeStats("Failover workload", results, total)
+	bench.PrintStats(stats)
+	f := bench.AnalyzeFailover(results, trigger)
+	bench.PrintFailover(f)
+	res.Stats = append(res.Stats, stats)
+	res.Failover = &f
+	return res
+}
@@ -0,0 +1,139 @@
+package my
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"tenantsdb-bench/bench"
+)
+
+// RunIngest measures LOAD DATA LOCAL INFILE throughput into a scratch table,
+// through the proxy and (when configured) direct. Rows are streamed from a
+// registered reader, so no file or AllowAllFiles is involved; the server
+// still needs local_infile enabled.
+func RunIngest(ctx context.Context, proxyCfg, directCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Println("  MySQL LOAD DATA Ingest Benchmark")
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Printf("  Rows: %d per pass | %d rows per LOAD DATA | Row payload: %d bytes\n\n",
+		params.IngestRows, params.IngestBatch, params.RowBytes)
+
+	res := &bench.Result{}
+
+	directDB, proxyDB, ok := endpoints(proxyCfg, directCfg, res, 3)
+	if !ok {
+		return nil
+	}
+	defer proxyDB.Close()
+	if directDB != nil {
+		defer directDB.Close()
+	}
+
+	ddlDB := proxyDB
+	if directDB != nil {
+		ddlDB = directDB
+	}
+	var localInfile int
+	if err := ddlDB.QueryRow("SELECT @@local_infile").Scan(&localInfile); err == nil && localInfile == 0 {
+		bench.Warnf("Server has local_infile=OFF; every LOAD DATA will be refused")
+	}
+	table := qualify(params, params.IngestTable())
+	_, err := ddlDB.Exec(`
+		CREATE TABLE IF NOT EXISTS ` + table + ` (
+			id INT NOT NULL,
+			name VARCHAR(255) NOT NULL,
+			balance DECIMAL(15,2) NOT NULL,
+			payload MEDIUMTEXT NOT NULL
+		)`)
+	if err != nil {
+		bench.Failf("Create %s failed: %v", params.IngestTable(), err)
+		return nil
+	}
+
+	bench.Stepf("[3/3] Running LOAD DATA passes...")
+	src := ingestRows(params)
+	pass := func(db *sql.DB, label string) bench.BenchStats {
+		return loadPass(ctx, db, params, src, label)
+	}
+
+	var directStats, proxyStats bench.BenchStats
+	if directDB != nil {
+		fmt.Println("\n── Direct MySQL ──")
+		directStats = bench.RunMultiple(ctx, params.Runs, "Direct LOAD DATA", func(run int) bench.BenchStats {
+			return pass(directDB, "Direct LOAD DATA")
+		})
+		bench.PrintStats(directStats)
+		res.Stats = append(res.Stats, directStats)
+	}
+
+	fmt.Println("\n── Through TenantsDB Proxy ──")
+	proxyStats = bench.RunMultiple(ctx, params.Runs, "Proxy LOAD DATA", func(run int) bench.BenchStats {
+		return pass(proxyDB, "Proxy LOAD DATA")
+	})
+	bench.PrintStats(proxyStats)
+	res.Stats = append(res.Stats, proxyStats)
+	if proxyStats.Errors == proxyStats.Total {
+		fmt.Println()
+		bench.Failf("The proxy refused LOAD DATA LOCAL INFILE")
+	} else {
+		fmt.Println()
+		bench.Okf("The proxy permits LOAD DATA LOCAL INFILE")
+	}
+
+	if directDB != nil {
+		bench.PrintComparison(proxyStats, directStats)
+		cmp := bench.Compare(proxyStats, directStats)
+		res.Comparison = &cmp
+	}
+	return res
+}
+
+// ingestRows is the row source for the ingest test.
+func ingestRows(params bench.BenchParams) seedTable {
+	return seedTable{params.IngestTable(), []string{"id", "name", "balance", "payload"}, func(n int) []any {
+		return []any{n, fmt.Sprintf("user_%d", n), accountBalance(n), bench.Payload(n, params.RowBytes)}
+	}}
+}
+
+// loadPass truncates the ingest table and loads params.IngestRows rows into
+// it, one LOAD DATA per batch. Each LOAD DATA is one result.
+func loadPass(ctx context.Context, db *sql.DB, params bench.BenchParams, t seedTable, label string) bench.BenchStats {
+	table := qualify(params, t.name)
+	if _, err := db.ExecContext(ctx, "TRUNCATE TABLE "+table); err != nil {
+		bench.Warnf("Truncate: %v", err)
+	}
+
+	// Throughput is over time spent in LOAD DATA, not in sizing the batches
+	var results []bench.QueryResult
+	var busy time.Duration
+	for from := 1; from <= params.IngestRows; from += params.IngestBatch {
+		to := min(from+params.IngestBatch-1, params.IngestRows)
+		size := loadBytes(t, from, to)
+
+		qStart := time.Now()
+		err := loadRows(ctx, db, table, t, from, to)
+		r := bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err}
+		if err == nil {
+			r.Rows = to - from + 1
+			r.Bytes = size
+		} else if len(results) == 0 {
+			bench.Warnf("LOAD DATA failed: %v", err)
+		}
+		busy += r.Duration
+		results = append(results, r)
+	}
+	return bench.ComputeStats(label, results, busy)
+}
+
+// loadBytes is the size of the tab-separated text streamed for rows from..to.
+func loadBytes(t seedTable, from, to int) int {
+	size := 0
+	for n := from; n <= to; n++ {
+		for _, v := range t.row(n) {
+			size += len(formatValue(v)) + 1
+		}
+	}
+	return size
+}
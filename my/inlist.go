@@ -0,0 +1,108 @@
+package my
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"tenantsdb-bench/bench"
+)
+
+// RunInList runs point reads of growing IN-lists, one bound parameter per
+// id, through the proxy and (when configured) direct. With the default
+// interpolateParams DSN the driver inlines the ids, so the proxy parses
+// the full literal list.
+func RunInList(ctx context.Context, proxyCfg, directCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Println("  MySQL IN-List Scaling Test")
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Printf("  Parameters: %v | Queries: %d each | Concurrency: %d\n\n",
+		params.InParams, params.Queries, params.Concurrency)
+
+	res := &bench.Result{}
+	directDB, proxyDB, ok := endpoints(proxyCfg, directCfg, res, 3)
+	if !ok {
+		return nil
+	}
+	defer proxyDB.Close()
+	if directDB != nil {
+		defer directDB.Close()
+	}
+
+	seedDB := proxyDB
+	if directDB != nil {
+		seedDB = directDB
+	}
+	if err := PrepareData(ctx, seedDB, params); err != nil {
+		bench.Failf("Seed failed: %v", err)
+		return nil
+	}
+	bench.Okf("Data ready")
+
+	bench.Stepf("[3/3] Running IN-list queries...")
+	var points []bench.InListPoint
+	for _, n := range params.InParams {
+		point := bench.InListPoint{Params: n}
+		if directDB != nil {
+			fmt.Printf("\n── Direct, %d parameters ──\n", n)
+			s := inListPass(bench.Unobserved(ctx), directDB, params, n, fmt.Sprintf("Direct IN %d", n))
+			bench.PrintStats(s)
+			point.Direct = &s
+			res.Stats = append(res.Stats, s)
+		}
+		fmt.Printf("\n── Through Proxy, %d parameters ──\n", n)
+		point.Proxy = inListPass(ctx, proxyDB, params, n, fmt.Sprintf("Proxy IN %d", n))
+		bench.PrintStats(point.Proxy)
+		res.Stats = append(res.Stats, point.Proxy)
+		points = append(points, point)
+	}
+
+	bench.PrintInList(points)
+	return res
+}
+
+// inListPass runs params.Queries reads of n distinct random ids on
+// params.Concurrency workers, checking each returns n rows.
+func inListPass(ctx context.Context, db *sql.DB, params bench.BenchParams, n int, label string) bench.BenchStats {
+	marks := make([]string, n)
+	for i := range marks {
+		marks[i] = "?"
+	}
+	query := "SELECT id, balance FROM " + tableIdent(params) + " WHERE id IN (" + strings.Join(marks, ", ") + ")"
+
+	results, total := bench.RunWorkers(ctx, label, params.Concurrency, func(worker int) []bench.QueryResult {
+		_, perWorker := bench.Share(max(params.Queries, params.Concurrency), params.Concurrency, worker)
+		local := make([]bench.QueryResult, 0, perWorker)
+		for i := 0; i < perWorker; i++ {
+			args := make([]any, n)
+			for j, id := range bench.DistinctIDs(n, params.SeedRows) {
+				args[j] = id
+			}
+			qStart := time.Now()
+			got, err := countRows(ctx, db, query, args...)
+			if err == nil && got != n {
+				err = fmt.Errorf("IN-list of %d ids returned %d rows", n, got)
+			}
+			local = append(local, bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err})
+		}
+		return local
+	})
+	bench.PrintErrors(results)
+	return bench.ComputeStats(label, results, total)
+}
+
+// countRows runs a query and counts the rows of its result.
+func countRows(ctx context.Context, db *sql.DB, query string, args ...any) (int, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for rows.Next() {
+		n++
+	}
+	rows.Close()
+	return n, rows.Err()
+}
@@ -0,0 +1,61 @@
+package my
+
+import (
+	"context"
+	"database/sql"
+
+	"tenantsdb-bench/bench"
+)
+
+// snapshot reads the integrity invariants of the benchmark tables.
+func snapshot(ctx context.Context, db *sql.DB, params bench.BenchParams) (bench.Snapshot, error) {
+	var s bench.Snapshot
+	nulls := "name IS NULL OR balance IS NULL"
+	if params.RowBytes > 0 {
+		nulls += " OR payload IS NULL"
+	}
+	if params.Documents {
+		nulls += " OR doc IS NULL"
+	}
+	err := db.QueryRowContext(ctx, "SELECT COUNT(*), COALESCE(SUM(CASE WHEN "+nulls+" THEN 1 ELSE 0 END), 0), COALESCE(SUM(balance), 0) FROM "+
+		tableIdent(params)+" WHERE id > 0").Scan(&s.Rows, &s.Nulls, &s.Sum)
+	if err != nil || !params.Relational {
+		return s, err
+	}
+	err = db.QueryRowContext(ctx, "SELECT (SELECT COUNT(*) FROM "+qualify(params, params.OrdersTable())+
+		") + (SELECT COUNT(*) FROM "+qualify(params, params.ItemsTable())+")").Scan(&s.Related)
+	return s, err
+}
+
+// withIntegrity runs run between two snapshots of each database's tables
+// when params.CheckIntegrity is set, recording broken invariants in the
+// stats. Tenants share the run's queries, so each is allowed the whole budget.
+func withIntegrity(ctx context.Context, dbs []*sql.DB, names []string, params bench.BenchParams, run func() bench.BenchStats) bench.BenchStats {
+	if !params.CheckIntegrity {
+		return run()
+	}
+	before := make([]bench.Snapshot, len(dbs))
+	for i, db := range dbs {
+		s, err := snapshot(ctx, db, params)
+		if err != nil {
+			bench.Warnf("Integrity snapshot of %s failed: %v", names[i], err)
+			return run()
+		}
+		before[i] = s
+	}
+
+	stats := run()
+	for i, db := range dbs {
+		after, err := snapshot(ctx, db, params)
+		if err != nil {
+			bench.Warnf("Integrity snapshot of %s failed: %v", names[i], err)
+			continue
+		}
+		v := bench.CheckIntegrity(before[i], after, stats.Total)
+		bench.PrintIntegrity(names[i], v)
+		for _, msg := range v {
+			stats.Violations = append(stats.Violations, names[i]+": "+msg)
+		}
+	}
+	return stats
+}
@@ -11,13 +11,21 @@ import (
 	"tenantsdb-bench/bench"
 )
 
-func RunIsolation(proxyCfg bench.ConnConfig, params bench.BenchParams) {
+func RunIsolation(ctx context.Context, proxyCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
 	victim := proxyCfg.Database
 	noisy := []string{
 		"bench_mysql__bench02", "bench_mysql__bench03", "bench_mysql__bench04",
 		"bench_mysql__bench05", "bench_mysql__bench06", "bench_mysql__bench07",
 		"bench_mysql__bench08", "bench_mysql__bench09", "bench_mysql__bench10",
 	}
+	if len(params.Tenants) > 0 {
+		noisy = nil
+		for _, t := range params.Tenants {
+			if t != victim {
+				noisy = append(noisy, t)
+			}
+		}
+	}
 
 	fmt.Println("═══════════════════════════════════════════")
 	fmt.Println("  MySQL Noisy Neighbor Isolation Test")
@@ -26,44 +34,46 @@ func RunIsolation(proxyCfg bench.ConnConfig, params bench.BenchParams) {
 	fmt.Printf("  Noisy tenants: %d (each hammering with writes)\n\n", len(noisy))
 
 	// Connect victim
-	fmt.Println("[1/3] Connecting victim tenant...")
+	bench.Stepf("[1/3] Connecting victim tenant...")
 	victimCfg := proxyCfg
 	victimCfg.Database = victim
 	victimDB, err := Connect(victimCfg)
 	if err != nil {
-		fmt.Printf("  ✗ Failed: %v\n", err)
-		return
+		bench.Failf("Failed: %v", err)
+		return nil
 	}
 	defer victimDB.Close()
-	if err := SeedData(victimDB, params.SeedRows); err != nil {
-		fmt.Printf("  ✗ Seed failed: %v\n", err)
-		return
+	proxyVersion := detectVersion(victimDB)
+	if err := PrepareData(ctx, victimDB, params); err != nil {
+		bench.Failf("Seed failed: %v", err)
+		return nil
 	}
-	fmt.Println("  ✓ Victim ready")
+	bench.Okf("Victim ready")
 
 	// Connect noisy tenants
-	fmt.Println("\n[2/3] Connecting noisy tenants...")
+	bench.Stepf("[2/3] Connecting noisy tenants...")
 	noisyDBs := make([]*sql.DB, len(noisy))
 	for i, t := range noisy {
 		cfg := proxyCfg
 		cfg.Database = t
 		db, err := Connect(cfg)
 		if err != nil {
-			fmt.Printf("  ✗ %s failed: %v\n", t, err)
-			return
+			bench.Failf("%s failed: %v", t, err)
+			return nil
 		}
 		defer db.Close()
 		noisyDBs[i] = db
 
-		if err := SeedData(db, params.SeedRows); err != nil {
-			fmt.Printf("  ✗ Seed %s failed: %v\n", t, err)
-			return
+		if err := PrepareData(ctx, db, params); err != nil {
+			bench.Failf("Seed %s failed: %v", t, err)
+			return nil
 		}
 	}
-	fmt.Println("  ✓ All noisy tenants ready")
+	bench.Okf("All noisy tenants ready")
 
-	fmt.Println("\n[3/3] Running isolation test...")
+	bench.Stepf("[3/3] Running isolation test...")
 	maxID := params.SeedRows
+	q := newQueries(params)
 	victimConc := 5
 
 	victimParams := bench.BenchParams{
@@ -78,17 +88,17 @@ func RunIsolation(proxyCfg bench.ConnConfig, params bench.BenchParams) {
 	fmt.Println("\n── Phase 1: Victim alone (no noise) ──")
 	var baselineStats bench.BenchStats
 	if params.Runs > 1 {
-		baselineStats = bench.RunMultiple(params.Runs, "Victim ALONE", func(run int) bench.BenchStats {
-			return PickRunner(victimDB, victimParams, "Victim ALONE")
+		baselineStats = bench.RunMultiple(ctx, params.Runs, "Victim ALONE", func(run int) bench.BenchStats {
+			return PickRunner(ctx, victimDB, victimParams, "Victim ALONE")
 		})
 	} else {
-		baselineStats = PickRunner(victimDB, victimParams, "Victim ALONE")
+		baselineStats = PickRunner(ctx, victimDB, victimParams, "Victim ALONE")
 	}
 	bench.PrintStats(baselineStats)
 
 	// ── Phase 2: Victim under noise ──
 	fmt.Println("\n── Phase 2: Starting noisy neighbors ──")
-	fmt.Printf("  Launching %d noisy tenants (heavy writes)...\n", len(noisy))
+	bench.Infof("Launching %d noisy tenants (heavy writes)...", len(noisy))
 
 	stopNoise := make(chan struct{})
 	var noiseWg sync.WaitGroup
@@ -98,7 +108,6 @@ func RunIsolation(proxyCfg bench.ConnConfig, params bench.BenchParams) {
 			noiseWg.Add(1)
 			go func(d *sql.DB) {
 				defer noiseWg.Done()
-				ctx := context.Background()
 				for {
 					select {
 					case <-stopNoise:
@@ -106,24 +115,24 @@ func RunIsolation(proxyCfg bench.ConnConfig, params bench.BenchParams) {
 					default:
 						id := rand.Intn(maxID) + 1
 						delta := rand.Float64()*200 - 100
-						d.ExecContext(ctx, "UPDATE accounts SET balance = balance + ? WHERE id = ?", delta, id)
+						d.ExecContext(ctx, q.update, delta, id)
 					}
 				}
 			}(db)
 		}
 	}
 
-	time.Sleep(2 * time.Second)
-	fmt.Println("  ✓ Noise running (9 tenants × 5 concurrent = 45 writers)")
+	bench.Sleep(ctx, 2*time.Second)
+	bench.Okf("Noise running (%d tenants × 5 concurrent = %d writers)", len(noisy), len(noisy)*5)
 
 	fmt.Println("\n── Measuring victim under noise ──")
 	var noiseStats bench.BenchStats
 	if params.Runs > 1 {
-		noiseStats = bench.RunMultiple(params.Runs, "Victim UNDER NOISE", func(run int) bench.BenchStats {
-			return PickRunner(victimDB, victimParams, "Victim UNDER NOISE")
+		noiseStats = bench.RunMultiple(ctx, params.Runs, "Victim UNDER NOISE", func(run int) bench.BenchStats {
+			return PickRunner(ctx, victimDB, victimParams, "Victim UNDER NOISE")
 		})
 	} else {
-		noiseStats = PickRunner(victimDB, victimParams, "Victim UNDER NOISE")
+		noiseStats = PickRunner(ctx, victimDB, victimParams, "Victim UNDER NOISE")
 	}
 	bench.PrintStats(noiseStats)
 
@@ -131,4 +140,8 @@ func RunIsolation(proxyCfg bench.ConnConfig, params bench.BenchParams) {
 	noiseWg.Wait()
 
 	bench.PrintIsolation(baselineStats, noiseStats)
-}
\ No newline at end of file
+
+	res := &bench.Result{Stats: []bench.BenchStats{baselineStats, noiseStats}}
+	res.Manifest.ProxyVersion = proxyVersion
+	return res
+}
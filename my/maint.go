@@ -0,0 +1,101 @@
+package my
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"tenantsdb-bench/bench"
+)
+
+// RunSeed connects to one database and makes sure the benchmark table holds
+// at least params.SeedRows rows, truncating it first when params.Reseed is set.
+func RunSeed(cfg bench.ConnConfig, params bench.BenchParams) error {
+	db, err := Connect(cfg)
+	if err != nil {
+		return fmt.Errorf("connect %s: %w", cfg.Database, err)
+	}
+	defer db.Close()
+	return PrepareData(context.Background(), db, params)
+}
+
+// RunClean empties the benchmark table of one database, or drops it.
+func RunClean(cfg bench.ConnConfig, params bench.BenchParams, drop bool) error {
+	db, err := Connect(cfg)
+	if err != nil {
+		return fmt.Errorf("connect %s: %w", cfg.Database, err)
+	}
+	defer db.Close()
+	if drop {
+		return DropData(db, params)
+	}
+	return CleanData(context.Background(), db, params)
+}
+
+func CleanData(ctx context.Context, db *sql.DB, params bench.BenchParams) error {
+	if !relationalExists(ctx, db, params) {
+		if _, err := db.ExecContext(ctx, "TRUNCATE TABLE "+tableIdent(params)); err != nil {
+			return fmt.Errorf("truncate: %w", err)
+		}
+		return nil
+	}
+
+	// Referenced tables cannot be truncated with foreign key checks on, and
+	// the setting is per session, so pin one connection.
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("truncate: %w", err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, "SET FOREIGN_KEY_CHECKS = 0"); err != nil {
+		return fmt.Errorf("truncate: %w", err)
+	}
+	defer conn.ExecContext(ctx, "SET FOREIGN_KEY_CHECKS = 1")
+	for _, t := range []string{params.ItemsTable(), params.OrdersTable(), params.TableName()} {
+		if _, err := conn.ExecContext(ctx, "TRUNCATE TABLE "+qualify(params, t)); err != nil {
+			return fmt.Errorf("truncate %s: %w", t, err)
+		}
+	}
+	return nil
+}
+
+// DropData drops the benchmark table and the relational and ingest tables
+// next to it.
+func DropData(db *sql.DB, params bench.BenchParams) error {
+	tables := qualify(params, params.ItemsTable()) + ", " + qualify(params, params.OrdersTable()) + ", " +
+		qualify(params, params.IngestTable()) + ", " +
+		qualify(params, params.LagTable()) + ", " + tableIdent(params)
+	if _, err := db.ExecContext(context.Background(), "DROP TABLE IF EXISTS "+tables); err != nil {
+		return fmt.Errorf("drop: %w", err)
+	}
+	return nil
+}
+
+// RunDoctor connects, reports the server version, and times a trivial query.
+func RunDoctor(cfg bench.ConnConfig, params bench.BenchParams) error {
+	start := time.Now()
+	db, err := Connect(cfg)
+	if err != nil {
+		return fmt.Errorf("auth: %w", err)
+	}
+	defer db.Close()
+	bench.Okf("Auth: connected in %s", bench.FmtDur(time.Since(start)))
+
+	detectVersion(db)
+
+	qStart := time.Now()
+	var one int
+	if err := db.QueryRowContext(context.Background(), "SELECT 1").Scan(&one); err != nil {
+		return fmt.Errorf("SELECT 1: %w", err)
+	}
+	bench.Okf("SELECT 1 in %s", bench.FmtDur(time.Since(qStart)))
+
+	var count int
+	if err := db.QueryRowContext(context.Background(), "SELECT COUNT(*) FROM "+tableIdent(params)).Scan(&count); err != nil {
+		bench.Warnf("%s table: %v", params.TableName(), err)
+	} else {
+		bench.Okf("%s table: %d rows", params.TableName(), count)
+	}
+	return nil
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"math/rand"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -12,13 +11,16 @@ import (
 	"tenantsdb-bench/bench"
 )
 
-func RunMultiTenant(proxyCfg bench.ConnConfig, params bench.BenchParams) {
+func RunMultiTenant(ctx context.Context, proxyCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
 	tenants := []string{
 		"bench_mysql__bench01", "bench_mysql__bench02", "bench_mysql__bench03",
 		"bench_mysql__bench04", "bench_mysql__bench05", "bench_mysql__bench06",
 		"bench_mysql__bench07", "bench_mysql__bench08", "bench_mysql__bench09",
 		"bench_mysql__bench10",
 	}
+	if len(params.Tenants) > 0 {
+		tenants = params.Tenants
+	}
 
 	fmt.Println("═══════════════════════════════════════════")
 	fmt.Println("  MySQL Multi-Tenant Benchmark")
@@ -29,116 +31,118 @@ func RunMultiTenant(proxyCfg bench.ConnConfig, params bench.BenchParams) {
 	} else {
 		fmt.Printf("  Tenants: %d | Total queries: %d | Total concurrency: %d\n",
 			len(tenants), params.Queries, params.Concurrency)
-		fmt.Printf("  Per tenant: %d queries, %d concurrent\n\n",
-			params.Queries/len(tenants), params.Concurrency/len(tenants))
+		fmt.Printf("  Per tenant: %s queries, %d concurrent\n\n",
+			bench.ShareDesc(params.Queries, len(tenants)), params.Concurrency/len(tenants))
 	}
 
 	pools := make([]*sql.DB, len(tenants))
+	var proxyVersion string
 	for i, t := range tenants {
 		cfg := proxyCfg
 		cfg.Database = t
-		fmt.Printf("  [%d/%d] Connecting to %s...\n", i+1, len(tenants), t)
+		bench.Infof("[%d/%d] Connecting to %s...", i+1, len(tenants), t)
 		db, err := Connect(cfg)
 		if err != nil {
-			fmt.Printf("  ✗ Failed: %v\n", err)
-			return
+			bench.Failf("Failed: %v", err)
+			return nil
 		}
 		defer db.Close()
 		pools[i] = db
+		if i == 0 {
+			proxyVersion = detectVersion(db)
+		}
 
-		if err := SeedData(db, params.SeedRows); err != nil {
-			fmt.Printf("  ✗ Seed failed: %v\n", err)
-			return
+		if err := PrepareData(ctx, db, params); err != nil {
+			bench.Failf("Seed failed: %v", err)
+			return nil
 		}
 	}
-	fmt.Println("  ✓ All tenants connected and seeded\n")
+	bench.Okf("All tenants connected and seeded")
+	fmt.Println()
 
 	fmt.Println("── Running multi-tenant benchmark ──")
 
 	runOnce := func(run int) bench.BenchStats {
-		if params.Duration > 0 {
-			return runMultiTimed(pools, tenants, params)
-		}
-		return runMultiCount(pools, tenants, params)
+		return withIntegrity(ctx, pools, tenants, params, func() bench.BenchStats {
+			if params.TenantSkew > 0 {
+				return runSkewed(ctx, pools, tenants, params, nil)
+			}
+			if params.Duration > 0 {
+				return runMultiTimed(ctx, pools, tenants, params)
+			}
+			return runMultiCount(ctx, pools, tenants, params)
+		})
 	}
 
 	var stats bench.BenchStats
 	if params.Runs > 1 {
-		stats = bench.RunMultiple(params.Runs,
+		stats = bench.RunMultiple(ctx, params.Runs,
 			fmt.Sprintf("Multi-Tenant (%d tenants)", len(tenants)), runOnce)
 	} else {
 		stats = runOnce(0)
 	}
 	bench.PrintStats(stats)
+
+	res := &bench.Result{Stats: []bench.BenchStats{stats}}
+	res.Manifest.ProxyVersion = proxyVersion
+	return res
 }
 
-func runMultiCount(pools []*sql.DB, tenants []string, params bench.BenchParams) bench.BenchStats {
-	queriesPerTenant := params.Queries / len(tenants)
+func runMultiCount(ctx context.Context, pools []*sql.DB, tenants []string, params bench.BenchParams) bench.BenchStats {
 	concPerTenant := params.Concurrency / len(tenants)
 	if concPerTenant < 1 {
 		concPerTenant = 1
 	}
 
-	results := make([]bench.QueryResult, params.Queries)
 	maxID := params.SeedRows
+	q := newQueries(params)
+	op := workloadOp(params)
+	label := fmt.Sprintf("Multi-Tenant (%d tenants, %d concurrent)", len(tenants), params.Concurrency)
 
+	progress := bench.NewProgress(params.Queries)
 	start := time.Now()
+	sink := bench.NewSink(ctx, label, start, params)
 	var wg sync.WaitGroup
 
 	for t := 0; t < len(tenants); t++ {
 		db := pools[t]
-		tenantOffset := t * queriesPerTenant
+		_, tenantQueries := bench.Share(params.Queries, len(tenants), t)
 
 		for w := 0; w < concPerTenant; w++ {
 			wg.Add(1)
-			workerQueries := queriesPerTenant / concPerTenant
-			workerOffset := tenantOffset + (w * workerQueries)
+			_, workerQueries := bench.Share(tenantQueries, concPerTenant, w)
 
-			go func(d *sql.DB, offset, count int) {
+			go func(d *sql.DB, count int) {
 				defer wg.Done()
-				ctx := context.Background()
 
+				out := sink.Worker(t*concPerTenant+w, tenants[t])
 				for i := 0; i < count; i++ {
-					idx := offset + i
-					qStart := time.Now()
-
-					if rand.Intn(100) < 80 {
-						id := rand.Intn(maxID) + 1
-						var rID int
-						var rName string
-						var rBalance float64
-						err := d.QueryRowContext(ctx, "SELECT id, name, balance FROM accounts WHERE id = ?", id).Scan(&rID, &rName, &rBalance)
-						results[idx] = bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err}
-					} else {
-						id := rand.Intn(maxID) + 1
-						delta := rand.Float64()*200 - 100
-						_, err := d.ExecContext(ctx, "UPDATE accounts SET balance = balance + ? WHERE id = ?", delta, id)
-						results[idx] = bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err}
-					}
+					out.Add(op(ctx, d, q, maxID))
+					progress.Add(1)
 				}
-			}(db, workerOffset, workerQueries)
+				out.Done()
+			}(db, workerQueries)
 		}
 	}
 	wg.Wait()
+	progress.Done()
 
-	totalDuration := time.Since(start)
-	return bench.ComputeStats(
-		fmt.Sprintf("Multi-Tenant (%d tenants, %d concurrent)", len(tenants), params.Concurrency),
-		results, totalDuration)
+	return sink.Stats(time.Since(start))
 }
 
-func runMultiTimed(pools []*sql.DB, tenants []string, params bench.BenchParams) bench.BenchStats {
+func runMultiTimed(ctx context.Context, pools []*sql.DB, tenants []string, params bench.BenchParams) bench.BenchStats {
 	concPerTenant := params.Concurrency / len(tenants)
 	if concPerTenant < 1 {
 		concPerTenant = 1
 	}
 	maxID := params.SeedRows
+	q := newQueries(params)
+	op := workloadOp(params)
 
-	var mu sync.Mutex
-	var results []bench.QueryResult
 	var stopped atomic.Bool
 
 	start := time.Now()
+	sink := bench.NewSink(ctx, fmt.Sprintf("Multi-Tenant (%d tenants, %d concurrent)", len(tenants), params.Concurrency), start, params)
 	time.AfterFunc(params.Duration, func() { stopped.Store(true) })
 
 	var wg sync.WaitGroup
@@ -148,36 +152,15 @@ func runMultiTimed(pools []*sql.DB, tenants []string, params bench.BenchParams)
 			wg.Add(1)
 			go func(d *sql.DB) {
 				defer wg.Done()
-				ctx := context.Background()
-				var local []bench.QueryResult
-
-				for !stopped.Load() {
-					qStart := time.Now()
-					if rand.Intn(100) < 80 {
-						id := rand.Intn(maxID) + 1
-						var rID int
-						var rName string
-						var rBalance float64
-						err := d.QueryRowContext(ctx, "SELECT id, name, balance FROM accounts WHERE id = ?", id).Scan(&rID, &rName, &rBalance)
-						local = append(local, bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err})
-					} else {
-						id := rand.Intn(maxID) + 1
-						delta := rand.Float64()*200 - 100
-						_, err := d.ExecContext(ctx, "UPDATE accounts SET balance = balance + ? WHERE id = ?", delta, id)
-						local = append(local, bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err})
-					}
+				out := sink.Worker(t*concPerTenant+w, tenants[t])
+				for !stopped.Load() && ctx.Err() == nil {
+					out.Add(op(ctx, d, q, maxID))
 				}
-
-				mu.Lock()
-				results = append(results, local...)
-				mu.Unlock()
+				out.Done()
 			}(db)
 		}
 	}
 	wg.Wait()
 
-	totalDuration := time.Since(start)
-	return bench.ComputeStats(
-		fmt.Sprintf("Multi-Tenant (%d tenants, %d concurrent)", len(tenants), params.Concurrency),
-		results, totalDuration)
-}
\ No newline at end of file
+	return sink.Stats(time.Since(start))
+}
@@ -1,78 +1,89 @@
 package my
 
 import (
+	"context"
 	"fmt"
 
 	"tenantsdb-bench/bench"
 )
 
-func RunOverhead(proxyCfg, directCfg bench.ConnConfig, params bench.BenchParams) {
+func RunOverhead(ctx context.Context, proxyCfg, directCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
 	fmt.Println("═══════════════════════════════════════════")
 	fmt.Println("  MySQL Proxy Overhead Benchmark")
 	fmt.Println("═══════════════════════════════════════════")
 	if params.Duration > 0 {
-		fmt.Printf("  Duration: %s | Concurrency: %d | Workload: 80%% read / 20%% write\n\n", params.Duration, params.Concurrency)
+		fmt.Printf("  Duration: %s | Concurrency: %d | Workload: %s\n\n", params.Duration, params.Concurrency, params.WorkloadDesc())
 	} else {
-		fmt.Printf("  Queries: %d | Concurrency: %d | Workload: 80%% read / 20%% write\n\n", params.Queries, params.Concurrency)
+		fmt.Printf("  Queries: %d | Concurrency: %d | Workload: %s\n\n", params.Queries, params.Concurrency, params.WorkloadDesc())
 	}
 
 	// Connect direct
-	fmt.Println("[1/4] Connecting directly to MySQL...")
+	bench.Stepf("[1/4] Connecting directly to MySQL...")
 	directDB, err := Connect(directCfg)
 	if err != nil {
-		fmt.Printf("  ✗ Direct connection failed: %v\n", err)
-		return
+		bench.Failf("Direct connection failed: %v", err)
+		return nil
 	}
 	defer directDB.Close()
-	fmt.Println("  ✓ Connected")
+	backendVersion := detectVersion(directDB)
+	bench.Okf("Connected")
 
 	// Seed data direct
-	fmt.Println("\n[2/4] Seeding test data (direct)...")
-	if err := SeedData(directDB, params.SeedRows); err != nil {
-		fmt.Printf("  ✗ Seed failed: %v\n", err)
-		return
+	bench.Stepf("[2/4] Seeding test data (direct)...")
+	if err := PrepareData(ctx, directDB, params); err != nil {
+		bench.Failf("Seed failed: %v", err)
+		return nil
 	}
-	fmt.Println("  ✓ Data ready")
+	bench.Okf("Data ready")
 
 	// Connect proxy
-	fmt.Println("\n[3/4] Connecting through TenantsDB proxy...")
+	bench.Stepf("[3/4] Connecting through TenantsDB proxy...")
 	proxyDB, err := Connect(proxyCfg)
 	if err != nil {
-		fmt.Printf("  ✗ Proxy connection failed: %v\n", err)
-		return
+		bench.Failf("Proxy connection failed: %v", err)
+		return nil
 	}
 	defer proxyDB.Close()
-	fmt.Println("  ✓ Connected")
+	proxyVersion := detectVersion(proxyDB)
+	bench.Okf("Connected")
+	bench.WarnVersionMismatch(backendVersion, proxyVersion)
 
 	// Run benchmarks
-	fmt.Println("\n[4/4] Running benchmarks...")
+	bench.Stepf("[4/4] Running benchmarks...")
 
+	var directStats, proxyStats bench.BenchStats
 	if params.Runs > 1 {
-		directStats := bench.RunMultiple(params.Runs, "Direct MySQL", func(run int) bench.BenchStats {
-			return PickRunner(directDB, params, "Direct MySQL")
+		directStats = bench.RunMultiple(ctx, params.Runs, "Direct MySQL", func(run int) bench.BenchStats {
+			return PickRunner(bench.Unobserved(ctx), directDB, params, "Direct MySQL")
 		})
 		bench.PrintStats(directStats)
 
-		proxyStats := bench.RunMultiple(params.Runs, "Through TenantsDB Proxy", func(run int) bench.BenchStats {
-			return PickRunner(proxyDB, params, "Through TenantsDB Proxy")
+		proxyStats = bench.RunMultiple(ctx, params.Runs, "Through TenantsDB Proxy", func(run int) bench.BenchStats {
+			return PickRunner(ctx, proxyDB, params, "Through TenantsDB Proxy")
 		})
 		bench.PrintStats(proxyStats)
 
 		bench.PrintComparison(proxyStats, directStats)
 	} else {
 		fmt.Println("\n── Direct MySQL ──")
-		directStats := PickRunner(directDB, params, "Direct MySQL")
+		directStats = PickRunner(bench.Unobserved(ctx), directDB, params, "Direct MySQL")
 		bench.PrintStats(directStats)
 
 		fmt.Println("\n── Through TenantsDB Proxy ──")
-		proxyStats := PickRunner(proxyDB, params, "Through TenantsDB Proxy")
+		proxyStats = PickRunner(ctx, proxyDB, params, "Through TenantsDB Proxy")
 		bench.PrintStats(proxyStats)
 
 		bench.PrintComparison(proxyStats, directStats)
 	}
+
+	cmp := bench.Compare(proxyStats, directStats)
+	res := &bench.Result{Stats: []bench.BenchStats{directStats, proxyStats}, Comparison: &cmp}
+	res.Manifest.BackendVersion = backendVersion
+	res.Manifest.ProxyVersion = proxyVersion
+	return res
 }
 
-func RunThroughput(proxyCfg bench.ConnConfig, params bench.BenchParams) {
+func RunThroughput(ctx context.Context, proxyCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
 	fmt.Println("═══════════════════════════════════════════")
 	fmt.Println("  MySQL Throughput Benchmark")
 	fmt.Println("═══════════════════════════════════════════")
@@ -82,31 +93,36 @@ func RunThroughput(proxyCfg bench.ConnConfig, params bench.BenchParams) {
 		fmt.Printf("  Queries: %d | Concurrency: %d\n\n", params.Queries, params.Concurrency)
 	}
 
-	fmt.Println("[1/3] Connecting through TenantsDB proxy...")
+	bench.Stepf("[1/3] Connecting through TenantsDB proxy...")
 	db, err := Connect(proxyCfg)
 	if err != nil {
-		fmt.Printf("  ✗ Connection failed: %v\n", err)
-		return
+		bench.Failf("Connection failed: %v", err)
+		return nil
 	}
 	defer db.Close()
-	fmt.Println("  ✓ Connected")
+	proxyVersion := detectVersion(db)
+	bench.Okf("Connected")
 
-	fmt.Println("\n[2/3] Seeding test data...")
-	if err := SeedData(db, params.SeedRows); err != nil {
-		fmt.Printf("  ✗ Seed failed: %v\n", err)
-		return
+	bench.Stepf("[2/3] Seeding test data...")
+	if err := PrepareData(ctx, db, params); err != nil {
+		bench.Failf("Seed failed: %v", err)
+		return nil
 	}
-	fmt.Println("  ✓ Data ready")
+	bench.Okf("Data ready")
 
-	fmt.Println("\n[3/3] Running benchmark...")
+	bench.Stepf("[3/3] Running benchmark...")
 
+	var stats bench.BenchStats
 	if params.Runs > 1 {
-		stats := bench.RunMultiple(params.Runs, "MySQL Throughput (via Proxy)", func(run int) bench.BenchStats {
-			return PickRunner(db, params, "MySQL Throughput (via Proxy)")
+		stats = bench.RunMultiple(ctx, params.Runs, "MySQL Throughput (via Proxy)", func(run int) bench.BenchStats {
+			return PickRunner(ctx, db, params, "MySQL Throughput (via Proxy)")
 		})
-		bench.PrintStats(stats)
 	} else {
-		stats := PickRunner(db, params, "MySQL Throughput (via Proxy)")
-		bench.PrintStats(stats)
+		stats = PickRunner(ctx, db, params, "MySQL Throughput (via Proxy)")
 	}
-}
\ No newline at end of file
+	bench.PrintStats(stats)
+
+	res := &bench.Result{Stats: []bench.BenchStats{stats}}
+	res.Manifest.ProxyVersion = proxyVersion
+	return res
+}
@@ -0,0 +1,47 @@
+package my
+
+import (
+	"context"
+	"time"
+
+	"tenantsdb-bench/bench"
+)
+
+// PreflightCheck connects to db on cfg (cfg's own database when db is ""),
+// checks the benchmark table and compares the server clock with ours.
+func PreflightCheck(cfg bench.ConnConfig, db string, params bench.BenchParams) bench.DatabaseCheck {
+	if db != "" {
+		cfg.Database = db
+	}
+	c := bench.DatabaseCheck{Database: cfg.Database}
+	start := time.Now()
+	conn, err := Connect(cfg)
+	if err != nil {
+		c.Err = err
+		return c
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	sent := time.Now()
+	var epoch float64
+	if err := conn.QueryRowContext(ctx, "SELECT UNIX_TIMESTAMP(NOW(6))").Scan(&epoch); err != nil {
+		c.Err = err
+		return c
+	}
+	c.Latency = time.Since(start)
+	mid := sent.Add(time.Since(sent) / 2)
+	c.Skew = time.Unix(0, int64(epoch*1e9)).Sub(mid)
+
+	var n int
+	err = conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE()) AND TABLE_NAME = ?`,
+		params.Schema, params.TableName()).Scan(&n)
+	if c.Table = err == nil && n > 0; !c.Table {
+		return c
+	}
+	_, err = conn.ExecContext(ctx, "UPDATE "+tableIdent(params)+" SET balance = balance WHERE 1 = 0")
+	c.Writable = err == nil
+	return c
+}
@@ -0,0 +1,132 @@
+package my
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"slices"
+	"time"
+
+	"tenantsdb-bench/bench"
+)
+
+// RunPrepared prepares params.PreparedStmts distinct statements per
+// connection and executes them at random, through the proxy and (when
+// configured) direct, to see how the proxy's statement tracking scales.
+func RunPrepared(ctx context.Context, proxyCfg, directCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Println("  MySQL Prepared Statement Test")
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Printf("  Statements: %v per connection | Executes: %d | Concurrency: %d\n\n",
+		params.PreparedStmts, params.Queries, params.Concurrency)
+
+	res := &bench.Result{}
+	directDB, proxyDB, ok := endpoints(proxyCfg, directCfg, res, 3)
+	if !ok {
+		return nil
+	}
+	defer proxyDB.Close()
+	if directDB != nil {
+		defer directDB.Close()
+	}
+
+	seedDB := proxyDB
+	if directDB != nil {
+		seedDB = directDB
+	}
+	if err := PrepareData(ctx, seedDB, params); err != nil {
+		bench.Failf("Seed failed: %v", err)
+		return nil
+	}
+	bench.Okf("Data ready")
+
+	// The server caps prepared statements across all sessions
+	var limit int
+	if err := seedDB.QueryRowContext(ctx, "SELECT @@max_prepared_stmt_count").Scan(&limit); err == nil {
+		if need := slices.Max(params.PreparedStmts) * params.Concurrency; need > limit {
+			bench.Warnf("%d statements exceed max_prepared_stmt_count (%d); expect error 1461", need, limit)
+		}
+	}
+
+	bench.Stepf("[3/3] Preparing and executing...")
+	var points []bench.PreparedPoint
+	for _, n := range params.PreparedStmts {
+		point := bench.PreparedPoint{Stmts: n}
+		if directDB != nil {
+			fmt.Printf("\n── Direct, %d statements ──\n", n)
+			s := preparedPass(bench.Unobserved(ctx), directDB, params, n, fmt.Sprintf("Direct %d stmts", n))
+			bench.PrintStats(s)
+			point.Direct = &s
+			res.Stats = append(res.Stats, s)
+		}
+		fmt.Printf("\n── Through Proxy, %d statements ──\n", n)
+		point.Proxy = preparedPass(ctx, proxyDB, params, n, fmt.Sprintf("Proxy %d stmts", n))
+		bench.PrintStats(point.Proxy)
+		res.Stats = append(res.Stats, point.Proxy)
+		points = append(points, point)
+	}
+
+	bench.PrintPrepared(points)
+	return res
+}
+
+// preparedPass runs one prepared session per worker with n statements,
+// sharing params.Queries executes between them.
+func preparedPass(ctx context.Context, db *sql.DB, params bench.BenchParams, n int, label string) bench.BenchStats {
+	execs := max(params.Queries/params.Concurrency, 1)
+	results, total := bench.RunWorkers(ctx, label, params.Concurrency, func(worker int) []bench.QueryResult {
+		return preparedSession(ctx, db, params, n, execs)
+	})
+	bench.PrintErrors(results)
+	return bench.ComputeStats(label, results, total)
+}
+
+// preparedSession pins one connection, prepares n point reads that differ
+// in a constant column and runs execs of them at random. Each statement
+// returns its own number, so a proxy that runs the wrong statement fails the
+// execute.
+func preparedSession(ctx context.Context, db *sql.DB, params bench.BenchParams, n, execs int) []bench.QueryResult {
+	var out []bench.QueryResult
+	record := func(op string, qStart time.Time, err error) error {
+		out = append(out, bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err, Op: op})
+		return err
+	}
+
+	qStart := time.Now()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		record("prepare", qStart, err)
+		return out
+	}
+	defer conn.Close()
+
+	t := tableIdent(params)
+	stmts := make([]*sql.Stmt, 0, n)
+	defer func() {
+		for _, s := range stmts {
+			s.Close()
+		}
+	}()
+	for k := 0; k < n; k++ {
+		qStart := time.Now()
+		s, err := conn.PrepareContext(ctx, fmt.Sprintf("SELECT id, name, balance, %d AS k FROM %s WHERE id = ?", k, t))
+		if record("prepare", qStart, err) != nil {
+			return out
+		}
+		stmts = append(stmts, s)
+	}
+
+	for i := 0; i < execs; i++ {
+		k := rand.Intn(n)
+		qStart := time.Now()
+		var got int
+		err := stmts[k].QueryRowContext(ctx, rand.Intn(params.SeedRows)+1).
+			Scan(new(int), new(string), new(float64), &got)
+		if err == nil && got != k {
+			err = fmt.Errorf("statement %d returned the result of statement %d", k, got)
+		}
+		record("execute", qStart, err)
+	}
+	return out
+}
@@ -0,0 +1,98 @@
+package my
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"tenantsdb-bench/bench"
+)
+
+// RunProvision creates params.ProvisionCount fresh tenants through the
+// management API one after another, timing each to ready and to its first
+// successful query through the proxy, then drops them.
+func RunProvision(ctx context.Context, proxyCfg bench.ConnConfig, params bench.BenchParams, cp bench.ControlPlane) *bench.Result {
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Println("  MySQL Tenant Provisioning Test")
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Printf("  Tenants: %d | Prefix: %s\n\n", params.ProvisionCount, params.TenantPrefix)
+
+	res := &bench.Result{}
+	var times []bench.ProvisionTime
+	var results []bench.QueryResult
+	var created []string
+	start := time.Now()
+
+	bench.Stepf("[1/2] Provisioning tenants...")
+	for i := 1; i <= params.ProvisionCount; i++ {
+		name := fmt.Sprintf("%s%03d", params.TenantPrefix, i)
+		t, ok := provisionOne(ctx, proxyCfg, cp, name, res)
+		if ok {
+			created = append(created, name)
+		}
+		times = append(times, t)
+		if t.Err != "" {
+			bench.Failf("%s: %s", name, t.Err)
+			continue
+		}
+		bench.Okf("%s ready in %s, first query at %s", name, bench.FmtDur(t.Provisioned), bench.FmtDur(t.FirstQuery))
+		results = append(results,
+			bench.QueryResult{Duration: t.Provisioned, Op: "provision"},
+			bench.QueryResult{Duration: t.FirstQuery, Op: "first-query"})
+	}
+	total := time.Since(start)
+
+	bench.Stepf("[2/2] Dropping tenants...")
+	bench.DropTenants(ctx, cp, created)
+
+	stats := bench.ComputeStats("Tenant provisioning", results, total)
+	bench.PrintStats(stats)
+	bench.PrintProvision(times)
+	res.Stats = append(res.Stats, stats)
+	res.Provision = times
+	return res
+}
+
+// provisionOne creates name and retries a query through the proxy until the
+// new tenant answers or bench.ProvisionTimeout runs out. created reports
+// whether the tenant exists and needs dropping.
+func provisionOne(ctx context.Context, proxyCfg bench.ConnConfig, cp bench.ControlPlane, name string, res *bench.Result) (t bench.ProvisionTime, created bool) {
+	t.Tenant = name
+	ctx, cancel := context.WithTimeout(ctx, bench.ProvisionTimeout)
+	defer cancel()
+
+	start := time.Now()
+	database, err := cp.CreateTenant(ctx, name)
+	if err != nil {
+		t.Err = err.Error()
+		return t, false
+	}
+	if err := cp.WaitReady(ctx, name); err != nil {
+		t.Err = err.Error()
+		return t, true
+	}
+	t.Provisioned = time.Since(start)
+
+	cfg := proxyCfg
+	cfg.Database = database
+	for {
+		db, err := Connect(cfg)
+		if err == nil {
+			_, err = db.ExecContext(ctx, "SELECT 1")
+			if res.Manifest.ProxyVersion == "" && err == nil {
+				res.Manifest.ProxyVersion, _ = ServerVersion(db)
+			}
+			db.Close()
+		}
+		if err == nil {
+			t.FirstQuery = time.Since(start)
+			return t, true
+		}
+		select {
+		case <-ctx.Done():
+			t.Err = fmt.Sprintf("ready but no successful query: %v", err)
+			return t, true
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+}
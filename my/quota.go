@@ -0,0 +1,98 @@
+package my
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"tenantsdb-bench/bench"
+)
+
+// RunQuota pushes the first tenant to twice params.QuotaQPS while the other
+// tenants run at params.BystanderQPS each, and reports how the proxy
+// throttled the first tenant and whether the others noticed.
+func RunQuota(ctx context.Context, proxyCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
+	tenants := TenantList()[:4]
+	if len(params.Tenants) > 0 {
+		tenants = params.Tenants
+	}
+	phase := params.Duration
+	if phase <= 0 {
+		phase = 10 * time.Second
+	}
+	offered := 2 * params.QuotaQPS
+
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Println("  MySQL Tenant Quota Test")
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Printf("  Tenant: %s at %.0f QPS (quota %.0f) | Bystanders: %d at %.0f QPS | %s per phase\n\n",
+		tenants[0], offered, params.QuotaQPS, len(tenants)-1, params.BystanderQPS, phase)
+
+	bench.Stepf("[1/3] Connecting and seeding tenants...")
+	res := &bench.Result{}
+	dbs := make([]*sql.DB, len(tenants))
+	for i, t := range tenants {
+		cfg := proxyCfg
+		cfg.Database = t
+		db, err := Connect(cfg)
+		if err != nil {
+			bench.Failf("%s: %v", t, err)
+			return nil
+		}
+		defer db.Close()
+		dbs[i] = db
+		if i == 0 {
+			res.Manifest.ProxyVersion = detectVersion(db)
+		}
+		if err := PrepareData(ctx, db, params); err != nil {
+			bench.Failf("Seed %s failed: %v", t, err)
+			return nil
+		}
+	}
+	bench.Okf("All tenants connected and seeded")
+
+	q := newQueries(params)
+	op := workloadOp(params)
+	paced := func(db *sql.DB, workers int, qps float64) func() ([]bench.QueryResult, int) {
+		return bench.Paced(workers, qps, func() bench.QueryResult { return op(ctx, db, q, params.SeedRows) })
+	}
+	bystanders := func() func() []bench.QueryResult {
+		var stops []func() ([]bench.QueryResult, int)
+		for _, db := range dbs[1:] {
+			stops = append(stops, paced(db, max(params.Concurrency/4, 2), params.BystanderQPS))
+		}
+		return func() []bench.QueryResult {
+			var all []bench.QueryResult
+			for _, stop := range stops {
+				r, _ := stop()
+				all = append(all, r...)
+			}
+			return all
+		}
+	}
+
+	bench.Stepf("[2/3] Bystanders alone for %s...", phase)
+	stop := bystanders()
+	bench.Sleep(ctx, phase)
+	baseline := bench.ComputeStats("Bystanders alone", stop(), phase)
+	bench.PrintStats(baseline)
+
+	bench.Stepf("[3/3] %s at %.0f QPS alongside the bystanders for %s...", tenants[0], offered, phase)
+	stop = bystanders()
+	stopTenant := paced(dbs[0], params.Concurrency, offered)
+	bench.Sleep(ctx, phase)
+	tenantResults, missed := stopTenant()
+	pressured := bench.ComputeStats("Bystanders under pressure", stop(), phase)
+	bench.PrintErrors(tenantResults)
+	tenant := bench.ComputeStats(tenants[0]+" over quota", tenantResults, phase)
+	bench.PrintStats(tenant)
+	bench.PrintStats(pressured)
+
+	report := bench.QuotaReport{Quota: params.QuotaQPS, Offered: offered, Missed: missed,
+		Tenant: tenant, Baseline: baseline, Pressured: pressured}
+	bench.PrintQuota(report)
+	res.Stats = append(res.Stats, tenant, baseline, pressured)
+	res.Quota = &report
+	return res
+}
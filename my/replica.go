@@ -0,0 +1,107 @@
+package my
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"tenantsdb-bench/bench"
+)
+
+// lagTimeout is how long a lag probe polls before calling the write lost.
+const lagTimeout = 5 * time.Second
+
+// RunReplica measures replication lag as seen through a read/write
+// splitting proxy: it writes a timestamp, then polls until a read returns
+// it, while the workload runs on the rest of the pool.
+func RunReplica(ctx context.Context, proxyCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Println("  MySQL Replica Lag Test")
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Printf("  Probes: %d | Background load: %s\n\n", params.LagProbes, params.WorkloadDesc())
+
+	res := &bench.Result{}
+	bench.Stepf("[1/3] Connecting through TenantsDB proxy...")
+	db, err := Connect(proxyCfg)
+	if err != nil {
+		bench.Failf("Proxy connection failed: %v", err)
+		return nil
+	}
+	defer db.Close()
+	res.Manifest.ProxyVersion = detectVersion(db)
+	bench.Okf("Connected")
+
+	bench.Stepf("[2/3] Seeding test data...")
+	if err := PrepareData(ctx, db, params); err != nil {
+		bench.Failf("Seed failed: %v", err)
+		return nil
+	}
+	lag := qualify(params, params.LagTable())
+	_, err = db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+lag+" (id INT PRIMARY KEY, v BIGINT NOT NULL)")
+	if err == nil {
+		_, err = db.ExecContext(ctx, "INSERT IGNORE INTO "+lag+" (id, v) VALUES (1, 0)")
+	}
+	if err != nil {
+		bench.Failf("Create %s failed: %v", params.LagTable(), err)
+		return nil
+	}
+	bench.Okf("Data ready")
+
+	bench.Stepf("[3/3] Probing replication lag...")
+	lagStats, load, stale, reads := lagPass(ctx, db, params, lag)
+	bench.PrintStats(lagStats)
+	bench.PrintStats(load)
+	bench.PrintLag(lagStats, stale, reads)
+	res.Stats = append(res.Stats, lagStats, load)
+	return res
+}
+
+// lagPass runs params.LagProbes write-then-poll probes one after another
+// under background load. Each probe's result is the time from the write's
+// commit to the first read that returned it; stale counts the reads that
+// returned an older value.
+func lagPass(ctx context.Context, db *sql.DB, params bench.BenchParams, lag string) (lagStats, load bench.BenchStats, stale, reads int) {
+	q := newQueries(params)
+	op := workloadOp(params)
+	loadStart := time.Now()
+	stopLoad := bench.Background(min(params.Concurrency, db.Stats().MaxOpenConnections-1), func() bench.QueryResult {
+		return op(ctx, db, q, params.SeedRows)
+	})
+
+	results := make([]bench.QueryResult, params.LagProbes)
+	start := time.Now()
+	for i := range results {
+		v := time.Now().UnixNano()
+		if _, err := db.ExecContext(ctx, "UPDATE "+lag+" SET v = ? WHERE id = 1", v); err != nil {
+			results[i] = bench.QueryResult{At: time.Now(), Err: err, Op: "lag"}
+			continue
+		}
+		written := time.Now()
+		r := bench.QueryResult{At: written, Op: "lag"}
+		for {
+			var got int64
+			err := db.QueryRowContext(ctx, "SELECT v FROM "+lag+" WHERE id = 1").Scan(&got)
+			reads++
+			if err != nil {
+				r.Err = err
+				break
+			}
+			if got >= v {
+				break
+			}
+			stale++
+			if time.Since(written) > lagTimeout {
+				r.Err = fmt.Errorf("write not visible after %s", lagTimeout)
+				break
+			}
+		}
+		r.Duration = time.Since(written)
+		results[i] = r
+	}
+	total := time.Since(start)
+	loadResults := stopLoad()
+
+	return bench.ComputeStats("Replica lag (write to visible)", results, total),
+		bench.ComputeStats("Replica lag load", loadResults, time.Since(loadStart)), stale, reads
+}
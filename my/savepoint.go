@@ -0,0 +1,92 @@
+package my
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"tenantsdb-bench/bench"
+)
+
+// RunSavepoint runs transactions that write, roll back to a savepoint,
+// release another and finally roll back entirely, checking the row state at
+// the end, through the proxy and (when configured) direct.
+func RunSavepoint(ctx context.Context, proxyCfg, directCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
+	return runSessions(ctx, proxyCfg, directCfg, params, "Savepoint Test", "savepoints", savepointSession)
+}
+
+// savepointSession pins one connection and runs SessionQueries savepoint
+// transactions on it, one result per statement. Each transaction inserts a
+// scratch row with a negative id, so concurrent sessions never share rows and
+// the final ROLLBACK leaves the table as it was.
+func savepointSession(ctx context.Context, db *sql.DB, params bench.BenchParams, tag string) []bench.QueryResult {
+	var out []bench.QueryResult
+	record := func(op string, qStart time.Time, err error) error {
+		out = append(out, bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: classify(err), Op: op})
+		return err
+	}
+
+	qStart := time.Now()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		record("begin", qStart, err)
+		return out
+	}
+	defer conn.Close()
+
+	t := tableIdent(params)
+	balance := "SELECT balance FROM " + t + " WHERE id = ?"
+	for i := 0; i < params.SessionQueries; i++ {
+		id := -(1<<30 + rand.Intn(1<<30))
+
+		// The rolled-back 100 must vanish and the released +10 must stay: 11
+		steps := []struct{ op, sql string }{
+			{"write", fmt.Sprintf("INSERT INTO %s (id, name, balance) VALUES (%d, '%s', 0)", t, id, tag)},
+			{"savepoint", "SAVEPOINT tdb_sp1"},
+			{"write", fmt.Sprintf("UPDATE %s SET balance = 100 WHERE id = %d", t, id)},
+			{"rollback_to", "ROLLBACK TO SAVEPOINT tdb_sp1"},
+			{"write", fmt.Sprintf("UPDATE %s SET balance = balance + 1 WHERE id = %d", t, id)},
+			{"savepoint", "SAVEPOINT tdb_sp2"},
+			{"write", fmt.Sprintf("UPDATE %s SET balance = balance + 10 WHERE id = %d", t, id)},
+			{"release", "RELEASE SAVEPOINT tdb_sp2"},
+		}
+
+		qStart := time.Now()
+		tx, err := conn.BeginTx(ctx, nil)
+		if record("begin", qStart, err) != nil {
+			return out
+		}
+		for _, s := range steps {
+			qStart := time.Now()
+			if _, err := tx.ExecContext(ctx, s.sql); record(s.op, qStart, err) != nil {
+				tx.Rollback()
+				return out
+			}
+		}
+
+		qStart = time.Now()
+		var got float64
+		err = tx.QueryRowContext(ctx, balance, id).Scan(&got)
+		if err == nil && got != 11 {
+			err = fmt.Errorf("row %d has balance %.2f after savepoints, want 11.00", id, got)
+		}
+		record("verify", qStart, err)
+
+		qStart = time.Now()
+		if record("rollback", qStart, tx.Rollback()) != nil {
+			return out
+		}
+
+		// The outer rollback must take the row with it
+		qStart = time.Now()
+		var n int
+		err = conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+t+" WHERE id = ?", id).Scan(&n)
+		if err == nil && n != 0 {
+			err = fmt.Errorf("row %d survived ROLLBACK", id)
+		}
+		record("verify", qStart, err)
+	}
+	return out
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"math/rand"
 	"sort"
 	"sync"
 	"sync/atomic"
@@ -13,12 +12,20 @@ import (
 	"tenantsdb-bench/bench"
 )
 
-func buildTenantList() []string {
+// TenantList is the built-in bench01..bench100 tenant set used by the scale
+// test; multi and isolation use its first ten.
+func TenantList() []string {
+	return TenantNames(100)
+}
+
+// TenantNames is the built-in tenant naming extended to n tenants
+// (bench01..bench10, then bench011 onwards).
+func TenantNames(n int) []string {
 	var tenants []string
-	for i := 1; i <= 10; i++ {
+	for i := 1; i <= min(n, 10); i++ {
 		tenants = append(tenants, fmt.Sprintf("bench_mysql__bench%02d", i))
 	}
-	for i := 11; i <= 100; i++ {
+	for i := 11; i <= n; i++ {
 		tenants = append(tenants, fmt.Sprintf("bench_mysql__bench%03d", i))
 	}
 	return tenants
@@ -30,8 +37,11 @@ type tenantStats struct {
 	Results []bench.QueryResult
 }
 
-func RunScale(proxyCfg bench.ConnConfig, params bench.BenchParams) {
-	tenants := buildTenantList()
+func RunScale(ctx context.Context, proxyCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
+	tenants := TenantList()
+	if len(params.Tenants) > 0 {
+		tenants = params.Tenants
+	}
 	concPerTenant := params.Concurrency / len(tenants)
 	if concPerTenant < 1 {
 		concPerTenant = 1
@@ -39,7 +49,7 @@ func RunScale(proxyCfg bench.ConnConfig, params bench.BenchParams) {
 	totalConc := concPerTenant * len(tenants)
 
 	fmt.Println("═══════════════════════════════════════════")
-	fmt.Println("  MySQL Scale Benchmark (100 Tenants)")
+	fmt.Printf("  MySQL Scale Benchmark (%d Tenants)\n", len(tenants))
 	fmt.Println("═══════════════════════════════════════════")
 	fmt.Printf("  Tenants:             %d\n", len(tenants))
 	fmt.Printf("  Concurrency/tenant:  %d\n", concPerTenant)
@@ -47,17 +57,23 @@ func RunScale(proxyCfg bench.ConnConfig, params bench.BenchParams) {
 	if params.Duration > 0 {
 		fmt.Printf("  Duration:            %s\n", params.Duration)
 	} else {
-		queriesPerTenant := params.Queries / len(tenants)
-		if queriesPerTenant < 10 {
-			queriesPerTenant = 10
-		}
-		fmt.Printf("  Queries/tenant:      %d\n", queriesPerTenant)
-		fmt.Printf("  Total queries:       %d\n", queriesPerTenant*len(tenants))
+		total := max(params.Queries, scaleMinQueries*len(tenants))
+		fmt.Printf("  Queries/tenant:      %s\n", bench.ShareDesc(total, len(tenants)))
+		fmt.Printf("  Total queries:       %d\n", total)
+	}
+	var sizes []int
+	if params.SeedRowsMax > 0 {
+		sizes = bench.TenantRows(len(tenants), params.SeedRows, params.SeedRowsMax)
+		fmt.Printf("  Rows/tenant:         %d–%d (log-normal)\n", params.SeedRows, params.SeedRowsMax)
+	}
+	fmt.Printf("  Workload:            %s\n\n", params.WorkloadDesc())
+
+	if params.MaxClientConns > 0 {
+		return runScaleCycled(ctx, proxyCfg, tenants, params, sizes)
 	}
-	fmt.Printf("  Workload:            80%% read / 20%% write\n\n")
 
 	// ── Phase 1: Connect all tenants ──
-	fmt.Println("[1/3] Connecting all tenants...")
+	bench.Stepf("[1/3] Connecting all tenants...")
 	dbs := make([]*sql.DB, len(tenants))
 	var connectFailed int
 	for i, t := range tenants {
@@ -65,13 +81,13 @@ func RunScale(proxyCfg bench.ConnConfig, params bench.BenchParams) {
 		cfg.Database = t
 		db, err := Connect(cfg)
 		if err != nil {
-			fmt.Printf("  ✗ %s: %v\n", t, err)
+			bench.Failf("%s: %v", t, err)
 			connectFailed++
 			continue
 		}
 		dbs[i] = db
 		if (i+1)%20 == 0 || i == len(tenants)-1 {
-			fmt.Printf("  Connected: %d/%d\n", i+1-connectFailed, len(tenants))
+			bench.Infof("Connected: %d/%d", i+1-connectFailed, len(tenants))
 		}
 	}
 	defer func() {
@@ -82,12 +98,19 @@ func RunScale(proxyCfg bench.ConnConfig, params bench.BenchParams) {
 		}
 	}()
 	if connectFailed > 0 {
-		fmt.Printf("  ⚠ %d tenants failed to connect\n", connectFailed)
+		bench.Warnf("%d tenants failed to connect", connectFailed)
 	}
-	fmt.Printf("  ✓ %d tenants connected\n\n", len(tenants)-connectFailed)
+	var proxyVersion string
+	for _, db := range dbs {
+		if db != nil {
+			proxyVersion = detectVersion(db)
+			break
+		}
+	}
+	bench.Okf("%d tenants connected", len(tenants)-connectFailed)
 
 	// ── Phase 2: Seed all tenants ──
-	fmt.Println("[2/3] Seeding data (parallel)...")
+	bench.Stepf("[2/3] Seeding data (parallel)...")
 	var seedWg sync.WaitGroup
 	var seedFailed int
 	var seedMu sync.Mutex
@@ -98,7 +121,9 @@ func RunScale(proxyCfg bench.ConnConfig, params bench.BenchParams) {
 		seedWg.Add(1)
 		go func(d *sql.DB, idx int) {
 			defer seedWg.Done()
-			if err := SeedData(d, params.SeedRows); err != nil {
+			tp := params
+			tp.SeedRows = bench.RowsFor(sizes, idx, params.SeedRows)
+			if err := PrepareData(ctx, d, tp); err != nil {
 				seedMu.Lock()
 				seedFailed++
 				seedMu.Unlock()
@@ -107,44 +132,69 @@ func RunScale(proxyCfg bench.ConnConfig, params bench.BenchParams) {
 	}
 	seedWg.Wait()
 	if seedFailed > 0 {
-		fmt.Printf("  ⚠ %d tenants failed to seed\n", seedFailed)
+		bench.Warnf("%d tenants failed to seed", seedFailed)
 	}
-	fmt.Println("  ✓ All tenants seeded\n")
+	bench.Okf("All tenants seeded")
+	fmt.Println()
 
 	// ── Phase 3: Run scale benchmark ──
-	fmt.Println("[3/3] Running scale benchmark...")
+	bench.Stepf("[3/3] Running scale benchmark...")
 	fmt.Println()
 
+	var ramp []bench.RampStep
 	runOnce := func(run int) bench.BenchStats {
+		if params.RampTenants > 0 {
+			var stats bench.BenchStats
+			stats, ramp = scaleRunRamp(ctx, dbs, tenants, params, concPerTenant, totalConc, sizes)
+			return stats
+		}
+		if params.TenantSkew > 0 {
+			return runSkewed(ctx, dbs, tenants, params, sizes)
+		}
 		if params.Duration > 0 {
-			return scaleRunTimed(dbs, tenants, params, concPerTenant, totalConc)
+			return scaleRunTimed(ctx, dbs, tenants, params, concPerTenant, totalConc, sizes)
 		}
-		return scaleRunCount(dbs, tenants, params, concPerTenant, totalConc)
+		return scaleRunCount(ctx, dbs, tenants, params, concPerTenant, totalConc, sizes)
 	}
 
+	var stats bench.BenchStats
 	if params.Runs > 1 {
-		stats := bench.RunMultiple(params.Runs, "Scale (100 tenants)", runOnce)
-		bench.PrintStats(stats)
+		stats = bench.RunMultiple(ctx, params.Runs, fmt.Sprintf("Scale (%d tenants)", len(tenants)), runOnce)
 	} else {
-		stats := runOnce(0)
-		bench.PrintStats(stats)
+		stats = runOnce(0)
 	}
+	bench.PrintStats(stats)
+
+	res := &bench.Result{Stats: []bench.BenchStats{stats}, Ramp: ramp}
+	res.Manifest.ProxyVersion = proxyVersion
+	return res
 }
 
-func scaleRunCount(dbs []*sql.DB, tenants []string, params bench.BenchParams, concPerTenant, totalConc int) bench.BenchStats {
-	maxID := params.SeedRows
-	queriesPerTenant := params.Queries / len(tenants)
-	if queriesPerTenant < 10 {
-		queriesPerTenant = 10
-	}
+// scaleMinQueries is the fewest queries each tenant runs in a count-based
+// scale test, however small -queries is.
+const scaleMinQueries = 10
+
+func scaleRunCount(ctx context.Context, dbs []*sql.DB, tenants []string, params bench.BenchParams, concPerTenant, totalConc int, sizes []int) bench.BenchStats {
+	q := newQueries(params)
+	op := workloadOp(params)
+	total := max(params.Queries, scaleMinQueries*len(tenants))
 
 	tResults := make([]tenantStats, len(tenants))
 	for i, t := range tenants {
+		_, n := bench.Share(total, len(tenants), i)
 		tResults[i] = tenantStats{
 			Name:    t,
-			Results: make([]bench.QueryResult, queriesPerTenant),
+			Results: make([]bench.QueryResult, n),
+		}
+	}
+
+	queued := 0
+	for t := range tenants {
+		if dbs[t] != nil {
+			queued += len(tResults[t].Results)
 		}
 	}
+	progress := bench.NewProgress(queued)
 
 	start := time.Now()
 	var wg sync.WaitGroup
@@ -157,42 +207,30 @@ func scaleRunCount(dbs []*sql.DB, tenants []string, params bench.BenchParams, co
 
 		for w := 0; w < concPerTenant; w++ {
 			wg.Add(1)
-			workerQueries := queriesPerTenant / concPerTenant
-			workerOffset := w * workerQueries
+			workerOffset, workerQueries := bench.Share(len(tResults[t].Results), concPerTenant, w)
 
 			go func(tIdx int, d *sql.DB, offset, count int) {
 				defer wg.Done()
-				ctx := context.Background()
 
 				for i := 0; i < count; i++ {
 					idx := offset + i
-					qStart := time.Now()
-
-					if rand.Intn(100) < 80 {
-						id := rand.Intn(maxID) + 1
-						var rID int
-						var rName string
-						var rBalance float64
-						err := d.QueryRowContext(ctx, "SELECT id, name, balance FROM accounts WHERE id = ?", id).Scan(&rID, &rName, &rBalance)
-						tResults[tIdx].Results[idx] = bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err}
-					} else {
-						id := rand.Intn(maxID) + 1
-						delta := rand.Float64()*200 - 100
-						_, err := d.ExecContext(ctx, "UPDATE accounts SET balance = balance + ? WHERE id = ?", delta, id)
-						tResults[tIdx].Results[idx] = bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err}
-					}
+					tResults[tIdx].Results[idx] = op(ctx, d, q, bench.RowsFor(sizes, tIdx, params.SeedRows))
+					progress.Add(1)
 				}
+				bench.ObserveWorker("Scale Test", tIdx*concPerTenant+w, tResults[tIdx].Results[offset:offset+count])
 			}(t, db, workerOffset, workerQueries)
 		}
 	}
 	wg.Wait()
+	progress.Done()
 
 	totalDuration := time.Since(start)
-	return computeScaleStats(tResults, dbs, tenants, totalDuration, totalConc)
+	return computeScaleStats(tResults, connected(dbs), tenants, totalDuration, totalConc, sizes)
 }
 
-func scaleRunTimed(dbs []*sql.DB, tenants []string, params bench.BenchParams, concPerTenant, totalConc int) bench.BenchStats {
-	maxID := params.SeedRows
+func scaleRunTimed(ctx context.Context, dbs []*sql.DB, tenants []string, params bench.BenchParams, concPerTenant, totalConc int, sizes []int) bench.BenchStats {
+	q := newQueries(params)
+	op := workloadOp(params)
 
 	type tenantCollector struct {
 		mu      sync.Mutex
@@ -215,26 +253,14 @@ func scaleRunTimed(dbs []*sql.DB, tenants []string, params bench.BenchParams, co
 			wg.Add(1)
 			go func(tIdx int, d *sql.DB) {
 				defer wg.Done()
-				ctx := context.Background()
 				var local []bench.QueryResult
 
-				for !stopped.Load() {
-					qStart := time.Now()
-					if rand.Intn(100) < 80 {
-						id := rand.Intn(maxID) + 1
-						var rID int
-						var rName string
-						var rBalance float64
-						err := d.QueryRowContext(ctx, "SELECT id, name, balance FROM accounts WHERE id = ?", id).Scan(&rID, &rName, &rBalance)
-						local = append(local, bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err})
-					} else {
-						id := rand.Intn(maxID) + 1
-						delta := rand.Float64()*200 - 100
-						_, err := d.ExecContext(ctx, "UPDATE accounts SET balance = balance + ? WHERE id = ?", delta, id)
-						local = append(local, bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err})
-					}
+				for !stopped.Load() && ctx.Err() == nil {
+					local = append(local, op(ctx, d, q, bench.RowsFor(sizes, tIdx, params.SeedRows)))
 				}
 
+				bench.ObserveWorker("Scale Test", tIdx*concPerTenant+w, local)
+
 				collectors[tIdx].mu.Lock()
 				collectors[tIdx].results = append(collectors[tIdx].results, local...)
 				collectors[tIdx].mu.Unlock()
@@ -243,29 +269,107 @@ func scaleRunTimed(dbs []*sql.DB, tenants []string, params bench.BenchParams, co
 	}
 	wg.Wait()
 
-	totalDuration := time.Since(start)
+	elapsed := time.Since(start)
+	_, totalDuration := bench.Window(nil, start, params, elapsed)
 
 	tResults := make([]tenantStats, len(tenants))
 	for i, t := range tenants {
-		tResults[i] = tenantStats{Name: t, Results: collectors[i].results}
+		kept, _ := bench.Window(collectors[i].results, start, params, elapsed)
+		tResults[i] = tenantStats{Name: t, Results: kept}
 	}
 
-	return computeScaleStats(tResults, dbs, tenants, totalDuration, totalConc)
+	return computeScaleStats(tResults, connected(dbs), tenants, totalDuration, totalConc, sizes)
 }
 
-func computeScaleStats(tResults []tenantStats, dbs []*sql.DB, tenants []string, totalDuration time.Duration, totalConc int) bench.BenchStats {
+// scaleRunRamp is the timed run with tenants brought online in waves of
+// params.RampTenants every params.RampEvery; tenants whose wave would start
+// after the run ends never join. It charts latency per step.
+func scaleRunRamp(ctx context.Context, dbs []*sql.DB, tenants []string, params bench.BenchParams, concPerTenant, totalConc int, sizes []int) (bench.BenchStats, []bench.RampStep) {
+	q := newQueries(params)
+	op := workloadOp(params)
+	byTenant := make([][]bench.QueryResult, len(tenants))
+	joined := append([]*sql.DB(nil), dbs...)
+	var mu sync.Mutex
+
+	start := time.Now()
+	deadline := start.Add(params.Duration)
+	var wg sync.WaitGroup
+	for t := range tenants {
+		joinAt := start.Add(time.Duration(t/params.RampTenants) * params.RampEvery)
+		if !joinAt.Before(deadline) {
+			joined[t] = nil
+		}
+		if joined[t] == nil {
+			continue
+		}
+		if t%params.RampTenants == 0 {
+			time.AfterFunc(time.Until(joinAt), func() {
+				bench.Infof("Wave %d: %d tenants online", t/params.RampTenants+1, min(t+params.RampTenants, len(tenants)))
+			})
+		}
+		for w := 0; w < concPerTenant; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				bench.Sleep(ctx, time.Until(joinAt))
+				var local []bench.QueryResult
+				for time.Now().Before(deadline) && ctx.Err() == nil {
+					local = append(local, op(ctx, joined[t], q, bench.RowsFor(sizes, t, params.SeedRows)))
+				}
+				bench.ObserveWorker("Scale Test", t*concPerTenant+w, local)
+				mu.Lock()
+				byTenant[t] = append(byTenant[t], local...)
+				mu.Unlock()
+			}()
+		}
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+	_, totalDuration := bench.Window(nil, start, params, elapsed)
+	for i := range byTenant {
+		byTenant[i], _ = bench.Window(byTenant[i], start, params, elapsed)
+	}
+
+	steps := bench.RampSteps(byTenant, params.RampTenants, start, params.RampEvery, params.Duration)
+	bench.PrintRamp(steps)
+	tResults := make([]tenantStats, len(tenants))
+	for i, t := range tenants {
+		tResults[i] = tenantStats{Name: t, Results: byTenant[i]}
+	}
+	return computeScaleStats(tResults, connected(joined), tenants, totalDuration, totalConc, sizes), steps
+}
+
+// connected reports which tenants have a connection.
+func connected(dbs []*sql.DB) []bool {
+	live := make([]bool, len(dbs))
+	for i, p := range dbs {
+		live[i] = p != nil
+	}
+	return live
+}
+
+func computeScaleStats(tResults []tenantStats, live []bool, tenants []string, totalDuration time.Duration, totalConc int, sizes []int) bench.BenchStats {
+	for _, t := range tResults {
+		bench.ObserveTenant(t.Name, t.Results)
+	}
 	var allResults []bench.QueryResult
 	var totalErrors int
 	var tenantP50s []float64
+	var rows []int
+	var perTenant []bench.BenchStats
+	var byTenant [][]bench.QueryResult
 
 	for i := range tResults {
-		if dbs[i] == nil {
+		if !live[i] {
 			continue
 		}
 		tResults[i].Stats = bench.ComputeStats(tResults[i].Name, tResults[i].Results, totalDuration)
 		allResults = append(allResults, tResults[i].Results...)
+		byTenant = append(byTenant, tResults[i].Results)
 		totalErrors += tResults[i].Stats.Errors
 		tenantP50s = append(tenantP50s, float64(tResults[i].Stats.LatencyP50.Microseconds()))
+		rows = append(rows, bench.RowsFor(sizes, i, 0))
+		perTenant = append(perTenant, tResults[i].Stats)
 	}
 
 	overall := bench.ComputeStats(
@@ -286,7 +390,7 @@ func computeScaleStats(tResults []tenantStats, dbs []*sql.DB, tenants []string,
 		}
 		var ranking []ranked
 		for i := range tResults {
-			if dbs[i] == nil {
+			if !live[i] {
 				continue
 			}
 			ranking = append(ranking, ranked{tResults[i].Name, tResults[i].Stats.LatencyP50})
@@ -297,7 +401,7 @@ func computeScaleStats(tResults []tenantStats, dbs []*sql.DB, tenants []string,
 
 		fmt.Println()
 		fmt.Println("╔═════════════════════════════════════════════════════════════╗")
-		fmt.Println("║  SCALE TEST RESULTS (100 TENANTS)                          ║")
+		fmt.Printf("║  %-59s║\n", fmt.Sprintf("SCALE TEST RESULTS (%d TENANTS)", len(tenants)))
 		fmt.Println("╠═════════════════════════════════════════════════════════════╣")
 		fmt.Printf("║  Total Queries:     %-39d║\n", overall.Total)
 		fmt.Printf("║  Total Errors:      %-39d║\n", totalErrors)
@@ -334,6 +438,14 @@ func computeScaleStats(tResults []tenantStats, dbs []*sql.DB, tenants []string,
 		}
 		fmt.Println("╚═════════════════════════════════════════════════════════════╝")
 	}
+	if first, rest := bench.SplitFirst(byTenant); len(first) > 0 {
+		bench.PrintFirstQueries(
+			bench.ComputeStats("First query per tenant", first, totalDuration),
+			bench.ComputeStats("Steady state", rest, totalDuration))
+	}
+	if sizes != nil && len(perTenant) > 0 {
+		bench.PrintSizes(rows, perTenant)
+	}
 
 	return overall
-}
\ No newline at end of file
+}
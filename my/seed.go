@@ -0,0 +1,299 @@
+package my
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+
+	"tenantsdb-bench/bench"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+const (
+	seedChunk      = 100_000 // rows per LOAD DATA (and per progress line)
+	maxInsertBytes = 4 << 20 // upper bound for one bulk INSERT statement
+)
+
+// readerSeq keeps LOAD DATA reader names unique across concurrent seeds.
+var readerSeq atomic.Int64
+
+// PrepareData seeds the benchmark table, first truncating it when
+// params.Reseed is set so every run starts from identical rows 1..SeedRows.
+func PrepareData(ctx context.Context, db *sql.DB, params bench.BenchParams) error {
+	if params.Reseed {
+		bench.Infof("Reseeding: truncating %s...", params.TableName())
+		if err := CleanData(ctx, db, params); err != nil {
+			return err
+		}
+	}
+	return SeedData(ctx, db, params)
+}
+
+// seedTable describes one generated table; row returns the column values of
+// the n-th row (1-based), so data is identical across runs and databases.
+type seedTable struct {
+	name string
+	cols []string
+	row  func(n int) []any
+}
+
+// SeedData tops the benchmark table up to params.SeedRows rows, plus the
+// orders and order_items tables when params.Relational is set.
+// Rows are streamed with LOAD DATA LOCAL INFILE; if the server or proxy
+// refuses it, multi-megabyte bulk INSERTs are used instead.
+func SeedData(ctx context.Context, db *sql.DB, params bench.BenchParams) error {
+
+	// Create tables if not exist (only works on direct connections, blocked by proxy DDL guard)
+	var count int
+	err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+tableIdent(params)).Scan(&count)
+	if err != nil || (params.Relational && !relationalExists(ctx, db, params)) {
+		if err := createTables(ctx, db, params); err != nil {
+			return fmt.Errorf("create table: %w", err)
+		}
+	}
+	if params.RowBytes > 0 {
+		if err := ensureColumn(ctx, db, params, "payload", "MEDIUMTEXT"); err != nil {
+			return err
+		}
+	}
+	if params.Documents {
+		if err := ensureColumn(ctx, db, params, "doc", "JSON"); err != nil {
+			return err
+		}
+	}
+
+	accounts := seedTable{params.TableName(), []string{"name", "balance"}, func(n int) []any {
+		row := []any{fmt.Sprintf("user_%d", n), accountBalance(n)}
+		if params.RowBytes > 0 {
+			row = append(row, bench.Payload(n, params.RowBytes))
+		}
+		if params.Documents {
+			row = append(row, bench.Document(n))
+		}
+		return row
+	}}
+	if params.RowBytes > 0 {
+		accounts.cols = append(accounts.cols, "payload")
+	}
+	if params.Documents {
+		accounts.cols = append(accounts.cols, "doc")
+	}
+	if err := fillTable(ctx, db, params, accounts, params.SeedRows); err != nil {
+		return err
+	}
+	if !params.Relational {
+		return nil
+	}
+
+	orders := seedTable{params.OrdersTable(), []string{"id", "account_id", "amount", "status"}, func(n int) []any {
+		return []any{n, bench.OrderAccount(n), bench.OrderAmount(n), bench.OrderStatus(n)}
+	}}
+	if err := fillTable(ctx, db, params, orders, params.SeedRows*bench.OrdersPerAccount); err != nil {
+		return err
+	}
+	items := seedTable{params.ItemsTable(), []string{"id", "order_id", "sku", "qty", "price"}, func(n int) []any {
+		return []any{n, bench.ItemOrder(n), bench.ItemSKU(n), bench.ItemQty(n), bench.ItemPrice(n)}
+	}}
+	return fillTable(ctx, db, params, items, params.SeedRows*bench.OrdersPerAccount*bench.ItemsPerOrder)
+}
+
+// fillTable tops one table up to rows rows. Negative ids, written by the
+// upsert workload, are not counted.
+func fillTable(ctx context.Context, db *sql.DB, params bench.BenchParams, t seedTable, rows int) error {
+	table := qualify(params, t.name)
+
+	var count int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+table+" WHERE id > 0").Scan(&count); err != nil {
+		return fmt.Errorf("seed check %s: %w", t.name, err)
+	}
+	if count >= rows {
+		bench.Infof("%s already seeded (%d rows)", t.name, count)
+		return nil
+	}
+
+	bench.Infof("Seeding %s: %d rows...", t.name, rows-count)
+	useLoad := true
+	for from := count + 1; from <= rows; from += seedChunk {
+		to := min(from+seedChunk-1, rows)
+
+		if useLoad {
+			if err := loadRows(ctx, db, table, t, from, to); err != nil {
+				if from != count+1 {
+					return fmt.Errorf("seed load %s at row %d: %w", t.name, from, err)
+				}
+				fmt.Printf("  LOAD DATA refused (%v), falling back to bulk INSERTs\n", err)
+				useLoad = false
+			}
+		}
+		if !useLoad {
+			if err := insertRows(ctx, db, table, t, from, to); err != nil {
+				return err
+			}
+		}
+
+		if rows-count > seedChunk {
+			bench.Infof("Seeded %d/%d rows (%.0f%%)", to-count, rows-count, float64(to-count)/float64(rows-count)*100)
+		}
+	}
+	return nil
+}
+
+// createTables creates the benchmark table, plus orders and order_items with
+// foreign keys when params.Relational is set.
+func createTables(ctx context.Context, db *sql.DB, params bench.BenchParams) error {
+	accounts := tableIdent(params)
+	stmts := []string{`
+		CREATE TABLE IF NOT EXISTS ` + accounts + ` (
+			id INT AUTO_INCREMENT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			balance DECIMAL(15,2) NOT NULL
+		)`}
+	if params.Relational {
+		orders := qualify(params, params.OrdersTable())
+		stmts = append(stmts, `
+		CREATE TABLE IF NOT EXISTS `+orders+` (
+			id INT PRIMARY KEY,
+			account_id INT NOT NULL,
+			amount DECIMAL(15,2) NOT NULL,
+			status VARCHAR(16) NOT NULL,
+			INDEX (account_id),
+			FOREIGN KEY (account_id) REFERENCES `+accounts+` (id)
+		)`, `
+		CREATE TABLE IF NOT EXISTS `+qualify(params, params.ItemsTable())+` (
+			id INT PRIMARY KEY,
+			order_id INT NOT NULL,
+			sku VARCHAR(32) NOT NULL,
+			qty INT NOT NULL,
+			price DECIMAL(15,2) NOT NULL,
+			INDEX (order_id),
+			FOREIGN KEY (order_id) REFERENCES `+orders+` (id)
+		)`)
+	}
+	for _, stmt := range stmts {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureColumn adds an optional column (the -row-bytes payload, the json
+// workload's doc) to an existing table.
+func ensureColumn(ctx context.Context, db *sql.DB, params bench.BenchParams, name, typ string) error {
+	table := tableIdent(params)
+	if _, err := db.ExecContext(ctx, "SELECT "+name+" FROM "+table+" LIMIT 0"); err == nil {
+		return nil
+	}
+	if _, err := db.ExecContext(ctx, "ALTER TABLE "+table+" ADD COLUMN "+name+" "+typ); err != nil {
+		return fmt.Errorf("add %s column: %w", name, err)
+	}
+	fmt.Printf("  Added %s column; rows seeded before it have none (use -reseed)\n", name)
+	return nil
+}
+
+// relationalExists reports whether the orders and order_items tables exist.
+func relationalExists(ctx context.Context, db *sql.DB, params bench.BenchParams) bool {
+	for _, t := range []string{params.OrdersTable(), params.ItemsTable()} {
+		var n int
+		if err := db.QueryRowContext(ctx, "SELECT 1 FROM "+qualify(params, t)+" LIMIT 1").Scan(&n); err != nil && err != sql.ErrNoRows {
+			return false
+		}
+	}
+	return true
+}
+
+// loadRows streams rows from..to through LOAD DATA LOCAL INFILE.
+func loadRows(ctx context.Context, db *sql.DB, table string, t seedTable, from, to int) error {
+	name := fmt.Sprintf("tdb_seed_%d", readerSeq.Add(1))
+	mysql.RegisterReaderHandler(name, func() io.Reader {
+		pr, pw := io.Pipe()
+		go func() {
+			var sb strings.Builder
+			for n := from; n <= to; n++ {
+				for c, v := range t.row(n) {
+					if c > 0 {
+						sb.WriteByte('\t')
+					}
+					sb.WriteString(formatValue(v))
+				}
+				sb.WriteByte('\n')
+				if sb.Len() > 64<<10 {
+					if _, err := io.WriteString(pw, sb.String()); err != nil {
+						return
+					}
+					sb.Reset()
+				}
+			}
+			io.WriteString(pw, sb.String())
+			pw.Close()
+		}()
+		return pr
+	})
+	defer mysql.DeregisterReaderHandler(name)
+
+	_, err := db.ExecContext(ctx, fmt.Sprintf(
+		"LOAD DATA LOCAL INFILE 'Reader::%s' INTO TABLE %s FIELDS TERMINATED BY '\\t' (%s)",
+		name, table, strings.Join(t.cols, ", ")))
+	return err
+}
+
+// insertRows inserts rows from..to with INSERT statements of up to
+// maxInsertBytes (or half of max_allowed_packet, if smaller).
+func insertRows(ctx context.Context, db *sql.DB, table string, t seedTable, from, to int) error {
+	limit := maxInsertBytes
+	var packet int
+	if err := db.QueryRowContext(ctx, "SELECT @@max_allowed_packet").Scan(&packet); err == nil && packet/2 < limit {
+		limit = packet / 2
+	}
+
+	prefix := "INSERT INTO " + table + " (" + strings.Join(t.cols, ", ") + ") VALUES "
+	var sb strings.Builder
+	start := from
+	for n := from; n <= to; n++ {
+		if sb.Len() == 0 {
+			sb.WriteString(prefix)
+			start = n
+		} else {
+			sb.WriteString(",")
+		}
+		sb.WriteString("(")
+		for c, v := range t.row(n) {
+			if c > 0 {
+				sb.WriteString(",")
+			}
+			if s, ok := v.(string); ok {
+				sb.WriteString("'" + strings.ReplaceAll(s, "'", "''") + "'")
+			} else {
+				sb.WriteString(formatValue(v))
+			}
+		}
+		sb.WriteString(")")
+
+		if sb.Len() >= limit || n == to {
+			if _, err := db.ExecContext(ctx, sb.String()); err != nil {
+				return fmt.Errorf("seed batch %s at row %d: %w", t.name, start, err)
+			}
+			sb.Reset()
+		}
+	}
+	return nil
+}
+
+// formatValue renders a seed value as LOAD DATA / SQL literal text.
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case float64:
+		return fmt.Sprintf("%.2f", v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// accountBalance is the deterministic seed balance of row n.
+func accountBalance(n int) float64 {
+	return float64(n*7919%1000000) / 100
+}
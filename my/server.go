@@ -0,0 +1,70 @@
+package my
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	"tenantsdb-bench/bench"
+)
+
+// statusCounters are the SHOW GLOBAL STATUS counters diffed across a run.
+var statusCounters = []string{
+	"Questions", "Com_select", "Com_insert", "Com_update", "Com_delete",
+	"Com_commit", "Com_rollback", "Slow_queries",
+	"Innodb_row_lock_waits", "Innodb_row_lock_time",
+	"Innodb_buffer_pool_read_requests", "Innodb_buffer_pool_reads",
+	"Created_tmp_disk_tables", "Connections", "Aborted_connects",
+}
+
+// statusGauges are point-in-time values reported as of the run's end.
+var statusGauges = []string{"Threads_connected", "Threads_running"}
+
+// WatchServer snapshots SHOW GLOBAL STATUS on the direct backend; the
+// returned stop snapshots again and returns the deltas.
+func WatchServer(direct bench.ConnConfig) (stop func() *bench.ServerStats, err error) {
+	db, err := Connect(direct)
+	if err != nil {
+		return nil, fmt.Errorf("connect direct: %w", err)
+	}
+	before, err := globalStatus(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	bench.Okf("Backend statistics snapshot taken (SHOW GLOBAL STATUS)")
+
+	return func() *bench.ServerStats {
+		defer db.Close()
+		after, err := globalStatus(db)
+		if err != nil {
+			bench.Warnf("Backend statistics: %v", err)
+			return nil
+		}
+		return &bench.ServerStats{
+			Source:   "SHOW GLOBAL STATUS",
+			Counters: append(bench.GaugeValues(statusGauges, before, after), bench.CounterDeltas(statusCounters, before, after)...),
+		}
+	}, nil
+}
+
+// globalStatus reads the numeric rows of SHOW GLOBAL STATUS.
+func globalStatus(db *sql.DB) (map[string]int64, error) {
+	rows, err := db.QueryContext(context.Background(), "SHOW GLOBAL STATUS")
+	if err != nil {
+		return nil, fmt.Errorf("show global status: %w", err)
+	}
+	defer rows.Close()
+	out := map[string]int64{}
+	for rows.Next() {
+		var name, value string
+		if err := rows.Scan(&name, &value); err != nil {
+			return nil, err
+		}
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			out[name] = n
+		}
+	}
+	return out, rows.Err()
+}
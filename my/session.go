@@ -0,0 +1,160 @@
+package my
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+	"time"
+
+	"tenantsdb-bench/bench"
+)
+
+var (
+	sessionZones = []string{"+00:00", "-05:00", "+01:00", "+09:00", "+10:00"}
+	sessionModes = []string{"STRICT_TRANS_TABLES", "ANSI_QUOTES,STRICT_TRANS_TABLES", "NO_ZERO_DATE,STRICT_TRANS_TABLES"}
+)
+
+// RunSession runs sessions that SET their own time_zone, sql_mode and a user
+// variable, then interleave queries with checks that the settings stuck,
+// through the proxy and (when configured) direct. A multiplexing proxy that
+// leaks or resets session state fails the verify op.
+func RunSession(ctx context.Context, proxyCfg, directCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
+	return runSessions(ctx, proxyCfg, directCfg, params, "Session State Test", "session state", session)
+}
+
+// sessionFunc runs one session tagged tag, one result per statement.
+type sessionFunc func(ctx context.Context, db *sql.DB, params bench.BenchParams, tag string) []bench.QueryResult
+
+// runSessions is the shared driver of the session-scoped tests: it seeds,
+// runs fn sessions through each endpoint and compares them.
+func runSessions(ctx context.Context, proxyCfg, directCfg bench.ConnConfig, params bench.BenchParams, title, what string, fn sessionFunc) *bench.Result {
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Printf("  MySQL %s\n", title)
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Printf("  Queries: %d | Concurrency: %d | Session queries: %d\n\n",
+		params.Queries, params.Concurrency, params.SessionQueries)
+
+	res := &bench.Result{}
+	directDB, proxyDB, ok := endpoints(proxyCfg, directCfg, res, 3)
+	if !ok {
+		return nil
+	}
+	defer proxyDB.Close()
+	if directDB != nil {
+		defer directDB.Close()
+	}
+
+	seedDB := proxyDB
+	if directDB != nil {
+		seedDB = directDB
+	}
+	if err := PrepareData(ctx, seedDB, params); err != nil {
+		bench.Failf("Seed failed: %v", err)
+		return nil
+	}
+	bench.Okf("Data ready")
+
+	bench.Stepf("[3/3] Running sessions...")
+	var directStats bench.BenchStats
+	if directDB != nil {
+		fmt.Println("\n── Direct MySQL ──")
+		directStats = bench.RunMultiple(ctx, params.Runs, "Direct "+what, func(run int) bench.BenchStats {
+			return sessionPass(bench.Unobserved(ctx), directDB, params, "Direct "+what, fn)
+		})
+		bench.PrintStats(directStats)
+		res.Stats = append(res.Stats, directStats)
+	}
+
+	fmt.Println("\n── Through TenantsDB Proxy ──")
+	proxyStats := bench.RunMultiple(ctx, params.Runs, "Proxy "+what, func(run int) bench.BenchStats {
+		return sessionPass(ctx, proxyDB, params, "Proxy "+what, fn)
+	})
+	bench.PrintStats(proxyStats)
+	res.Stats = append(res.Stats, proxyStats)
+	if proxyStats.Errors > 0 {
+		fmt.Println()
+		bench.Warnf("%s broke through the proxy", what)
+	} else {
+		fmt.Println()
+		bench.Okf("%s held up through the proxy", what)
+	}
+
+	if directDB != nil {
+		bench.PrintComparison(proxyStats, directStats)
+		cmp := bench.Compare(proxyStats, directStats)
+		res.Comparison = &cmp
+	}
+	return res
+}
+
+// sessionPass runs sessions on params.Concurrency workers until about
+// params.Queries statements are done.
+func sessionPass(ctx context.Context, db *sql.DB, params bench.BenchParams, label string, fn sessionFunc) bench.BenchStats {
+	sessions := max(params.Queries/(2*params.SessionQueries+1)/params.Concurrency, 1)
+	results, total := bench.RunWorkers(ctx, label, params.Concurrency, func(worker int) []bench.QueryResult {
+		var local []bench.QueryResult
+		for i := 0; i < sessions; i++ {
+			local = append(local, fn(ctx, db, params, fmt.Sprintf("tdb_w%d_s%d", worker, i))...)
+		}
+		return local
+	})
+	bench.PrintErrors(results)
+	return bench.ComputeStats(label, results, total)
+}
+
+// session pins one connection, sets its state, then alternates point reads
+// with verifying the state, one result per statement.
+func session(ctx context.Context, db *sql.DB, params bench.BenchParams, tag string) []bench.QueryResult {
+	var out []bench.QueryResult
+	record := func(op string, qStart time.Time, err error) error {
+		out = append(out, bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err, Op: op})
+		return err
+	}
+
+	zone := sessionZones[rand.Intn(len(sessionZones))]
+	mode := sessionModes[rand.Intn(len(sessionModes))]
+
+	qStart := time.Now()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		record("set", qStart, err)
+		return out
+	}
+	defer conn.Close()
+	defer conn.ExecContext(ctx, "SET SESSION time_zone = DEFAULT, sql_mode = DEFAULT, @tdb_tag = NULL")
+
+	_, err = conn.ExecContext(ctx, "SET SESSION time_zone = ?, sql_mode = ?, @tdb_tag = ?", zone, mode, tag)
+	if record("set", qStart, err) != nil {
+		return out
+	}
+
+	q := newQueries(params)
+	for i := 0; i < params.SessionQueries; i++ {
+		qStart := time.Now()
+		id := rand.Intn(params.SeedRows) + 1
+		err := conn.QueryRowContext(ctx, q.selectByID, id).Scan(new(int), new(string), new(float64))
+		record("query", qStart, err)
+
+		qStart = time.Now()
+		var gotZone, gotMode, gotTag string
+		err = conn.QueryRowContext(ctx, "SELECT @@session.time_zone, @@session.sql_mode, @tdb_tag").
+			Scan(&gotZone, &gotMode, &gotTag)
+		if err == nil && (gotZone != zone || !sameModes(gotMode, mode) || gotTag != tag) {
+			err = fmt.Errorf("session state lost: time_zone=%q sql_mode=%q @tdb_tag=%q, want %q %q %q",
+				gotZone, gotMode, gotTag, zone, mode, tag)
+		}
+		record("verify", qStart, err)
+	}
+	return out
+}
+
+// sameModes compares sql_mode lists regardless of the server's ordering.
+func sameModes(a, b string) bool {
+	x, y := strings.Split(a, ","), strings.Split(b, ",")
+	slices.Sort(x)
+	slices.Sort(y)
+	return slices.Equal(x, y)
+}
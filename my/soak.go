@@ -0,0 +1,117 @@
+package my
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"tenantsdb-bench/bench"
+)
+
+// RunSoak holds params.Concurrency connections open for params.Duration,
+// each running a point read every params.SoakInterval, and reports
+// disconnects and latency drift as the connections age.
+func RunSoak(ctx context.Context, proxyCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Println("  MySQL Connection Soak Test")
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Printf("  Connections: %d | Query every %s | Duration: %s\n\n",
+		params.Concurrency, params.SoakInterval, params.Duration)
+
+	res := &bench.Result{}
+	bench.Stepf("[1/2] Connecting through TenantsDB proxy...")
+	db, err := Connect(proxyCfg)
+	if err != nil {
+		bench.Failf("Proxy connection failed: %v", err)
+		return nil
+	}
+	defer db.Close()
+	res.Manifest.ProxyVersion = detectVersion(db)
+	if err := PrepareData(ctx, db, params); err != nil {
+		bench.Failf("Seed failed: %v", err)
+		return nil
+	}
+	// Only the soak decides when a connection dies
+	db.SetMaxOpenConns(params.Concurrency)
+	db.SetConnMaxLifetime(0)
+	bench.Okf("Data ready")
+
+	bench.Stepf("[2/2] Soaking for %s...", params.Duration)
+	q := newQueries(params)
+	report := bench.SoakReport{Conns: params.Concurrency, Interval: params.SoakInterval}
+	var mu sync.Mutex
+	var firstDropAt time.Time
+	start := time.Now()
+	deadline := start.Add(params.Duration)
+
+	results, total := bench.RunWorkers(ctx, "Soak queries", params.Concurrency, func(worker int) []bench.QueryResult {
+		var out []bench.QueryResult
+		var conn *sql.Conn
+		var opened time.Time
+		defer func() {
+			if conn != nil {
+				conn.Close()
+			}
+		}()
+
+		// Spread the workers over the interval so queries stay sparse
+		next := time.Now().Add(time.Duration(rand.Int63n(int64(params.SoakInterval))))
+		for {
+			if conn == nil {
+				qStart := time.Now()
+				c, err := db.Conn(ctx)
+				if err != nil {
+					out = append(out, bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err, Op: "connect"})
+				} else {
+					conn, opened = c, time.Now()
+				}
+			}
+			if next.After(deadline) {
+				break
+			}
+			if !bench.Sleep(ctx, time.Until(next)) {
+				break
+			}
+			next = next.Add(params.SoakInterval)
+			if conn == nil {
+				continue
+			}
+
+			qctx, cancel := context.WithTimeout(ctx, bench.SoakQueryTimeout)
+			qStart := time.Now()
+			err := conn.QueryRowContext(qctx, q.selectByID, rand.Intn(params.SeedRows)+1).Scan(new(int), new(string), new(float64))
+			out = append(out, bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err, Op: "query"})
+			lost := err != nil && conn.PingContext(qctx) != nil
+			cancel()
+
+			age := time.Since(opened)
+			mu.Lock()
+			report.OldestConn = max(report.OldestConn, age)
+			if lost {
+				report.Disconnects++
+				if firstDropAt.IsZero() || qStart.Before(firstDropAt) {
+					firstDropAt, report.FirstDrop = qStart, age
+				}
+				bench.Failf("Connection %d lost after %s: %v", worker+1, age.Round(time.Second), err)
+			}
+			mu.Unlock()
+			if lost {
+				conn.Close()
+				conn = nil
+			}
+		}
+		return out
+	})
+
+	bench.PrintErrors(results)
+	stats := bench.ComputeStats("Soak queries", results, total)
+	bench.PrintStats(stats)
+	bench.SummarizeSoak(&report, results, start, params.Duration)
+	bench.PrintSoak(report)
+	res.Stats = append(res.Stats, stats)
+	res.Soak = &report
+	return res
+}
@@ -0,0 +1,114 @@
+package my
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"tenantsdb-bench/bench"
+)
+
+// RunStream fetches results of increasing size and measures time to the
+// first row and to the last, through the proxy and (when configured) direct.
+func RunStream(ctx context.Context, proxyCfg, directCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Println("  MySQL Result Streaming Benchmark")
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Printf("  Result sizes: %v rows | %d queries each\n\n", params.StreamRows, params.StreamIters)
+
+	res := &bench.Result{}
+
+	directDB, proxyDB, ok := endpoints(proxyCfg, directCfg, res, 3)
+	if !ok {
+		return nil
+	}
+	defer proxyDB.Close()
+	if directDB != nil {
+		defer directDB.Close()
+	}
+
+	seedDB := proxyDB
+	if directDB != nil {
+		seedDB = directDB
+	}
+	if err := PrepareData(ctx, seedDB, params); err != nil {
+		bench.Failf("Seed failed: %v", err)
+		return nil
+	}
+	bench.Okf("Data ready")
+
+	bench.Stepf("[3/3] Streaming results...")
+	query := "SELECT id, name, balance FROM " + tableIdent(params) + " ORDER BY id LIMIT ?"
+
+	var points []bench.StreamPoint
+	for _, n := range params.StreamRows {
+		point := bench.StreamPoint{Rows: n}
+		if directDB != nil {
+			fmt.Printf("\n── Direct, %d rows ──\n", n)
+			s := streamRuns(ctx, directDB, query, n, params.StreamIters, fmt.Sprintf("Direct %d rows", n))
+			bench.PrintStats(s)
+			point.Direct = &s
+			res.Stats = append(res.Stats, s)
+		}
+		fmt.Printf("\n── Through Proxy, %d rows ──\n", n)
+		point.Proxy = streamRuns(ctx, proxyDB, query, n, params.StreamIters, fmt.Sprintf("Proxy %d rows", n))
+		bench.PrintStats(point.Proxy)
+		res.Stats = append(res.Stats, point.Proxy)
+		points = append(points, point)
+	}
+
+	bench.PrintStreaming(points)
+	return res
+}
+
+// streamRuns fetches an n-row result iters times, one query at a time.
+func streamRuns(ctx context.Context, db *sql.DB, query string, n, iters int, label string) bench.BenchStats {
+	results := make([]bench.QueryResult, iters)
+	start := time.Now()
+	for i := range results {
+		results[i] = streamOnce(ctx, db, query, n)
+		if results[i].Err != nil {
+			bench.Warnf("Error: %v", results[i].Err)
+		}
+	}
+	return bench.ComputeStats(label, results, time.Since(start))
+}
+
+// streamOnce reads every row of one n-row result, noting when the first
+// row arrived.
+func streamOnce(ctx context.Context, db *sql.DB, query string, n int) bench.QueryResult {
+	qStart := time.Now()
+	r := bench.QueryResult{At: qStart}
+
+	rows, err := db.QueryContext(ctx, query, n)
+	if err != nil {
+		r.Err = err
+		r.Duration = time.Since(qStart)
+		return r
+	}
+	got := 0
+	for rows.Next() {
+		if got == 0 {
+			r.FirstRow = time.Since(qStart)
+		}
+		var id int
+		var name string
+		var balance float64
+		if err := rows.Scan(&id, &name, &balance); err != nil {
+			rows.Close()
+			r.Err = err
+			r.Duration = time.Since(qStart)
+			return r
+		}
+		r.Bytes += len(name) + 16
+		got++
+	}
+	rows.Close()
+	r.Duration = time.Since(qStart)
+	r.Err = rows.Err()
+	if r.Err == nil && got != n {
+		r.Err = fmt.Errorf("got %d rows, want %d", got, n)
+	}
+	return r
+}
@@ -0,0 +1,76 @@
+package my
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"tenantsdb-bench/bench"
+)
+
+// tempRows is how many rows each session puts in its temporary table.
+const tempRows = 100
+
+// RunTempTable runs sessions that create a temporary table, fill it with
+// values unique to the session and read them back, through the proxy and
+// (when configured) direct. If the proxy hands the session a different
+// backend, the table vanishes or holds another session's values.
+func RunTempTable(ctx context.Context, proxyCfg, directCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
+	return runSessions(ctx, proxyCfg, directCfg, params, "Temporary Table Test", "temporary tables", tempSession)
+}
+
+// tempSession pins one connection and runs create, insert, SessionQueries
+// read-backs and drop on a temporary table, one result per statement.
+func tempSession(ctx context.Context, db *sql.DB, params bench.BenchParams, tag string) []bench.QueryResult {
+	var out []bench.QueryResult
+	record := func(op string, qStart time.Time, err error) error {
+		out = append(out, bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err, Op: op})
+		return err
+	}
+
+	qStart := time.Now()
+	conn, err := db.Conn(ctx)
+	if err == nil {
+		defer conn.Close()
+		_, err = conn.ExecContext(ctx, "CREATE TEMPORARY TABLE tdb_tmp (id INT PRIMARY KEY, v BIGINT NOT NULL)")
+	}
+	if record("create", qStart, err) != nil {
+		return out
+	}
+	defer conn.ExecContext(ctx, "DROP TEMPORARY TABLE IF EXISTS tdb_tmp")
+
+	// Values are offset by a per-session base so leaks between sessions show
+	base := rand.Int63n(1 << 40)
+	var sb strings.Builder
+	sb.WriteString("INSERT INTO tdb_tmp (id, v) VALUES ")
+	for id := 1; id <= tempRows; id++ {
+		if id > 1 {
+			sb.WriteString(",")
+		}
+		fmt.Fprintf(&sb, "(%d,%d)", id, base+int64(id))
+	}
+	qStart = time.Now()
+	_, err = conn.ExecContext(ctx, sb.String())
+	if record("insert", qStart, err) != nil {
+		return out
+	}
+
+	for i := 0; i < params.SessionQueries; i++ {
+		id := rand.Intn(tempRows) + 1
+		qStart := time.Now()
+		var v int64
+		err := conn.QueryRowContext(ctx, "SELECT v FROM tdb_tmp WHERE id = ?", id).Scan(&v)
+		if err == nil && v != base+int64(id) {
+			err = fmt.Errorf("temp table row %d holds %d, want %d (another session's table)", id, v, base+int64(id))
+		}
+		record("query", qStart, err)
+	}
+
+	qStart = time.Now()
+	_, err = conn.ExecContext(ctx, "DROP TEMPORARY TABLE tdb_tmp")
+	record("drop", qStart, err)
+	return out
+}
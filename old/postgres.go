@@ -233,7 +233,8 @@ func RunPostgresMultiTenant(proxyCfg ConnConfig, params BenchParams) {
 			return
 		}
 	}
-	fmt.Println("  ✓ All tenants connected and seeded\n")
+	fmt.Println("  ✓ All tenants connected and seeded")
+	fmt.Println()
 
 	fmt.Println("── Running multi-tenant benchmark ──")
 	queriesPerTenant := params.Queries / len(tenants)
@@ -105,7 +105,8 @@ func RunPostgresScale(proxyCfg ConnConfig, params BenchParams) {
 	if seedFailed > 0 {
 		fmt.Printf("  ⚠ %d tenants failed to seed\n", seedFailed)
 	}
-	fmt.Println("  ✓ All tenants seeded\n")
+	fmt.Println("  ✓ All tenants seeded")
+	fmt.Println()
 
 	// ── Phase 3: Run scale benchmark ──
 	fmt.Println("[3/3] Running scale benchmark...")
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// logFileName inserts the start time before path's extension, so repeated
+// runs with the same -log-file keep their own files.
+func logFileName(path string, at time.Time) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "-" + at.Format("20060102-150405") + ext
+}
+
+// teeOutput copies everything written to stdout and stderr from here on
+// into a new file at path, as well as to the terminal. The returned stop
+// flushes and closes the file; it is also registered with cleanups so fail
+// does not lose the tail.
+func teeOutput(path string) (stop func(), err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	file := &lockedWriter{w: f}
+	stop, err = redirect(func(orig *os.File) io.Writer {
+		return teeWriter{file: file, term: orig}
+	})
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() { stop(); f.Close() }, nil
+}
+
+// plainOutput rewrites box drawing, emoji and other non-ASCII symbols on
+// stdout and stderr from here on into ASCII, for CI logs and files that
+// mangle them. Single-column symbols get one character and emoji two, so
+// tables stay aligned.
+func plainOutput() (stop func(), err error) {
+	return redirect(func(orig *os.File) io.Writer {
+		return &plainWriter{w: orig}
+	})
+}
+
+// isTerminal reports whether f is a terminal rather than a pipe or file.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// usePlain starts plainOutput for -plain and returns its stop, a no-op when
+// on is false. NO_COLOR is set so commands the run starts leave out color
+// too.
+func usePlain(on bool) func() {
+	if !on {
+		return func() {}
+	}
+	os.Setenv("NO_COLOR", "1")
+	stop, err := plainOutput()
+	if err != nil {
+		fail("-plain: %v", err)
+	}
+	return stop
+}
+
+// redirect points stdout and stderr at pipes whose contents are copied to
+// the writers wrap returns for the original files. The returned stop
+// restores the originals and waits for the copies to drain; it is also
+// registered with cleanups, and is safe to call more than once.
+func redirect(wrap func(orig *os.File) io.Writer) (stop func(), err error) {
+	var wg sync.WaitGroup
+	var restore []func()
+	for _, std := range []**os.File{&os.Stdout, &os.Stderr} {
+		r, w, err := os.Pipe()
+		if err != nil {
+			for _, fn := range restore {
+				fn()
+			}
+			return nil, err
+		}
+		orig := *std
+		*std = w
+		dst := wrap(orig)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			io.Copy(dst, r)
+			if f, ok := dst.(interface{ Flush() }); ok {
+				f.Flush()
+			}
+			r.Close()
+		}()
+		restore = append(restore, func() {
+			*std = orig
+			w.Close()
+		})
+	}
+
+	var once sync.Once
+	stop = func() {
+		once.Do(func() {
+			for _, fn := range restore {
+				fn()
+			}
+			wg.Wait()
+		})
+	}
+	cleanups = append(cleanups, stop)
+	return stop, nil
+}
+
+// teeWriter writes to the log file and the terminal; a terminal that has
+// gone away does not stop the file from getting the rest.
+type teeWriter struct {
+	file, term io.Writer
+}
+
+func (t teeWriter) Write(p []byte) (int, error) {
+	t.term.Write(p)
+	return t.file.Write(p)
+}
+
+// lockedWriter serializes the stdout and stderr copies into one file.
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
+
+// asciiSymbols maps what the tables and progress lines print to ASCII, as
+// many characters as columns each symbol takes, so tables stay aligned. A
+// variation selector cut off from its emoji by a split write is dropped.
+var asciiSymbols = strings.NewReplacer(
+	"─", "-", "═", "=", "│", "|", "║", "|",
+	"┌", "+", "┐", "+", "└", "+", "┘", "+", "├", "+", "┤", "+",
+	"╔", "+", "╗", "+", "╚", "+", "╝", "+", "╠", "+", "╣", "+", "╦", "+", "╩", "+", "╬", "+",
+	"✅", "OK", "❌", "XX", "⚠️", "!!", "⏱", ">>",
+	"✓", "+", "✗", "x", "⚠", "!", "▶", ">", "▲", "^", "█", "#",
+	"—", "-", "–", "-", "→", ">", "×", "x", "µ", "u", "σ", "s", "•", "*",
+	"±", "~", "…", ".", "\ufe0f", "",
+)
+
+// plainWriter applies asciiSymbols, holding back a multi-byte character
+// split across writes until the rest of it arrives.
+type plainWriter struct {
+	w       io.Writer
+	pending []byte
+}
+
+func (p *plainWriter) Write(b []byte) (int, error) {
+	buf := append(p.pending, b...)
+	end := len(buf)
+	// Back up over an incomplete trailing character
+	for i := max(0, end-utf8.UTFMax); i < end; i++ {
+		if utf8.RuneStart(buf[i]) && !utf8.FullRune(buf[i:end]) {
+			end = i
+			break
+		}
+	}
+	p.pending = append([]byte(nil), buf[end:]...)
+	if _, err := asciiSymbols.WriteString(p.w, string(buf[:end])); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// Flush writes anything still held back.
+func (p *plainWriter) Flush() {
+	asciiSymbols.WriteString(p.w, string(p.pending))
+	p.pending = nil
+}
@@ -0,0 +1,87 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"tenantsdb-bench/bench"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// advisoryClass is the first key of every advisory lock the test takes, so
+// it cannot collide with locks an application holds on the same database.
+const advisoryClass = 7571
+
+// advisoryHolders maps each held lock key to the session holding it. Two
+// sessions in it at once means the proxy let two clients own the same lock.
+var advisoryHolders sync.Map
+
+// RunAdvisory runs sessions that take, check and release advisory locks on
+// params.LockKeys contended keys, through the proxy and (when configured)
+// direct.
+func RunAdvisory(ctx context.Context, proxyCfg, directCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
+	return runSessions(ctx, proxyCfg, directCfg, params, "Advisory Lock Test", "advisory locks", advisorySession)
+}
+
+// advisorySession pins one connection and locks, verifies and unlocks a
+// random key SessionQueries times, one result per statement. Verify checks
+// the backend really holds the lock and no other session does.
+func advisorySession(ctx context.Context, pool *pgxpool.Pool, params bench.BenchParams, tag string) []bench.QueryResult {
+	var out []bench.QueryResult
+	record := func(op string, qStart time.Time, err error) error {
+		out = append(out, bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err, Op: op})
+		return err
+	}
+
+	qStart := time.Now()
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		record("lock", qStart, err)
+		return out
+	}
+	defer conn.Release()
+	defer conn.Exec(ctx, "SELECT pg_advisory_unlock_all()")
+
+	for i := 0; i < params.SessionQueries; i++ {
+		key := rand.Intn(params.LockKeys)
+
+		qStart := time.Now()
+		_, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1, $2)", advisoryClass, key)
+		if record("lock", qStart, err) != nil {
+			return out
+		}
+		other, clash := advisoryHolders.LoadOrStore(key, tag)
+
+		qStart = time.Now()
+		var held bool
+		err = conn.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM pg_locks WHERE locktype = 'advisory'
+			AND pid = pg_backend_pid() AND classid = $1 AND objid = $2 AND objsubid = 2 AND granted)`,
+			advisoryClass, key).Scan(&held)
+		switch {
+		case err != nil:
+		case clash:
+			err = fmt.Errorf("lock %d granted to %s while %s held it", key, tag, other)
+		case !held:
+			err = fmt.Errorf("lock %d granted but not held by this backend", key)
+		}
+		record("verify", qStart, err)
+		if !clash {
+			advisoryHolders.Delete(key)
+		}
+
+		qStart = time.Now()
+		var released bool
+		err = conn.QueryRow(ctx, "SELECT pg_advisory_unlock($1, $2)", advisoryClass, key).Scan(&released)
+		if err == nil && !released {
+			err = fmt.Errorf("lock %d was not held at unlock", key)
+		}
+		if record("unlock", qStart, err) != nil {
+			return out
+		}
+	}
+	return out
+}
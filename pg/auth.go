@@ -0,0 +1,157 @@
+package pg
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"time"
+
+	"tenantsdb-bench/bench"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// authMethods names the AuthenticationRequest codes a server opens with.
+var authMethods = map[uint32]string{
+	0:  "trust",
+	3:  "cleartext",
+	5:  "md5",
+	7:  "gss",
+	9:  "sspi",
+	10: "scram-sha-256",
+}
+
+// sniffConn keeps the first bytes the server sends: its authentication
+// request, as the startup message is the first thing the client writes.
+type sniffConn struct {
+	net.Conn
+	head []byte
+}
+
+func (c *sniffConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if need := 9 - len(c.head); need > 0 && n > 0 {
+		c.head = append(c.head, p[:min(n, need)]...)
+	}
+	return n, err
+}
+
+// method names the request: 'R', a length and the method code.
+func (c *sniffConn) method() string {
+	if len(c.head) < 9 || c.head[0] != 'R' {
+		return ""
+	}
+	code := binary.BigEndian.Uint32(c.head[5:9])
+	if m, ok := authMethods[code]; ok {
+		return m
+	}
+	return fmt.Sprintf("auth code %d", code)
+}
+
+// RunAuth times params.AuthConnects fresh connections per user through the
+// proxy, and directly when directCfg is set, reading the method each server
+// asked for off the wire, so what auth pass-through adds shows per method.
+func RunAuth(ctx context.Context, proxyCfg, directCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Println("  PostgreSQL Authentication Latency Test")
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Printf("  %d connects per user, without TLS\n\n", params.AuthConnects)
+
+	names, cfgs := []string{"proxy"}, []bench.ConnConfig{proxyCfg}
+	if directCfg.IsSet() {
+		names, cfgs = append(names, "direct"), append(cfgs, directCfg)
+	}
+
+	res := &bench.Result{}
+	for i, name := range names {
+		bench.Stepf("[%d/%d] Connecting to the %s endpoint...", i+1, len(names), name)
+		base, err := pgx.ParseConfig(dsnOf(cfgs[i], "disable"))
+		if err != nil {
+			bench.Failf("%s config: %v", name, err)
+			return nil
+		}
+		if cfgs[i].DSN != "" {
+			if cfgs[i].Database != "" {
+				base.Database = cfgs[i].Database
+			}
+			if base.Password == "" {
+				base.Password = cfgs[i].Password
+			}
+		}
+		// Plaintext, so the server's authentication request can be read
+		base.TLSConfig, base.Fallbacks = nil, nil
+
+		users := params.AuthUsers
+		if len(users) == 0 {
+			users = []string{base.User}
+		}
+		for _, user := range users {
+			cfg := base.Copy()
+			cfg.User, cfg.Password = user, params.AuthPassword(user, base.Password)
+			start := time.Now()
+			results, dials, method := timeConnects(ctx, cfg, params.AuthConnects)
+			a := bench.SummarizeAuth(name, cfg.User, method, results, dials)
+			if a.Errors < a.Connects {
+				bench.Okf("%s: %s, connect p50 %s", cfg.User, orUnknown(method), bench.FmtDur(a.P50))
+			} else {
+				bench.Failf("%s: %s", cfg.User, a.FirstErr)
+			}
+			res.Auth = append(res.Auth, a)
+			res.Stats = append(res.Stats, bench.ComputeStats(
+				fmt.Sprintf("%s %s (%s)", name, cfg.User, orUnknown(method)), results, time.Since(start)))
+		}
+	}
+
+	bench.PrintAuth(res.Auth)
+	return res
+}
+
+// timeConnects opens and closes n connections with cfg, timing each from
+// dial to ready for queries, and returns the TCP dial times of those that
+// succeeded and the method the server asked for.
+func timeConnects(ctx context.Context, cfg *pgx.ConnConfig, n int) ([]bench.QueryResult, []time.Duration, string) {
+	var sniff *sniffConn
+	var dialed time.Duration
+	dialer := &net.Dialer{KeepAlive: 5 * time.Minute}
+	cfg.DialFunc = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		start := time.Now()
+		c, err := dialer.DialContext(ctx, network, addr)
+		dialed = time.Since(start)
+		if err != nil {
+			return nil, err
+		}
+		sniff = &sniffConn{Conn: c}
+		return sniff, nil
+	}
+
+	var results []bench.QueryResult
+	var dials []time.Duration
+	var method string
+	for i := 0; i < n && ctx.Err() == nil; i++ {
+		sniff = nil
+		start := time.Now()
+		cctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		conn, err := pgconn.ConnectConfig(cctx, &cfg.Config)
+		took := time.Since(start)
+		cancel()
+		results = append(results, bench.QueryResult{At: start, Duration: took, Err: err, Op: "connect"})
+		if err == nil {
+			dials = append(dials, dialed)
+			conn.Close(ctx)
+		}
+		if sniff != nil && sniff.method() != "" {
+			method = sniff.method()
+		}
+	}
+	return results, dials, method
+}
+
+// orUnknown is method, or "unknown" when the server sent no request.
+func orUnknown(method string) string {
+	if method == "" {
+		return "unknown"
+	}
+	return method
+}
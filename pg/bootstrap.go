@@ -0,0 +1,48 @@
+package pg
+
+import (
+	"context"
+	"errors"
+
+	"tenantsdb-bench/bench"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// SQLTenants is a bench.ControlPlane that makes each tenant a database on
+// the server behind cfg, for running multi-tenant tests without the
+// management API.
+type SQLTenants struct {
+	pool *pgxpool.Pool
+}
+
+func NewSQLTenants(cfg bench.ConnConfig) (*SQLTenants, error) {
+	pool, err := Connect(cfg, "disable")
+	if err != nil {
+		return nil, err
+	}
+	return &SQLTenants{pool: pool}, nil
+}
+
+func (s *SQLTenants) Close() { s.pool.Close() }
+
+// CreateTenant creates the database; one left over from an earlier run is
+// reused.
+func (s *SQLTenants) CreateTenant(ctx context.Context, name string) (string, error) {
+	_, err := s.pool.Exec(ctx, "CREATE DATABASE "+pgx.Identifier{name}.Sanitize())
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "42P04" {
+		err = nil
+	}
+	return name, err
+}
+
+// WaitReady returns at once: CREATE DATABASE is done when it returns.
+func (s *SQLTenants) WaitReady(ctx context.Context, name string) error { return nil }
+
+func (s *SQLTenants) DeleteTenant(ctx context.Context, name string) error {
+	_, err := s.pool.Exec(ctx, "DROP DATABASE IF EXISTS "+pgx.Identifier{name}.Sanitize())
+	return err
+}
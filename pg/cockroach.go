@@ -0,0 +1,63 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// maxRestarts is how many times a write CockroachDB asks to restart is
+// rerun before its error is recorded.
+const maxRestarts = 10
+
+// restartable reports whether err is CockroachDB asking the client to
+// restart the transaction (SQLSTATE 40001, "restart transaction: ...").
+func restartable(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "40001"
+}
+
+// retry runs fn and, on CockroachDB, reruns it while the server asks for a
+// restart. The op's latency includes every attempt, as a client sees it.
+func (q queries) retry(ctx context.Context, fn func() error) error {
+	err := fn()
+	for i := 0; i < q.restarts && restartable(err) && ctx.Err() == nil; i++ {
+		err = fn()
+	}
+	return err
+}
+
+// exec runs one write statement through retry.
+func (q queries) exec(ctx context.Context, pool *pgxpool.Pool, sql string, args ...any) error {
+	return q.retry(ctx, func() error {
+		_, err := pool.Exec(ctx, sql, args...)
+		return err
+	})
+}
+
+// asOfClause renders params.AsOf as an AS OF SYSTEM TIME clause: follower
+// reads, or a fixed staleness such as 10s.
+func asOfClause(asOf string) string {
+	if asOf == "follower" {
+		return " AS OF SYSTEM TIME follower_read_timestamp()"
+	}
+	d, _ := time.ParseDuration(asOf)
+	return fmt.Sprintf(" AS OF SYSTEM TIME '-%gs'", d.Seconds())
+}
+
+// withAsOf puts clause at the end of sql's FROM clause, where CockroachDB
+// expects AS OF SYSTEM TIME.
+func withAsOf(sql, clause string) string {
+	end := len(sql)
+	for _, kw := range []string{" WHERE ", " GROUP BY ", " ORDER BY ", " LIMIT "} {
+		if i := strings.Index(sql, kw); i >= 0 && i < end {
+			end = i
+		}
+	}
+	return sql[:end] + clause + sql[end:]
+}
@@ -0,0 +1,85 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"tenantsdb-bench/bench"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// RunColdStart closes every connection to the tenant, waits
+// params.HibernateAfter so the platform can scale it to zero, then times a
+// fresh connect plus first query, for params.WakeCycles cycles.
+func RunColdStart(ctx context.Context, proxyCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Println("  PostgreSQL Cold Start Test")
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Printf("  Cycles: %d | Idle before each: %s\n\n", params.WakeCycles, params.HibernateAfter)
+
+	res := &bench.Result{}
+	bench.Stepf("[1/2] Connecting through TenantsDB proxy...")
+	pool, err := Connect(proxyCfg, "disable")
+	if err != nil {
+		bench.Failf("Proxy connection failed: %v", err)
+		return nil
+	}
+	res.Manifest.ProxyVersion = detectVersion(pool)
+	if err := PrepareData(ctx, pool, params); err != nil {
+		pool.Close()
+		bench.Failf("Seed failed: %v", err)
+		return nil
+	}
+	cfg := pool.Config().ConnConfig.Copy()
+	pool.Close()
+	bench.Okf("Data ready, all connections closed")
+
+	bench.Stepf("[2/2] Running wake cycles...")
+	q := newQueries(params)
+	var wake, warm []bench.QueryResult
+	start := time.Now()
+	for c := 1; c <= params.WakeCycles; c++ {
+		bench.Infof("Cycle %d/%d: idling %s...", c, params.WakeCycles, params.HibernateAfter)
+		if !bench.Sleep(ctx, params.HibernateAfter) {
+			break
+		}
+
+		wctx, cancel := context.WithTimeout(ctx, bench.WakeTimeout)
+		qStart := time.Now()
+		conn, err := pgx.ConnectConfig(wctx, cfg.Copy())
+		if err == nil {
+			err = conn.QueryRow(wctx, q.selectByID, rand.Intn(params.SeedRows)+1).Scan(new(int), new(string), new(float64))
+		}
+		cancel()
+		r := bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err, Op: "wake"}
+		wake = append(wake, r)
+		if err != nil {
+			bench.Failf("Wake failed after %s: %v", bench.FmtDur(r.Duration), err)
+			if conn != nil {
+				conn.Close(ctx)
+			}
+			continue
+		}
+		bench.Okf("Woke in %s", bench.FmtDur(r.Duration))
+
+		for i := 0; i < bench.WarmQueries; i++ {
+			qStart := time.Now()
+			err := conn.QueryRow(ctx, q.selectByID, rand.Intn(params.SeedRows)+1).Scan(new(int), new(string), new(float64))
+			warm = append(warm, bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err, Op: "warm"})
+		}
+		conn.Close(ctx)
+	}
+
+	total := time.Since(start)
+	bench.PrintErrors(append(wake, warm...))
+	wakeStats := bench.ComputeStats("Wake (connect + first query)", wake, total)
+	warmStats := bench.ComputeStats("Warm queries", warm, total)
+	bench.PrintStats(wakeStats)
+	bench.PrintStats(warmStats)
+	bench.PrintColdStart(wakeStats, warmStats)
+	res.Stats = append(res.Stats, wakeStats, warmStats)
+	return res
+}
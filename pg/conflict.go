@@ -0,0 +1,160 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"tenantsdb-bench/bench"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RunConflict runs read-modify-write transactions on the hot rows at
+// params.Isolation, retrying serialization failures, through the proxy and
+// (when configured) direct, and compares failure rates and lost updates.
+func RunConflict(ctx context.Context, proxyCfg, directCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Println("  PostgreSQL Isolation Conflict Test")
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Printf("  Transactions: %d | Concurrency: %d | %s on %d hot rows\n\n",
+		params.Queries, params.Concurrency, params.Isolation, params.HotRows)
+
+	res := &bench.Result{}
+	directPool, proxyPool, ok := endpoints(proxyCfg, directCfg, res, 3)
+	if !ok {
+		return nil
+	}
+	defer proxyPool.Close()
+	if directPool != nil {
+		defer directPool.Close()
+	}
+
+	seedPool := proxyPool
+	if directPool != nil {
+		seedPool = directPool
+	}
+	if err := PrepareData(ctx, seedPool, params); err != nil {
+		bench.Failf("Seed failed: %v", err)
+		return nil
+	}
+	bench.Okf("Data ready")
+
+	bench.Stepf("[3/3] Running conflicting transactions...")
+	var directStats *bench.BenchStats
+	if directPool != nil {
+		fmt.Println("\n── Direct PostgreSQL ──")
+		s := bench.RunMultiple(ctx, params.Runs, "Direct "+params.Isolation, func(run int) bench.BenchStats {
+			return conflictPass(bench.Unobserved(ctx), directPool, params, "Direct "+params.Isolation)
+		})
+		bench.PrintStats(s)
+		res.Stats = append(res.Stats, s)
+		directStats = &s
+	}
+
+	fmt.Println("\n── Through TenantsDB Proxy ──")
+	proxyStats := bench.RunMultiple(ctx, params.Runs, "Proxy "+params.Isolation, func(run int) bench.BenchStats {
+		return conflictPass(ctx, proxyPool, params, "Proxy "+params.Isolation)
+	})
+	bench.PrintStats(proxyStats)
+	res.Stats = append(res.Stats, proxyStats)
+
+	// Postgres prevents lost updates at both levels
+	bench.PrintConflicts(directStats, proxyStats, false)
+	if directStats != nil {
+		bench.PrintComparison(proxyStats, *directStats)
+		cmp := bench.Compare(proxyStats, *directStats)
+		res.Comparison = &cmp
+	}
+	return res
+}
+
+// conflictPass runs about params.Queries increments of random hot rows on
+// params.Concurrency workers. Every attempt is one result; the hot rows'
+// balance must grow by exactly the number of commits, and any shortfall is
+// reported as LostUpdates.
+func conflictPass(ctx context.Context, pool *pgxpool.Pool, params bench.BenchParams, label string) bench.BenchStats {
+	q := newQueries(params)
+	iso := pgx.Serializable
+	if params.Isolation == "repeatable-read" {
+		iso = pgx.RepeatableRead
+	}
+	sum := "SELECT COALESCE(SUM(balance), 0) FROM " + q.table + " WHERE id BETWEEN 1 AND $1"
+
+	if err := checkIsolation(ctx, pool, iso); err != nil {
+		bench.Warnf("%v", err)
+	}
+
+	var before float64
+	if err := pool.QueryRow(ctx, sum, params.HotRows).Scan(&before); err != nil {
+		bench.Warnf("Hot row sum: %v", err)
+	}
+
+	results, total := bench.RunWorkers(ctx, label, params.Concurrency, func(worker int) []bench.QueryResult {
+		_, perWorker := bench.Share(max(params.Queries, params.Concurrency), params.Concurrency, worker)
+		var local []bench.QueryResult
+		for i := 0; i < perWorker; i++ {
+			id := rand.Intn(params.HotRows) + 1
+			for attempt := 0; attempt <= bench.ConflictRetries; attempt++ {
+				qStart := time.Now()
+				err := classify(increment(ctx, pool, q, iso, id))
+				local = append(local, bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err, Op: "txn"})
+				if !errors.Is(err, bench.ErrSerialization) && !errors.Is(err, bench.ErrDeadlock) {
+					break
+				}
+			}
+		}
+		return local
+	})
+	bench.PrintErrors(results)
+	stats := bench.ComputeStats(label, results, total)
+
+	var after float64
+	if err := pool.QueryRow(ctx, sum, params.HotRows).Scan(&after); err != nil {
+		bench.Warnf("Hot row sum: %v", err)
+		return stats
+	}
+	stats.LostUpdates = max(stats.Total-stats.Errors-int(after-before+0.5), 0)
+	return stats
+}
+
+// increment reads a row's balance and writes it back plus one, in one
+// transaction at iso.
+func increment(ctx context.Context, pool *pgxpool.Pool, q queries, iso pgx.TxIsoLevel, id int) error {
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: iso})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var balance float64
+	if err := tx.QueryRow(ctx, "SELECT balance FROM "+q.table+" WHERE id = $1", id).Scan(&balance); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, "UPDATE "+q.table+" SET balance = $1 WHERE id = $2", balance+1, id); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// checkIsolation reports an error when a transaction begun at iso runs at
+// another level, as a proxy that rewrites BEGIN would make it.
+func checkIsolation(ctx context.Context, pool *pgxpool.Pool, iso pgx.TxIsoLevel) error {
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: iso})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var got string
+	if err := tx.QueryRow(ctx, "SELECT current_setting('transaction_isolation')").Scan(&got); err != nil {
+		return err
+	}
+	if got != string(iso) {
+		return fmt.Errorf("transaction runs at %s, asked for %s", got, iso)
+	}
+	return nil
+}
@@ -4,12 +4,14 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"tenantsdb-bench/bench"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -17,15 +19,39 @@ func Connect(c bench.ConnConfig, sslmode string) (*pgxpool.Pool, error) {
 	if sslmode == "" {
 		sslmode = "disable"
 	}
-	dsn := fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=%s",
-		c.User, c.Password, c.Host, c.Port, c.Database, sslmode)
-
-	config, err := pgxpool.ParseConfig(dsn)
+	config, err := pgxpool.ParseConfig(dsnOf(c, sslmode))
 	if err != nil {
 		return nil, err
 	}
-	config.MaxConns = 10
-	config.MinConns = 2
+	if c.DSN != "" {
+		// Tenant loops override the database; env/file passwords fill a gap
+		if c.Database != "" {
+			config.ConnConfig.Database = c.Database
+		}
+		if config.ConnConfig.Password == "" {
+			config.ConnConfig.Password = c.Password
+		}
+	}
+	if c.SearchPath != "" {
+		config.ConnConfig.RuntimeParams["search_path"] = pgx.Identifier{c.SearchPath}.Sanitize()
+	}
+	if bench.Slow != nil {
+		config.ConnConfig.Tracer = slowTracer{bench.Slow}
+	}
+	if !strings.Contains(c.DSN, "pool_max_conns") {
+		config.MaxConns = 10
+	}
+	if !strings.Contains(c.DSN, "pool_min_conns") {
+		config.MinConns = 2
+	}
+	switch c.Strategy {
+	case "per-query":
+		// Every release closes the connection, so each query dials anew
+		config.MinConns = 0
+		config.AfterRelease = func(*pgx.Conn) bool { return false }
+	case "single":
+		config.MaxConns, config.MinConns = 1, 1
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -39,170 +65,178 @@ func Connect(c bench.ConnConfig, sslmode string) (*pgxpool.Pool, error) {
 		pool.Close()
 		return nil, err
 	}
+	bench.Debugf("Connected to %s:%d/%s (pool %d-%d)", config.ConnConfig.Host, config.ConnConfig.Port, config.ConnConfig.Database, config.MinConns, config.MaxConns)
 	return pool, nil
 }
 
-func SeedData(pool *pgxpool.Pool, rows int) error {
-	ctx := context.Background()
-	var count int
-	err := pool.QueryRow(ctx, "SELECT COUNT(*) FROM accounts").Scan(&count)
+// dsnOf is the connection string for c: its DSN, or one built from its
+// fields.
+func dsnOf(c bench.ConnConfig, sslmode string) string {
+	if c.DSN != "" {
+		return c.DSN
+	}
+	return fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=%s",
+		c.User, c.Password, c.Host, c.Port, c.Database, sslmode)
+}
+
+// ServerVersion returns SELECT version() for the connection. Through the proxy
+// this is whatever backend the proxy routed the tenant to.
+func ServerVersion(pool *pgxpool.Pool) (string, error) {
+	var v string
+	err := pool.QueryRow(context.Background(), "SELECT version()").Scan(&v)
+	return v, err
+}
+
+// detectVersion prints the server version and returns it ("" if unknown).
+func detectVersion(pool *pgxpool.Pool) string {
+	v, err := ServerVersion(pool)
 	if err != nil {
-		return fmt.Errorf("seed check: %w", err)
+		bench.Warnf("Version check failed: %v", err)
+		return ""
 	}
-	if count >= rows {
-		fmt.Printf("  Data already seeded (%d rows)\n", count)
-		return nil
+	bench.Infof("Server version: %s", bench.ShortVersion(v))
+	return v
+}
+
+// endpoints connects through the proxy and, when directCfg is set, directly,
+// printing steps 1 and 2 of steps and recording versions in res. direct is
+// nil without a direct endpoint; the caller closes both pools.
+func endpoints(proxyCfg, directCfg bench.ConnConfig, res *bench.Result, steps int) (direct, proxy *pgxpool.Pool, ok bool) {
+	if directCfg.IsSet() {
+		bench.Stepf("[1/%d] Connecting directly to PostgreSQL...", steps)
+		p, err := Connect(directCfg, "disable")
+		if err != nil {
+			bench.Failf("Direct connection failed: %v", err)
+			return nil, nil, false
+		}
+		direct = p
+		res.Manifest.BackendVersion = detectVersion(p)
+		bench.Okf("Connected")
+	} else {
+		bench.Stepf("[1/%d] No direct endpoint, measuring the proxy only", steps)
 	}
 
-	fmt.Printf("  Seeding %d rows...\n", rows)
-	_, err = pool.Exec(ctx, fmt.Sprintf(`
-		INSERT INTO accounts (name, balance)
-		SELECT 'user_' || i, (random() * 10000)::decimal(15,2)
-		FROM generate_series(1, %d) i
-		ON CONFLICT DO NOTHING
-	`, rows))
-	return err
+	bench.Stepf("[2/%d] Connecting through TenantsDB proxy...", steps)
+	p, err := Connect(proxyCfg, "disable")
+	if err != nil {
+		bench.Failf("Proxy connection failed: %v", err)
+		if direct != nil {
+			direct.Close()
+		}
+		return nil, nil, false
+	}
+	res.Manifest.ProxyVersion = detectVersion(p)
+	bench.Okf("Connected")
+	return direct, p, true
 }
 
 // RunQueries runs a fixed number of queries (count-based mode).
-func RunQueries(pool *pgxpool.Pool, params bench.BenchParams, label string) bench.BenchStats {
-	ctx := context.Background()
+func RunQueries(ctx context.Context, pool *pgxpool.Pool, params bench.BenchParams, label string) bench.BenchStats {
 	maxID := params.SeedRows
+	q := newQueries(params)
+	op := oltpOp(params)
 
 	// Warmup
-	fmt.Printf("  Warming up (%d queries)...\n", params.Warmup)
+	bench.Infof("Warming up (%d queries)...", params.Warmup)
 	for i := 0; i < params.Warmup; i++ {
 		id := rand.Intn(maxID) + 1
-		pool.QueryRow(ctx, "SELECT id, name, balance FROM accounts WHERE id = $1", id).Scan(new(int), new(string), new(float64))
+		pool.QueryRow(ctx, q.selectByID, id).Scan(new(int), new(string), new(float64))
 	}
 
 	// Benchmark
-	fmt.Printf("  Running %d queries (%d concurrent)...\n", params.Queries, params.Concurrency)
-
-	results := make([]bench.QueryResult, params.Queries)
-	queriesPerWorker := params.Queries / params.Concurrency
+	bench.Infof("Running %d queries (%d concurrent)...", params.Queries, params.Concurrency)
 
+	workers := params.Concurrency - params.AggWorkers
+	progress := bench.NewProgress(params.Queries)
 	start := time.Now()
+	sink := bench.NewSink(ctx, label, start, params)
+
+	// Aggregation workers run alongside until the counted queries are done
+	stopAgg := bench.Background(params.AggWorkers, func() bench.QueryResult {
+		return aggQuery(ctx, pool, q, maxID)
+	})
 
 	var wg sync.WaitGroup
-	for w := 0; w < params.Concurrency; w++ {
+	for w := 0; w < workers; w++ {
 		wg.Add(1)
 		go func(workerID int) {
 			defer wg.Done()
-			offset := workerID * queriesPerWorker
-
-			for i := 0; i < queriesPerWorker; i++ {
-				idx := offset + i
-				qStart := time.Now()
-
-				if rand.Intn(100) < 80 {
-					id := rand.Intn(maxID) + 1
-					var rID int
-					var rName string
-					var rBalance float64
-					err := pool.QueryRow(ctx, "SELECT id, name, balance FROM accounts WHERE id = $1", id).Scan(&rID, &rName, &rBalance)
-					results[idx] = bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err}
-				} else {
-					id := rand.Intn(maxID) + 1
-					delta := rand.Float64()*200 - 100
-					_, err := pool.Exec(ctx, "UPDATE accounts SET balance = balance + $1 WHERE id = $2", delta, id)
-					results[idx] = bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err}
-				}
+			bench.Pin(workerID)
+			_, count := bench.Share(params.Queries, workers, workerID)
+
+			out := sink.Worker(workerID, "")
+			for i := 0; i < count; i++ {
+				out.Add(op(ctx, pool, q, maxID))
+				progress.Add(1)
 			}
+			out.Done()
 		}(w)
 	}
 	wg.Wait()
+	progress.Done()
 
 	totalDuration := time.Since(start)
-
-	errCount := 0
-	for _, r := range results {
-		if r.Err != nil && errCount < 5 {
-			fmt.Printf("  ⚠ Error: %v\n", r.Err)
-			errCount++
-		}
-	}
-
-	return bench.ComputeStats(label, results, totalDuration)
+	sink.AddResults(stopAgg())
+	return sink.Stats(totalDuration)
 }
 
 // RunQueriesTimed runs queries for a fixed duration (time-based mode).
 // Returns results collected during the duration window.
-func RunQueriesTimed(pool *pgxpool.Pool, params bench.BenchParams, label string) bench.BenchStats {
+func RunQueriesTimed(ctx context.Context, pool *pgxpool.Pool, params bench.BenchParams, label string) bench.BenchStats {
 	if params.Duration <= 0 {
-		return RunQueries(pool, params, label)
+		return RunQueries(ctx, pool, params, label)
 	}
 
-	ctx := context.Background()
 	maxID := params.SeedRows
+	q := newQueries(params)
+	op := oltpOp(params)
 
 	// Warmup
-	fmt.Printf("  Warming up (%d queries)...\n", params.Warmup)
+	bench.Infof("Warming up (%d queries)...", params.Warmup)
 	for i := 0; i < params.Warmup; i++ {
 		id := rand.Intn(maxID) + 1
-		pool.QueryRow(ctx, "SELECT id, name, balance FROM accounts WHERE id = $1", id).Scan(new(int), new(string), new(float64))
+		pool.QueryRow(ctx, q.selectByID, id).Scan(new(int), new(string), new(float64))
 	}
 
-	fmt.Printf("  Running for %s (%d concurrent)...\n", params.Duration, params.Concurrency)
+	bench.Infof("Running for %s (%d concurrent)...", params.Duration, params.Concurrency)
 
-	var mu sync.Mutex
-	var results []bench.QueryResult
 	var stopped atomic.Bool
 
 	start := time.Now()
+	sink := bench.NewSink(ctx, label, start, params)
+	stopAgg := bench.Background(params.AggWorkers, func() bench.QueryResult {
+		return aggQuery(ctx, pool, q, maxID)
+	})
 
 	// Stop signal after duration
 	time.AfterFunc(params.Duration, func() { stopped.Store(true) })
 
 	var wg sync.WaitGroup
-	for w := 0; w < params.Concurrency; w++ {
+	for w := 0; w < params.Concurrency-params.AggWorkers; w++ {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			var local []bench.QueryResult
-
-			for !stopped.Load() {
-				qStart := time.Now()
-
-				if rand.Intn(100) < 80 {
-					id := rand.Intn(maxID) + 1
-					var rID int
-					var rName string
-					var rBalance float64
-					err := pool.QueryRow(ctx, "SELECT id, name, balance FROM accounts WHERE id = $1", id).Scan(&rID, &rName, &rBalance)
-					local = append(local, bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err})
-				} else {
-					id := rand.Intn(maxID) + 1
-					delta := rand.Float64()*200 - 100
-					_, err := pool.Exec(ctx, "UPDATE accounts SET balance = balance + $1 WHERE id = $2", delta, id)
-					local = append(local, bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err})
-				}
+			bench.Pin(w)
+			out := sink.Worker(w, "")
+			for !stopped.Load() && ctx.Err() == nil {
+				out.Add(op(ctx, pool, q, maxID))
 			}
-
-			mu.Lock()
-			results = append(results, local...)
-			mu.Unlock()
+			out.Done()
 		}()
 	}
 	wg.Wait()
 
-	totalDuration := time.Since(start)
+	sink.AddResults(stopAgg())
+	return sink.Stats(time.Since(start))
+}
 
-	errCount := 0
-	for _, r := range results {
-		if r.Err != nil && errCount < 5 {
-			fmt.Printf("  ⚠ Error: %v\n", r.Err)
-			errCount++
+// PickRunner returns the right runner based on params.Duration, checking
+// data integrity around it when asked.
+func PickRunner(ctx context.Context, pool *pgxpool.Pool, params bench.BenchParams, label string) bench.BenchStats {
+	return withIntegrity(ctx, []*pgxpool.Pool{pool}, []string{params.TableName()}, params, func() bench.BenchStats {
+		if params.Duration > 0 {
+			return RunQueriesTimed(ctx, pool, params, label)
 		}
-	}
-
-	return bench.ComputeStats(label, results, totalDuration)
+		return RunQueries(ctx, pool, params, label)
+	})
 }
-
-// PickRunner returns the right runner based on params.Duration.
-func PickRunner(pool *pgxpool.Pool, params bench.BenchParams, label string) bench.BenchStats {
-	if params.Duration > 0 {
-		return RunQueriesTimed(pool, params, label)
-	}
-	return RunQueries(pool, params, label)
-}
\ No newline at end of file
@@ -0,0 +1,139 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"tenantsdb-bench/bench"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RunCursor declares server-side cursors and fetches them in batches,
+// timing every round trip, through the proxy and (when configured) direct.
+// A proxy that cannot keep a portal open across statements fails here.
+func RunCursor(ctx context.Context, proxyCfg, directCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Println("  PostgreSQL Cursor Fetch Benchmark")
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Printf("  Fetches: %d | Concurrency: %d | %d fetches of %d rows per cursor\n\n",
+		params.Queries, params.Concurrency, params.CursorFetches, params.FetchSize)
+
+	res := &bench.Result{}
+	directPool, proxyPool, ok := endpoints(proxyCfg, directCfg, res, 3)
+	if !ok {
+		return nil
+	}
+	defer proxyPool.Close()
+	if directPool != nil {
+		defer directPool.Close()
+	}
+
+	seedPool := proxyPool
+	if directPool != nil {
+		seedPool = directPool
+	}
+	if err := PrepareData(ctx, seedPool, params); err != nil {
+		bench.Failf("Seed failed: %v", err)
+		return nil
+	}
+	bench.Okf("Data ready")
+
+	bench.Stepf("[3/3] Fetching cursors...")
+	var directStats bench.BenchStats
+	if directPool != nil {
+		fmt.Println("\n── Direct PostgreSQL ──")
+		directStats = bench.RunMultiple(ctx, params.Runs, "Direct cursor", func(run int) bench.BenchStats {
+			return cursorPass(bench.Unobserved(ctx), directPool, params, "Direct cursor")
+		})
+		bench.PrintStats(directStats)
+		res.Stats = append(res.Stats, directStats)
+	}
+
+	fmt.Println("\n── Through TenantsDB Proxy ──")
+	proxyStats := bench.RunMultiple(ctx, params.Runs, "Proxy cursor", func(run int) bench.BenchStats {
+		return cursorPass(ctx, proxyPool, params, "Proxy cursor")
+	})
+	bench.PrintStats(proxyStats)
+	res.Stats = append(res.Stats, proxyStats)
+
+	if directPool != nil {
+		bench.PrintComparison(proxyStats, directStats)
+		cmp := bench.Compare(proxyStats, directStats)
+		res.Comparison = &cmp
+	}
+	return res
+}
+
+// cursorPass runs cursor sessions on params.Concurrency workers until about
+// params.Queries fetches are done.
+func cursorPass(ctx context.Context, pool *pgxpool.Pool, params bench.BenchParams, label string) bench.BenchStats {
+	sessions := max(params.Queries/params.CursorFetches/params.Concurrency, 1)
+	results, total := bench.RunWorkers(ctx, label, params.Concurrency, func(worker int) []bench.QueryResult {
+		var local []bench.QueryResult
+		for i := 0; i < sessions; i++ {
+			local = append(local, cursorSession(ctx, pool, params)...)
+		}
+		return local
+	})
+	bench.PrintErrors(results)
+	return bench.ComputeStats(label, results, total)
+}
+
+// cursorSession opens a cursor at a random id, fetches it in batches and
+// closes it, one result per round trip. A fetch returning fewer rows than
+// asked before the end of the table is an error: the portal was lost.
+func cursorSession(ctx context.Context, pool *pgxpool.Pool, params bench.BenchParams) []bench.QueryResult {
+	var out []bench.QueryResult
+	record := func(op string, qStart time.Time, err error, rows int) error {
+		out = append(out, bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err, Op: op, Rows: rows})
+		return err
+	}
+
+	qStart := time.Now()
+	tx, err := pool.Begin(ctx)
+	if err == nil {
+		defer tx.Rollback(ctx)
+		from := rand.Intn(params.SeedRows) + 1
+		_, err = tx.Exec(ctx, "DECLARE bench_cur NO SCROLL CURSOR FOR SELECT id, name, balance FROM "+
+			tableIdent(params)+" WHERE id >= $1 ORDER BY id", from)
+	}
+	if record("declare", qStart, err, 0) != nil {
+		return out
+	}
+
+	fetch := fmt.Sprintf("FETCH %d FROM bench_cur", params.FetchSize)
+	for i := 0; i < params.CursorFetches; i++ {
+		qStart := time.Now()
+		rows, err := tx.Query(ctx, fetch)
+		n := 0
+		if err == nil {
+			for rows.Next() {
+				n++
+			}
+			rows.Close()
+			err = rows.Err()
+		}
+		if err == nil && n < params.FetchSize {
+			// Past the end of the table is fine; anything else lost rows
+			if n == 0 && i == 0 {
+				err = fmt.Errorf("first fetch returned no rows")
+			}
+			record("fetch", qStart, err, n)
+			break
+		}
+		if record("fetch", qStart, err, n) != nil {
+			return out
+		}
+	}
+
+	qStart = time.Now()
+	_, err = tx.Exec(ctx, "CLOSE bench_cur")
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	record("close", qStart, err, 0)
+	return out
+}
@@ -0,0 +1,50 @@
+package pg
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+
+	"tenantsdb-bench/bench"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// sslRequest is the startup message asking a Postgres server for TLS.
+var sslRequest = []byte{0, 0, 0, 8, 0x04, 0xd2, 0x16, 0x2f}
+
+// Address returns the host and port cfg connects to, from its DSN if set.
+func Address(c bench.ConnConfig) (string, int, error) {
+	if c.DSN == "" {
+		return c.Host, c.Port, nil
+	}
+	config, err := pgconn.ParseConfig(c.DSN)
+	if err != nil {
+		return "", 0, err
+	}
+	return config.Host, int(config.Port), nil
+}
+
+// ProbeTLS sends an SSLRequest and, if the server answers 'S', completes a
+// TLS handshake without verifying the certificate.
+func ProbeTLS(c net.Conn, host string) (*tls.Conn, error) {
+	if _, err := c.Write(sslRequest); err != nil {
+		return nil, err
+	}
+	answer := make([]byte, 1)
+	if _, err := c.Read(answer); err != nil {
+		return nil, err
+	}
+	switch answer[0] {
+	case 'N':
+		return nil, nil
+	case 'S':
+	default:
+		return nil, fmt.Errorf("unexpected SSLRequest answer %q", answer[0])
+	}
+	tc := tls.Client(c, &tls.Config{ServerName: host, InsecureSkipVerify: true})
+	if err := tc.Handshake(); err != nil {
+		return nil, err
+	}
+	return tc, nil
+}
@@ -0,0 +1,86 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+
+	"tenantsdb-bench/bench"
+	"tenantsdb-bench/control"
+)
+
+// Driver runs the Postgres tests behind bench.NewRunner and the run command.
+type Driver struct {
+	API *control.Client // management API, needed by the provision test
+}
+
+func (Driver) Name() string { return "postgres" }
+
+// Run runs one test. A nil result with ErrIncomplete means the runner
+// stopped early and printed why.
+func (d Driver) Run(ctx context.Context, test string, proxyCfg, directCfg bench.ConnConfig, params bench.BenchParams) (*bench.Result, error) {
+	var res *bench.Result
+	switch test {
+	case "overhead":
+		res = RunOverhead(ctx, proxyCfg, directCfg, params)
+	case "throughput":
+		res = RunThroughput(ctx, proxyCfg, params)
+	case "multi":
+		res = RunMultiTenant(ctx, proxyCfg, params)
+	case "isolation":
+		res = RunIsolation(ctx, proxyCfg, params)
+	case "scale":
+		res = RunScale(ctx, proxyCfg, params)
+	case "leakage":
+		res = RunLeakage(ctx, proxyCfg, params)
+	case "replica":
+		res = RunReplica(ctx, proxyCfg, params)
+	case "failover":
+		res = RunFailover(ctx, proxyCfg, params)
+	case "connlimit":
+		res = RunConnLimit(ctx, proxyCfg, params)
+	case "auth":
+		res = RunAuth(ctx, proxyCfg, directCfg, params)
+	case "quota":
+		res = RunQuota(ctx, proxyCfg, params)
+	case "idle":
+		res = RunIdle(ctx, proxyCfg, params)
+	case "soak":
+		res = RunSoak(ctx, proxyCfg, params)
+	case "coldstart":
+		res = RunColdStart(ctx, proxyCfg, params)
+	case "provision":
+		res = RunProvision(ctx, proxyCfg, params, d.API)
+	case "churn":
+		res = RunChurn(ctx, proxyCfg, params)
+	case "stream":
+		res = RunStream(ctx, proxyCfg, directCfg, params)
+	case "ingest":
+		res = RunIngest(ctx, proxyCfg, directCfg, params)
+	case "notify":
+		res = RunNotify(ctx, proxyCfg, directCfg, params)
+	case "cursor":
+		res = RunCursor(ctx, proxyCfg, directCfg, params)
+	case "session":
+		res = RunSession(ctx, proxyCfg, directCfg, params)
+	case "temptable":
+		res = RunTempTable(ctx, proxyCfg, directCfg, params)
+	case "savepoint":
+		res = RunSavepoint(ctx, proxyCfg, directCfg, params)
+	case "conflict":
+		res = RunConflict(ctx, proxyCfg, directCfg, params)
+	case "prepared":
+		res = RunPrepared(ctx, proxyCfg, directCfg, params)
+	case "inlist":
+		res = RunInList(ctx, proxyCfg, directCfg, params)
+	case "verify":
+		res = RunVerify(ctx, proxyCfg, directCfg, params)
+	case "advisory":
+		res = RunAdvisory(ctx, proxyCfg, directCfg, params)
+	default:
+		return nil, fmt.Errorf("%w: %s", bench.ErrUnknownTest, test)
+	}
+	if res == nil {
+		return nil, bench.ErrIncomplete
+	}
+	return res, nil
+}
@@ -0,0 +1,84 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"tenantsdb-bench/bench"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// RunIdle opens one connection per params.IdleIntervals, leaves each idle
+// for its interval and then queries it, reporting at which idle time the
+// proxy drops connections and what the client sees when it does.
+func RunIdle(ctx context.Context, proxyCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Println("  PostgreSQL Idle Timeout Test")
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Printf("  Idle intervals: %v\n\n", params.IdleIntervals)
+
+	res := &bench.Result{}
+	bench.Stepf("[1/2] Connecting through TenantsDB proxy...")
+	pool, err := Connect(proxyCfg, "disable")
+	if err != nil {
+		bench.Failf("Proxy connection failed: %v", err)
+		return nil
+	}
+	res.Manifest.ProxyVersion = detectVersion(pool)
+	cfg := pool.Config().ConnConfig.Copy()
+	pool.Close()
+
+	conns := make([]*pgx.Conn, len(params.IdleIntervals))
+	for i := range conns {
+		conn, err := pgx.ConnectConfig(ctx, cfg.Copy())
+		if err != nil {
+			bench.Failf("Connection %d failed: %v", i+1, err)
+			return nil
+		}
+		defer conn.Close(ctx)
+		conns[i] = conn
+	}
+	bench.Okf("%d connections open", len(conns))
+
+	bench.Stepf("[2/2] Idling (longest %s)...", params.IdleIntervals[len(params.IdleIntervals)-1])
+	probes := make([]bench.IdleProbe, len(conns))
+	results := make([]bench.QueryResult, len(conns))
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i, idle := range params.IdleIntervals {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			bench.Sleep(ctx, idle)
+			qctx, cancel := context.WithTimeout(ctx, bench.IdleQueryTimeout)
+			defer cancel()
+			qStart := time.Now()
+			_, err := conns[i].Exec(qctx, "SELECT 1")
+			p := bench.IdleProbe{Idle: idle, Alive: err == nil, Latency: time.Since(qStart), Hung: qctx.Err() != nil}
+			if err != nil {
+				p.Err = err.Error()
+				var pgErr *pgconn.PgError
+				if errors.As(err, &pgErr) {
+					p.Code = "SQLSTATE " + pgErr.Code
+				}
+				bench.Failf("After %s idle: %v", idle, err)
+			} else {
+				bench.Okf("After %s idle: alive", idle)
+			}
+			probes[i] = p
+			results[i] = bench.QueryResult{At: qStart, Duration: p.Latency, Err: err, Op: "query"}
+		}()
+	}
+	wg.Wait()
+
+	stats := bench.ComputeStats("Query after idle", results, time.Since(start))
+	bench.PrintIdle(probes)
+	res.Stats = append(res.Stats, stats)
+	res.Idle = probes
+	return res
+}
@@ -0,0 +1,131 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"tenantsdb-bench/bench"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RunIngest measures COPY FROM STDIN throughput into a scratch table,
+// through the proxy and (when configured) direct.
+func RunIngest(ctx context.Context, proxyCfg, directCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Println("  PostgreSQL COPY Ingest Benchmark")
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Printf("  Rows: %d per pass | %d rows per COPY | Row payload: %d bytes\n\n",
+		params.IngestRows, params.IngestBatch, params.RowBytes)
+
+	res := &bench.Result{}
+
+	directPool, proxyPool, ok := endpoints(proxyCfg, directCfg, res, 3)
+	if !ok {
+		return nil
+	}
+	defer proxyPool.Close()
+	if directPool != nil {
+		defer directPool.Close()
+	}
+
+	ddlPool := proxyPool
+	if directPool != nil {
+		ddlPool = directPool
+	}
+	table := qualify(params, params.IngestTable())
+	_, err := ddlPool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS `+table.Sanitize()+` (
+			id INT NOT NULL,
+			name TEXT NOT NULL,
+			balance DECIMAL(15,2) NOT NULL,
+			payload TEXT NOT NULL
+		)`)
+	if err != nil {
+		bench.Failf("Create %s failed: %v", params.IngestTable(), err)
+		return nil
+	}
+
+	bench.Stepf("[3/3] Running COPY passes...")
+	src := ingestRows(params)
+	pass := func(pool *pgxpool.Pool, label string) bench.BenchStats {
+		return copyPass(ctx, pool, params, src, label)
+	}
+
+	var directStats, proxyStats bench.BenchStats
+	if directPool != nil {
+		fmt.Println("\n── Direct PostgreSQL ──")
+		directStats = bench.RunMultiple(ctx, params.Runs, "Direct COPY", func(run int) bench.BenchStats {
+			return pass(directPool, "Direct COPY")
+		})
+		bench.PrintStats(directStats)
+		res.Stats = append(res.Stats, directStats)
+	}
+
+	fmt.Println("\n── Through TenantsDB Proxy ──")
+	proxyStats = bench.RunMultiple(ctx, params.Runs, "Proxy COPY", func(run int) bench.BenchStats {
+		return pass(proxyPool, "Proxy COPY")
+	})
+	bench.PrintStats(proxyStats)
+	res.Stats = append(res.Stats, proxyStats)
+	if proxyStats.Errors == proxyStats.Total {
+		fmt.Println()
+		bench.Failf("The proxy refused every COPY")
+	}
+
+	if directPool != nil {
+		bench.PrintComparison(proxyStats, directStats)
+		cmp := bench.Compare(proxyStats, directStats)
+		res.Comparison = &cmp
+	}
+	return res
+}
+
+// ingestRows is the row source for the ingest test.
+func ingestRows(params bench.BenchParams) seedTable {
+	return seedTable{params.IngestTable(), []string{"id", "name", "balance", "payload"}, func(n int) []any {
+		return []any{n, fmt.Sprintf("user_%d", n), accountBalance(n), bench.Payload(n, params.RowBytes)}
+	}}
+}
+
+// copyPass truncates the ingest table and loads params.IngestRows rows into
+// it, one COPY per batch. Each COPY is one result.
+func copyPass(ctx context.Context, pool *pgxpool.Pool, params bench.BenchParams, t seedTable, label string) bench.BenchStats {
+	table := qualify(params, t.name)
+	if _, err := pool.Exec(ctx, "TRUNCATE "+table.Sanitize()); err != nil {
+		bench.Warnf("Truncate: %v", err)
+	}
+
+	// Throughput is over time spent in COPY, not in sizing the batches
+	var results []bench.QueryResult
+	var busy time.Duration
+	for from := 1; from <= params.IngestRows; from += params.IngestBatch {
+		to := min(from+params.IngestBatch-1, params.IngestRows)
+		size := copyBytes(t, from, to)
+		src := &seqRows{next: from, last: to, row: t.row}
+
+		qStart := time.Now()
+		n, err := pool.CopyFrom(ctx, table, t.cols, src)
+		r := bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err, Rows: int(n)}
+		if err == nil {
+			r.Bytes = size
+		} else if len(results) == 0 {
+			bench.Warnf("COPY failed: %v", err)
+		}
+		busy += r.Duration
+		results = append(results, r)
+	}
+	return bench.ComputeStats(label, results, busy)
+}
+
+// copyBytes is the text size of rows from..to, an estimate of what COPY sent.
+func copyBytes(t seedTable, from, to int) int {
+	size := 0
+	for n := from; n <= to; n++ {
+		for _, v := range t.row(n) {
+			size += len(fmt.Sprint(v)) + 1
+		}
+	}
+	return size
+}
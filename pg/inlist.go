@@ -0,0 +1,107 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"tenantsdb-bench/bench"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RunInList runs point reads of growing IN-lists, one bound parameter per
+// id, through the proxy and (when configured) direct.
+func RunInList(ctx context.Context, proxyCfg, directCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Println("  PostgreSQL IN-List Scaling Test")
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Printf("  Parameters: %v | Queries: %d each | Concurrency: %d\n\n",
+		params.InParams, params.Queries, params.Concurrency)
+
+	res := &bench.Result{}
+	directPool, proxyPool, ok := endpoints(proxyCfg, directCfg, res, 3)
+	if !ok {
+		return nil
+	}
+	defer proxyPool.Close()
+	if directPool != nil {
+		defer directPool.Close()
+	}
+
+	seedPool := proxyPool
+	if directPool != nil {
+		seedPool = directPool
+	}
+	if err := PrepareData(ctx, seedPool, params); err != nil {
+		bench.Failf("Seed failed: %v", err)
+		return nil
+	}
+	bench.Okf("Data ready")
+
+	bench.Stepf("[3/3] Running IN-list queries...")
+	var points []bench.InListPoint
+	for _, n := range params.InParams {
+		point := bench.InListPoint{Params: n}
+		if directPool != nil {
+			fmt.Printf("\n── Direct, %d parameters ──\n", n)
+			s := inListPass(bench.Unobserved(ctx), directPool, params, n, fmt.Sprintf("Direct IN %d", n))
+			bench.PrintStats(s)
+			point.Direct = &s
+			res.Stats = append(res.Stats, s)
+		}
+		fmt.Printf("\n── Through Proxy, %d parameters ──\n", n)
+		point.Proxy = inListPass(ctx, proxyPool, params, n, fmt.Sprintf("Proxy IN %d", n))
+		bench.PrintStats(point.Proxy)
+		res.Stats = append(res.Stats, point.Proxy)
+		points = append(points, point)
+	}
+
+	bench.PrintInList(points)
+	return res
+}
+
+// inListPass runs params.Queries reads of n distinct random ids on
+// params.Concurrency workers, checking each returns n rows.
+func inListPass(ctx context.Context, pool *pgxpool.Pool, params bench.BenchParams, n int, label string) bench.BenchStats {
+	marks := make([]string, n)
+	for i := range marks {
+		marks[i] = fmt.Sprintf("$%d", i+1)
+	}
+	query := "SELECT id, balance FROM " + tableIdent(params) + " WHERE id IN (" + strings.Join(marks, ", ") + ")"
+
+	results, total := bench.RunWorkers(ctx, label, params.Concurrency, func(worker int) []bench.QueryResult {
+		_, perWorker := bench.Share(max(params.Queries, params.Concurrency), params.Concurrency, worker)
+		local := make([]bench.QueryResult, 0, perWorker)
+		for i := 0; i < perWorker; i++ {
+			args := make([]any, n)
+			for j, id := range bench.DistinctIDs(n, params.SeedRows) {
+				args[j] = id
+			}
+			qStart := time.Now()
+			got, err := countRows(ctx, pool, query, args...)
+			if err == nil && got != n {
+				err = fmt.Errorf("IN-list of %d ids returned %d rows", n, got)
+			}
+			local = append(local, bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err})
+		}
+		return local
+	})
+	bench.PrintErrors(results)
+	return bench.ComputeStats(label, results, total)
+}
+
+// countRows runs a query and counts the rows of its result.
+func countRows(ctx context.Context, pool *pgxpool.Pool, sql string, args ...any) (int, error) {
+	rows, err := pool.Query(ctx, sql, args...)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for rows.Next() {
+		n++
+	}
+	rows.Close()
+	return n, rows.Err()
+}
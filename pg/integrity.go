@@ -0,0 +1,62 @@
+package pg
+
+import (
+	"context"
+
+	"tenantsdb-bench/bench"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// snapshot reads the integrity invariants of the benchmark tables.
+func snapshot(ctx context.Context, pool *pgxpool.Pool, params bench.BenchParams) (bench.Snapshot, error) {
+	var s bench.Snapshot
+	nulls := "name IS NULL OR balance IS NULL"
+	if params.RowBytes > 0 {
+		nulls += " OR payload IS NULL"
+	}
+	if params.Documents {
+		nulls += " OR doc IS NULL"
+	}
+	err := pool.QueryRow(ctx, "SELECT COUNT(*), COUNT(*) FILTER (WHERE "+nulls+"), COALESCE(SUM(balance), 0) FROM "+
+		tableIdent(params)+" WHERE id > 0").Scan(&s.Rows, &s.Nulls, &s.Sum)
+	if err != nil || !params.Relational {
+		return s, err
+	}
+	err = pool.QueryRow(ctx, "SELECT (SELECT COUNT(*) FROM "+qualify(params, params.OrdersTable()).Sanitize()+
+		") + (SELECT COUNT(*) FROM "+qualify(params, params.ItemsTable()).Sanitize()+")").Scan(&s.Related)
+	return s, err
+}
+
+// withIntegrity runs run between two snapshots of each pool's tables when
+// params.CheckIntegrity is set, recording broken invariants in the stats.
+// Tenants share the run's queries, so each is allowed the whole budget.
+func withIntegrity(ctx context.Context, pools []*pgxpool.Pool, names []string, params bench.BenchParams, run func() bench.BenchStats) bench.BenchStats {
+	if !params.CheckIntegrity {
+		return run()
+	}
+	before := make([]bench.Snapshot, len(pools))
+	for i, pool := range pools {
+		s, err := snapshot(ctx, pool, params)
+		if err != nil {
+			bench.Warnf("Integrity snapshot of %s failed: %v", names[i], err)
+			return run()
+		}
+		before[i] = s
+	}
+
+	stats := run()
+	for i, pool := range pools {
+		after, err := snapshot(ctx, pool, params)
+		if err != nil {
+			bench.Warnf("Integrity snapshot of %s failed: %v", names[i], err)
+			continue
+		}
+		v := bench.CheckIntegrity(before[i], after, stats.Total)
+		bench.PrintIntegrity(names[i], v)
+		for _, msg := range v {
+			stats.Violations = append(stats.Violations, names[i]+": "+msg)
+		}
+	}
+	return stats
+}
@@ -0,0 +1,171 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"tenantsdb-bench/bench"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// markerBase is the id of the first tenant's marker row; tenant i writes
+// markerBase - i, far below the negative ids the upsert workload uses.
+const markerBase = -2_000_000_000
+
+// RunLeakage writes a marker row naming its tenant into every tenant, then
+// probes each tenant for markers under concurrent workload load. Seeing any
+// marker but its own means the proxy routed a query to the wrong tenant.
+func RunLeakage(ctx context.Context, proxyCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
+	tenants := TenantList()[:10]
+	if len(params.Tenants) > 0 {
+		tenants = params.Tenants
+	}
+	concPerTenant := max(params.Concurrency/len(tenants), 1)
+
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Println("  PostgreSQL Cross-Tenant Leakage Probe")
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Printf("  Tenants: %d | Queries: %d (half probes) | Concurrency/tenant: %d\n\n",
+		len(tenants), params.Queries, concPerTenant)
+
+	bench.Stepf("[1/3] Connecting and seeding tenants...")
+	res := &bench.Result{}
+	pools := make([]*pgxpool.Pool, len(tenants))
+	for i, t := range tenants {
+		cfg := proxyCfg
+		cfg.Database = t
+		pool, err := Connect(cfg, "disable")
+		if err != nil {
+			bench.Failf("%s: %v", t, err)
+			return nil
+		}
+		defer pool.Close()
+		pools[i] = pool
+		if i == 0 {
+			res.Manifest.ProxyVersion = detectVersion(pool)
+		}
+		if err := PrepareData(ctx, pool, params); err != nil {
+			bench.Failf("Seed %s failed: %v", t, err)
+			return nil
+		}
+	}
+	bench.Okf("All tenants connected and seeded")
+
+	bench.Stepf("[2/3] Writing marker rows...")
+	table := tableIdent(params)
+	for i, pool := range pools {
+		id := markerBase - i
+		_, err := pool.Exec(ctx, "DELETE FROM "+table+" WHERE id = $1", id)
+		if err == nil {
+			_, err = pool.Exec(ctx, "INSERT INTO "+table+" (id, name, balance) VALUES ($1, $2, 0)", id, "tdb_marker_"+tenants[i])
+		}
+		if err != nil {
+			bench.Failf("Marker in %s failed: %v", tenants[i], err)
+			return nil
+		}
+	}
+	defer func() {
+		for i, pool := range pools {
+			pool.Exec(ctx, "DELETE FROM "+table+" WHERE id = $1", markerBase-i)
+		}
+	}()
+	bench.Okf("%d markers written", len(pools))
+
+	bench.Stepf("[3/3] Probing under load...")
+	stats := bench.RunMultiple(ctx, params.Runs, "Leakage probe", func(run int) bench.BenchStats {
+		return leakagePass(ctx, pools, tenants, params, concPerTenant)
+	})
+	bench.PrintStats(stats)
+	res.Stats = append(res.Stats, stats)
+	return res
+}
+
+// leakagePass runs concPerTenant workers per tenant that alternate a
+// workload query with a marker probe, and reports any leak found.
+func leakagePass(ctx context.Context, pools []*pgxpool.Pool, tenants []string, params bench.BenchParams, concPerTenant int) bench.BenchStats {
+	q := newQueries(params)
+	op := workloadOp(params)
+	probe := "SELECT name FROM " + q.table + " WHERE id <= $1 ORDER BY id"
+	perWorker := max(params.Queries/len(tenants)/concPerTenant/2, 1)
+
+	var mu sync.Mutex
+	var results []bench.QueryResult
+	start := time.Now()
+	var wg sync.WaitGroup
+	for t, pool := range pools {
+		own := "tdb_marker_" + tenants[t]
+		for w := 0; w < concPerTenant; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				local := make([]bench.QueryResult, 0, 2*perWorker)
+				for i := 0; i < perWorker; i++ {
+					r := op(ctx, pool, q, params.SeedRows)
+					if r.Op == "" {
+						r.Op = "load"
+					}
+					local = append(local, r)
+
+					qStart := time.Now()
+					names, err := markers(ctx, pool, probe)
+					if err == nil {
+						err = checkMarkers(tenants[t], own, names)
+					}
+					local = append(local, bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err, Op: "probe"})
+				}
+				mu.Lock()
+				results = append(results, local...)
+				mu.Unlock()
+			}()
+		}
+	}
+	wg.Wait()
+	total := time.Since(start)
+
+	bench.PrintErrors(results)
+	leaks := 0
+	for _, r := range results {
+		if errors.Is(r.Err, bench.ErrLeak) {
+			leaks++
+		}
+	}
+	bench.PrintLeaks(leaks, len(results)/2)
+	return bench.ComputeStats(fmt.Sprintf("Leakage probe (%d tenants)", len(tenants)), results, total)
+}
+
+// markers returns the names of the marker rows visible to pool.
+func markers(ctx context.Context, pool *pgxpool.Pool, probe string) ([]string, error) {
+	rows, err := pool.Query(ctx, probe, markerBase)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// checkMarkers fails unless names is exactly the tenant's own marker.
+func checkMarkers(tenant, own string, names []string) error {
+	found := false
+	for _, n := range names {
+		if n != own {
+			return fmt.Errorf("%w: %s sees %s", bench.ErrLeak, tenant, n)
+		}
+		found = true
+	}
+	if !found {
+		return fmt.Errorf("%w: %s cannot see its own marker", bench.ErrLeak, tenant)
+	}
+	return nil
+}
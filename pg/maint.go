@@ -0,0 +1,91 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"tenantsdb-bench/bench"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RunSeed connects to one database and makes sure the benchmark table holds
+// at least params.SeedRows rows, truncating it first when params.Reseed is set.
+func RunSeed(cfg bench.ConnConfig, params bench.BenchParams) error {
+	pool, err := Connect(cfg, "disable")
+	if err != nil {
+		return fmt.Errorf("connect %s: %w", cfg.Database, err)
+	}
+	defer pool.Close()
+	return PrepareData(context.Background(), pool, params)
+}
+
+// RunClean empties the benchmark table of one database, or drops it.
+func RunClean(cfg bench.ConnConfig, params bench.BenchParams, drop bool) error {
+	pool, err := Connect(cfg, "disable")
+	if err != nil {
+		return fmt.Errorf("connect %s: %w", cfg.Database, err)
+	}
+	defer pool.Close()
+	if drop {
+		return DropData(pool, params)
+	}
+	return CleanData(context.Background(), pool, params)
+}
+
+func CleanData(ctx context.Context, pool *pgxpool.Pool, params bench.BenchParams) error {
+	stmt := "TRUNCATE " + tableIdent(params) + " RESTART IDENTITY CASCADE"
+	switch {
+	case params.Tenancy == "rls":
+		// TRUNCATE ignores the policy and would empty every tenant
+		stmt = "DELETE FROM " + tableIdent(params)
+	case params.Cockroach:
+		// No sequence to restart: seeding sets the ids itself
+		stmt = "TRUNCATE " + tableIdent(params) + " CASCADE"
+	}
+	if _, err := pool.Exec(ctx, stmt); err != nil {
+		return fmt.Errorf("truncate: %w", err)
+	}
+	return nil
+}
+
+// DropData drops the benchmark table and the relational and ingest tables
+// next to it.
+func DropData(pool *pgxpool.Pool, params bench.BenchParams) error {
+	tables := qualify(params, params.ItemsTable()).Sanitize() + ", " + qualify(params, params.OrdersTable()).Sanitize() + ", " +
+		qualify(params, params.IngestTable()).Sanitize() + ", " +
+		qualify(params, params.LagTable()).Sanitize() + ", " + tableIdent(params)
+	if _, err := pool.Exec(context.Background(), "DROP TABLE IF EXISTS "+tables); err != nil {
+		return fmt.Errorf("drop: %w", err)
+	}
+	return nil
+}
+
+// RunDoctor connects, reports the server version, and times a trivial query.
+func RunDoctor(cfg bench.ConnConfig, params bench.BenchParams) error {
+	start := time.Now()
+	pool, err := Connect(cfg, "disable")
+	if err != nil {
+		return fmt.Errorf("auth: %w", err)
+	}
+	defer pool.Close()
+	bench.Okf("Auth: connected in %s", bench.FmtDur(time.Since(start)))
+
+	detectVersion(pool)
+
+	qStart := time.Now()
+	var one int
+	if err := pool.QueryRow(context.Background(), "SELECT 1").Scan(&one); err != nil {
+		return fmt.Errorf("SELECT 1: %w", err)
+	}
+	bench.Okf("SELECT 1 in %s", bench.FmtDur(time.Since(qStart)))
+
+	var count int
+	if err := pool.QueryRow(context.Background(), "SELECT COUNT(*) FROM "+tableIdent(params)).Scan(&count); err != nil {
+		bench.Warnf("%s table: %v", params.TableName(), err)
+	} else {
+		bench.Okf("%s table: %d rows", params.TableName(), count)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"tenantsdb-bench/bench"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const notifyChannel = "tdb_bench_notify"
+
+// RunNotify measures NOTIFY-to-LISTEN delivery latency under the mixed
+// workload, through the proxy and (when configured) direct.
+func RunNotify(ctx context.Context, proxyCfg, directCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Println("  PostgreSQL LISTEN/NOTIFY Latency Test")
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Printf("  Notifications: %d | Background load: %s\n\n", params.Notifications, params.WorkloadDesc())
+
+	res := &bench.Result{}
+
+	directPool, proxyPool, ok := endpoints(proxyCfg, directCfg, res, 3)
+	if !ok {
+		return nil
+	}
+	defer proxyPool.Close()
+	if directPool != nil {
+		defer directPool.Close()
+	}
+
+	seedPool := proxyPool
+	if directPool != nil {
+		seedPool = directPool
+	}
+	if err := PrepareData(ctx, seedPool, params); err != nil {
+		bench.Failf("Seed failed: %v", err)
+		return nil
+	}
+	bench.Okf("Data ready")
+
+	bench.Stepf("[3/3] Sending notifications...")
+	var directStats bench.BenchStats
+	if directPool != nil {
+		fmt.Println("\n── Direct PostgreSQL ──")
+		var load bench.BenchStats
+		directStats, load = notifyPass(ctx, directPool, params, "Direct NOTIFY delivery")
+		bench.PrintStats(directStats)
+		bench.PrintStats(load)
+		res.Stats = append(res.Stats, directStats, load)
+	}
+
+	fmt.Println("\n── Through TenantsDB Proxy ──")
+	proxyStats, load := notifyPass(ctx, proxyPool, params, "Proxy NOTIFY delivery")
+	bench.PrintStats(proxyStats)
+	bench.PrintStats(load)
+	res.Stats = append(res.Stats, proxyStats, load)
+	if proxyStats.Errors > 0 {
+		fmt.Println()
+		bench.Warnf("%d of %d notifications were not delivered through the proxy", proxyStats.Errors, proxyStats.Total)
+	}
+
+	if directPool != nil {
+		bench.PrintComparison(proxyStats, directStats)
+		cmp := bench.Compare(proxyStats, directStats)
+		res.Comparison = &cmp
+	}
+	return res
+}
+
+// notifyPass sends params.Notifications notifications one after another
+// while the workload runs on the rest of the pool, and returns delivery
+// latency (send to receipt) and the background load's stats.
+func notifyPass(ctx context.Context, pool *pgxpool.Pool, params bench.BenchParams, label string) (delivery, load bench.BenchStats) {
+	n := params.Notifications
+
+	listener, err := pool.Acquire(ctx)
+	if err != nil {
+		bench.Failf("Listener connection: %v", err)
+		return bench.BenchStats{Label: label}, bench.BenchStats{Label: label + " load"}
+	}
+	defer listener.Release()
+	if _, err := listener.Exec(ctx, "LISTEN "+pgx.Identifier{notifyChannel}.Sanitize()); err != nil {
+		bench.Failf("LISTEN: %v", err)
+		return bench.BenchStats{Label: label}, bench.BenchStats{Label: label + " load"}
+	}
+	defer listener.Exec(ctx, "UNLISTEN *")
+
+	// Keep a connection free for the sender next to the listener
+	q := newQueries(params)
+	op := workloadOp(params)
+	loadStart := time.Now()
+	stopLoad := bench.Background(min(params.Concurrency, int(pool.Config().MaxConns)-2), func() bench.QueryResult {
+		return op(ctx, pool, q, params.SeedRows)
+	})
+
+	token := strconv.FormatInt(rand.Int63(), 36)
+	results := make([]bench.QueryResult, n)
+	got := make([]bool, n)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for received := 0; received < n; {
+			wctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			note, err := listener.Conn().WaitForNotification(wctx)
+			cancel()
+			if err != nil {
+				return
+			}
+			f := strings.Fields(note.Payload)
+			if len(f) != 3 || f[0] != token {
+				continue // another run on the same channel
+			}
+			seq, _ := strconv.Atoi(f[1])
+			sentNs, _ := strconv.ParseInt(f[2], 10, 64)
+			if seq < 0 || seq >= n || got[seq] {
+				continue
+			}
+			sent := time.Unix(0, sentNs)
+			results[seq] = bench.QueryResult{At: sent, Duration: time.Since(sent)}
+			got[seq] = true
+			received++
+		}
+	}()
+
+	start := time.Now()
+	sendErr := make([]error, n)
+	for i := 0; i < n; i++ {
+		payload := fmt.Sprintf("%s %d %d", token, i, time.Now().UnixNano())
+		_, sendErr[i] = pool.Exec(ctx, "SELECT pg_notify($1, $2)", notifyChannel, payload)
+	}
+	<-done
+	total := time.Since(start)
+	loadResults := stopLoad()
+
+	for i := range results {
+		if got[i] {
+			continue
+		}
+		results[i].Err = sendErr[i]
+		if results[i].Err == nil {
+			results[i].Err = errors.New("notification not delivered")
+		}
+	}
+	return bench.ComputeStats(label, results, total),
+		bench.ComputeStats(label+" load", loadResults, time.Since(loadStart))
+}
@@ -1,61 +1,66 @@
 package pg
 
 import (
+	"context"
 	"fmt"
 
 	"tenantsdb-bench/bench"
 )
 
-func RunOverhead(proxyCfg, directCfg bench.ConnConfig, params bench.BenchParams) {
+func RunOverhead(ctx context.Context, proxyCfg, directCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
 	fmt.Println("═══════════════════════════════════════════")
 	fmt.Println("  PostgreSQL Proxy Overhead Benchmark")
 	fmt.Println("═══════════════════════════════════════════")
 	if params.Duration > 0 {
-		fmt.Printf("  Duration: %s | Concurrency: %d | Workload: 80%% read / 20%% write\n\n", params.Duration, params.Concurrency)
+		fmt.Printf("  Duration: %s | Concurrency: %d | Workload: %s\n\n", params.Duration, params.Concurrency, params.WorkloadDesc())
 	} else {
-		fmt.Printf("  Queries: %d | Concurrency: %d | Workload: 80%% read / 20%% write\n\n", params.Queries, params.Concurrency)
+		fmt.Printf("  Queries: %d | Concurrency: %d | Workload: %s\n\n", params.Queries, params.Concurrency, params.WorkloadDesc())
 	}
 
 	// Connect direct
-	fmt.Println("[1/4] Connecting directly to PostgreSQL...")
+	bench.Stepf("[1/4] Connecting directly to PostgreSQL...")
 	directPool, err := Connect(directCfg, "disable")
 	if err != nil {
-		fmt.Printf("  ✗ Direct connection failed: %v\n", err)
-		return
+		bench.Failf("Direct connection failed: %v", err)
+		return nil
 	}
 	defer directPool.Close()
-	fmt.Println("  ✓ Connected")
+	backendVersion := detectVersion(directPool)
+	bench.Okf("Connected")
 
 	// Seed data direct
-	fmt.Println("\n[2/4] Seeding test data (direct)...")
-	if err := SeedData(directPool, params.SeedRows); err != nil {
-		fmt.Printf("  ✗ Seed failed: %v\n", err)
-		return
+	bench.Stepf("[2/4] Seeding test data (direct)...")
+	if err := PrepareData(ctx, directPool, params); err != nil {
+		bench.Failf("Seed failed: %v", err)
+		return nil
 	}
-	fmt.Println("  ✓ Data ready")
+	bench.Okf("Data ready")
 
 	// Connect proxy
-	fmt.Println("\n[3/4] Connecting through TenantsDB proxy...")
+	bench.Stepf("[3/4] Connecting through TenantsDB proxy...")
 	proxyPool, err := Connect(proxyCfg, "disable")
 	if err != nil {
-		fmt.Printf("  ✗ Proxy connection failed: %v\n", err)
-		return
+		bench.Failf("Proxy connection failed: %v", err)
+		return nil
 	}
 	defer proxyPool.Close()
-	fmt.Println("  ✓ Connected")
+	proxyVersion := detectVersion(proxyPool)
+	bench.Okf("Connected")
+	bench.WarnVersionMismatch(backendVersion, proxyVersion)
 
 	// Run benchmarks
-	fmt.Println("\n[4/4] Running benchmarks...")
+	bench.Stepf("[4/4] Running benchmarks...")
 
+	var directStats, proxyStats bench.BenchStats
 	if params.Runs > 1 {
 		// Multi-run mode: 5 runs each, median reported
-		directStats := bench.RunMultiple(params.Runs, "Direct PostgreSQL", func(run int) bench.BenchStats {
-			return PickRunner(directPool, params, "Direct PostgreSQL")
+		directStats = bench.RunMultiple(ctx, params.Runs, "Direct PostgreSQL", func(run int) bench.BenchStats {
+			return PickRunner(bench.Unobserved(ctx), directPool, params, "Direct PostgreSQL")
 		})
 		bench.PrintStats(directStats)
 
-		proxyStats := bench.RunMultiple(params.Runs, "Through TenantsDB Proxy", func(run int) bench.BenchStats {
-			return PickRunner(proxyPool, params, "Through TenantsDB Proxy")
+		proxyStats = bench.RunMultiple(ctx, params.Runs, "Through TenantsDB Proxy", func(run int) bench.BenchStats {
+			return PickRunner(ctx, proxyPool, params, "Through TenantsDB Proxy")
 		})
 		bench.PrintStats(proxyStats)
 
@@ -63,18 +68,24 @@ func RunOverhead(proxyCfg, directCfg bench.ConnConfig, params bench.BenchParams)
 	} else {
 		// Single run
 		fmt.Println("\n── Direct PostgreSQL ──")
-		directStats := PickRunner(directPool, params, "Direct PostgreSQL")
+		directStats = PickRunner(bench.Unobserved(ctx), directPool, params, "Direct PostgreSQL")
 		bench.PrintStats(directStats)
 
 		fmt.Println("\n── Through TenantsDB Proxy ──")
-		proxyStats := PickRunner(proxyPool, params, "Through TenantsDB Proxy")
+		proxyStats = PickRunner(ctx, proxyPool, params, "Through TenantsDB Proxy")
 		bench.PrintStats(proxyStats)
 
 		bench.PrintComparison(proxyStats, directStats)
 	}
+
+	cmp := bench.Compare(proxyStats, directStats)
+	res := &bench.Result{Stats: []bench.BenchStats{directStats, proxyStats}, Comparison: &cmp}
+	res.Manifest.BackendVersion = backendVersion
+	res.Manifest.ProxyVersion = proxyVersion
+	return res
 }
 
-func RunThroughput(proxyCfg bench.ConnConfig, params bench.BenchParams) {
+func RunThroughput(ctx context.Context, proxyCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
 	fmt.Println("═══════════════════════════════════════════")
 	fmt.Println("  PostgreSQL Throughput Benchmark")
 	fmt.Println("═══════════════════════════════════════════")
@@ -84,31 +95,36 @@ func RunThroughput(proxyCfg bench.ConnConfig, params bench.BenchParams) {
 		fmt.Printf("  Queries: %d | Concurrency: %d\n\n", params.Queries, params.Concurrency)
 	}
 
-	fmt.Println("[1/3] Connecting through TenantsDB proxy...")
+	bench.Stepf("[1/3] Connecting through TenantsDB proxy...")
 	pool, err := Connect(proxyCfg, "disable")
 	if err != nil {
-		fmt.Printf("  ✗ Connection failed: %v\n", err)
-		return
+		bench.Failf("Connection failed: %v", err)
+		return nil
 	}
 	defer pool.Close()
-	fmt.Println("  ✓ Connected")
+	proxyVersion := detectVersion(pool)
+	bench.Okf("Connected")
 
-	fmt.Println("\n[2/3] Seeding test data...")
-	if err := SeedData(pool, params.SeedRows); err != nil {
-		fmt.Printf("  ✗ Seed failed: %v\n", err)
-		return
+	bench.Stepf("[2/3] Seeding test data...")
+	if err := PrepareData(ctx, pool, params); err != nil {
+		bench.Failf("Seed failed: %v", err)
+		return nil
 	}
-	fmt.Println("  ✓ Data ready")
+	bench.Okf("Data ready")
 
-	fmt.Println("\n[3/3] Running benchmark...")
+	bench.Stepf("[3/3] Running benchmark...")
 
+	var stats bench.BenchStats
 	if params.Runs > 1 {
-		stats := bench.RunMultiple(params.Runs, "PostgreSQL Throughput (via Proxy)", func(run int) bench.BenchStats {
-			return PickRunner(pool, params, "PostgreSQL Throughput (via Proxy)")
+		stats = bench.RunMultiple(ctx, params.Runs, "PostgreSQL Throughput (via Proxy)", func(run int) bench.BenchStats {
+			return PickRunner(ctx, pool, params, "PostgreSQL Throughput (via Proxy)")
 		})
-		bench.PrintStats(stats)
 	} else {
-		stats := PickRunner(pool, params, "PostgreSQL Throughput (via Proxy)")
-		bench.PrintStats(stats)
+		stats = PickRunner(ctx, pool, params, "PostgreSQL Throughput (via Proxy)")
 	}
-}
\ No newline at end of file
+	bench.PrintStats(stats)
+
+	res := &bench.Result{Stats: []bench.BenchStats{stats}}
+	res.Manifest.ProxyVersion = proxyVersion
+	return res
+}
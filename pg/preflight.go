@@ -0,0 +1,50 @@
+package pg
+
+import (
+	"context"
+	"time"
+
+	"tenantsdb-bench/bench"
+)
+
+// PreflightCheck connects to db on cfg (cfg's own database when db is "";
+// a schema in it with -tenancy schema, a role with -tenancy rls), checks the benchmark table and
+// compares the server clock with ours.
+func PreflightCheck(cfg bench.ConnConfig, db string, params bench.BenchParams) bench.DatabaseCheck {
+	if db != "" {
+		cfg = tenantConfig(cfg, params, db)
+	}
+	c := bench.DatabaseCheck{Database: cfg.Database}
+	if cfg.SearchPath != "" {
+		c.Database += "." + cfg.SearchPath
+	}
+	if params.Tenancy == "rls" && db != "" {
+		c.Database += " as " + cfg.User
+	}
+	start := time.Now()
+	pool, err := Connect(cfg, "disable")
+	if err != nil {
+		c.Err = err
+		return c
+	}
+	defer pool.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	sent := time.Now()
+	var epoch float64
+	if err := pool.QueryRow(ctx, "SELECT extract(epoch FROM clock_timestamp())::float8").Scan(&epoch); err != nil {
+		c.Err = err
+		return c
+	}
+	c.Latency = time.Since(start)
+	mid := sent.Add(time.Since(sent) / 2)
+	c.Skew = time.Unix(0, int64(epoch*1e9)).Sub(mid)
+
+	if err := pool.QueryRow(ctx, "SELECT to_regclass($1) IS NOT NULL", tableIdent(params)).Scan(&c.Table); err != nil || !c.Table {
+		return c
+	}
+	_, err = pool.Exec(ctx, "UPDATE "+tableIdent(params)+" SET balance = balance WHERE false")
+	c.Writable = err == nil
+	return c
+}
@@ -0,0 +1,119 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"tenantsdb-bench/bench"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RunPrepared prepares params.PreparedStmts distinct statements per
+// connection and executes them at random, through the proxy and (when
+// configured) direct, to see how the proxy's statement tracking scales.
+func RunPrepared(ctx context.Context, proxyCfg, directCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Println("  PostgreSQL Prepared Statement Test")
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Printf("  Statements: %v per connection | Executes: %d | Concurrency: %d\n\n",
+		params.PreparedStmts, params.Queries, params.Concurrency)
+
+	res := &bench.Result{}
+	directPool, proxyPool, ok := endpoints(proxyCfg, directCfg, res, 3)
+	if !ok {
+		return nil
+	}
+	defer proxyPool.Close()
+	if directPool != nil {
+		defer directPool.Close()
+	}
+
+	seedPool := proxyPool
+	if directPool != nil {
+		seedPool = directPool
+	}
+	if err := PrepareData(ctx, seedPool, params); err != nil {
+		bench.Failf("Seed failed: %v", err)
+		return nil
+	}
+	bench.Okf("Data ready")
+
+	bench.Stepf("[3/3] Preparing and executing...")
+	var points []bench.PreparedPoint
+	for _, n := range params.PreparedStmts {
+		point := bench.PreparedPoint{Stmts: n}
+		if directPool != nil {
+			fmt.Printf("\n── Direct, %d statements ──\n", n)
+			s := preparedPass(bench.Unobserved(ctx), directPool, params, n, fmt.Sprintf("Direct %d stmts", n))
+			bench.PrintStats(s)
+			point.Direct = &s
+			res.Stats = append(res.Stats, s)
+		}
+		fmt.Printf("\n── Through Proxy, %d statements ──\n", n)
+		point.Proxy = preparedPass(ctx, proxyPool, params, n, fmt.Sprintf("Proxy %d stmts", n))
+		bench.PrintStats(point.Proxy)
+		res.Stats = append(res.Stats, point.Proxy)
+		points = append(points, point)
+	}
+
+	bench.PrintPrepared(points)
+	return res
+}
+
+// preparedPass runs one prepared session per worker with n statements,
+// sharing params.Queries executes between them.
+func preparedPass(ctx context.Context, pool *pgxpool.Pool, params bench.BenchParams, n int, label string) bench.BenchStats {
+	execs := max(params.Queries/params.Concurrency, 1)
+	results, total := bench.RunWorkers(ctx, label, params.Concurrency, func(worker int) []bench.QueryResult {
+		return preparedSession(ctx, pool, params, n, execs)
+	})
+	bench.PrintErrors(results)
+	return bench.ComputeStats(label, results, total)
+}
+
+// preparedSession pins one connection, prepares n point reads that differ
+// in a constant column and runs execs of them at random. Each statement
+// returns its own number, so a proxy that runs the wrong statement fails the
+// execute.
+func preparedSession(ctx context.Context, pool *pgxpool.Pool, params bench.BenchParams, n, execs int) []bench.QueryResult {
+	var out []bench.QueryResult
+	record := func(op string, qStart time.Time, err error) error {
+		out = append(out, bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err, Op: op})
+		return err
+	}
+
+	qStart := time.Now()
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		record("prepare", qStart, err)
+		return out
+	}
+	defer conn.Release()
+	defer conn.Conn().DeallocateAll(ctx)
+
+	t := tableIdent(params)
+	for k := 0; k < n; k++ {
+		qStart := time.Now()
+		_, err := conn.Conn().Prepare(ctx, fmt.Sprintf("tdb_stmt_%d", k),
+			fmt.Sprintf("SELECT id, name, balance, %d AS k FROM %s WHERE id = $1", k, t))
+		if record("prepare", qStart, err) != nil {
+			return out
+		}
+	}
+
+	for i := 0; i < execs; i++ {
+		k := rand.Intn(n)
+		qStart := time.Now()
+		var got int
+		err := conn.QueryRow(ctx, fmt.Sprintf("tdb_stmt_%d", k), rand.Intn(params.SeedRows)+1).
+			Scan(new(int), new(string), new(float64), &got)
+		if err == nil && got != k {
+			err = fmt.Errorf("statement %d returned the result of statement %d", k, got)
+		}
+		record("execute", qStart, err)
+	}
+	return out
+}
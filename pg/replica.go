@@ -0,0 +1,108 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"tenantsdb-bench/bench"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// lagTimeout is how long a lag probe polls before calling the write lost.
+const lagTimeout = 5 * time.Second
+
+// RunReplica measures replication lag as seen through a read/write
+// splitting proxy: it writes a timestamp, then polls until a read returns
+// it, while the workload runs on the rest of the pool.
+func RunReplica(ctx context.Context, proxyCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Println("  PostgreSQL Replica Lag Test")
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Printf("  Probes: %d | Background load: %s\n\n", params.LagProbes, params.WorkloadDesc())
+
+	res := &bench.Result{}
+	bench.Stepf("[1/3] Connecting through TenantsDB proxy...")
+	pool, err := Connect(proxyCfg, "disable")
+	if err != nil {
+		bench.Failf("Proxy connection failed: %v", err)
+		return nil
+	}
+	defer pool.Close()
+	res.Manifest.ProxyVersion = detectVersion(pool)
+	bench.Okf("Connected")
+
+	bench.Stepf("[2/3] Seeding test data...")
+	if err := PrepareData(ctx, pool, params); err != nil {
+		bench.Failf("Seed failed: %v", err)
+		return nil
+	}
+	lag := qualify(params, params.LagTable()).Sanitize()
+	_, err = pool.Exec(ctx, "CREATE TABLE IF NOT EXISTS "+lag+" (id INT PRIMARY KEY, v BIGINT NOT NULL)")
+	if err == nil {
+		_, err = pool.Exec(ctx, "INSERT INTO "+lag+" (id, v) VALUES (1, 0) ON CONFLICT (id) DO NOTHING")
+	}
+	if err != nil {
+		bench.Failf("Create %s failed: %v", params.LagTable(), err)
+		return nil
+	}
+	bench.Okf("Data ready")
+
+	bench.Stepf("[3/3] Probing replication lag...")
+	lagStats, load, stale, reads := lagPass(ctx, pool, params, lag)
+	bench.PrintStats(lagStats)
+	bench.PrintStats(load)
+	bench.PrintLag(lagStats, stale, reads)
+	res.Stats = append(res.Stats, lagStats, load)
+	return res
+}
+
+// lagPass runs params.LagProbes write-then-poll probes one after another
+// under background load. Each probe's result is the time from the write's
+// commit to the first read that returned it; stale counts the reads that
+// returned an older value.
+func lagPass(ctx context.Context, pool *pgxpool.Pool, params bench.BenchParams, lag string) (lagStats, load bench.BenchStats, stale, reads int) {
+	q := newQueries(params)
+	op := workloadOp(params)
+	loadStart := time.Now()
+	stopLoad := bench.Background(min(params.Concurrency, int(pool.Config().MaxConns)-1), func() bench.QueryResult {
+		return op(ctx, pool, q, params.SeedRows)
+	})
+
+	results := make([]bench.QueryResult, params.LagProbes)
+	start := time.Now()
+	for i := range results {
+		v := time.Now().UnixNano()
+		if _, err := pool.Exec(ctx, "UPDATE "+lag+" SET v = $1 WHERE id = 1", v); err != nil {
+			results[i] = bench.QueryResult{At: time.Now(), Err: err, Op: "lag"}
+			continue
+		}
+		written := time.Now()
+		r := bench.QueryResult{At: written, Op: "lag"}
+		for {
+			var got int64
+			err := pool.QueryRow(ctx, "SELECT v FROM "+lag+" WHERE id = 1").Scan(&got)
+			reads++
+			if err != nil {
+				r.Err = err
+				break
+			}
+			if got >= v {
+				break
+			}
+			stale++
+			if time.Since(written) > lagTimeout {
+				r.Err = fmt.Errorf("write not visible after %s", lagTimeout)
+				break
+			}
+		}
+		r.Duration = time.Since(written)
+		results[i] = r
+	}
+	total := time.Since(start)
+	loadResults := stopLoad()
+
+	return bench.ComputeStats("Replica lag (write to visible)", results, total),
+		bench.ComputeStats("Replica lag load", loadResults, time.Since(loadStart)), stale, reads
+}
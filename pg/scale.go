@@ -3,7 +3,6 @@ package pg
 import (
 	"context"
 	"fmt"
-	"math/rand"
 	"sort"
 	"sync"
 	"sync/atomic"
@@ -14,12 +13,20 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-func buildTenantList() []string {
+// TenantList is the built-in bench01..bench100 tenant set used by the scale
+// test; multi and isolation use its first ten.
+func TenantList() []string {
+	return TenantNames(100)
+}
+
+// TenantNames is the built-in tenant naming extended to n tenants
+// (bench01..bench10, then bench011 onwards).
+func TenantNames(n int) []string {
 	var tenants []string
-	for i := 1; i <= 10; i++ {
+	for i := 1; i <= min(n, 10); i++ {
 		tenants = append(tenants, fmt.Sprintf("bench_pg__bench%02d", i))
 	}
-	for i := 11; i <= 100; i++ {
+	for i := 11; i <= n; i++ {
 		tenants = append(tenants, fmt.Sprintf("bench_pg__bench%03d", i))
 	}
 	return tenants
@@ -31,8 +38,11 @@ type tenantStats struct {
 	Results []bench.QueryResult
 }
 
-func RunScale(proxyCfg bench.ConnConfig, params bench.BenchParams) {
-	tenants := buildTenantList()
+func RunScale(ctx context.Context, proxyCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
+	tenants := TenantList()
+	if len(params.Tenants) > 0 {
+		tenants = params.Tenants
+	}
 	concPerTenant := params.Concurrency / len(tenants)
 	if concPerTenant < 1 {
 		concPerTenant = 1
@@ -40,7 +50,7 @@ func RunScale(proxyCfg bench.ConnConfig, params bench.BenchParams) {
 	totalConc := concPerTenant * len(tenants)
 
 	fmt.Println("═══════════════════════════════════════════")
-	fmt.Println("  PostgreSQL Scale Benchmark (100 Tenants)")
+	fmt.Printf("  PostgreSQL Scale Benchmark (%d Tenants)\n", len(tenants))
 	fmt.Println("═══════════════════════════════════════════")
 	fmt.Printf("  Tenants:             %d\n", len(tenants))
 	fmt.Printf("  Concurrency/tenant:  %d\n", concPerTenant)
@@ -48,31 +58,36 @@ func RunScale(proxyCfg bench.ConnConfig, params bench.BenchParams) {
 	if params.Duration > 0 {
 		fmt.Printf("  Duration:            %s\n", params.Duration)
 	} else {
-		queriesPerTenant := params.Queries / len(tenants)
-		if queriesPerTenant < 10 {
-			queriesPerTenant = 10
-		}
-		fmt.Printf("  Queries/tenant:      %d\n", queriesPerTenant)
-		fmt.Printf("  Total queries:       %d\n", queriesPerTenant*len(tenants))
+		total := max(params.Queries, scaleMinQueries*len(tenants))
+		fmt.Printf("  Queries/tenant:      %s\n", bench.ShareDesc(total, len(tenants)))
+		fmt.Printf("  Total queries:       %d\n", total)
+	}
+	var sizes []int
+	if params.SeedRowsMax > 0 {
+		sizes = bench.TenantRows(len(tenants), params.SeedRows, params.SeedRowsMax)
+		fmt.Printf("  Rows/tenant:         %d–%d (log-normal)\n", params.SeedRows, params.SeedRowsMax)
+	}
+	fmt.Printf("  Workload:            %s\n\n", params.WorkloadDesc())
+
+	if params.MaxClientConns > 0 {
+		return runScaleCycled(ctx, proxyCfg, tenants, params, sizes)
 	}
-	fmt.Printf("  Workload:            80%% read / 20%% write\n\n")
 
 	// ── Phase 1: Connect all tenants ──
-	fmt.Println("[1/3] Connecting all tenants...")
+	bench.Stepf("[1/3] Connecting all tenants...")
 	pools := make([]*pgxpool.Pool, len(tenants))
 	var connectFailed int
 	for i, t := range tenants {
-		cfg := proxyCfg
-		cfg.Database = t
+		cfg := tenantConfig(proxyCfg, params, t)
 		pool, err := Connect(cfg, "disable")
 		if err != nil {
-			fmt.Printf("  ✗ %s: %v\n", t, err)
+			bench.Failf("%s: %v", t, err)
 			connectFailed++
 			continue
 		}
 		pools[i] = pool
 		if (i+1)%20 == 0 || i == len(tenants)-1 {
-			fmt.Printf("  Connected: %d/%d\n", i+1-connectFailed, len(tenants))
+			bench.Infof("Connected: %d/%d", i+1-connectFailed, len(tenants))
 		}
 	}
 	defer func() {
@@ -83,12 +98,19 @@ func RunScale(proxyCfg bench.ConnConfig, params bench.BenchParams) {
 		}
 	}()
 	if connectFailed > 0 {
-		fmt.Printf("  ⚠ %d tenants failed to connect\n", connectFailed)
+		bench.Warnf("%d tenants failed to connect", connectFailed)
 	}
-	fmt.Printf("  ✓ %d tenants connected\n\n", len(tenants)-connectFailed)
+	var proxyVersion string
+	for _, pool := range pools {
+		if pool != nil {
+			proxyVersion = detectVersion(pool)
+			break
+		}
+	}
+	bench.Okf("%d tenants connected", len(tenants)-connectFailed)
 
 	// ── Phase 2: Seed all tenants ──
-	fmt.Println("[2/3] Seeding data (parallel)...")
+	bench.Stepf("[2/3] Seeding data (parallel)...")
 	var seedWg sync.WaitGroup
 	var seedFailed int
 	var seedMu sync.Mutex
@@ -99,7 +121,9 @@ func RunScale(proxyCfg bench.ConnConfig, params bench.BenchParams) {
 		seedWg.Add(1)
 		go func(p *pgxpool.Pool, idx int) {
 			defer seedWg.Done()
-			if err := SeedData(p, params.SeedRows); err != nil {
+			tp := params
+			tp.SeedRows = bench.RowsFor(sizes, idx, params.SeedRows)
+			if err := PrepareData(ctx, p, tp); err != nil {
 				seedMu.Lock()
 				seedFailed++
 				seedMu.Unlock()
@@ -108,44 +132,69 @@ func RunScale(proxyCfg bench.ConnConfig, params bench.BenchParams) {
 	}
 	seedWg.Wait()
 	if seedFailed > 0 {
-		fmt.Printf("  ⚠ %d tenants failed to seed\n", seedFailed)
+		bench.Warnf("%d tenants failed to seed", seedFailed)
 	}
-	fmt.Println("  ✓ All tenants seeded\n")
+	bench.Okf("All tenants seeded")
+	fmt.Println()
 
 	// ── Phase 3: Run scale benchmark ──
-	fmt.Println("[3/3] Running scale benchmark...")
+	bench.Stepf("[3/3] Running scale benchmark...")
 	fmt.Println()
 
+	var ramp []bench.RampStep
 	runOnce := func(run int) bench.BenchStats {
+		if params.RampTenants > 0 {
+			var stats bench.BenchStats
+			stats, ramp = scaleRunRamp(ctx, pools, tenants, params, concPerTenant, totalConc, sizes)
+			return stats
+		}
+		if params.TenantSkew > 0 {
+			return runSkewed(ctx, pools, tenants, params, sizes)
+		}
 		if params.Duration > 0 {
-			return scaleRunTimed(pools, tenants, params, concPerTenant, totalConc)
+			return scaleRunTimed(ctx, pools, tenants, params, concPerTenant, totalConc, sizes)
 		}
-		return scaleRunCount(pools, tenants, params, concPerTenant, totalConc)
+		return scaleRunCount(ctx, pools, tenants, params, concPerTenant, totalConc, sizes)
 	}
 
+	var stats bench.BenchStats
 	if params.Runs > 1 {
-		stats := bench.RunMultiple(params.Runs, "Scale (100 tenants)", runOnce)
-		bench.PrintStats(stats)
+		stats = bench.RunMultiple(ctx, params.Runs, fmt.Sprintf("Scale (%d tenants)", len(tenants)), runOnce)
 	} else {
-		stats := runOnce(0)
-		bench.PrintStats(stats)
+		stats = runOnce(0)
 	}
+	bench.PrintStats(stats)
+
+	res := &bench.Result{Stats: []bench.BenchStats{stats}, Ramp: ramp}
+	res.Manifest.ProxyVersion = proxyVersion
+	return res
 }
 
-func scaleRunCount(pools []*pgxpool.Pool, tenants []string, params bench.BenchParams, concPerTenant, totalConc int) bench.BenchStats {
-	maxID := params.SeedRows
-	queriesPerTenant := params.Queries / len(tenants)
-	if queriesPerTenant < 10 {
-		queriesPerTenant = 10
-	}
+// scaleMinQueries is the fewest queries each tenant runs in a count-based
+// scale test, however small -queries is.
+const scaleMinQueries = 10
+
+func scaleRunCount(ctx context.Context, pools []*pgxpool.Pool, tenants []string, params bench.BenchParams, concPerTenant, totalConc int, sizes []int) bench.BenchStats {
+	q := newQueries(params)
+	op := workloadOp(params)
+	total := max(params.Queries, scaleMinQueries*len(tenants))
 
 	tResults := make([]tenantStats, len(tenants))
 	for i, t := range tenants {
+		_, n := bench.Share(total, len(tenants), i)
 		tResults[i] = tenantStats{
 			Name:    t,
-			Results: make([]bench.QueryResult, queriesPerTenant),
+			Results: make([]bench.QueryResult, n),
+		}
+	}
+
+	queued := 0
+	for t := range tenants {
+		if pools[t] != nil {
+			queued += len(tResults[t].Results)
 		}
 	}
+	progress := bench.NewProgress(queued)
 
 	start := time.Now()
 	var wg sync.WaitGroup
@@ -158,42 +207,30 @@ func scaleRunCount(pools []*pgxpool.Pool, tenants []string, params bench.BenchPa
 
 		for w := 0; w < concPerTenant; w++ {
 			wg.Add(1)
-			workerQueries := queriesPerTenant / concPerTenant
-			workerOffset := w * workerQueries
+			workerOffset, workerQueries := bench.Share(len(tResults[t].Results), concPerTenant, w)
 
 			go func(tIdx int, p *pgxpool.Pool, offset, count int) {
 				defer wg.Done()
-				ctx := context.Background()
 
 				for i := 0; i < count; i++ {
 					idx := offset + i
-					qStart := time.Now()
-
-					if rand.Intn(100) < 80 {
-						id := rand.Intn(maxID) + 1
-						var rID int
-						var rName string
-						var rBalance float64
-						err := p.QueryRow(ctx, "SELECT id, name, balance FROM accounts WHERE id = $1", id).Scan(&rID, &rName, &rBalance)
-						tResults[tIdx].Results[idx] = bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err}
-					} else {
-						id := rand.Intn(maxID) + 1
-						delta := rand.Float64()*200 - 100
-						_, err := p.Exec(ctx, "UPDATE accounts SET balance = balance + $1 WHERE id = $2", delta, id)
-						tResults[tIdx].Results[idx] = bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err}
-					}
+					tResults[tIdx].Results[idx] = op(ctx, p, q, bench.RowsFor(sizes, tIdx, params.SeedRows))
+					progress.Add(1)
 				}
+				bench.ObserveWorker("Scale Test", tIdx*concPerTenant+w, tResults[tIdx].Results[offset:offset+count])
 			}(t, pool, workerOffset, workerQueries)
 		}
 	}
 	wg.Wait()
+	progress.Done()
 
 	totalDuration := time.Since(start)
-	return computeScaleStats(tResults, pools, tenants, totalDuration, totalConc)
+	return computeScaleStats(tResults, connected(pools), tenants, totalDuration, totalConc, sizes)
 }
 
-func scaleRunTimed(pools []*pgxpool.Pool, tenants []string, params bench.BenchParams, concPerTenant, totalConc int) bench.BenchStats {
-	maxID := params.SeedRows
+func scaleRunTimed(ctx context.Context, pools []*pgxpool.Pool, tenants []string, params bench.BenchParams, concPerTenant, totalConc int, sizes []int) bench.BenchStats {
+	q := newQueries(params)
+	op := workloadOp(params)
 
 	// Per-tenant result collection with per-tenant mutex
 	type tenantCollector struct {
@@ -217,26 +254,14 @@ func scaleRunTimed(pools []*pgxpool.Pool, tenants []string, params bench.BenchPa
 			wg.Add(1)
 			go func(tIdx int, p *pgxpool.Pool) {
 				defer wg.Done()
-				ctx := context.Background()
 				var local []bench.QueryResult
 
-				for !stopped.Load() {
-					qStart := time.Now()
-					if rand.Intn(100) < 80 {
-						id := rand.Intn(maxID) + 1
-						var rID int
-						var rName string
-						var rBalance float64
-						err := p.QueryRow(ctx, "SELECT id, name, balance FROM accounts WHERE id = $1", id).Scan(&rID, &rName, &rBalance)
-						local = append(local, bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err})
-					} else {
-						id := rand.Intn(maxID) + 1
-						delta := rand.Float64()*200 - 100
-						_, err := p.Exec(ctx, "UPDATE accounts SET balance = balance + $1 WHERE id = $2", delta, id)
-						local = append(local, bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err})
-					}
+				for !stopped.Load() && ctx.Err() == nil {
+					local = append(local, op(ctx, p, q, bench.RowsFor(sizes, tIdx, params.SeedRows)))
 				}
 
+				bench.ObserveWorker("Scale Test", tIdx*concPerTenant+w, local)
+
 				collectors[tIdx].mu.Lock()
 				collectors[tIdx].results = append(collectors[tIdx].results, local...)
 				collectors[tIdx].mu.Unlock()
@@ -245,30 +270,108 @@ func scaleRunTimed(pools []*pgxpool.Pool, tenants []string, params bench.BenchPa
 	}
 	wg.Wait()
 
-	totalDuration := time.Since(start)
+	elapsed := time.Since(start)
+	_, totalDuration := bench.Window(nil, start, params, elapsed)
 
 	// Convert collectors to tenantStats
 	tResults := make([]tenantStats, len(tenants))
 	for i, t := range tenants {
-		tResults[i] = tenantStats{Name: t, Results: collectors[i].results}
+		kept, _ := bench.Window(collectors[i].results, start, params, elapsed)
+		tResults[i] = tenantStats{Name: t, Results: kept}
 	}
 
-	return computeScaleStats(tResults, pools, tenants, totalDuration, totalConc)
+	return computeScaleStats(tResults, connected(pools), tenants, totalDuration, totalConc, sizes)
 }
 
-func computeScaleStats(tResults []tenantStats, pools []*pgxpool.Pool, tenants []string, totalDuration time.Duration, totalConc int) bench.BenchStats {
+// scaleRunRamp is the timed run with tenants brought online in waves of
+// params.RampTenants every params.RampEvery; tenants whose wave would start
+// after the run ends never join. It charts latency per step.
+func scaleRunRamp(ctx context.Context, pools []*pgxpool.Pool, tenants []string, params bench.BenchParams, concPerTenant, totalConc int, sizes []int) (bench.BenchStats, []bench.RampStep) {
+	q := newQueries(params)
+	op := workloadOp(params)
+	byTenant := make([][]bench.QueryResult, len(tenants))
+	joined := append([]*pgxpool.Pool(nil), pools...)
+	var mu sync.Mutex
+
+	start := time.Now()
+	deadline := start.Add(params.Duration)
+	var wg sync.WaitGroup
+	for t := range tenants {
+		joinAt := start.Add(time.Duration(t/params.RampTenants) * params.RampEvery)
+		if !joinAt.Before(deadline) {
+			joined[t] = nil
+		}
+		if joined[t] == nil {
+			continue
+		}
+		if t%params.RampTenants == 0 {
+			time.AfterFunc(time.Until(joinAt), func() {
+				bench.Infof("Wave %d: %d tenants online", t/params.RampTenants+1, min(t+params.RampTenants, len(tenants)))
+			})
+		}
+		for w := 0; w < concPerTenant; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				bench.Sleep(ctx, time.Until(joinAt))
+				var local []bench.QueryResult
+				for time.Now().Before(deadline) && ctx.Err() == nil {
+					local = append(local, op(ctx, joined[t], q, bench.RowsFor(sizes, t, params.SeedRows)))
+				}
+				bench.ObserveWorker("Scale Test", t*concPerTenant+w, local)
+				mu.Lock()
+				byTenant[t] = append(byTenant[t], local...)
+				mu.Unlock()
+			}()
+		}
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+	_, totalDuration := bench.Window(nil, start, params, elapsed)
+	for i := range byTenant {
+		byTenant[i], _ = bench.Window(byTenant[i], start, params, elapsed)
+	}
+
+	steps := bench.RampSteps(byTenant, params.RampTenants, start, params.RampEvery, params.Duration)
+	bench.PrintRamp(steps)
+	tResults := make([]tenantStats, len(tenants))
+	for i, t := range tenants {
+		tResults[i] = tenantStats{Name: t, Results: byTenant[i]}
+	}
+	return computeScaleStats(tResults, connected(joined), tenants, totalDuration, totalConc, sizes), steps
+}
+
+// connected reports which tenants have a connection.
+func connected(pools []*pgxpool.Pool) []bool {
+	live := make([]bool, len(pools))
+	for i, p := range pools {
+		live[i] = p != nil
+	}
+	return live
+}
+
+func computeScaleStats(tResults []tenantStats, live []bool, tenants []string, totalDuration time.Duration, totalConc int, sizes []int) bench.BenchStats {
+	for _, t := range tResults {
+		bench.ObserveTenant(t.Name, t.Results)
+	}
 	var allResults []bench.QueryResult
 	var totalErrors int
 	var tenantP50s []float64
+	var rows []int
+	var perTenant []bench.BenchStats
+	var byTenant [][]bench.QueryResult
 
 	for i := range tResults {
-		if pools[i] == nil {
+		if !live[i] {
 			continue
 		}
 		tResults[i].Stats = bench.ComputeStats(tResults[i].Name, tResults[i].Results, totalDuration)
 		allResults = append(allResults, tResults[i].Results...)
+		byTenant = append(byTenant, tResults[i].Results)
 		totalErrors += tResults[i].Stats.Errors
 		tenantP50s = append(tenantP50s, float64(tResults[i].Stats.LatencyP50.Microseconds()))
+		rows = append(rows, bench.RowsFor(sizes, i, 0))
+		perTenant = append(perTenant, tResults[i].Stats)
 	}
 
 	overall := bench.ComputeStats(
@@ -290,7 +393,7 @@ func computeScaleStats(tResults []tenantStats, pools []*pgxpool.Pool, tenants []
 		}
 		var ranking []ranked
 		for i := range tResults {
-			if pools[i] == nil {
+			if !live[i] {
 				continue
 			}
 			ranking = append(ranking, ranked{tResults[i].Name, tResults[i].Stats.LatencyP50})
@@ -301,7 +404,7 @@ func computeScaleStats(tResults []tenantStats, pools []*pgxpool.Pool, tenants []
 
 		fmt.Println()
 		fmt.Println("╔═════════════════════════════════════════════════════════════╗")
-		fmt.Println("║  SCALE TEST RESULTS (100 TENANTS)                          ║")
+		fmt.Printf("║  %-59s║\n", fmt.Sprintf("SCALE TEST RESULTS (%d TENANTS)", len(tenants)))
 		fmt.Println("╠═════════════════════════════════════════════════════════════╣")
 		fmt.Printf("║  Total Queries:     %-39d║\n", overall.Total)
 		fmt.Printf("║  Total Errors:      %-39d║\n", totalErrors)
@@ -338,6 +441,14 @@ func computeScaleStats(tResults []tenantStats, pools []*pgxpool.Pool, tenants []
 		}
 		fmt.Println("╚═════════════════════════════════════════════════════════════╝")
 	}
+	if first, rest := bench.SplitFirst(byTenant); len(first) > 0 {
+		bench.PrintFirstQueries(
+			bench.ComputeStats("First query per tenant", first, totalDuration),
+			bench.ComputeStats("Steady state", rest, totalDuration))
+	}
+	if sizes != nil && len(perTenant) > 0 {
+		bench.PrintSizes(rows, perTenant)
+	}
 
 	return overall
-}
\ No newline at end of file
+}
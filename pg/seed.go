@@ -0,0 +1,299 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"tenantsdb-bench/bench"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// seedChunk is the number of rows streamed per COPY (and per progress line).
+const seedChunk = 100_000
+
+// PrepareData seeds the benchmark table, first truncating it when
+// params.Reseed is set so every run starts from identical rows 1..SeedRows.
+func PrepareData(ctx context.Context, pool *pgxpool.Pool, params bench.BenchParams) error {
+	if params.Reseed {
+		bench.Infof("Reseeding: truncating %s...", params.TableName())
+		if err := CleanData(ctx, pool, params); err != nil {
+			return err
+		}
+	}
+	return SeedData(ctx, pool, params)
+}
+
+// seedTable describes one generated table; row returns the column values of
+// the n-th row (1-based), so data is identical across runs and databases.
+type seedTable struct {
+	name string
+	cols []string
+	row  func(n int) []any
+}
+
+// SeedData tops the benchmark table up to params.SeedRows rows, plus the
+// orders and order_items tables when params.Relational is set. Rows are
+// streamed with COPY FROM STDIN; if the endpoint refuses COPY it falls back
+// to multi-row INSERTs.
+func SeedData(ctx context.Context, pool *pgxpool.Pool, params bench.BenchParams) error {
+
+	var count int
+	err := pool.QueryRow(ctx, "SELECT COUNT(*) FROM "+tableIdent(params)).Scan(&count)
+	if err != nil || (params.Relational && !relationalExists(ctx, pool, params)) {
+		// Missing table: create it (only works where DDL is allowed, e.g. direct)
+		if cerr := createTables(ctx, pool, params); cerr != nil {
+			if err == nil {
+				err = cerr
+			}
+			return fmt.Errorf("seed check: %w", err)
+		}
+	}
+	if params.RowBytes > 0 {
+		if err := ensureColumn(ctx, pool, params, "payload", "TEXT"); err != nil {
+			return err
+		}
+	}
+	if params.Documents {
+		if err := ensureColumn(ctx, pool, params, "doc", "JSONB"); err != nil {
+			return err
+		}
+	}
+
+	accounts := seedTable{params.TableName(), []string{"name", "balance"}, func(n int) []any {
+		row := []any{fmt.Sprintf("user_%d", n), accountBalance(n)}
+		if params.RowBytes > 0 {
+			row = append(row, bench.Payload(n, params.RowBytes))
+		}
+		if params.Documents {
+			row = append(row, bench.Document(n))
+		}
+		return row
+	}}
+	if params.RowBytes > 0 {
+		accounts.cols = append(accounts.cols, "payload")
+	}
+	if params.Documents {
+		accounts.cols = append(accounts.cols, "doc")
+	}
+	if params.Cockroach || params.Tenancy == "rls" {
+		// CockroachDB's SERIAL is unique_rowid(), not 1..N, and a shared RLS
+		// table numbers each tenant's rows 1..N, so ids are explicit
+		accounts.cols = append([]string{"id"}, accounts.cols...)
+		row := accounts.row
+		accounts.row = func(n int) []any { return append([]any{n}, row(n)...) }
+	}
+	if err := fillTable(ctx, pool, params, accounts, params.SeedRows); err != nil {
+		return err
+	}
+	if !params.Relational {
+		return nil
+	}
+
+	orders := seedTable{params.OrdersTable(), []string{"id", "account_id", "amount", "status"}, func(n int) []any {
+		return []any{n, bench.OrderAccount(n), bench.OrderAmount(n), bench.OrderStatus(n)}
+	}}
+	if err := fillTable(ctx, pool, params, orders, params.SeedRows*bench.OrdersPerAccount); err != nil {
+		return err
+	}
+	items := seedTable{params.ItemsTable(), []string{"id", "order_id", "sku", "qty", "price"}, func(n int) []any {
+		return []any{n, bench.ItemOrder(n), bench.ItemSKU(n), bench.ItemQty(n), bench.ItemPrice(n)}
+	}}
+	return fillTable(ctx, pool, params, items, params.SeedRows*bench.OrdersPerAccount*bench.ItemsPerOrder)
+}
+
+// fillTable tops one table up to rows rows. Negative ids, written by the
+// upsert workload, are not counted.
+func fillTable(ctx context.Context, pool *pgxpool.Pool, params bench.BenchParams, t seedTable, rows int) error {
+	ident := qualify(params, t.name)
+
+	var count int
+	if err := pool.QueryRow(ctx, "SELECT COUNT(*) FROM "+ident.Sanitize()+" WHERE id > 0").Scan(&count); err != nil {
+		return fmt.Errorf("seed check %s: %w", t.name, err)
+	}
+	if count >= rows {
+		bench.Infof("%s already seeded (%d rows)", t.name, count)
+		return nil
+	}
+
+	bench.Infof("Seeding %s: %d rows...", t.name, rows-count)
+	// CockroachDB has no binary COPY, which is what pgx sends, and Postgres
+	// refuses COPY FROM into a table whose row-level security applies
+	useCopy := !params.Cockroach && params.Tenancy != "rls"
+	for from := count + 1; from <= rows; from += seedChunk {
+		to := min(from+seedChunk-1, rows)
+
+		if useCopy {
+			_, err := pool.CopyFrom(ctx, ident, t.cols, &seqRows{next: from, last: to, row: t.row})
+			if err != nil {
+				if from != count+1 {
+					return fmt.Errorf("seed copy %s at row %d: %w", t.name, from, err)
+				}
+				bench.Warnf("COPY refused (%v), falling back to INSERT batches", err)
+				useCopy = false
+			}
+		}
+		if !useCopy {
+			if err := insertRows(ctx, pool, ident.Sanitize(), t, from, to); err != nil {
+				return err
+			}
+		}
+
+		if rows-count > seedChunk {
+			bench.Infof("Seeded %d/%d rows (%.0f%%)", to-count, rows-count, float64(to-count)/float64(rows-count)*100)
+		}
+	}
+	return nil
+}
+
+// createTables creates the benchmark table (and its schema, if one is set),
+// plus orders and order_items with foreign keys when params.Relational is set.
+func createTables(ctx context.Context, pool *pgxpool.Pool, params bench.BenchParams) error {
+	if params.Schema != "" {
+		if _, err := pool.Exec(ctx, "CREATE SCHEMA IF NOT EXISTS "+pgx.Identifier{params.Schema}.Sanitize()); err != nil {
+			return err
+		}
+	}
+	accounts := tableIdent(params)
+	stmts := []string{`
+		CREATE TABLE IF NOT EXISTS ` + accounts + ` (` + accountsColumns(params) + `
+		)`}
+	if params.Relational {
+		orders := qualify(params, params.OrdersTable()).Sanitize()
+		items := qualify(params, params.ItemsTable()).Sanitize()
+		stmts = append(stmts, `
+		CREATE TABLE IF NOT EXISTS `+orders+` (
+			id INT PRIMARY KEY,
+			account_id INT NOT NULL REFERENCES `+accounts+` (id),
+			amount DECIMAL(15,2) NOT NULL,
+			status TEXT NOT NULL
+		)`, `
+		CREATE INDEX IF NOT EXISTS `+pgx.Identifier{params.OrdersTable() + "_account_idx"}.Sanitize()+`
+			ON `+orders+` (account_id)`, `
+		CREATE TABLE IF NOT EXISTS `+items+` (
+			id INT PRIMARY KEY,
+			order_id INT NOT NULL REFERENCES `+orders+` (id),
+			sku TEXT NOT NULL,
+			qty INT NOT NULL,
+			price DECIMAL(15,2) NOT NULL
+		)`, `
+		CREATE INDEX IF NOT EXISTS `+pgx.Identifier{params.ItemsTable() + "_order_idx"}.Sanitize()+`
+			ON `+items+` (order_id)`)
+	}
+	for _, stmt := range stmts {
+		if _, err := pool.Exec(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// accountsColumns is the column list of the benchmark table. On
+// CockroachDB, uuid keys spread rows over random ranges, and the workload's
+// id lookups go through a unique index storing the read columns; serial keys
+// are ids 1..N, which put every insert on the last range. With -tenancy rls
+// the table is shared and keyed by (tenant_id, id).
+func accountsColumns(params bench.BenchParams) string {
+	const rest = `
+			name TEXT NOT NULL,
+			balance DECIMAL(15,2) NOT NULL`
+	switch {
+	case params.Tenancy == "rls":
+		return `
+			tenant_id TEXT NOT NULL DEFAULT current_user,
+			id INT NOT NULL,` + rest + `,
+			PRIMARY KEY (tenant_id, id)`
+	case !params.Cockroach:
+		return `
+			id SERIAL PRIMARY KEY,` + rest
+	case params.KeyType == "uuid":
+		return `
+			uid UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			id INT8 NOT NULL,` + rest + `,
+			UNIQUE INDEX (id) STORING (name, balance)`
+	}
+	return `
+			id INT8 PRIMARY KEY,` + rest
+}
+
+// ensureColumn adds an optional column (the -row-bytes payload, the json
+// workload's doc) to an existing table.
+func ensureColumn(ctx context.Context, pool *pgxpool.Pool, params bench.BenchParams, name, typ string) error {
+	table := tableIdent(params)
+	if _, err := pool.Exec(ctx, "SELECT "+name+" FROM "+table+" LIMIT 0"); err == nil {
+		return nil
+	}
+	if _, err := pool.Exec(ctx, "ALTER TABLE "+table+" ADD COLUMN "+name+" "+typ); err != nil {
+		return fmt.Errorf("add %s column: %w", name, err)
+	}
+	fmt.Printf("  Added %s column; rows seeded before it have none (use -reseed)\n", name)
+	return nil
+}
+
+// relationalExists reports whether the orders and order_items tables exist.
+func relationalExists(ctx context.Context, pool *pgxpool.Pool, params bench.BenchParams) bool {
+	var orders, items *string
+	err := pool.QueryRow(ctx, "SELECT to_regclass($1)::text, to_regclass($2)::text",
+		qualify(params, params.OrdersTable()).Sanitize(),
+		qualify(params, params.ItemsTable()).Sanitize()).Scan(&orders, &items)
+	return err == nil && orders != nil && items != nil
+}
+
+// insertRows inserts rows from..to with 1000-row INSERT statements.
+func insertRows(ctx context.Context, pool *pgxpool.Pool, table string, t seedTable, from, to int) error {
+	const batch = 1000
+	for i := from; i <= to; i += batch {
+		end := min(i+batch-1, to)
+
+		var sb strings.Builder
+		sb.WriteString("INSERT INTO " + table + " (" + strings.Join(t.cols, ", ") + ") VALUES ")
+		args := make([]any, 0, (end-i+1)*len(t.cols))
+		for n := i; n <= end; n++ {
+			if n > i {
+				sb.WriteString(",")
+			}
+			sb.WriteString("(")
+			for c := range t.cols {
+				if c > 0 {
+					sb.WriteString(",")
+				}
+				fmt.Fprintf(&sb, "$%d", len(args)+c+1)
+			}
+			sb.WriteString(")")
+			args = append(args, t.row(n)...)
+		}
+		if _, err := pool.Exec(ctx, sb.String(), args...); err != nil {
+			return fmt.Errorf("seed batch %s at row %d: %w", t.name, i, err)
+		}
+	}
+	return nil
+}
+
+// seqRows is a pgx.CopyFromSource producing rows next..last.
+type seqRows struct {
+	next, last int
+	cur        int
+	row        func(n int) []any
+}
+
+func (r *seqRows) Next() bool {
+	if r.next > r.last {
+		return false
+	}
+	r.cur = r.next
+	r.next++
+	return true
+}
+
+func (r *seqRows) Values() ([]any, error) {
+	return r.row(r.cur), nil
+}
+
+func (r *seqRows) Err() error { return nil }
+
+// accountBalance is the deterministic seed balance of row n.
+func accountBalance(n int) float64 {
+	return float64(n*7919%1000000) / 100
+}
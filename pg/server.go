@@ -0,0 +1,202 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"tenantsdb-bench/bench"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// databaseCounters are the pg_stat_database columns diffed across a run,
+// summed over every database since tenants may live in any of them.
+var databaseCounters = []string{
+	"xact_commit", "xact_rollback", "blks_read", "blks_hit",
+	"tup_returned", "tup_fetched", "tup_inserted", "tup_updated", "tup_deleted",
+	"temp_files", "temp_bytes", "deadlocks",
+}
+
+// EventPoll is how often WatchServer looks for checkpoints and autovacuum.
+const EventPoll = time.Second
+
+// WatchServer snapshots pg_stat_database and pg_stat_statements on the
+// direct backend and watches for checkpoints and autovacuum workers; the
+// returned stop snapshots again and returns the deltas and events.
+func WatchServer(direct bench.ConnConfig) (stop func() *bench.ServerStats, err error) {
+	pool, err := Connect(direct, "disable")
+	if err != nil {
+		return nil, fmt.Errorf("connect direct: %w", err)
+	}
+	ctx := context.Background()
+	dbBefore, err := databaseStats(ctx, pool)
+	if err != nil {
+		pool.Close()
+		return nil, err
+	}
+	stmtBefore, stmtErr := statementStats(ctx, pool)
+	bench.Okf("Backend statistics snapshot taken (pg_stat_database)")
+	stopEvents := watchEvents(pool)
+
+	return func() *bench.ServerStats {
+		defer pool.Close()
+		s := &bench.ServerStats{Source: "pg_stat_database", Events: stopEvents()}
+		dbAfter, err := databaseStats(ctx, pool)
+		if err != nil {
+			bench.Warnf("Backend statistics: %v", err)
+			return nil
+		}
+		s.Counters = bench.CounterDeltas(databaseCounters, dbBefore, dbAfter)
+		if stmtErr != nil {
+			s.Note = "pg_stat_statements unavailable (" + stmtErr.Error() + ")"
+			return s
+		}
+		stmtAfter, err := statementStats(ctx, pool)
+		if err != nil {
+			s.Note = "pg_stat_statements unavailable (" + err.Error() + ")"
+			return s
+		}
+		s.Statements = bench.StatementDeltas(stmtBefore, stmtAfter)
+		return s
+	}, nil
+}
+
+func databaseStats(ctx context.Context, pool *pgxpool.Pool) (map[string]int64, error) {
+	sums := make([]string, len(databaseCounters))
+	for i, c := range databaseCounters {
+		sums[i] = "COALESCE(sum(" + c + "), 0)::bigint"
+	}
+	vals := make([]int64, len(databaseCounters))
+	dest := make([]any, len(vals))
+	for i := range vals {
+		dest[i] = &vals[i]
+	}
+	if err := pool.QueryRow(ctx, "SELECT "+strings.Join(sums, ", ")+" FROM pg_stat_database").Scan(dest...); err != nil {
+		return nil, fmt.Errorf("pg_stat_database: %w", err)
+	}
+	out := make(map[string]int64, len(vals))
+	for i, c := range databaseCounters {
+		out[c] = vals[i]
+	}
+	return out, nil
+}
+
+// statementStats reads pg_stat_statements keyed by queryid; total_exec_time
+// replaced total_time in Postgres 13.
+func statementStats(ctx context.Context, pool *pgxpool.Pool) (map[string]bench.StatementDelta, error) {
+	var err error
+	for _, col := range []string{"total_exec_time", "total_time"} {
+		var out map[string]bench.StatementDelta
+		if out, err = readStatements(ctx, pool, col); err == nil {
+			return out, nil
+		}
+	}
+	return nil, err
+}
+
+func readStatements(ctx context.Context, pool *pgxpool.Pool, timeCol string) (map[string]bench.StatementDelta, error) {
+	rows, err := pool.Query(ctx, `SELECT queryid::text || '/' || dbid::text || '/' || userid::text, query,
+		calls, rows, `+timeCol+`, shared_blks_hit, shared_blks_read
+		FROM pg_stat_statements WHERE queryid IS NOT NULL`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := map[string]bench.StatementDelta{}
+	for rows.Next() {
+		var id string
+		var d bench.StatementDelta
+		var ms float64
+		if err := rows.Scan(&id, &d.Query, &d.Calls, &d.Rows, &ms, &d.BlksHit, &d.BlksRead); err != nil {
+			return nil, err
+		}
+		d.TotalTime = time.Duration(ms * float64(time.Millisecond))
+		d.Query = strings.Join(strings.Fields(d.Query), " ")
+		out[id] = d
+	}
+	return out, rows.Err()
+}
+
+// watchEvents polls for completed checkpoints and running autovacuum
+// workers until the returned stop is called.
+func watchEvents(pool *pgxpool.Pool) (stop func() []bench.ServerEvent) {
+	var (
+		events []bench.ServerEvent
+		done   = make(chan struct{})
+		exited = make(chan struct{})
+	)
+	ctx := context.Background()
+	lastCkpt, ckptErr := checkpoints(ctx, pool)
+	vacuums := map[string]bool{}
+
+	go func() {
+		defer close(exited)
+		tick := time.NewTicker(EventPoll)
+		defer tick.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-tick.C:
+			}
+			now := time.Now()
+			if ckptErr == nil {
+				if n, err := checkpoints(ctx, pool); err == nil && n > lastCkpt {
+					events = append(events, bench.ServerEvent{At: now, Kind: "checkpoint"})
+					lastCkpt = n
+				}
+			}
+			running, err := autovacuums(ctx, pool)
+			if err != nil {
+				continue
+			}
+			for key, table := range running {
+				if !vacuums[key] {
+					events = append(events, bench.ServerEvent{At: now, Kind: "autovacuum", Detail: table})
+				}
+			}
+			vacuums = map[string]bool{}
+			for key := range running {
+				vacuums[key] = true
+			}
+		}
+	}()
+	return func() []bench.ServerEvent {
+		close(done)
+		<-exited
+		return events
+	}
+}
+
+// checkpoints returns how many checkpoints have completed; the counters
+// moved to pg_stat_checkpointer in Postgres 17.
+func checkpoints(ctx context.Context, pool *pgxpool.Pool) (int64, error) {
+	var n int64
+	err := pool.QueryRow(ctx, "SELECT num_timed + num_requested FROM pg_stat_checkpointer").Scan(&n)
+	if err != nil {
+		err = pool.QueryRow(ctx, "SELECT checkpoints_timed + checkpoints_req FROM pg_stat_bgwriter").Scan(&n)
+	}
+	return n, err
+}
+
+// autovacuums returns the running autovacuum workers keyed by pid and
+// query, with what each is working on.
+func autovacuums(ctx context.Context, pool *pgxpool.Pool) (map[string]string, error) {
+	rows, err := pool.Query(ctx, `SELECT pid::text || ' ' || query, query
+		FROM pg_stat_activity WHERE backend_type = 'autovacuum worker' AND query <> ''`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := map[string]string{}
+	for rows.Next() {
+		var key, query string
+		if err := rows.Scan(&key, &query); err != nil {
+			return nil, err
+		}
+		out[key] = strings.TrimPrefix(query, "autovacuum: ")
+	}
+	return out, rows.Err()
+}
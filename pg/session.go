@@ -0,0 +1,148 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"tenantsdb-bench/bench"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var sessionZones = []string{"UTC", "America/New_York", "Europe/Berlin", "Asia/Tokyo", "Australia/Sydney"}
+
+// RunSession runs sessions that SET their own time zone, search_path and
+// application_name, then interleave queries with checks that the settings
+// stuck, through the proxy and (when configured) direct. A multiplexing
+// proxy that leaks or resets session state fails the verify op.
+func RunSession(ctx context.Context, proxyCfg, directCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
+	return runSessions(ctx, proxyCfg, directCfg, params, "Session State Test", "session state", session)
+}
+
+// sessionFunc runs one session tagged tag, one result per statement.
+type sessionFunc func(ctx context.Context, pool *pgxpool.Pool, params bench.BenchParams, tag string) []bench.QueryResult
+
+// runSessions is the shared driver of the session-scoped tests: it seeds,
+// runs fn sessions through each endpoint and compares them.
+func runSessions(ctx context.Context, proxyCfg, directCfg bench.ConnConfig, params bench.BenchParams, title, what string, fn sessionFunc) *bench.Result {
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Printf("  PostgreSQL %s\n", title)
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Printf("  Queries: %d | Concurrency: %d | Session queries: %d\n\n",
+		params.Queries, params.Concurrency, params.SessionQueries)
+
+	res := &bench.Result{}
+	directPool, proxyPool, ok := endpoints(proxyCfg, directCfg, res, 3)
+	if !ok {
+		return nil
+	}
+	defer proxyPool.Close()
+	if directPool != nil {
+		defer directPool.Close()
+	}
+
+	seedPool := proxyPool
+	if directPool != nil {
+		seedPool = directPool
+	}
+	if err := PrepareData(ctx, seedPool, params); err != nil {
+		bench.Failf("Seed failed: %v", err)
+		return nil
+	}
+	bench.Okf("Data ready")
+
+	bench.Stepf("[3/3] Running sessions...")
+	var directStats bench.BenchStats
+	if directPool != nil {
+		fmt.Println("\n── Direct PostgreSQL ──")
+		directStats = bench.RunMultiple(ctx, params.Runs, "Direct "+what, func(run int) bench.BenchStats {
+			return sessionPass(bench.Unobserved(ctx), directPool, params, "Direct "+what, fn)
+		})
+		bench.PrintStats(directStats)
+		res.Stats = append(res.Stats, directStats)
+	}
+
+	fmt.Println("\n── Through TenantsDB Proxy ──")
+	proxyStats := bench.RunMultiple(ctx, params.Runs, "Proxy "+what, func(run int) bench.BenchStats {
+		return sessionPass(ctx, proxyPool, params, "Proxy "+what, fn)
+	})
+	bench.PrintStats(proxyStats)
+	res.Stats = append(res.Stats, proxyStats)
+	if proxyStats.Errors > 0 {
+		fmt.Println()
+		bench.Warnf("%s broke through the proxy", what)
+	} else {
+		fmt.Println()
+		bench.Okf("%s held up through the proxy", what)
+	}
+
+	if directPool != nil {
+		bench.PrintComparison(proxyStats, directStats)
+		cmp := bench.Compare(proxyStats, directStats)
+		res.Comparison = &cmp
+	}
+	return res
+}
+
+// sessionPass runs sessions on params.Concurrency workers until about
+// params.Queries statements are done.
+func sessionPass(ctx context.Context, pool *pgxpool.Pool, params bench.BenchParams, label string, fn sessionFunc) bench.BenchStats {
+	sessions := max(params.Queries/(2*params.SessionQueries+1)/params.Concurrency, 1)
+	results, total := bench.RunWorkers(ctx, label, params.Concurrency, func(worker int) []bench.QueryResult {
+		var local []bench.QueryResult
+		for i := 0; i < sessions; i++ {
+			local = append(local, fn(ctx, pool, params, fmt.Sprintf("tdb_w%d_s%d", worker, i))...)
+		}
+		return local
+	})
+	bench.PrintErrors(results)
+	return bench.ComputeStats(label, results, total)
+}
+
+// session pins one connection, sets its state, then alternates point reads
+// with verifying the state, one result per statement.
+func session(ctx context.Context, pool *pgxpool.Pool, params bench.BenchParams, tag string) []bench.QueryResult {
+	var out []bench.QueryResult
+	record := func(op string, qStart time.Time, err error) error {
+		out = append(out, bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err, Op: op})
+		return err
+	}
+
+	zone := sessionZones[rand.Intn(len(sessionZones))]
+	path := tag + `, "$user", public`
+
+	qStart := time.Now()
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		record("set", qStart, err)
+		return out
+	}
+	defer conn.Release()
+	defer conn.Exec(ctx, "RESET ALL")
+
+	_, err = conn.Exec(ctx, fmt.Sprintf("SET TIME ZONE '%s'; SET search_path TO %s; SET application_name = '%s'", zone, path, tag))
+	if record("set", qStart, err) != nil {
+		return out
+	}
+
+	q := newQueries(params)
+	for i := 0; i < params.SessionQueries; i++ {
+		qStart := time.Now()
+		id := rand.Intn(params.SeedRows) + 1
+		err := conn.QueryRow(ctx, q.selectByID, id).Scan(new(int), new(string), new(float64))
+		record("query", qStart, err)
+
+		qStart = time.Now()
+		var gotZone, gotPath, gotApp string
+		err = conn.QueryRow(ctx, "SELECT current_setting('TimeZone'), current_setting('search_path'), current_setting('application_name')").
+			Scan(&gotZone, &gotPath, &gotApp)
+		if err == nil && (gotZone != zone || gotPath != path || gotApp != tag) {
+			err = fmt.Errorf("session state lost: TimeZone=%q search_path=%q application_name=%q, want %q %q %q",
+				gotZone, gotPath, gotApp, zone, path, tag)
+		}
+		record("verify", qStart, err)
+	}
+	return out
+}
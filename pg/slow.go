@@ -0,0 +1,100 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"tenantsdb-bench/bench"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// slowTracer times every statement on a connection and offers it to
+// bench.Slow.
+type slowTracer struct {
+	log *bench.SlowLog
+}
+
+type slowStartKey struct{}
+
+type slowStart struct {
+	at   time.Time
+	sql  string
+	args []any
+}
+
+func (t slowTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, slowStartKey{}, slowStart{at: time.Now(), sql: data.SQL, args: data.Args})
+}
+
+func (t slowTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	st, ok := ctx.Value(slowStartKey{}).(slowStart)
+	if !ok {
+		return
+	}
+	d := time.Since(st.at)
+	if !t.log.Wants(d) {
+		return
+	}
+	cfg := conn.Config()
+	q := bench.SlowQuery{
+		At:       st.at,
+		Duration: d,
+		Server:   fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+		Tenant:   cfg.Database,
+		SQL:      st.sql,
+		Args:     st.args,
+	}
+	for _, a := range st.args {
+		q.Params = append(q.Params, bench.FormatArg(a))
+	}
+	if data.Err != nil {
+		q.Err = data.Err.Error()
+	}
+	t.log.Add(q)
+}
+
+// ExplainSlow runs EXPLAIN (without ANALYZE) for each captured statement
+// on the direct backend and attaches the plans.
+func ExplainSlow(direct bench.ConnConfig, queries []bench.SlowQuery) {
+	pool, err := Connect(direct, "disable")
+	if err != nil {
+		bench.Warnf("EXPLAIN: connect direct: %v", err)
+		return
+	}
+	defer pool.Close()
+	for i := range queries {
+		queries[i].Plan = explain(pool, queries[i])
+	}
+}
+
+func explain(pool *pgxpool.Pool, q bench.SlowQuery) string {
+	verb := strings.ToUpper(strings.Fields(q.SQL + " x")[0])
+	switch verb {
+	case "SELECT", "INSERT", "UPDATE", "DELETE", "WITH":
+	default:
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	rows, err := pool.Query(ctx, "EXPLAIN "+q.SQL, q.Args...)
+	if err != nil {
+		return "EXPLAIN failed: " + err.Error()
+	}
+	defer rows.Close()
+	var plan strings.Builder
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return "EXPLAIN failed: " + err.Error()
+		}
+		plan.WriteString(line + "\n")
+	}
+	if err := rows.Err(); err != nil {
+		return "EXPLAIN failed: " + err.Error()
+	}
+	return plan.String()
+}
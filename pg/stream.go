@@ -0,0 +1,115 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"tenantsdb-bench/bench"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RunStream fetches results of increasing size and measures time to the
+// first row and to the last, through the proxy and (when configured) direct.
+func RunStream(ctx context.Context, proxyCfg, directCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Println("  PostgreSQL Result Streaming Benchmark")
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Printf("  Result sizes: %v rows | %d queries each\n\n", params.StreamRows, params.StreamIters)
+
+	res := &bench.Result{}
+
+	directPool, proxyPool, ok := endpoints(proxyCfg, directCfg, res, 3)
+	if !ok {
+		return nil
+	}
+	defer proxyPool.Close()
+	if directPool != nil {
+		defer directPool.Close()
+	}
+
+	seedPool := proxyPool
+	if directPool != nil {
+		seedPool = directPool
+	}
+	if err := PrepareData(ctx, seedPool, params); err != nil {
+		bench.Failf("Seed failed: %v", err)
+		return nil
+	}
+	bench.Okf("Data ready")
+
+	bench.Stepf("[3/3] Streaming results...")
+	query := "SELECT id, name, balance FROM " + tableIdent(params) + " ORDER BY id LIMIT $1"
+
+	var points []bench.StreamPoint
+	for _, n := range params.StreamRows {
+		point := bench.StreamPoint{Rows: n}
+		if directPool != nil {
+			fmt.Printf("\n── Direct, %d rows ──\n", n)
+			s := streamRuns(ctx, directPool, query, n, params.StreamIters, fmt.Sprintf("Direct %d rows", n))
+			bench.PrintStats(s)
+			point.Direct = &s
+			res.Stats = append(res.Stats, s)
+		}
+		fmt.Printf("\n── Through Proxy, %d rows ──\n", n)
+		point.Proxy = streamRuns(ctx, proxyPool, query, n, params.StreamIters, fmt.Sprintf("Proxy %d rows", n))
+		bench.PrintStats(point.Proxy)
+		res.Stats = append(res.Stats, point.Proxy)
+		points = append(points, point)
+	}
+
+	bench.PrintStreaming(points)
+	return res
+}
+
+// streamRuns fetches an n-row result iters times, one query at a time.
+func streamRuns(ctx context.Context, pool *pgxpool.Pool, query string, n, iters int, label string) bench.BenchStats {
+	results := make([]bench.QueryResult, iters)
+	start := time.Now()
+	for i := range results {
+		results[i] = streamOnce(ctx, pool, query, n)
+		if results[i].Err != nil {
+			bench.Warnf("Error: %v", results[i].Err)
+		}
+	}
+	return bench.ComputeStats(label, results, time.Since(start))
+}
+
+// streamOnce reads every row of one n-row result, noting when the first
+// row arrived.
+func streamOnce(ctx context.Context, pool *pgxpool.Pool, query string, n int) bench.QueryResult {
+	qStart := time.Now()
+	r := bench.QueryResult{At: qStart}
+
+	rows, err := pool.Query(ctx, query, n)
+	if err != nil {
+		r.Err = err
+		r.Duration = time.Since(qStart)
+		return r
+	}
+	got := 0
+	for rows.Next() {
+		if got == 0 {
+			r.FirstRow = time.Since(qStart)
+		}
+		var id int
+		var name string
+		var balance float64
+		if err := rows.Scan(&id, &name, &balance); err != nil {
+			rows.Close()
+			r.Err = err
+			r.Duration = time.Since(qStart)
+			return r
+		}
+		r.Bytes += len(name) + 16
+		got++
+	}
+	rows.Close()
+	r.Duration = time.Since(qStart)
+	r.Err = rows.Err()
+	if r.Err == nil && got != n {
+		r.Err = fmt.Errorf("got %d rows, want %d", got, n)
+	}
+	return r
+}
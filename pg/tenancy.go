@@ -0,0 +1,173 @@
+package pg
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"tenantsdb-bench/bench"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// tenantConfig points cfg at tenant: its database, with -tenancy schema its
+// schema in cfg's database, selected through the search_path startup
+// parameter so every statement stays unqualified, or with -tenancy rls its
+// role, which the shared table's policy filters rows by.
+func tenantConfig(cfg bench.ConnConfig, params bench.BenchParams, tenant string) bench.ConnConfig {
+	switch params.Tenancy {
+	case "schema":
+		cfg.SearchPath = tenant
+	case "rls":
+		cfg.User, cfg.Password = tenant, params.TenantPassword
+	default:
+		cfg.Database = tenant
+	}
+	return cfg
+}
+
+// pointAt is tenantConfig for a pool config.
+func pointAt(c *pgxpool.Config, params bench.BenchParams, tenant string) {
+	switch params.Tenancy {
+	case "schema":
+		c.ConnConfig.RuntimeParams["search_path"] = pgx.Identifier{tenant}.Sanitize()
+	case "rls":
+		c.ConnConfig.User, c.ConnConfig.Password = tenant, params.TenantPassword
+	default:
+		c.ConnConfig.Database = tenant
+	}
+}
+
+// SchemaTenants is a bench.ControlPlane that makes each tenant a schema in
+// the database behind cfg, for -tenancy schema.
+type SchemaTenants struct {
+	pool *pgxpool.Pool
+}
+
+func NewSchemaTenants(cfg bench.ConnConfig) (*SchemaTenants, error) {
+	pool, err := Connect(cfg, "disable")
+	if err != nil {
+		return nil, err
+	}
+	return &SchemaTenants{pool: pool}, nil
+}
+
+func (s *SchemaTenants) Close() { s.pool.Close() }
+
+// CreateTenant creates the schema; one left over from an earlier run is
+// reused.
+func (s *SchemaTenants) CreateTenant(ctx context.Context, name string) (string, error) {
+	_, err := s.pool.Exec(ctx, "CREATE SCHEMA IF NOT EXISTS "+pgx.Identifier{name}.Sanitize())
+	return name, err
+}
+
+// WaitReady returns at once: CREATE SCHEMA is done when it returns.
+func (s *SchemaTenants) WaitReady(ctx context.Context, name string) error { return nil }
+
+// DeleteTenant drops the schema with the tables seeded into it.
+func (s *SchemaTenants) DeleteTenant(ctx context.Context, name string) error {
+	_, err := s.pool.Exec(ctx, "DROP SCHEMA IF EXISTS "+pgx.Identifier{name}.Sanitize()+" CASCADE")
+	return err
+}
+
+// RLSTenants is a bench.ControlPlane that makes each tenant a login role
+// sharing one benchmark table in the database behind cfg, for -tenancy rls.
+// The table carries a tenant_id column defaulting to current_user and a
+// row-level security policy that shows each role only its own rows.
+type RLSTenants struct {
+	pool   *pgxpool.Pool
+	params bench.BenchParams
+
+	// Password is the roles' password, generated for the run.
+	Password string
+}
+
+// NewRLSTenants connects as cfg's user, which owns the shared table and so
+// bypasses its policy, and creates the table and policy if missing.
+func NewRLSTenants(cfg bench.ConnConfig, params bench.BenchParams) (*RLSTenants, error) {
+	pool, err := Connect(cfg, "disable")
+	if err != nil {
+		return nil, err
+	}
+	key := make([]byte, 16)
+	rand.Read(key)
+	s := &RLSTenants{pool: pool, params: params, Password: hex.EncodeToString(key)}
+	if err := s.setup(context.Background()); err != nil {
+		pool.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *RLSTenants) Close() { s.pool.Close() }
+
+// setup creates the shared table with the optional columns the run needs,
+// since tenant roles cannot alter it, and (re)creates its policy.
+func (s *RLSTenants) setup(ctx context.Context) error {
+	if err := createTables(ctx, s.pool, s.params); err != nil {
+		return fmt.Errorf("create table: %w", err)
+	}
+	if s.params.RowBytes > 0 {
+		if err := ensureColumn(ctx, s.pool, s.params, "payload", "TEXT"); err != nil {
+			return err
+		}
+	}
+	if s.params.Documents {
+		if err := ensureColumn(ctx, s.pool, s.params, "doc", "JSONB"); err != nil {
+			return err
+		}
+	}
+	table := tableIdent(s.params)
+	for _, stmt := range []string{
+		"ALTER TABLE " + table + " ENABLE ROW LEVEL SECURITY",
+		"DROP POLICY IF EXISTS tenant_rows ON " + table,
+		"CREATE POLICY tenant_rows ON " + table + " USING (tenant_id = current_user)",
+	} {
+		if _, err := s.pool.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("row-level security: %w", err)
+		}
+	}
+	return nil
+}
+
+// CreateTenant creates the role with the run's password and grants it the
+// shared table; a role left over from an earlier run gets the new password.
+func (s *RLSTenants) CreateTenant(ctx context.Context, name string) (string, error) {
+	role := pgx.Identifier{name}.Sanitize()
+	login := " LOGIN PASSWORD '" + s.Password + "'"
+	_, err := s.pool.Exec(ctx, "CREATE ROLE "+role+login)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "42710" {
+		_, err = s.pool.Exec(ctx, "ALTER ROLE "+role+login)
+	}
+	if err != nil {
+		return "", err
+	}
+	_, err = s.pool.Exec(ctx, "GRANT SELECT, INSERT, UPDATE, DELETE ON "+tableIdent(s.params)+" TO "+role)
+	return name, err
+}
+
+// WaitReady returns at once: CREATE ROLE is done when it returns.
+func (s *RLSTenants) WaitReady(ctx context.Context, name string) error { return nil }
+
+// DeleteTenant deletes the tenant's rows from the shared table and drops
+// its role.
+func (s *RLSTenants) DeleteTenant(ctx context.Context, name string) error {
+	table, role := tableIdent(s.params), pgx.Identifier{name}.Sanitize()
+	if _, err := s.pool.Exec(ctx, "DELETE FROM "+table+" WHERE tenant_id = $1", name); err != nil {
+		return err
+	}
+	for _, stmt := range []string{
+		"REVOKE ALL ON " + table + " FROM " + role,
+		"DROP ROLE IF EXISTS " + role,
+	} {
+		if _, err := s.pool.Exec(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"tenantsdb-bench/bench"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// verifyQuery is one query of the verify test's deterministic set.
+type verifyQuery struct {
+	name, sql string
+}
+
+// verifyQueries is the verify test's query set: every multi-row query is
+// fully ordered so direct and proxy must return identical results.
+func verifyQueries(params bench.BenchParams) []verifyQuery {
+	t := tableIdent(params)
+	qs := []verifyQuery{
+		{"point reads", "SELECT id, name, balance FROM " + t + " WHERE id BETWEEN 1 AND 20 ORDER BY id"},
+		{"range", "SELECT id, name, balance FROM " + t + " WHERE id > 0 ORDER BY id LIMIT 1000"},
+		{"offset page", "SELECT id, name, balance FROM " + t + " WHERE id > 0 ORDER BY id LIMIT 50 OFFSET 500"},
+		{"aggregates", "SELECT COUNT(*), SUM(balance), MIN(balance), MAX(balance), AVG(balance) FROM " + t + " WHERE id > 0"},
+		{"group by", "SELECT id % 10 AS bucket, COUNT(*), SUM(balance) FROM " + t + " WHERE id > 0 GROUP BY 1 ORDER BY 1"},
+		{"empty", "SELECT id, name, balance FROM " + t + " WHERE id = 0"},
+		{"types", `SELECT NULL::text, '', true, 32767::int2, 2147483647::int4, 9223372036854775807::int8,
+			1.5::float4, 2.25::float8, 12345.67::numeric(10,2), 'héllo ✓ 日本'::text, '\xdeadbeef'::bytea,
+			'2024-02-29 12:34:56.789+00'::timestamptz, '2024-02-29'::date, '{"a": [1, 2, {"b": null}]}'::jsonb,
+			ARRAY[1, 2, 3], '7c9e6679-7425-40de-944b-e07fc1f90ae7'::uuid, interval '1 day 02:03:04'`},
+	}
+	if params.RowBytes > 0 {
+		qs = append(qs, verifyQuery{"payload", "SELECT id, payload FROM " + t + " WHERE id BETWEEN 1 AND 20 ORDER BY id"})
+	}
+	if params.Documents {
+		qs = append(qs, verifyQuery{"documents", "SELECT id, doc FROM " + t + " WHERE id BETWEEN 1 AND 20 ORDER BY id"})
+	}
+	if params.Relational {
+		o := qualify(params, params.OrdersTable()).Sanitize()
+		it := qualify(params, params.ItemsTable()).Sanitize()
+		qs = append(qs, verifyQuery{"join", "SELECT a.id, a.name, o.id, o.amount, o.status, it.sku, it.qty, it.price FROM " + t + " a" +
+			" JOIN " + o + " o ON o.account_id = a.id JOIN " + it + " it ON it.order_id = o.id" +
+			" WHERE a.id BETWEEN 1 AND 20 ORDER BY a.id, o.id, it.id"})
+	}
+	return qs
+}
+
+// RunVerify runs the same deterministic queries directly and through the
+// proxy and compares column types and values, to prove the proxy returns
+// what the database does.
+func RunVerify(ctx context.Context, proxyCfg, directCfg bench.ConnConfig, params bench.BenchParams) *bench.Result {
+	fmt.Println("═══════════════════════════════════════════")
+	fmt.Println("  PostgreSQL Result Verification")
+	fmt.Println("═══════════════════════════════════════════")
+
+	res := &bench.Result{}
+	directPool, proxyPool, ok := endpoints(proxyCfg, directCfg, res, 3)
+	if !ok {
+		return nil
+	}
+	defer proxyPool.Close()
+	defer directPool.Close()
+
+	if err := PrepareData(ctx, directPool, params); err != nil {
+		bench.Failf("Seed failed: %v", err)
+		return nil
+	}
+	bench.Okf("Data ready")
+
+	bench.Stepf("[3/3] Comparing results...")
+	qs := verifyQueries(params)
+	var directRes, proxyRes []bench.QueryResult
+	var directBusy, proxyBusy time.Duration
+	bad := 0
+	for _, q := range qs {
+		d, dr := fetchSet(ctx, directPool, q)
+		p, pr := fetchSet(ctx, proxyPool, q)
+		directRes, proxyRes = append(directRes, dr), append(proxyRes, pr)
+		directBusy += dr.Duration
+		proxyBusy += pr.Duration
+		switch {
+		case dr.Err != nil:
+			bench.Warnf("%s: direct failed: %v", q.name, dr.Err)
+		case pr.Err != nil:
+			bench.Failf("%s: proxy failed: %v", q.name, pr.Err)
+			bad++
+		default:
+			if diff := d.Diff(p); diff != "" {
+				bench.Failf("%s: %s (direct vs proxy)", q.name, diff)
+				proxyRes[len(proxyRes)-1].Err = fmt.Errorf("%s: %s", q.name, diff)
+				bad++
+			} else {
+				bench.Okf("%s (%d rows)", q.name, len(d.Rows))
+			}
+		}
+	}
+
+	bench.PrintVerify(len(qs), bad)
+	directStats := bench.ComputeStats("Direct verify", directRes, directBusy)
+	proxyStats := bench.ComputeStats("Proxy verify", proxyRes, proxyBusy)
+	res.Stats = append(res.Stats, directStats, proxyStats)
+	return res
+}
+
+// fetchSet runs q and reduces its result to a ResultSet, timing the query.
+func fetchSet(ctx context.Context, pool *pgxpool.Pool, q verifyQuery) (bench.ResultSet, bench.QueryResult) {
+	qStart := time.Now()
+	r := bench.QueryResult{At: qStart, Op: q.name}
+	var set bench.ResultSet
+
+	rows, err := pool.Query(ctx, q.sql)
+	if err != nil {
+		r.Err = err
+		r.Duration = time.Since(qStart)
+		return set, r
+	}
+	for _, f := range rows.FieldDescriptions() {
+		name := fmt.Sprintf("oid %d", f.DataTypeOID)
+		if t, ok := rows.Conn().TypeMap().TypeForOID(f.DataTypeOID); ok {
+			name = t.Name
+		}
+		set.Types = append(set.Types, name)
+	}
+	for rows.Next() {
+		vals, err := rows.Values()
+		if err != nil {
+			r.Err = err
+			break
+		}
+		row := make([]string, len(vals))
+		for i, v := range vals {
+			row[i] = fmt.Sprintf("%v", v)
+		}
+		set.Rows = append(set.Rows, row)
+	}
+	rows.Close()
+	r.Duration = time.Since(qStart)
+	if r.Err == nil {
+		r.Err = rows.Err()
+	}
+	return set, r
+}
@@ -0,0 +1,83 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"tenantsdb-bench/bench"
+)
+
+// SampleWaits polls pg_stat_activity on the direct backend every interval
+// and tallies what active client backends were waiting on, until the
+// returned stop is called.
+func SampleWaits(direct bench.ConnConfig, interval time.Duration) (stop func() *bench.WaitReport, err error) {
+	pool, err := Connect(direct, "disable")
+	if err != nil {
+		return nil, fmt.Errorf("connect direct: %w", err)
+	}
+
+	var (
+		counts = map[string]int{}
+		polls  int
+		failed int
+		done   = make(chan struct{})
+		wg     sync.WaitGroup
+	)
+	poll := func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), max(interval, time.Second))
+		defer cancel()
+		rows, err := pool.Query(ctx, `SELECT COALESCE(wait_event_type || ':' || wait_event, 'CPU')
+			FROM pg_stat_activity
+			WHERE state = 'active' AND backend_type = 'client backend' AND pid <> pg_backend_pid()`)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var ev string
+			if err := rows.Scan(&ev); err != nil {
+				return err
+			}
+			counts[ev]++
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		polls++
+		return nil
+	}
+	if err := poll(); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("pg_stat_activity: %w", err)
+	}
+	bench.Okf("Sampling backend wait events every %s", interval)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		tick := time.NewTicker(interval)
+		defer tick.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-tick.C:
+				if err := poll(); err != nil {
+					failed++
+				}
+			}
+		}
+	}()
+
+	return func() *bench.WaitReport {
+		close(done)
+		wg.Wait()
+		pool.Close()
+		if failed > 0 {
+			bench.Warnf("%d wait-event polls failed", failed)
+		}
+		return bench.SummarizeWaits(polls, counts)
+	}, nil
+}
@@ -0,0 +1,348 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"tenantsdb-bench/bench"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// queries holds the benchmark statements rendered for the configured table.
+type queries struct {
+	table        string // quoted, schema-qualified
+	selectByID   string
+	update       string
+	join2        string // orders of one account, joined to the account
+	join3        string // line items of one account, across orders
+	selectWide   string // selectByID plus the payload column
+	updateWide   string // rewrites the payload column
+	pageOffset   string // one page by LIMIT/OFFSET
+	pageKeyset   string // one page after a given id
+	aggGroup     string // whole-table GROUP BY
+	aggRange     string // SUM/AVG over an id range
+	upsert       string // insert-or-add-to-balance by id
+	jsonContains string // containment filter over an id range
+	jsonPath     string // two fields extracted from one document
+	jsonUpdate   string // rewrites one field of a document
+	rowBytes     int
+	pageSize     int
+	hotPct       int
+	hotRows      int
+	restarts     int // CockroachDB restarts retried per write (0 = none)
+}
+
+// label names an op for the per-op breakdown, which mixedQuery only
+// reports when hot-row writes make it interesting.
+func (q queries) label(op string) string {
+	if q.hotPct == 0 {
+		return ""
+	}
+	return op
+}
+
+func newQueries(params bench.BenchParams) queries {
+	t := tableIdent(params)
+	o := qualify(params, params.OrdersTable()).Sanitize()
+	i := qualify(params, params.ItemsTable()).Sanitize()
+	q := queries{
+		table:      t,
+		selectByID: "SELECT id, name, balance FROM " + t + " WHERE id = $1",
+		update:     "UPDATE " + t + " SET balance = balance + $1 WHERE id = $2",
+		join2: "SELECT o.id, o.amount, o.status, a.name FROM " + o + " o" +
+			" JOIN " + t + " a ON a.id = o.account_id WHERE o.account_id = $1",
+		join3: "SELECT a.name, o.id, it.sku, it.qty, it.price FROM " + t + " a" +
+			" JOIN " + o + " o ON o.account_id = a.id" +
+			" JOIN " + i + " it ON it.order_id = o.id WHERE a.id = $1",
+		selectWide: "SELECT id, name, balance, payload FROM " + t + " WHERE id = $1",
+		updateWide: "UPDATE " + t + " SET payload = $1, balance = balance + $2 WHERE id = $3",
+		pageOffset: "SELECT id, name, balance FROM " + t + " ORDER BY id LIMIT $1 OFFSET $2",
+		pageKeyset: "SELECT id, name, balance FROM " + t + " WHERE id > $1 ORDER BY id LIMIT $2",
+		aggGroup:   "SELECT id % 100 AS bucket, COUNT(*), SUM(balance) FROM " + t + " GROUP BY id % 100",
+		aggRange:   "SELECT COUNT(*), SUM(balance), AVG(balance) FROM " + t + " WHERE id BETWEEN $1 AND $2",
+		upsert: "INSERT INTO " + t + " AS a (id, name, balance) VALUES ($1, $2, $3)" +
+			" ON CONFLICT (id) DO UPDATE SET balance = a.balance + EXCLUDED.balance",
+		rowBytes: params.RowBytes,
+		pageSize: params.PageSize,
+		hotPct:   params.HotPct,
+		hotRows:  params.HotRows,
+	}
+	if params.Cockroach {
+		q.restarts = maxRestarts
+	}
+	if params.Tenancy == "rls" {
+		// The shared table's key; tenant_id defaults to the tenant's role
+		q.upsert = "INSERT INTO " + t + " AS a (id, name, balance) VALUES ($1, $2, $3)" +
+			" ON CONFLICT (tenant_id, id) DO UPDATE SET balance = a.balance + EXCLUDED.balance"
+	}
+	if params.AsOf != "" {
+		clause := asOfClause(params.AsOf)
+		for _, read := range []*string{&q.selectByID, &q.join2, &q.join3, &q.selectWide,
+			&q.pageOffset, &q.pageKeyset, &q.aggGroup, &q.aggRange} {
+			*read = withAsOf(*read, clause)
+		}
+	}
+	return q
+}
+
+// tableIdent quotes the benchmark table, schema-qualified when -schema is set.
+func tableIdent(params bench.BenchParams) string {
+	return qualify(params, params.TableName()).Sanitize()
+}
+
+// qualify names a table in the configured schema, if any.
+func qualify(params bench.BenchParams, table string) pgx.Identifier {
+	if params.Schema != "" {
+		return pgx.Identifier{params.Schema, table}
+	}
+	return pgx.Identifier{table}
+}
+
+// opFunc runs one operation of a workload.
+type opFunc func(ctx context.Context, pool *pgxpool.Pool, q queries, maxID int) bench.QueryResult
+
+// workloadOp picks the operation for params.Workload.
+func workloadOp(params bench.BenchParams) opFunc {
+	switch params.Workload {
+	case "join":
+		return joinQuery
+	case "wide":
+		return wideQuery
+	case "page":
+		return pageQuery
+	case "agg":
+		return aggQuery
+	case "upsert":
+		return upsertQuery
+	case "json":
+		return jsonQuery
+	}
+	if f, ok := bench.LookupWorkload(params.Workload); ok {
+		return registeredOp(f, params)
+	}
+	return mixedQuery
+}
+
+// registeredOp adapts a bench.RegisterWorkload workload; if it does not
+// support Postgres, every operation fails with the factory's error.
+func registeredOp(f bench.WorkloadFactory, params bench.BenchParams) opFunc {
+	fn, err := f("postgres", params)
+	return func(ctx context.Context, pool *pgxpool.Pool, q queries, maxID int) bench.QueryResult {
+		if err != nil {
+			return bench.QueryResult{At: time.Now(), Err: err}
+		}
+		return fn(ctx, pool, maxID)
+	}
+}
+
+// oltpOp is workloadOp, with results tagged "oltp" when aggregation
+// workers run alongside so the two can be told apart.
+func oltpOp(params bench.BenchParams) opFunc {
+	op := workloadOp(params)
+	if params.AggWorkers == 0 {
+		return op
+	}
+	return func(ctx context.Context, pool *pgxpool.Pool, q queries, maxID int) bench.QueryResult {
+		r := op(ctx, pool, q, maxID)
+		if r.Op == "" {
+			r.Op = "oltp"
+		}
+		return r
+	}
+}
+
+// mixedQuery runs one operation of the 80% read / 20% write workload.
+func mixedQuery(ctx context.Context, pool *pgxpool.Pool, q queries, maxID int) bench.QueryResult {
+	qStart := time.Now()
+	id := rand.Intn(maxID) + 1
+
+	if rand.Intn(100) < 80 {
+		var rID int
+		var rName string
+		var rBalance float64
+		err := pool.QueryRow(ctx, q.selectByID, id).Scan(&rID, &rName, &rBalance)
+		return bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err, Op: q.label("read")}
+	}
+
+	delta := rand.Float64()*200 - 100
+	if q.hotPct > 0 && rand.Intn(100) < q.hotPct {
+		err := hotTransfer(ctx, pool, q, delta)
+		return bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: classify(err), Op: "hot_write"}
+	}
+	err := q.exec(ctx, pool, q.update, delta, id)
+	return bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: classify(err), Op: q.label("write")}
+}
+
+// joinQuery runs one operation of the relational workload: 50% point reads,
+// 15% two-table joins, 15% three-table joins and 20% balance updates.
+func joinQuery(ctx context.Context, pool *pgxpool.Pool, q queries, maxID int) bench.QueryResult {
+	qStart := time.Now()
+	id := rand.Intn(maxID) + 1
+
+	var err error
+	switch r := rand.Intn(100); {
+	case r < 50:
+		var rID int
+		var rName string
+		var rBalance float64
+		err = pool.QueryRow(ctx, q.selectByID, id).Scan(&rID, &rName, &rBalance)
+	case r < 65:
+		err = drain(ctx, pool, q.join2, id)
+	case r < 80:
+		err = drain(ctx, pool, q.join3, id)
+	default:
+		err = q.exec(ctx, pool, q.update, rand.Float64()*200-100, id)
+	}
+	return bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: classify(err)}
+}
+
+// wideQuery runs one operation of the -row-bytes workload: 80% reads of a
+// whole wide row and 20% rewrites of its payload. Bytes counts name and
+// payload bytes moved.
+func wideQuery(ctx context.Context, pool *pgxpool.Pool, q queries, maxID int) bench.QueryResult {
+	qStart := time.Now()
+	id := rand.Intn(maxID) + 1
+
+	if rand.Intn(100) < 80 {
+		var rID int
+		var rName string
+		var rBalance float64
+		var rPayload []byte
+		err := pool.QueryRow(ctx, q.selectWide, id).Scan(&rID, &rName, &rBalance, &rPayload)
+		return bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err, Bytes: len(rName) + len(rPayload)}
+	}
+
+	payload := bench.Payload(rand.Int(), q.rowBytes)
+	err := q.exec(ctx, pool, q.updateWide, payload, rand.Float64()*200-100, id)
+	return bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: classify(err), Bytes: len(payload)}
+}
+
+// pageQuery fetches one random page of a list endpoint, half the time by
+// OFFSET and half by keyset, so the two patterns can be compared per op.
+func pageQuery(ctx context.Context, pool *pgxpool.Pool, q queries, maxID int) bench.QueryResult {
+	qStart := time.Now()
+	pages := max(maxID/q.pageSize, 1)
+	start := rand.Intn(pages) * q.pageSize
+
+	var err error
+	op := "page_offset"
+	if rand.Intn(2) == 0 {
+		err = drain(ctx, pool, q.pageOffset, q.pageSize, start)
+	} else {
+		op = "page_keyset"
+		err = drain(ctx, pool, q.pageKeyset, start, q.pageSize)
+	}
+	return bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err, Op: op}
+}
+
+// aggQuery runs one reporting query: a GROUP BY over the whole table or
+// SUM/AVG over a random tenth of it.
+func aggQuery(ctx context.Context, pool *pgxpool.Pool, q queries, maxID int) bench.QueryResult {
+	qStart := time.Now()
+	if rand.Intn(2) == 0 {
+		err := drain(ctx, pool, q.aggGroup)
+		return bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err, Op: "agg_group"}
+	}
+	from := rand.Intn(maxID) + 1
+	err := drain(ctx, pool, q.aggRange, from, from+maxID/10)
+	return bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err, Op: "agg_range"}
+}
+
+// upsertQuery runs one upsert: half hit a seeded row and take the update
+// path, half hit ids -1..-maxID, which insert on first use. Negative ids stay
+// clear of the ids seeding hands out.
+func upsertQuery(ctx context.Context, pool *pgxpool.Pool, q queries, maxID int) bench.QueryResult {
+	qStart := time.Now()
+	id := rand.Intn(maxID) + 1
+	if rand.Intn(2) == 0 {
+		id = -id
+	}
+	err := q.exec(ctx, pool, q.upsert, id, fmt.Sprintf("user_%d", id), rand.Float64()*200-100)
+	return bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: classify(err)}
+}
+
+// docNoteBytes is the size of the notes value jsonQuery writes, large enough
+// to exercise big bind parameters.
+const docNoteBytes = 2048
+
+// jsonQuery runs one operation of the document workload: 40% containment
+// filters over 100 ids, 40% path reads and 20% partial updates.
+func jsonQuery(ctx context.Context, pool *pgxpool.Pool, q queries, maxID int) bench.QueryResult {
+	qStart := time.Now()
+	id := rand.Intn(maxID) + 1
+
+	switch r := rand.Intn(100); {
+	case r < 40:
+		err := drain(ctx, pool, q.jsonContains, id, id+100, bench.DocumentFilter(rand.Int()))
+		return bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err, Op: "json_contains"}
+	case r < 80:
+		err := drain(ctx, pool, q.jsonPath, id)
+		return bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: err, Op: "json_path"}
+	}
+	note := bench.Payload(rand.Int(), docNoteBytes)
+	err := q.exec(ctx, pool, q.jsonUpdate, note, id)
+	return bench.QueryResult{At: qStart, Duration: time.Since(qStart), Err: classify(err), Op: "json_update", Bytes: len(note)}
+}
+
+// hotTransfer moves delta between two distinct hot rows in one transaction,
+// locking them in random order so concurrent transfers can deadlock. On
+// CockroachDB the whole transaction is rerun when it must restart.
+func hotTransfer(ctx context.Context, pool *pgxpool.Pool, q queries, delta float64) error {
+	a := rand.Intn(q.hotRows) + 1
+	b := (a+rand.Intn(q.hotRows-1))%q.hotRows + 1
+	return q.retry(ctx, func() error {
+		return transfer(ctx, pool, q, delta, a, b)
+	})
+}
+
+// transfer is one attempt of hotTransfer.
+func transfer(ctx context.Context, pool *pgxpool.Pool, q queries, delta float64, a, b int) error {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, q.update, -delta, a); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, q.update, delta, b); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// classify wraps deadlock, lock timeout and serialization errors in their
+// bench sentinels.
+func classify(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+	switch pgErr.Code {
+	case "40P01":
+		return fmt.Errorf("%w: %v", bench.ErrDeadlock, err)
+	case "55P03":
+		return fmt.Errorf("%w: %v", bench.ErrLockTimeout, err)
+	case "40001":
+		return fmt.Errorf("%w: %v", bench.ErrSerialization, err)
+	}
+	return err
+}
+
+// drain runs a query and reads every row of the result.
+func drain(ctx context.Context, pool *pgxpool.Pool, sql string, args ...any) error {
+	rows, err := pool.Query(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+	}
+	rows.Close()
+	return rows.Err()
+}
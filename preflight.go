@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+
+	"tenantsdb-bench/bench"
+	"tenantsdb-bench/ms"
+	"tenantsdb-bench/my"
+	"tenantsdb-bench/pg"
+)
+
+// runPreflight checks every endpoint and tenant the run will use — reachable,
+// benchmark table present and writable, server clock close to ours — plus
+// the open-file headroom, and exits before any measurement if a database
+// cannot be reached.
+func runPreflight(dbType, test string, proxyCfg, directCfg bench.ConnConfig, hasDirect bool, params bench.BenchParams) {
+	var check func(cfg bench.ConnConfig, db string) bench.DatabaseCheck
+	var names func(n int) []string
+	switch dbType {
+	case "postgres", "cockroach":
+		check = func(cfg bench.ConnConfig, db string) bench.DatabaseCheck { return pg.PreflightCheck(cfg, db, params) }
+		names = pg.TenantNames
+	case "mysql":
+		check = func(cfg bench.ConnConfig, db string) bench.DatabaseCheck { return my.PreflightCheck(cfg, db, params) }
+		names = my.TenantNames
+	case "mssql":
+		check = func(cfg bench.ConnConfig, db string) bench.DatabaseCheck { return ms.PreflightCheck(cfg, db, params) }
+		names = ms.TenantNames
+	default:
+		return
+	}
+
+	tenants := params.Tenants
+	if n := tenantsNeeded(test); len(tenants) == 0 && n > 1 {
+		tenants = names(n)
+	}
+	bench.Stepf("Preflight checks...")
+
+	dbs := tenants
+	if len(dbs) == 0 {
+		dbs = []string{""}
+	}
+	failed := bench.PrintPreflight("proxy", bench.CheckDatabases(dbs, func(db string) bench.DatabaseCheck {
+		return check(proxyCfg, db)
+	}))
+	if hasDirect {
+		failed += bench.PrintPreflight("direct", []bench.DatabaseCheck{check(directCfg, "")})
+	}
+
+	need := len(dbs) * 10
+	if params.MaxClientConns > 0 {
+		need = params.MaxClientConns
+	}
+	if hasDirect {
+		need += 10
+	}
+	bench.PreflightFDs(need)
+
+	if failed > 0 {
+		fail("preflight: %d database(s) unreachable (-skip-preflight runs anyway)", failed)
+	}
+	fmt.Println()
+}
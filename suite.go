@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"tenantsdb-bench/bench"
+	"tenantsdb-bench/control"
+)
+
+// suiteTests are the tests -test all runs, in order.
+var suiteTests = []string{"overhead", "throughput", "multi", "isolation", "scale"}
+
+// runSuite runs suiteTests one after another with the same configuration
+// and returns a result holding each of theirs. Overhead is skipped without
+// a direct endpoint; multi and isolation use the first ten of a longer
+// tenant list.
+func runSuite(ctx context.Context, dbType string, proxyCfg, directCfg bench.ConnConfig, hasDirect bool, params bench.BenchParams, api *control.Client) *bench.Result {
+	suite := &bench.Result{}
+	for i, test := range suiteTests {
+		if ctx.Err() != nil {
+			break
+		}
+		bench.Stepf("Suite %d/%d: %s", i+1, len(suiteTests), test)
+		if test == "overhead" && !hasDirect {
+			bench.Warnf("Skipped: needs -direct-* flags")
+			continue
+		}
+		p := params
+		if (test == "multi" || test == "isolation") && len(p.Tenants) > 10 {
+			p.Tenants = p.Tenants[:10]
+		}
+		started := time.Now()
+		res := runTest(ctx, dbType, test, proxyCfg, directCfg, p, api)
+		if res == nil {
+			bench.Failf("%s did not complete", test)
+			continue
+		}
+		res.DB, res.Test, res.Started = dbType, test, started
+		suite.Suite = append(suite.Suite, res)
+		if v := res.Manifest.ProxyVersion; v != "" {
+			suite.Manifest.ProxyVersion = v
+		}
+		if v := res.Manifest.BackendVersion; v != "" {
+			suite.Manifest.BackendVersion = v
+		}
+	}
+	bench.PrintSuite(suite.Suite)
+	return suite
+}
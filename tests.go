@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"tenantsdb-bench/bench"
+	"tenantsdb-bench/control"
+	"tenantsdb-bench/ms"
+	"tenantsdb-bench/my"
+	"tenantsdb-bench/pg"
+)
+
+// driver returns the test driver for dbType.
+func driver(dbType string, api *control.Client) bench.Driver {
+	switch dbType {
+	case "postgres", "cockroach":
+		return pg.Driver{API: api}
+	case "mysql":
+		return my.Driver{API: api}
+	case "mssql":
+		return ms.Driver{}
+	}
+	fail("database type '%s' not yet implemented", dbType)
+	return nil
+}
+
+// runTest runs one -test against dbType and returns its result (nil if the
+// runner stopped early).
+func runTest(ctx context.Context, dbType, test string, proxyCfg, directCfg bench.ConnConfig, params bench.BenchParams, api *control.Client) *bench.Result {
+	res, err := driver(dbType, api).Run(ctx, test, proxyCfg, directCfg, params)
+	if errors.Is(err, bench.ErrUnknownTest) {
+		fail("%v", err)
+	}
+	return res
+}